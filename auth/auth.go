@@ -0,0 +1,253 @@
+// Package auth issues and verifies bearer tickets used to authenticate API
+// requests. Tickets are signed with a server ed25519 key (generated on
+// first use and persisted in confDir) and carry coarse scopes rather than
+// per-endpoint permissions.
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Scope is a coarse permission a Ticket may carry.
+type Scope string
+
+const (
+	ScopeSubscriptionsRead  Scope = "subscriptions:read"
+	ScopeSubscriptionsWrite Scope = "subscriptions:write"
+	ScopeConfigWrite        Scope = "config:write"
+
+	// ScopeAdmin implies every other scope.
+	ScopeAdmin Scope = "admin"
+)
+
+// Ticket is the decoded, authenticated claims carried by a bearer token.
+type Ticket struct {
+	// ID is the hex-encoded nonce. It is also the key used to revoke the
+	// ticket via DELETE /api/auth/tickets/{id}.
+	ID        string
+	Subject   string
+	Scopes    []string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// Has reports whether the ticket carries scope. ScopeAdmin satisfies every
+// scope check.
+func (t Ticket) Has(scope Scope) bool {
+	for _, s := range t.Scopes {
+		if Scope(s) == scope || Scope(s) == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Signer issues and verifies tickets using a server ed25519 key persisted in
+// confDir. The key is generated on first use.
+type Signer struct {
+	priv ed25519.PrivateKey
+	pub  ed25519.PublicKey
+}
+
+const keyFile = "auth_key"
+
+// LoadSigner reads the server signing key from confDir/auth_key, generating
+// and persisting a new ed25519 key pair if none exists yet.
+func LoadSigner(confDir string) (*Signer, error) {
+	path := filepath.Join(confDir, keyFile)
+
+	raw, err := os.ReadFile(path)
+	if err == nil {
+		if len(raw) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("auth: %s has the wrong length for an ed25519 key", path)
+		}
+		priv := ed25519.PrivateKey(raw)
+		return &Signer{priv: priv, pub: priv.Public().(ed25519.PublicKey)}, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(confDir, 0o755); err != nil {
+		return nil, err
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, priv, 0o600); err != nil {
+		return nil, err
+	}
+	return &Signer{priv: priv, pub: pub}, nil
+}
+
+// Issue mints a new ticket for subject with scopes, valid for ttl, and
+// returns both the opaque bearer token and its decoded claims.
+func (s *Signer) Issue(subject string, scopes []string, ttl time.Duration) (token string, claims Ticket, err error) {
+	var nonce [16]byte
+	if _, err = rand.Read(nonce[:]); err != nil {
+		return "", Ticket{}, err
+	}
+
+	now := time.Now().UTC()
+	claims = Ticket{
+		ID:        hex.EncodeToString(nonce[:]),
+		Subject:   subject,
+		Scopes:    scopes,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	body := encode(nonce, claims)
+	sig := ed25519.Sign(s.priv, body)
+	token = base64.RawURLEncoding.EncodeToString(append(body, sig...))
+	return token, claims, nil
+}
+
+// Verify decodes and authenticates token, returning its claims. It checks
+// the signature and expiry only — callers are responsible for consulting a
+// revocation list (see store.Store's Revoke/IsTicketRevoked).
+func (s *Signer) Verify(token string) (Ticket, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Ticket{}, errors.New("auth: malformed ticket")
+	}
+	if len(raw) <= ed25519.SignatureSize {
+		return Ticket{}, errors.New("auth: truncated ticket")
+	}
+
+	body, sig := raw[:len(raw)-ed25519.SignatureSize], raw[len(raw)-ed25519.SignatureSize:]
+	if !ed25519.Verify(s.pub, body, sig) {
+		return Ticket{}, errors.New("auth: invalid signature")
+	}
+
+	claims, err := decode(body)
+	if err != nil {
+		return Ticket{}, err
+	}
+	if time.Now().After(claims.ExpiresAt) {
+		return Ticket{}, errors.New("auth: ticket expired")
+	}
+	return claims, nil
+}
+
+// ---- BARE-style binary encoding ----
+//
+// The ticket body (everything the signature covers) is laid out as:
+//
+//	string   subject     (uvarint length prefix + UTF-8 bytes)
+//	uint     scope count  (uvarint)
+//	string   scopes[i]    (repeated, same framing as subject)
+//	int64    issued_at    (8 bytes, big-endian, Unix seconds)
+//	int64    expires_at   (8 bytes, big-endian, Unix seconds)
+//	byte[16] nonce
+//
+// This is hand-rolled rather than pulled from a generic BARE library —
+// the schema is fixed and small enough that a dependency would buy nothing.
+
+func encode(nonce [16]byte, t Ticket) []byte {
+	var buf []byte
+	buf = appendString(buf, t.Subject)
+	buf = appendUvarint(buf, uint64(len(t.Scopes)))
+	for _, scope := range t.Scopes {
+		buf = appendString(buf, scope)
+	}
+	buf = appendInt64(buf, t.IssuedAt.Unix())
+	buf = appendInt64(buf, t.ExpiresAt.Unix())
+	buf = append(buf, nonce[:]...)
+	return buf
+}
+
+func decode(buf []byte) (Ticket, error) {
+	subject, buf, err := readString(buf)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	count, buf, err := readUvarint(buf)
+	if err != nil {
+		return Ticket{}, err
+	}
+	scopes := make([]string, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var scope string
+		scope, buf, err = readString(buf)
+		if err != nil {
+			return Ticket{}, err
+		}
+		scopes = append(scopes, scope)
+	}
+
+	issuedUnix, buf, err := readInt64(buf)
+	if err != nil {
+		return Ticket{}, err
+	}
+	expiresUnix, buf, err := readInt64(buf)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	if len(buf) != 16 {
+		return Ticket{}, errors.New("auth: malformed ticket body")
+	}
+
+	return Ticket{
+		ID:        hex.EncodeToString(buf),
+		Subject:   subject,
+		Scopes:    scopes,
+		IssuedAt:  time.Unix(issuedUnix, 0).UTC(),
+		ExpiresAt: time.Unix(expiresUnix, 0).UTC(),
+	}, nil
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = appendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func readString(buf []byte) (string, []byte, error) {
+	n, rest, err := readUvarint(buf)
+	if err != nil {
+		return "", nil, err
+	}
+	if uint64(len(rest)) < n {
+		return "", nil, errors.New("auth: malformed ticket body")
+	}
+	return string(rest[:n]), rest[n:], nil
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+func readUvarint(buf []byte) (uint64, []byte, error) {
+	v, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return 0, nil, errors.New("auth: malformed ticket body")
+	}
+	return v, buf[n:], nil
+}
+
+func appendInt64(buf []byte, v int64) []byte {
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], uint64(v))
+	return append(buf, tmp[:]...)
+}
+
+func readInt64(buf []byte) (int64, []byte, error) {
+	if len(buf) < 8 {
+		return 0, nil, errors.New("auth: malformed ticket body")
+	}
+	return int64(binary.BigEndian.Uint64(buf[:8])), buf[8:], nil
+}
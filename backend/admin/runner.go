@@ -0,0 +1,87 @@
+// Package admin provides a small harness for long-running, operator-initiated
+// operations (bulk source imports, overseer_task_id backfills, subscription
+// migrations, ...) backed by store.AdminTask, so they can be observed and
+// resumed from an operations panel instead of running as silent goroutines.
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
+)
+
+// Func is the work performed by a Runner. It should call Task.Report
+// periodically to record progress, and return an error to fail the task.
+type Func func(ctx context.Context, task *Task) error
+
+// Task is handed to a running Func so it can report progress against the
+// store.AdminTask it's backed by.
+type Task struct {
+	*store.AdminTask
+
+	st store.Store
+}
+
+// Report persists progress and a human-readable status message.
+func (t *Task) Report(ctx context.Context, progress int, message string) error {
+	if err := t.st.UpdateAdminTaskProgress(ctx, t.ID, progress, message); err != nil {
+		return err
+	}
+	t.Progress = progress
+	t.Message = message
+	return nil
+}
+
+// Runner starts Funcs against freshly created store.AdminTasks, catching
+// panics and persisting the final status.
+type Runner struct {
+	Store store.Store
+}
+
+// NewRunner returns a Runner backed by st.
+func NewRunner(st store.Store) *Runner {
+	return &Runner{Store: st}
+}
+
+// Start creates an admin_tasks row for kind/doerID/payload/total and runs fn
+// in a new goroutine, reporting its outcome back to the store once it
+// returns. It returns the created task immediately; callers poll
+// store.GetAdminTask (or ListAdminTasks) for progress rather than blocking
+// on Start.
+func (r *Runner) Start(ctx context.Context, kind string, doerID int64, payload json.RawMessage, total int, fn Func) (*store.AdminTask, error) {
+	at, err := r.Store.CreateAdminTask(ctx, kind, doerID, payload, total)
+	if err != nil {
+		return nil, err
+	}
+
+	go r.run(at, fn)
+	return at, nil
+}
+
+// run drives fn to completion, independent of the context Start was called
+// with (that context belongs to the request that kicked the task off, and
+// is typically cancelled well before the task finishes).
+func (r *Runner) run(at *store.AdminTask, fn Func) {
+	ctx := context.Background()
+	task := &Task{AdminTask: at, st: r.Store}
+
+	runErr := func() (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("admin task %s panicked: %v", at.ID, p)
+			}
+		}()
+		return fn(ctx, task)
+	}()
+
+	status := store.AdminTaskSucceeded
+	if runErr != nil {
+		status = store.AdminTaskFailed
+	}
+	if err := r.Store.MarkAdminTaskDone(ctx, at.ID, status, runErr); err != nil {
+		log.Printf("admin: mark task %s done: %v", at.ID, err)
+	}
+}
@@ -3,14 +3,19 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -25,15 +30,22 @@ var accessTokenTTL = func() time.Duration {
 	return time.Hour
 }()
 
-// Claims is the JWT payload.
+// Claims is the JWT payload. A token is either a user session token
+// (SessionID/Role set, ClientID empty) issued by IssueAccessToken, or an
+// OAuth2 app access token (ClientID/Scope set, SessionID the zero value)
+// issued by IssueAppAccessToken; middleware.RequireAuth injects whichever
+// fields are present into the request context.
 type Claims struct {
 	jwt.RegisteredClaims
-	SessionID uuid.UUID `json:"sid"`
-	Role      string    `json:"role"`
+	SessionID uuid.UUID `json:"sid,omitempty"`
+	Role      string    `json:"role,omitempty"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Scope     string    `json:"scope,omitempty"`
 }
 
-// IssueAccessToken creates a signed HS256 JWT for the given user/session.
-func IssueAccessToken(secret []byte, userID int64, sessionID uuid.UUID, role string) (string, error) {
+// IssueAccessToken creates a signed JWT for the given user/session, using
+// keys' current signing key and stamping its kid into the token header.
+func IssueAccessToken(keys *KeySet, userID int64, sessionID uuid.UUID, role string) (string, error) {
 	now := time.Now()
 	claims := Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
@@ -44,17 +56,54 @@ func IssueAccessToken(secret []byte, userID int64, sessionID uuid.UUID, role str
 		SessionID: sessionID,
 		Role:      role,
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secret)
+	return sign(keys, claims)
 }
 
-// ParseAccessToken validates the token signature and expiry, returning the claims.
-func ParseAccessToken(secret []byte, raw string) (*Claims, error) {
+// IssueAppAccessToken creates a signed JWT for an OAuth2 app acting on
+// behalf of userID, scoped to scope (a space-separated list of granted
+// scopes, e.g. "subscriptions:read files:read"). middleware.RequireScope
+// reads Scope back off the parsed claims to enforce it per route.
+func IssueAppAccessToken(keys *KeySet, userID int64, clientID, scope string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   fmt.Sprintf("%d", userID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(accessTokenTTL)),
+		},
+		ClientID: clientID,
+		Scope:    scope,
+	}
+	return sign(keys, claims)
+}
+
+// sign signs claims with keys' current signing key, stamping its kid into
+// the JWT header so ParseAccessToken can look up the matching verify key.
+func sign(keys *KeySet, claims Claims) (string, error) {
+	key, err := keys.current()
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.signingMethod(), claims)
+	token.Header["kid"] = key.KID
+	return token.SignedString(key.Private)
+}
+
+// ParseAccessToken validates the token signature and expiry, returning the
+// claims. The verifying key is looked up by the token's kid header; a kid
+// unknown to keys (never issued by it, or aged out of its rotation grace
+// window) is rejected, as is an algorithm mismatch against that key.
+func ParseAccessToken(keys *KeySet, raw string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(raw, &Claims{}, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := keys.byKID(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown or retired key id %q", kid)
+		}
+		if key.signingMethod().Alg() != t.Method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
-		return secret, nil
+		return key.Public, nil
 	})
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -69,20 +118,154 @@ func ParseAccessToken(secret []byte, raw string) (*Claims, error) {
 	return claims, nil
 }
 
-// HashPassword returns a bcrypt hash of the password.
+// Argon2id parameters for HashPassword. Changing these doesn't invalidate
+// existing hashes (the encoded string carries its own params), but it does
+// mean NeedsRehash starts reporting true for them, so updates flow through
+// on next login.
+const (
+	argon2Time      uint32 = 3
+	argon2MemoryKiB uint32 = 64 * 1024
+	argon2Threads   uint8  = 2
+	argon2SaltLen          = 16
+	argon2KeyLen           = 32
+)
+
+// HashPassword returns an Argon2id hash of password, PHC-string encoded as
+// $argon2id$v=<version>$m=<memory>,t=<time>,p=<threads>$<salt>$<key>, both
+// salt and key base64 raw-std encoded.
 func HashPassword(password string) (string, error) {
-	b, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
 		return "", err
 	}
-	return string(b), nil
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2MemoryKiB, argon2Threads, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argon2MemoryKiB, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
 }
 
-// CheckPassword reports whether password matches the bcrypt hash.
+// CheckPassword reports whether password matches hash. hash may be either
+// the current Argon2id encoding or a legacy bcrypt hash — existing rows
+// keep authenticating until NeedsRehash triggers a login-time upgrade.
 func CheckPassword(hash, password string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		memory, t, threads, salt, key, err := parseArgon2idHash(hash)
+		if err != nil {
+			return false
+		}
+		computed := argon2.IDKey([]byte(password), salt, t, memory, threads, uint32(len(key)))
+		return subtle.ConstantTimeCompare(computed, key) == 1
+	}
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
 }
 
+// NeedsRehash reports whether hash should be upgraded to the current
+// Argon2id parameters — true for any non-Argon2id hash (e.g. legacy bcrypt)
+// and for Argon2id hashes encoded with weaker-than-current parameters, so a
+// policy tightening (e.g. raising argon2MemoryKiB) also migrates old rows.
+func NeedsRehash(hash string) bool {
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		return true
+	}
+	memory, t, threads, _, _, err := parseArgon2idHash(hash)
+	if err != nil {
+		return true
+	}
+	return memory < argon2MemoryKiB || t < argon2Time || threads < argon2Threads
+}
+
+// parseArgon2idHash splits an encoded $argon2id$... hash into its
+// parameters, salt, and key.
+func parseArgon2idHash(encoded string) (memory, time_ uint32, threads uint8, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return 0, 0, 0, nil, nil, errors.New("auth: malformed argon2id hash")
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return 0, 0, 0, nil, nil, fmt.Errorf("auth: malformed argon2id version: %w", err)
+	}
+	var m, t, p int
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &m, &t, &p); err != nil {
+		return 0, 0, 0, nil, nil, fmt.Errorf("auth: malformed argon2id params: %w", err)
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return 0, 0, 0, nil, nil, fmt.Errorf("auth: malformed argon2id salt: %w", err)
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return 0, 0, 0, nil, nil, fmt.Errorf("auth: malformed argon2id key: %w", err)
+	}
+	return uint32(m), uint32(t), uint8(p), salt, key, nil
+}
+
+// PasswordPolicyViolation is one way a password failed PasswordPolicy.Check.
+// Code is a stable machine-readable token; router handlers translate
+// violations into their own field-level validation error type rather than
+// this package depending on router's.
+type PasswordPolicyViolation struct {
+	Code    string
+	Message string
+}
+
+// PasswordPolicy enforces minimum length/complexity before a password is
+// hashed. DefaultPasswordPolicy is what createUser/updateUser apply.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPasswordPolicy is applied by createUser/updateUser.
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:    8,
+	RequireUpper: true,
+	RequireLower: true,
+	RequireDigit: true,
+}
+
+// Check reports every rule password fails, in a stable order, so a caller
+// can surface them all at once instead of one round trip per violation.
+func (p PasswordPolicy) Check(password string) []PasswordPolicyViolation {
+	var violations []PasswordPolicyViolation
+	if len(password) < p.MinLength {
+		violations = append(violations, PasswordPolicyViolation{
+			Code:    "too_short",
+			Message: fmt.Sprintf("must be at least %d characters", p.MinLength),
+		})
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, PasswordPolicyViolation{Code: "missing_uppercase", Message: "must contain an uppercase letter"})
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, PasswordPolicyViolation{Code: "missing_lowercase", Message: "must contain a lowercase letter"})
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, PasswordPolicyViolation{Code: "missing_digit", Message: "must contain a digit"})
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, PasswordPolicyViolation{Code: "missing_symbol", Message: "must contain a symbol"})
+	}
+	return violations
+}
+
 // GenerateRefreshToken returns a cryptographically random 32-byte base64 string.
 func GenerateRefreshToken() (string, error) {
 	b := make([]byte, 32)
@@ -91,3 +274,38 @@ func GenerateRefreshToken() (string, error) {
 	}
 	return base64.RawURLEncoding.EncodeToString(b), nil
 }
+
+// GenerateClientID returns a random, URL-safe identifier for a new OAuth2
+// app — public, logged in plaintext alongside the app's other metadata.
+func GenerateClientID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateClientSecret returns a random OAuth2 client secret. Only its hash
+// (via HashPassword) is persisted; the plaintext is shown to the caller once,
+// at registration time, exactly like a user password.
+func GenerateClientSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// VerifyPKCE reports whether verifier hashes to challenge under method
+// ("S256" or "plain"), per RFC 7636. Unknown methods always fail closed.
+func VerifyPKCE(method, challenge, verifier string) bool {
+	switch method {
+	case "plain":
+		return verifier == challenge
+	case "S256":
+		sum := sha256.Sum256([]byte(verifier))
+		return base64.RawURLEncoding.EncodeToString(sum[:]) == challenge
+	default:
+		return false
+	}
+}
@@ -0,0 +1,406 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AccessTokenTTL returns the configured access token lifetime (see
+// ACCESS_TOKEN_TTL in auth.go), exported so callers sizing a key rotation grace
+// window — which must outlive any token signed just before the rotation —
+// don't have to duplicate the env var parsing.
+func AccessTokenTTL() time.Duration { return accessTokenTTL }
+
+// Key is one signing/verification keypair in a KeySet. Private and Public
+// hold []byte for HS256 (the same secret in both fields), *rsa.PrivateKey /
+// *rsa.PublicKey for RS256, or ed25519.PrivateKey / ed25519.PublicKey for
+// EdDSA.
+type Key struct {
+	KID       string
+	Algorithm string // "HS256", "RS256", or "EdDSA"
+	Private   any
+	Public    any
+	NotBefore time.Time
+	// NotAfter is zero while the key is eligible to sign new tokens. Rotate
+	// sets it on the keys it demotes, after which they verify-only until it
+	// passes, after which byKID no longer finds them and the next Rotate
+	// drops them entirely.
+	NotAfter time.Time
+}
+
+func (k Key) signingMethod() jwt.SigningMethod {
+	switch k.Algorithm {
+	case "RS256":
+		return jwt.SigningMethodRS256
+	case "EdDSA":
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// active reports whether k may still be used to sign new tokens.
+func (k Key) active(now time.Time) bool {
+	return !now.Before(k.NotBefore) && (k.NotAfter.IsZero() || now.Before(k.NotAfter))
+}
+
+// KeySet is an ordered, newest-first set of signing/verification keys. It
+// replaces a single shared HS256 secret so tokens can be verified by
+// downstream services (thumbnailer, future workers) without giving them
+// anything that could sign a forged token, and so the signing key can be
+// rotated without a hard cutover.
+//
+// A KeySet with no backing directory (dir == "") is in-memory only — see
+// NewHMACKeySet, used when AUTH_KEYS_DIR isn't set. One backed by a
+// directory (see LoadKeySet) persists every Rotate atomically and can be
+// refreshed from disk via Reload, intended to be wired to SIGHUP.
+type KeySet struct {
+	mu   sync.RWMutex
+	dir  string
+	keys []Key // newest NotBefore first
+}
+
+// NewHMACKeySet wraps a single HS256 secret in a KeySet with no persistence
+// directory, preserving pre-rotation behavior for deployments that haven't
+// set AUTH_KEYS_DIR.
+func NewHMACKeySet(secret []byte) *KeySet {
+	return &KeySet{keys: []Key{{
+		KID:       "legacy-hmac",
+		Algorithm: "HS256",
+		Private:   secret,
+		Public:    secret,
+	}}}
+}
+
+// LoadKeySet reads every key file in dir and returns a KeySet backed by it.
+// A missing directory is not an error — Empty() will report true, and the
+// caller (main.go) is expected to call Rotate to seed it.
+func LoadKeySet(dir string) (*KeySet, error) {
+	ks := &KeySet{dir: dir}
+	if err := ks.Reload(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Empty reports whether the set has no keys at all, i.e. a fresh
+// AUTH_KEYS_DIR that hasn't been seeded with an initial Rotate yet.
+func (ks *KeySet) Empty() bool {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return len(ks.keys) == 0
+}
+
+// current returns the newest signing-eligible key.
+func (ks *KeySet) current() (Key, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	now := time.Now()
+	for _, k := range ks.keys {
+		if k.active(now) {
+			return k, nil
+		}
+	}
+	return Key{}, errors.New("auth: no active signing key")
+}
+
+// byKID looks up a key regardless of whether it's still signing-eligible,
+// so a token signed moments before a rotation keeps validating through its
+// own expiry. A kid that isn't found at all — because it was never issued
+// by this KeySet or has already aged out of the grace window and been
+// dropped — is rejected, satisfying ParseAccessToken's "reject retired kid"
+// requirement without a separate retired-but-present state to track.
+func (ks *KeySet) byKID(kid string) (Key, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	now := time.Now()
+	for _, k := range ks.keys {
+		if k.KID == kid {
+			if !k.NotAfter.IsZero() && now.After(k.NotAfter) {
+				return Key{}, false // grace window elapsed, not yet dropped
+			}
+			return k, true
+		}
+	}
+	return Key{}, false
+}
+
+// Rotate generates a new EdDSA keypair, promotes it to the signing key,
+// demotes every previously-active key to verify-only for a grace window of
+// dur (callers pass AccessTokenTTL so a token signed moments before
+// rotation stays valid through its own expiry), drops any key whose grace
+// window has already elapsed, and persists the resulting set atomically.
+func (ks *KeySet) Rotate(dur time.Duration) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("auth: generate keypair: %w", err)
+	}
+	kid, err := randomKID()
+	if err != nil {
+		return fmt.Errorf("auth: generate kid: %w", err)
+	}
+
+	now := time.Now()
+	newKey := Key{KID: kid, Algorithm: "EdDSA", Private: priv, Public: pub, NotBefore: now}
+
+	kept := ks.keys[:0:0]
+	for _, k := range ks.keys {
+		if !k.NotAfter.IsZero() && !now.Before(k.NotAfter) {
+			continue // grace window already elapsed — drop entirely
+		}
+		if k.NotAfter.IsZero() {
+			k.NotAfter = now.Add(dur)
+		}
+		kept = append(kept, k)
+	}
+	ks.keys = append([]Key{newKey}, kept...)
+
+	if ks.dir == "" {
+		return nil
+	}
+	return ks.persistLocked()
+}
+
+// Reload re-reads the backing directory, replacing the in-memory key list.
+// It's a no-op for an in-memory (NewHMACKeySet) KeySet. Intended to be
+// called from a SIGHUP handler after an operator has dropped in or removed
+// key files out-of-band.
+func (ks *KeySet) Reload() error {
+	if ks.dir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			ks.mu.Lock()
+			ks.keys = nil
+			ks.mu.Unlock()
+			return nil
+		}
+		return fmt.Errorf("auth: read keys dir %s: %w", ks.dir, err)
+	}
+
+	var keys []Key
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(ks.dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("auth: read key file %s: %w", e.Name(), err)
+		}
+		var kf keyFile
+		if err := json.Unmarshal(b, &kf); err != nil {
+			return fmt.Errorf("auth: parse key file %s: %w", e.Name(), err)
+		}
+		k, err := decodeKey(kf)
+		if err != nil {
+			return fmt.Errorf("auth: decode key file %s: %w", e.Name(), err)
+		}
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].NotBefore.After(keys[j].NotBefore) })
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+	return nil
+}
+
+// JWK is one entry of a JSON Web Key Set, RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// JWKS is the response body of GET /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS renders every currently-valid verification key (signing key plus any
+// still inside their post-rotation grace window) as a JSON Web Key Set.
+// HS256 keys are never included — publishing a symmetric secret would let
+// anyone forge tokens, defeating the point of asymmetric rotation.
+func (ks *KeySet) JWKS() JWKS {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	now := time.Now()
+	out := JWKS{Keys: []JWK{}}
+	for _, k := range ks.keys {
+		if !k.NotAfter.IsZero() && now.After(k.NotAfter) {
+			continue
+		}
+		jwk := JWK{Kid: k.KID, Use: "sig"}
+		switch pub := k.Public.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty, jwk.Crv, jwk.Alg = "OKP", "Ed25519", "EdDSA"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		case *rsa.PublicKey:
+			jwk.Kty, jwk.Alg = "RSA", "RS256"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		default:
+			continue // HS256 (or anything else unpublishable) — skip
+		}
+		out.Keys = append(out.Keys, jwk)
+	}
+	return out
+}
+
+// keyFile is the on-disk encoding of a Key, one per "<kid>.json" file in a
+// KeySet's directory.
+type keyFile struct {
+	KID        string     `json:"kid"`
+	Algorithm  string     `json:"algorithm"`
+	PrivatePEM string     `json:"private_pem,omitempty"`
+	PublicPEM  string     `json:"public_pem,omitempty"`
+	Secret     string     `json:"secret_base64,omitempty"` // HS256 only
+	NotBefore  time.Time  `json:"not_before"`
+	NotAfter   *time.Time `json:"not_after,omitempty"`
+}
+
+func encodeKey(k Key) (keyFile, error) {
+	kf := keyFile{KID: k.KID, Algorithm: k.Algorithm, NotBefore: k.NotBefore}
+	if !k.NotAfter.IsZero() {
+		na := k.NotAfter
+		kf.NotAfter = &na
+	}
+
+	if k.Algorithm == "HS256" {
+		secret, _ := k.Private.([]byte)
+		kf.Secret = base64.StdEncoding.EncodeToString(secret)
+		return kf, nil
+	}
+
+	privDER, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return keyFile{}, fmt.Errorf("marshal private key: %w", err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(k.Public)
+	if err != nil {
+		return keyFile{}, fmt.Errorf("marshal public key: %w", err)
+	}
+	kf.PrivatePEM = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}))
+	kf.PublicPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+	return kf, nil
+}
+
+func decodeKey(kf keyFile) (Key, error) {
+	k := Key{KID: kf.KID, Algorithm: kf.Algorithm, NotBefore: kf.NotBefore}
+	if kf.NotAfter != nil {
+		k.NotAfter = *kf.NotAfter
+	}
+
+	if kf.Algorithm == "HS256" {
+		secret, err := base64.StdEncoding.DecodeString(kf.Secret)
+		if err != nil {
+			return Key{}, fmt.Errorf("decode secret: %w", err)
+		}
+		k.Private, k.Public = secret, secret
+		return k, nil
+	}
+
+	privBlock, _ := pem.Decode([]byte(kf.PrivatePEM))
+	if privBlock == nil {
+		return Key{}, errors.New("no PEM block in private_pem")
+	}
+	priv, err := x509.ParsePKCS8PrivateKey(privBlock.Bytes)
+	if err != nil {
+		return Key{}, fmt.Errorf("parse private key: %w", err)
+	}
+	pubBlock, _ := pem.Decode([]byte(kf.PublicPEM))
+	if pubBlock == nil {
+		return Key{}, errors.New("no PEM block in public_pem")
+	}
+	pub, err := x509.ParsePKIXPublicKey(pubBlock.Bytes)
+	if err != nil {
+		return Key{}, fmt.Errorf("parse public key: %w", err)
+	}
+	k.Private, k.Public = priv, pub
+	return k, nil
+}
+
+// persistLocked writes every key in ks.keys to its own "<kid>.json.tmp" file
+// and renames it into place, then removes any stale "<kid>.json" files left
+// over from keys that have since aged out. Callers must hold ks.mu.
+func (ks *KeySet) persistLocked() error {
+	if err := os.MkdirAll(ks.dir, 0o700); err != nil {
+		return fmt.Errorf("auth: create keys dir %s: %w", ks.dir, err)
+	}
+
+	keep := make(map[string]bool, len(ks.keys))
+	for _, k := range ks.keys {
+		keep[k.KID] = true
+		kf, err := encodeKey(k)
+		if err != nil {
+			return fmt.Errorf("auth: encode key %s: %w", k.KID, err)
+		}
+		b, err := json.MarshalIndent(kf, "", "  ")
+		if err != nil {
+			return fmt.Errorf("auth: marshal key %s: %w", k.KID, err)
+		}
+		path := filepath.Join(ks.dir, k.KID+".json")
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, b, 0o600); err != nil {
+			return fmt.Errorf("auth: write key file %s: %w", tmp, err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return fmt.Errorf("auth: rename key file %s: %w", tmp, err)
+		}
+	}
+
+	entries, err := os.ReadDir(ks.dir)
+	if err != nil {
+		return fmt.Errorf("auth: read keys dir %s: %w", ks.dir, err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		if keep[strings.TrimSuffix(e.Name(), ".json")] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(ks.dir, e.Name())); err != nil {
+			log.Printf("auth: remove retired key file %s: %v", e.Name(), err)
+		}
+	}
+	return nil
+}
+
+// randomKID returns a random, URL-safe key id.
+func randomKID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/store/postgres"
+)
+
+// bootstrapAdmin seeds an initial admin user when INIT_CREATE_ADMIN=true.
+// It defers entirely to postgres.DB.SeedAdminUser, which is itself a no-op
+// once the users table is non-empty, so re-running initdb against an
+// already-seeded deployment is safe.
+func bootstrapAdmin(ctx context.Context, dbDSN string) error {
+	create, _ := strconv.ParseBool(os.Getenv("INIT_CREATE_ADMIN"))
+	if !create {
+		return nil
+	}
+
+	username := os.Getenv("INIT_ADMIN_USERNAME")
+	password := os.Getenv("INIT_ADMIN_PASSWORD")
+	if username == "" || password == "" {
+		return fmt.Errorf("INIT_CREATE_ADMIN=true requires INIT_ADMIN_USERNAME and INIT_ADMIN_PASSWORD")
+	}
+	if os.Getenv("INIT_ADMIN_EMAIL") != "" {
+		log.Println("initdb: INIT_ADMIN_EMAIL is set but store.User has no email field yet — ignoring")
+	}
+
+	db, err := postgres.Open(ctx, dbDSN)
+	if err != nil {
+		return fmt.Errorf("connect for admin bootstrap: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.SeedAdminUser(ctx, username, password); err != nil {
+		return fmt.Errorf("seed admin user: %w", err)
+	}
+	log.Println("initdb: admin bootstrap checked (no-op if the users table was already non-empty)")
+
+	// Don't leave the password sitting in this process's environment any
+	// longer than it has to.
+	os.Unsetenv("INIT_ADMIN_PASSWORD")
+	return nil
+}
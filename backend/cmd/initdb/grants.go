@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Grant describes one privilege grant to the app role, parsed from
+// INITDB_GRANTS (a JSON array) or, if that's unset, a file named by
+// INITDB_GRANTS_FILE containing the same JSON. If neither is set, ensureDB
+// falls back to the historical GRANT ALL PRIVILEGES ON DATABASE +
+// GRANT ALL ON SCHEMA public.
+type Grant struct {
+	// Object is "SCHEMA", "TABLE", "SEQUENCE", or "DATABASE".
+	Object string `json:"object"`
+
+	// Name is the target identifier: a bare name for SCHEMA/DATABASE, a
+	// (possibly schema-qualified) name for TABLE/SEQUENCE, or
+	// "ALL IN SCHEMA <schema>" to grant on every existing TABLE/SEQUENCE in
+	// that schema.
+	Name string `json:"name"`
+
+	// Privileges must each appear in privilegeWhitelist.
+	Privileges []string `json:"privileges"`
+
+	WithGrantOption bool `json:"withGrantOption,omitempty"`
+}
+
+// privilegeWhitelist bounds what loadGrants will accept, so INITDB_GRANTS
+// can only ever produce a GRANT of an actual PostgreSQL privilege — never
+// arbitrary SQL.
+var privilegeWhitelist = map[string]bool{
+	"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true,
+	"TRUNCATE": true, "REFERENCES": true, "TRIGGER": true,
+	"USAGE": true, "CREATE": true, "CONNECT": true, "TEMPORARY": true,
+	"TEMP": true, "EXECUTE": true, "ALL": true, "ALL PRIVILEGES": true,
+}
+
+// sequencePrivileges is the subset of privilegeWhitelist PostgreSQL accepts
+// on sequences; used to filter a SCHEMA grant's privilege list down to
+// something `ALTER DEFAULT PRIVILEGES ... ON SEQUENCES` will accept.
+var sequencePrivileges = map[string]bool{
+	"SELECT": true, "UPDATE": true, "USAGE": true, "ALL": true, "ALL PRIVILEGES": true,
+}
+
+// loadGrants parses INITDB_GRANTS (or the file named by INITDB_GRANTS_FILE)
+// into a list of Grants, validating object kind and privilege names.
+// Returns (nil, nil) if neither env var is set.
+func loadGrants() ([]Grant, error) {
+	raw := os.Getenv("INITDB_GRANTS")
+	if raw == "" {
+		if path := os.Getenv("INITDB_GRANTS_FILE"); path != "" {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", path, err)
+			}
+			raw = string(b)
+		}
+	}
+	if raw == "" {
+		return nil, nil
+	}
+
+	var grants []Grant
+	if err := json.Unmarshal([]byte(raw), &grants); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	for i, g := range grants {
+		switch g.Object {
+		case "SCHEMA", "TABLE", "SEQUENCE", "DATABASE":
+		default:
+			return nil, fmt.Errorf("grant %d: unsupported object %q", i, g.Object)
+		}
+		if g.Name == "" {
+			return nil, fmt.Errorf("grant %d: name is required", i)
+		}
+		if len(g.Privileges) == 0 {
+			return nil, fmt.Errorf("grant %d: privileges is required", i)
+		}
+		for _, p := range g.Privileges {
+			if !privilegeWhitelist[strings.ToUpper(p)] {
+				return nil, fmt.Errorf("grant %d: privilege %q is not allowed", i, p)
+			}
+		}
+	}
+	return grants, nil
+}
+
+// pgExecer is the subset of *pgx.Conn applyGrants needs; satisfied by both
+// the maintenance-database and app-database connections ensureDB opens.
+type pgExecer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// applyGrants emits the GRANT (and, for SCHEMA grants, ALTER DEFAULT
+// PRIVILEGES) statements for each entry. DATABASE-object grants run against
+// conn, the maintenance-database connection — DATABASE is a cluster-wide
+// object, so it doesn't matter which database the session is in — while
+// SCHEMA/TABLE/SEQUENCE grants run against appConn, which must already be
+// connected to the target database.
+func applyGrants(ctx context.Context, conn, appConn pgExecer, appUser string, grants []Grant) error {
+	role := quoteIdent(appUser)
+
+	for _, g := range grants {
+		privs := strings.Join(g.Privileges, ", ")
+		withGrant := ""
+		if g.WithGrantOption {
+			withGrant = " WITH GRANT OPTION"
+		}
+
+		switch g.Object {
+		case "DATABASE":
+			stmt := fmt.Sprintf("GRANT %s ON DATABASE %s TO %s%s", privs, quoteIdent(g.Name), role, withGrant)
+			if _, err := conn.Exec(ctx, stmt); err != nil {
+				return fmt.Errorf("grant on database %q: %w", g.Name, err)
+			}
+
+		case "SCHEMA":
+			schema := g.Name
+			stmt := fmt.Sprintf("GRANT %s ON SCHEMA %s TO %s%s", privs, quoteIdent(schema), role, withGrant)
+			if _, err := appConn.Exec(ctx, stmt); err != nil {
+				return fmt.Errorf("grant on schema %q: %w", schema, err)
+			}
+
+			// So tables/sequences migrations create later inherit the same
+			// access without another manual GRANT.
+			tableStmt := fmt.Sprintf("ALTER DEFAULT PRIVILEGES IN SCHEMA %s GRANT %s ON TABLES TO %s", quoteIdent(schema), privs, role)
+			if _, err := appConn.Exec(ctx, tableStmt); err != nil {
+				return fmt.Errorf("default privileges on tables in schema %q: %w", schema, err)
+			}
+			if seqPrivs := filterPrivileges(g.Privileges, sequencePrivileges); len(seqPrivs) > 0 {
+				seqStmt := fmt.Sprintf("ALTER DEFAULT PRIVILEGES IN SCHEMA %s GRANT %s ON SEQUENCES TO %s", quoteIdent(schema), strings.Join(seqPrivs, ", "), role)
+				if _, err := appConn.Exec(ctx, seqStmt); err != nil {
+					return fmt.Errorf("default privileges on sequences in schema %q: %w", schema, err)
+				}
+			}
+
+		case "TABLE", "SEQUENCE":
+			target, err := grantTarget(g.Object, g.Name)
+			if err != nil {
+				return err
+			}
+			stmt := fmt.Sprintf("GRANT %s ON %s TO %s%s", privs, target, role, withGrant)
+			if _, err := appConn.Exec(ctx, stmt); err != nil {
+				return fmt.Errorf("grant on %s %q: %w", strings.ToLower(g.Object), g.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// grantTarget renders object/name as the target clause of a GRANT
+// statement: name "ALL IN SCHEMA public" with object "TABLE" becomes
+// "ALL TABLES IN SCHEMA public"; anything else is a single identifier.
+func grantTarget(object, name string) (string, error) {
+	const allPrefix = "ALL IN SCHEMA "
+	if strings.HasPrefix(strings.ToUpper(name), allPrefix) {
+		schema := strings.TrimSpace(name[len(allPrefix):])
+		if schema == "" {
+			return "", fmt.Errorf("%q: schema name is required after ALL IN SCHEMA", name)
+		}
+		return fmt.Sprintf("ALL %sS IN SCHEMA %s", object, quoteIdent(schema)), nil
+	}
+	return fmt.Sprintf("%s %s", object, quoteIdent(name)), nil
+}
+
+func filterPrivileges(privs []string, allowed map[string]bool) []string {
+	var out []string
+	for _, p := range privs {
+		if allowed[strings.ToUpper(p)] {
+			out = append(out, strings.ToUpper(p))
+		}
+	}
+	return out
+}
+
+// quoteIdent double-quotes a PostgreSQL identifier, doubling any embedded
+// double quotes — equivalent to lib/pq's QuoteIdentifier, reimplemented
+// here since this module depends on pgx, not lib/pq.
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const defaultLockTimeout = 2 * time.Minute
+
+// acquireInitdbLock takes a session-level PostgreSQL advisory lock keyed by
+// a hash of the app database name, so two initdb containers racing against
+// the same database (a common Kubernetes Job / systemd template unit
+// hazard) don't run ensureDB/RunMigrations concurrently. The lock is held
+// on a dedicated connection; call the returned release func (always, even
+// on error — it's a no-op if the lock was never taken) to close it and
+// release the lock.
+//
+// Once acquired, this process proceeds through the normal ensureDB /
+// RunMigrations / bootstrapAdmin path unconditionally rather than trying to
+// detect "the winner already finished" and skip straight to exit 0 — that
+// path is already fully idempotent (CREATE DATABASE/ROLE existence checks,
+// RunMigrations' isAlreadyAtLatestVersion no-op), so a loser that acquires
+// the lock after the winner released it does the same safe, cheap
+// re-verification a dedicated skip path would, without a second code path
+// to keep correct.
+//
+// INITDB_LOCK_TIMEOUT (default 2m) bounds how long a loser polls before
+// giving up. INITDB_ON_LOCK selects what a loser does when the lock is
+// already held: "wait" (default) polls until acquired or the timeout
+// elapses; "fail" returns an error immediately.
+func acquireInitdbLock(ctx context.Context, dsn, appDB string) (release func(), err error) {
+	key := advisoryLockKey(appDB)
+
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("lock connect: %w", err)
+	}
+	release = func() { conn.Close(ctx) }
+
+	acquired, err := tryAdvisoryLock(ctx, conn, key)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	if acquired {
+		return release, nil
+	}
+
+	onLock := os.Getenv("INITDB_ON_LOCK")
+	if onLock == "" {
+		onLock = "wait"
+	}
+	if onLock == "fail" {
+		release()
+		return nil, fmt.Errorf("another initdb run holds the advisory lock for database %q", appDB)
+	}
+	if onLock != "wait" {
+		release()
+		return nil, fmt.Errorf("INITDB_ON_LOCK must be \"wait\" or \"fail\", got %q", onLock)
+	}
+
+	timeout := defaultLockTimeout
+	if v := os.Getenv("INITDB_LOCK_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			release()
+			return nil, fmt.Errorf("INITDB_LOCK_TIMEOUT: %w", err)
+		}
+		timeout = d
+	}
+
+	log.Printf("initdb: another run holds the advisory lock for database %q — waiting up to %s", appDB, timeout)
+	deadline := time.Now().Add(timeout)
+	for {
+		select {
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+
+		acquired, err := tryAdvisoryLock(ctx, conn, key)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		if acquired {
+			log.Printf("initdb: acquired advisory lock for database %q after waiting", appDB)
+			return release, nil
+		}
+		if time.Now().After(deadline) {
+			release()
+			return nil, fmt.Errorf("timed out after %s waiting for the advisory lock on database %q", timeout, appDB)
+		}
+	}
+}
+
+func tryAdvisoryLock(ctx context.Context, conn *pgx.Conn, key int64) (bool, error) {
+	var ok bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&ok); err != nil {
+		return false, fmt.Errorf("pg_try_advisory_lock: %w", err)
+	}
+	return ok, nil
+}
+
+// advisoryLockKey hashes name to an int64, since pg_try_advisory_lock takes
+// a single bigint key rather than an identifier.
+func advisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("sticky-dvr:initdb:" + name))
+	return int64(h.Sum64())
+}
+
+// appDBNameFromDSN extracts the database name from a postgres DSN, the same
+// way ensureDB does for its own purposes.
+func appDBNameFromDSN(dsn string) (string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", fmt.Errorf("parse DB_DSN: %w", err)
+	}
+	return strings.TrimPrefix(u.Path, "/"), nil
+}
+
+// maintenanceDSN builds a DSN pointing at the cluster's always-present
+// "postgres" maintenance database, reusing appDSN's host and query string.
+// Used for admin operations — and the advisory lock above — that must
+// succeed even before the app database itself exists.
+func maintenanceDSN(appDSN, adminUser, adminPass string) (string, error) {
+	u, err := url.Parse(appDSN)
+	if err != nil {
+		return "", fmt.Errorf("parse DB_DSN: %w", err)
+	}
+	dsn := fmt.Sprintf("postgres://%s:%s@%s/postgres", adminUser, adminPass, u.Host)
+	if u.RawQuery != "" {
+		dsn += "?" + u.RawQuery
+	}
+	return dsn, nil
+}
@@ -9,8 +9,11 @@
 //       CREATE DATABASE  <app-db>   (idempotent via pg_database check)
 //       CREATE ROLE IF NOT EXISTS <app-user> WITH LOGIN
 //       ALTER  ROLE      <app-user> WITH PASSWORD '<app-pass>'
+//     then grants privileges to <app-user>: by default
 //       GRANT  ALL PRIVILEGES ON DATABASE <app-db> TO <app-user>
 //       GRANT  ALL ON SCHEMA public TO <app-user>   (run inside app-db)
+//     or, if INITDB_GRANTS / INITDB_GRANTS_FILE is set, the declarative
+//     grants it describes instead — see grants.go.
 //
 //  2. Regardless of admin credentials, connects using DB_DSN and runs
 //     all pending golang-migrate up-migrations from the embedded SQL files.
@@ -26,6 +29,48 @@
 //
 //	PG_ADMIN_USER     — postgres superuser name (e.g. "postgres")
 //	PG_ADMIN_PASSWORD — postgres superuser password
+//
+// Optional env vars (superuser setup only):
+//
+//	INITDB_GRANTS      — JSON array of grants.Grant to apply instead of the
+//	                     default ALL-PRIVILEGES grant; see grants.go
+//	INITDB_GRANTS_FILE — path to a file containing the same JSON, for cases
+//	                     where mounting a file is easier than an env var
+//
+//	APP_ROLE_CONNECTION_LIMIT — CONNECTION LIMIT for the app role; unset = -1 (unlimited)
+//	APP_ROLE_VALID_UNTIL      — VALID UNTIL for the app role, e.g. "2030-01-01"
+//	APP_ROLE_CREATEDB         — "true" grants CREATEDB; default NOCREATEDB
+//	APP_ROLE_REPLICATION      — "true" grants REPLICATION; default NOREPLICATION
+//	APP_ROLE_BYPASS_RLS       — "true" grants BYPASSRLS; default NOBYPASSRLS
+//	APP_ROLE_INHERIT          — "true" grants INHERIT; default NOINHERIT
+//
+// See roles.go; all APP_ROLE_* attributes converge via ALTER ROLE on every
+// run, not just at first creation.
+//
+// Optional env var, honored here and by postgres.RunMigrations:
+//
+//	DB_MIGRATION_ROLE — role migrations run as (via a SET ROLE-equivalent
+//	                    connection option; see postgres.withSessionRole).
+//	                    Must be a bare identifier. initdb additionally grants
+//	                    the app role default access to objects this role
+//	                    creates, so table ownership stays stable across
+//	                    DSN-user changes.
+//
+//  4. After migrations succeed, optionally seeds a first admin user — see
+//     admin.go — strictly on first run (a no-op once the users table is
+//     non-empty), gated on:
+//
+//	INIT_CREATE_ADMIN  — "true" to enable
+//	INIT_ADMIN_USERNAME, INIT_ADMIN_PASSWORD — required together
+//	INIT_ADMIN_EMAIL — accepted but currently ignored (no email column yet)
+//
+// The entire run (steps 1-4) is guarded by a PostgreSQL session advisory
+// lock keyed on the app database name — see lock.go — so two initdb
+// containers racing against the same database serialize instead of
+// colliding:
+//
+//	INITDB_ON_LOCK      — "wait" (default) or "fail" when the lock is held
+//	INITDB_LOCK_TIMEOUT — how long a "wait" run polls before giving up; default "2m"
 package main
 
 import (
@@ -50,9 +95,33 @@ func main() {
 	adminUser := os.Getenv("PG_ADMIN_USER")
 	adminPass := os.Getenv("PG_ADMIN_PASSWORD")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	// 10 minutes leaves headroom for the default 2-minute advisory-lock wait
+	// (see lock.go) plus the actual setup/migration work.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
 
+	appDB, err := appDBNameFromDSN(dbDSN)
+	if err != nil {
+		log.Fatalf("initdb: %v", err)
+	}
+
+	// Take the lock against the maintenance 'postgres' database when we have
+	// admin creds, since the app database itself may not exist yet; fall
+	// back to the app DSN otherwise (it must already exist for a
+	// migrations-only run to do anything).
+	lockDSN := dbDSN
+	if adminUser != "" && adminPass != "" {
+		lockDSN, err = maintenanceDSN(dbDSN, adminUser, adminPass)
+		if err != nil {
+			log.Fatalf("initdb: %v", err)
+		}
+	}
+	lockRelease, err := acquireInitdbLock(ctx, lockDSN, appDB)
+	if err != nil {
+		log.Fatalf("initdb: %v", err)
+	}
+	defer lockRelease()
+
 	if adminUser != "" && adminPass != "" {
 		log.Println("initdb: admin credentials present — ensuring app database and role exist")
 		if err := ensureDB(ctx, dbDSN, adminUser, adminPass); err != nil {
@@ -67,7 +136,12 @@ func main() {
 	if err := postgres.RunMigrations(dbDSN); err != nil {
 		log.Fatalf("initdb: migrations failed: %v", err)
 	}
-	log.Println("initdb: migrations OK — exiting")
+	log.Println("initdb: migrations OK")
+
+	if err := bootstrapAdmin(ctx, dbDSN); err != nil {
+		log.Fatalf("initdb: admin bootstrap failed: %v", err)
+	}
+	log.Println("initdb: exiting")
 }
 
 // ensureDB connects as the postgres superuser and idempotently creates
@@ -92,10 +166,9 @@ func ensureDB(ctx context.Context, appDSN, adminUser, adminPass string) error {
 		return fmt.Errorf("DB_DSN must include a username")
 	}
 
-	// Build admin DSN pointing at the maintenance 'postgres' database.
-	adminDSN := fmt.Sprintf("postgres://%s:%s@%s/postgres", adminUser, adminPass, u.Host)
-	if u.RawQuery != "" {
-		adminDSN += "?" + u.RawQuery
+	adminDSN, err := maintenanceDSN(appDSN, adminUser, adminPass)
+	if err != nil {
+		return err
 	}
 
 	conn, err := pgx.Connect(ctx, adminDSN)
@@ -125,35 +198,51 @@ func ensureDB(ctx context.Context, appDSN, adminUser, adminPass string) error {
 		log.Printf("initdb: database %q already exists", appDB)
 	}
 
-	// Create role if not exists and set password.
+	attrs, err := loadRoleAttrs()
+	if err != nil {
+		return fmt.Errorf("parse role attributes: %w", err)
+	}
+
+	// Create role if not exists, then converge attributes via ALTER ROLE
+	// either way: CREATE ROLE only fires once, but operators change
+	// APP_ROLE_* env vars across redeploys and expect them to take effect
+	// on an already-existing role too.
 	_, err = conn.Exec(ctx,
-		fmt.Sprintf(`CREATE ROLE %q WITH LOGIN NOINHERIT`, appUser))
+		fmt.Sprintf(`CREATE ROLE %s WITH %s`, quoteIdent(appUser), attrs.clauses()))
 	if err != nil {
 		// "duplicate_object" (42710) means role already exists — that's fine.
 		if !isDuplicateObject(err) {
 			return fmt.Errorf("create role %q: %w", appUser, err)
 		}
-		log.Printf("initdb: role %q already exists", appUser)
+		_, err = conn.Exec(ctx,
+			fmt.Sprintf(`ALTER ROLE %s WITH %s`, quoteIdent(appUser), attrs.clauses()))
+		if err != nil {
+			return fmt.Errorf("alter role %q attributes: %w", appUser, err)
+		}
+		log.Printf("initdb: role %q already exists, attributes converged", appUser)
 	} else {
 		log.Printf("initdb: created role %q", appUser)
 	}
 
 	// Always update password (handles rotation) and grant on database.
+	// ALTER ROLE ... PASSWORD requires a literal token, not a bind
+	// parameter — pgx's parameter binding doesn't apply here — so this
+	// uses quoteLiteral for proper SQL-literal escaping instead.
 	if appPass != "" {
 		_, err = conn.Exec(ctx,
-			fmt.Sprintf(`ALTER ROLE %q WITH PASSWORD '%s'`, appUser, appPass))
+			fmt.Sprintf(`ALTER ROLE %s WITH ENCRYPTED PASSWORD %s`, quoteIdent(appUser), quoteLiteral(appPass)))
 		if err != nil {
 			return fmt.Errorf("set password for role %q: %w", appUser, err)
 		}
 	}
 
-	_, err = conn.Exec(ctx,
-		fmt.Sprintf(`GRANT ALL PRIVILEGES ON DATABASE %q TO %q`, appDB, appUser))
+	grants, err := loadGrants()
 	if err != nil {
-		return fmt.Errorf("grant on database: %w", err)
+		return fmt.Errorf("parse grants: %w", err)
 	}
 
-	// Connect to the app database to grant schema access (required in PG 15+).
+	// Connect to the app database to grant schema/table/sequence access
+	// (required in PG 15+, and where ALTER DEFAULT PRIVILEGES must run).
 	appAdminDSN := fmt.Sprintf("postgres://%s:%s@%s/%s", adminUser, adminPass, u.Host, appDB)
 	if u.RawQuery != "" {
 		appAdminDSN += "?" + u.RawQuery
@@ -164,13 +253,49 @@ func ensureDB(ctx context.Context, appDSN, adminUser, adminPass string) error {
 	}
 	defer appConn.Close(ctx)
 
-	_, err = appConn.Exec(ctx,
-		fmt.Sprintf(`GRANT ALL ON SCHEMA public TO %q`, appUser))
-	if err != nil {
-		return fmt.Errorf("grant schema to role: %w", err)
+	if len(grants) == 0 {
+		// No INITDB_GRANTS configured — preserve the historical behaviour.
+		_, err = conn.Exec(ctx,
+			fmt.Sprintf(`GRANT ALL PRIVILEGES ON DATABASE %s TO %s`, quoteIdent(appDB), quoteIdent(appUser)))
+		if err != nil {
+			return fmt.Errorf("grant on database: %w", err)
+		}
+		_, err = appConn.Exec(ctx,
+			fmt.Sprintf(`GRANT ALL ON SCHEMA public TO %s`, quoteIdent(appUser)))
+		if err != nil {
+			return fmt.Errorf("grant schema to role: %w", err)
+		}
+		log.Printf("initdb: default privileges granted on %q to %q", appDB, appUser)
+	} else {
+		if err := applyGrants(ctx, conn, appConn, appUser, grants); err != nil {
+			return fmt.Errorf("apply INITDB_GRANTS: %w", err)
+		}
+		log.Printf("initdb: %d declarative grant(s) applied to %q", len(grants), appUser)
 	}
 
-	log.Printf("initdb: privileges granted on %q to %q", appDB, appUser)
+	return ensureMigrationRoleDefaults(ctx, appConn, appUser)
+}
+
+// ensureMigrationRoleDefaults, when DB_MIGRATION_ROLE is set, grants appUser
+// default access to objects future migrations create as that role — mirrors
+// the SCHEMA branch of applyGrants, but scoped "FOR ROLE" the migration role
+// rather than the connecting session, since migrations run as that role via
+// postgres.RunMigrations' SET-ROLE-by-DSN-option (see withSessionRole).
+func ensureMigrationRoleDefaults(ctx context.Context, appConn pgExecer, appUser string) error {
+	migrationRole := os.Getenv("DB_MIGRATION_ROLE")
+	if migrationRole == "" {
+		return nil
+	}
+	if err := postgres.ValidateMigrationRole(migrationRole); err != nil {
+		return err
+	}
+	stmt := fmt.Sprintf(
+		`ALTER DEFAULT PRIVILEGES FOR ROLE %s IN SCHEMA public GRANT ALL PRIVILEGES ON TABLES TO %s`,
+		quoteIdent(migrationRole), quoteIdent(appUser))
+	if _, err := appConn.Exec(ctx, stmt); err != nil {
+		return fmt.Errorf("default privileges for role %q: %w", migrationRole, err)
+	}
+	log.Printf("initdb: future objects created by role %q will grant default privileges to %q", migrationRole, appUser)
 	return nil
 }
 
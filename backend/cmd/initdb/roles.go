@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// roleAttrs holds the extended CREATE ROLE / ALTER ROLE attributes read from
+// APP_ROLE_* env vars, applied idempotently on every initdb run so they
+// converge even when the role already existed from a prior run.
+//
+// Superuser is deliberately not configurable here — this role is always
+// created NOSUPERUSER regardless of env vars.
+type roleAttrs struct {
+	Inherit         bool
+	CreateDB        bool
+	Replication     bool
+	BypassRLS       bool
+	ConnectionLimit *int
+	ValidUntil      string // e.g. "2030-01-01" or "infinity"; passed through as a quoted literal
+}
+
+// loadRoleAttrs reads APP_ROLE_CONNECTION_LIMIT, APP_ROLE_VALID_UNTIL,
+// APP_ROLE_CREATEDB, APP_ROLE_REPLICATION, APP_ROLE_BYPASS_RLS, and
+// APP_ROLE_INHERIT from the environment.
+func loadRoleAttrs() (roleAttrs, error) {
+	ra := roleAttrs{
+		Inherit:     parseBoolEnv("APP_ROLE_INHERIT", false),
+		CreateDB:    parseBoolEnv("APP_ROLE_CREATEDB", false),
+		Replication: parseBoolEnv("APP_ROLE_REPLICATION", false),
+		BypassRLS:   parseBoolEnv("APP_ROLE_BYPASS_RLS", false),
+		ValidUntil:  os.Getenv("APP_ROLE_VALID_UNTIL"),
+	}
+	if v := os.Getenv("APP_ROLE_CONNECTION_LIMIT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return ra, fmt.Errorf("APP_ROLE_CONNECTION_LIMIT: %w", err)
+		}
+		ra.ConnectionLimit = &n
+	}
+	return ra, nil
+}
+
+func parseBoolEnv(name string, def bool) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// clauses renders the attribute list shared by CREATE ROLE and ALTER ROLE,
+// always including LOGIN, but not ENCRYPTED PASSWORD — the password
+// is applied separately since it needs literal quoting, not attribute
+// syntax.
+func (ra roleAttrs) clauses() string {
+	parts := []string{"LOGIN", "NOSUPERUSER"}
+	if ra.Inherit {
+		parts = append(parts, "INHERIT")
+	} else {
+		parts = append(parts, "NOINHERIT")
+	}
+	if ra.CreateDB {
+		parts = append(parts, "CREATEDB")
+	} else {
+		parts = append(parts, "NOCREATEDB")
+	}
+	if ra.Replication {
+		parts = append(parts, "REPLICATION")
+	} else {
+		parts = append(parts, "NOREPLICATION")
+	}
+	if ra.BypassRLS {
+		parts = append(parts, "BYPASSRLS")
+	} else {
+		parts = append(parts, "NOBYPASSRLS")
+	}
+	if ra.ConnectionLimit != nil {
+		parts = append(parts, fmt.Sprintf("CONNECTION LIMIT %d", *ra.ConnectionLimit))
+	} else {
+		parts = append(parts, "CONNECTION LIMIT -1")
+	}
+	if ra.ValidUntil != "" {
+		parts = append(parts, "VALID UNTIL "+quoteLiteral(ra.ValidUntil))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteLiteral single-quotes a SQL string literal, doubling any embedded
+// single quotes. DDL statements like ALTER ROLE ... WITH PASSWORD require a
+// literal token there — PostgreSQL's grammar doesn't accept a bind
+// parameter in that position — so this, not pgx parameter binding, is the
+// correct way to safely interpolate one.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -1,14 +1,22 @@
 // Package config manages the global backend configuration.
-// Defaults are loaded from an embedded YAML file; the live config is stored
-// in a single DB row and read/written via the ConfigStore interface.
+// Defaults are loaded from an embedded YAML file; the live config is
+// versioned history read/written via the ConfigStore interface.
 package config
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	_ "embed"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
 	"gopkg.in/yaml.v3"
 )
 
@@ -23,6 +31,11 @@ type Data struct {
 	Framerate        int    `json:"framerate"         yaml:"framerate"`
 	OutPattern       string `json:"out_pattern"       yaml:"out_pattern"`
 	LogPattern       string `json:"log_pattern"       yaml:"log_pattern"`
+	// RecordingsRoot is the directory under which {driver}/{username}'s segment
+	// files are written, used by the resource sampler to find the active
+	// segment file and measure free disk space. Must match the volume OutPattern
+	// resolves into.
+	RecordingsRoot string `json:"recordings_root"   yaml:"recordings_root"`
 	SegmentLength    string `json:"segment_length"    yaml:"segment_length"`
 	CheckInterval    string `json:"check_interval"    yaml:"check_interval"`
 	RetryDelay       string `json:"retry_delay"       yaml:"retry_delay"`
@@ -38,17 +51,90 @@ type Data struct {
 	StartConcurrency  int    `json:"start_concurrency"  yaml:"start_concurrency"`
 	ErrorThreshold    int    `json:"error_threshold"    yaml:"error_threshold"`
 	ErrorWindow       string `json:"error_window"       yaml:"error_window"`
+	// StallThreshold is how long a recording source's segment bytes/sec can stay
+	// at zero while recordingState == "recording" before a stalled worker_events
+	// row is emitted.
+	StallThreshold string `json:"stall_threshold" yaml:"stall_threshold"`
+	// LeaveTimeout bounds how long Manager.Leave waits per source for the
+	// current recording session to end before force-stopping it.
+	LeaveTimeout string `json:"leave_timeout" yaml:"leave_timeout"`
+	// MinRestartInterval rejects Manager.Restart calls that arrive within this
+	// long of the worker's last start, to stop UI double-clicks (or retries)
+	// from hammering the overseer with Stop/Start pairs.
+	MinRestartInterval string `json:"min_restart_interval" yaml:"min_restart_interval"`
+	// EventFlushDelay is how long the per-source worker-event coalescer waits
+	// after a non-terminal event before writing the pending batch, so a quick
+	// started/exited/restarting bounce during a flaky source collapses into
+	// one worker_events row instead of three.
+	EventFlushDelay string `json:"event_flush_delay" yaml:"event_flush_delay"`
+
+	// RestartPolicy gates how often manager.startWorker will relaunch a
+	// source's overseer task (as opposed to RestartDelay/ErrorThreshold/
+	// ErrorWindow above, which configure the overseer's own in-task crash
+	// retry policy). See manager.restartTracker.
+	RestartPolicy RestartPolicyBlock `json:"restart_policy" yaml:"restart_policy"`
+
+	// StartScheduler weights manager.bulkStart's candidate scoring, so a cold
+	// reconnect with many sources revives the ones worth reviving first
+	// instead of firing in arbitrary slice order. See manager.scoreCandidates.
+	StartScheduler StartSchedulerConfig `json:"start_scheduler" yaml:"start_scheduler"`
 
 	// DriverURLs maps driver names to URL templates.
 	// Use {{.Username}} as the performer name placeholder.
 	DriverURLs map[string]string `json:"driver_urls" yaml:"driver_urls"`
+
+	// DiskFreeThresholdBytes is the free-space floor (on the filesystem
+	// backing RecordingsRoot) below which the sampler fires a
+	// disk_quota_reached notification. Zero disables the check.
+	DiskFreeThresholdBytes int64 `json:"disk_free_threshold_bytes" yaml:"disk_free_threshold_bytes"`
+}
+
+// RestartPolicyConfig bounds how many times manager.startWorker will retry a
+// source within Interval before backing off (Mode "delay") or giving up and
+// marking the source errored (Mode "fail"). Durations are parsed with
+// time.ParseDuration; an unparsable or empty field falls back to the
+// manager's built-in default for that field.
+type RestartPolicyConfig struct {
+	Attempts int    `json:"attempts" yaml:"attempts"`
+	Interval string `json:"interval" yaml:"interval"`
+	Delay    string `json:"delay" yaml:"delay"`
+	MaxDelay string `json:"max_delay" yaml:"max_delay"`
+	Mode     string `json:"mode" yaml:"mode"` // "delay" | "fail"
+}
+
+// RestartPolicyBlock is RestartPolicyConfig plus optional per-driver overrides.
+// A driver present in PerDriver replaces Default wholesale for that driver.
+type RestartPolicyBlock struct {
+	Default   RestartPolicyConfig            `json:"default" yaml:"default"`
+	PerDriver map[string]RestartPolicyConfig `json:"per_driver,omitempty" yaml:"per_driver,omitempty"`
 }
 
-// ConfigStore is the persistence interface for the live config row.
-// Implemented by store/postgres.DB; defined here to avoid circular imports.
+// StartSchedulerConfig holds the scoring weights for manager.bulkStart's
+// candidate ordering. A zero field falls back to the manager's built-in
+// default for that field; durations are parsed with time.ParseDuration.
+type StartSchedulerConfig struct {
+	// InSessionBonus is added if the source had an in-progress recording
+	// session at disconnect.
+	InSessionBonus int `json:"in_session_bonus" yaml:"in_session_bonus"`
+	// RecentRecordingBonus is added if the source recorded within RecentRecordingWindow.
+	RecentRecordingBonus  int    `json:"recent_recording_bonus" yaml:"recent_recording_bonus"`
+	RecentRecordingWindow string `json:"recent_recording_window" yaml:"recent_recording_window"`
+	// PerSubscriberWeight is multiplied by the source's active subscriber count.
+	PerSubscriberWeight int `json:"per_subscriber_weight" yaml:"per_subscriber_weight"`
+	// FlappingPenalty (typically negative) is added if the source restarted
+	// within FlappingWindow, to deprioritize sources that are crash-looping.
+	FlappingPenalty int    `json:"flapping_penalty" yaml:"flapping_penalty"`
+	FlappingWindow  string `json:"flapping_window" yaml:"flapping_window"`
+}
+
+// ConfigStore is the persistence interface for the versioned config history;
+// it's exactly store.Store's config subset, named locally so Global doesn't
+// need the rest of store.Store's methods.
 type ConfigStore interface {
 	GetConfig(ctx context.Context) (map[string]any, error)
-	SetConfig(ctx context.Context, data map[string]any) error
+	// SetConfig records a new config version. authorID is nil for
+	// system-initiated writes (e.g. persistDefaults on first boot).
+	SetConfig(ctx context.Context, data map[string]any, authorID *int64, comment string) (*store.ConfigVersion, error)
 }
 
 // Global is a thread-safe, DB-backed wrapper around Data.
@@ -96,7 +182,30 @@ func (g *Global) persistDefaults(ctx context.Context) error {
 	if err := json.Unmarshal(b, &m); err != nil {
 		return err
 	}
-	return g.st.SetConfig(ctx, m)
+	_, err = g.st.SetConfig(ctx, m, nil, "seeded defaults")
+	return err
+}
+
+// Reload re-reads the persisted config and refreshes the in-memory cache,
+// for callers that changed it directly through the store (e.g. a config
+// version rollback) rather than via Set.
+func (g *Global) Reload(ctx context.Context) error {
+	raw, err := g.st.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	var d Data
+	if err := json.Unmarshal(b, &d); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	g.data = d
+	g.mu.Unlock()
+	return nil
 }
 
 // defaults returns the built-in configuration by parsing the embedded YAML.
@@ -113,8 +222,9 @@ func (g *Global) Get() Data {
 	return g.data
 }
 
-// Set replaces the configuration and persists it to the DB.
-func (g *Global) Set(ctx context.Context, d Data) error {
+// Set replaces the configuration, persisting it as a new version (authorID
+// nil for system-initiated changes) rather than overwriting the last one.
+func (g *Global) Set(ctx context.Context, d Data, authorID *int64, comment string) error {
 	b, err := json.Marshal(d)
 	if err != nil {
 		return err
@@ -123,7 +233,7 @@ func (g *Global) Set(ctx context.Context, d Data) error {
 	if err := json.Unmarshal(b, &m); err != nil {
 		return err
 	}
-	if err := g.st.SetConfig(ctx, m); err != nil {
+	if _, err := g.st.SetConfig(ctx, m, authorID, comment); err != nil {
 		return err
 	}
 	g.mu.Lock()
@@ -131,3 +241,206 @@ func (g *Global) Set(ctx context.Context, d Data) error {
 	g.mu.Unlock()
 	return nil
 }
+
+// ErrFingerprintMismatch is returned by SetIfMatch when expectedFingerprint
+// no longer matches the config's current Fingerprint, so PUT /api/config can
+// reply 412 Precondition Failed instead of silently clobbering a concurrent
+// edit.
+var ErrFingerprintMismatch = errors.New("config: fingerprint mismatch")
+
+// Fingerprint returns a SHA-256 hex digest of the current config's canonical
+// JSON encoding (struct field order, map keys sorted by encoding/json),
+// used as GET /api/config's ETag and PUT /api/config's If-Match value.
+func (g *Global) Fingerprint() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.fingerprintLocked()
+}
+
+func (g *Global) fingerprintLocked() string {
+	b, _ := json.Marshal(g.data)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// SetIfMatch behaves like Set, but fails with ErrFingerprintMismatch if the
+// config changed since expectedFingerprint was read. The lock is held across
+// the persist call (unlike Set, which only holds it to swap g.data) so the
+// check-then-write is atomic with respect to other SetIfMatch/Set callers.
+func (g *Global) SetIfMatch(ctx context.Context, expectedFingerprint string, d Data, authorID *int64, comment string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.fingerprintLocked() != expectedFingerprint {
+		return ErrFingerprintMismatch
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	if _, err := g.st.SetConfig(ctx, m, authorID, comment); err != nil {
+		return err
+	}
+	g.data = d
+	return nil
+}
+
+// splitJSONPath turns a "/"-separated RFC-6901-style path (e.g.
+// "/restart_policy/default/attempts") into its field segments, which are
+// matched against Data's json tags.
+func splitJSONPath(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// MarshalJSONPath returns the raw JSON encoding of the field addressed by
+// path in the current config.
+func (g *Global) MarshalJSONPath(path string) (json.RawMessage, error) {
+	segs := splitJSONPath(path)
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("config: path must not be empty")
+	}
+
+	g.mu.RLock()
+	b, err := json.Marshal(g.data)
+	g.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	var v any = m
+	for _, seg := range segs {
+		mm, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("config: path %q: %q is not an object", path, seg)
+		}
+		v, ok = mm[seg]
+		if !ok {
+			return nil, fmt.Errorf("config: path %q: field %q not found", path, seg)
+		}
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSONPath decodes value into the field addressed by path, persists
+// the whole config as a new version, and refreshes the in-memory cache,
+// atomically under the same lock PUT /api/config's SetIfMatch uses — the
+// PATCH /api/config?path=... handler's single-field update.
+func (g *Global) UnmarshalJSONPath(ctx context.Context, path string, value json.RawMessage, authorID *int64, comment string) error {
+	segs := splitJSONPath(path)
+	if len(segs) == 0 {
+		return fmt.Errorf("config: path must not be empty")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	b, err := json.Marshal(g.data)
+	if err != nil {
+		return err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	cur := m
+	for _, seg := range segs[:len(segs)-1] {
+		nv, ok := cur[seg]
+		if !ok {
+			return fmt.Errorf("config: path %q: field %q not found", path, seg)
+		}
+		nm, ok := nv.(map[string]any)
+		if !ok {
+			return fmt.Errorf("config: path %q: field %q is not an object", path, seg)
+		}
+		cur = nm
+	}
+	last := segs[len(segs)-1]
+	if _, ok := cur[last]; !ok {
+		return fmt.Errorf("config: path %q: field %q not found", path, last)
+	}
+	var v any
+	if err := json.Unmarshal(value, &v); err != nil {
+		return fmt.Errorf("config: invalid value: %w", err)
+	}
+	cur[last] = v
+
+	b, err = json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	var d Data
+	if err := json.Unmarshal(b, &d); err != nil {
+		return err
+	}
+
+	if _, err := g.st.SetConfig(ctx, m, authorID, comment); err != nil {
+		return err
+	}
+	g.data = d
+	return nil
+}
+
+// Validate checks a candidate config payload's shape and the handful of
+// invariants the rest of the package relies on (duration strings must
+// parse, restart policy modes must be recognised, ...), rejecting it before
+// it's ever persisted as a new version. Register it with
+// store/postgres.WithConfigValidator at Open time.
+func Validate(data map[string]any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	var d Data
+	if err := dec.Decode(&d); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	for _, f := range []struct {
+		name  string
+		value string
+	}{
+		{"segment_length", d.SegmentLength},
+		{"check_interval", d.CheckInterval},
+		{"retry_delay", d.RetryDelay},
+		{"segment_timeout", d.SegmentTimeout},
+		{"recording_timeout", d.RecordingTimeout},
+		{"restart_delay", d.RestartDelay},
+		{"reconcile_interval", d.ReconcileInterval},
+		{"error_window", d.ErrorWindow},
+		{"stall_threshold", d.StallThreshold},
+		{"leave_timeout", d.LeaveTimeout},
+		{"min_restart_interval", d.MinRestartInterval},
+		{"event_flush_delay", d.EventFlushDelay},
+	} {
+		if f.value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(f.value); err != nil {
+			return fmt.Errorf("config: %s: %w", f.name, err)
+		}
+	}
+
+	if d.Resolution < 0 || d.Framerate < 0 || d.StartConcurrency < 0 || d.ErrorThreshold < 0 {
+		return fmt.Errorf("config: numeric fields must not be negative")
+	}
+	if mode := d.RestartPolicy.Default.Mode; mode != "" && mode != "delay" && mode != "fail" {
+		return fmt.Errorf(`config: restart_policy.default.mode must be "delay" or "fail"`)
+	}
+
+	return nil
+}
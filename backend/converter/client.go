@@ -1,19 +1,30 @@
-// Package converter provides a WebSocket client for the sticky-converter service.
-// The converter uses sticky-overseer v2 protocol at /ws.
-// Note: the converter only exposes queued/active/errored tasks; successfully completed
-// conversions are tracked in the converter's internal DB and not exposed via this API.
+// Package converter provides a persistent WebSocket client for the
+// sticky-converter service. The converter uses sticky-overseer v2 protocol
+// at /ws.
+// Note: the converter only exposes queued/active/errored tasks; successfully
+// completed conversions are tracked in the converter's internal DB and not
+// exposed via this API.
 package converter
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/logger"
 )
 
 // GlobalMetrics holds aggregate counters from the converter's in-memory state.
@@ -34,6 +45,13 @@ type PoolInfo struct {
 	Limit      int `json:"limit"`
 	Running    int `json:"running"`
 	QueueDepth int `json:"queue_depth"`
+
+	// AggregateETASec is sum(remaining_duration/speed) across every running
+	// task with known duration and speed, clamped to [0, 24h]; tasks with
+	// indeterminate duration or not-yet-reported speed are excluded from the
+	// sum rather than treated as zero. Computed client-side from progress
+	// state, not sent by the converter itself.
+	AggregateETASec int64 `json:"aggregate_eta_sec"`
 }
 
 // FileInfo describes a single conversion task returned to API consumers.
@@ -43,6 +61,15 @@ type FileInfo struct {
 	Status     string `json:"status"`
 	Pipeline   string `json:"pipeline"`
 	ErrorCount int    `json:"error_count,omitempty"`
+
+	// Progress fields are derived from ffmpeg progress lines in the task's
+	// output frames (see progressState/applyProgressLine); they read as
+	// indeterminate/zero until the converter has emitted at least one.
+	ProgressPercent float64 `json:"progress_percent"`          // -1 = indeterminate (no duration yet); else 0-99.9, 100 once completed
+	CurrentTimeSec  float64 `json:"current_time_sec,omitempty"`
+	DurationSec     float64 `json:"duration_sec,omitempty"` // 0 = unknown (e.g. a live-stream remux)
+	SpeedX          float64 `json:"speed_x,omitempty"`      // 0 = ffmpeg reported "N/A" (startup)
+	ETASec          int64   `json:"eta_sec"`                // -1 = unknown
 }
 
 // taskInfo mirrors the overseer v2 TaskInfo for converter tasks.
@@ -55,242 +82,953 @@ type taskInfo struct {
 	ErrorMessage string            `json:"error_message,omitempty"`
 }
 
-// Client is a WebSocket client for the sticky-converter service.
+// Event is a broadcastable converter task lifecycle message, delivered to
+// Subscribe callers over the persistent Run(ctx) connection.
+type Event struct {
+	Type   string `json:"type"` // task_started | task_completed | task_errored
+	TaskID string `json:"task_id,omitempty"`
+	File   string `json:"file,omitempty"`
+	State  string `json:"state,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// OutputEvent is a single tailed output line fanned out to SubscribeTail
+// callers for one task_id.
+type OutputEvent struct {
+	TaskID string    `json:"task_id"`
+	Stream string    `json:"stream,omitempty"`
+	Data   string    `json:"data,omitempty"`
+	TS     time.Time `json:"ts"`
+}
+
+// maxTailDrops is the number of consecutive dropped events before a slow
+// tail subscriber is disconnected (its channel closed).
+const maxTailDrops = 20
+
+// tailRingSize is how many recent output lines are kept per task so a
+// TailOutput caller that joins after the task started still gets context
+// instead of a blank screen.
+const tailRingSize = 200
+
+// tailState tracks consecutive drops for a single tail listener.
+type tailState struct {
+	dropped int
+}
+
+// progressState tracks the live ffmpeg progress for one task, updated by
+// applyProgressLine as output frames arrive. The zero value means "nothing
+// parsed yet" and reports as indeterminate via percent/etaSec.
+type progressState struct {
+	currentTimeSec float64
+	durationSec    float64 // 0 = unknown (e.g. a live-stream remux)
+	speedX         float64 // 0 = ffmpeg reported "N/A" (startup)
+	done           bool
+}
+
+// percent returns the clamped completion percentage, or -1 if durationSec is
+// unknown (indeterminate progress).
+func (p progressState) percent() float64 {
+	if p.done {
+		return 100
+	}
+	if p.durationSec <= 0 {
+		return -1
+	}
+	pct := p.currentTimeSec / p.durationSec * 100
+	switch {
+	case pct < 0:
+		return 0
+	case pct > 99.9:
+		return 99.9
+	default:
+		return pct
+	}
+}
+
+// etaSec returns the estimated seconds remaining, or -1 if duration or speed
+// isn't known yet.
+func (p progressState) etaSec() int64 {
+	if p.done {
+		return 0
+	}
+	if p.durationSec <= 0 || p.speedX <= 0 {
+		return -1
+	}
+	remaining := p.durationSec - p.currentTimeSec
+	if remaining < 0 {
+		remaining = 0
+	}
+	eta := remaining / p.speedX
+	if eta < 0 {
+		eta = 0
+	}
+	return int64(eta)
+}
+
+// progressKV matches ffmpeg's "key=value" progress tokens, present both in
+// the `-progress pipe:1` stream (one per line: out_time_ms=, speed=,
+// progress=) and the legacy single-line stderr format
+// ("frame=... time=... speed=1.02x").
+var progressKV = regexp.MustCompile(`(\w+)=\s*(\S+)`)
+
+// progressDuration matches ffmpeg's one-time startup banner line
+// ("Duration: 01:23:45.67, start: ...").
+var progressDuration = regexp.MustCompile(`Duration:\s*(\d+):(\d+):(\d+(?:\.\d+)?)`)
+
+// applyProgressLine updates st from a single line of converter task output.
+func applyProgressLine(st *progressState, line string) {
+	if m := progressDuration.FindStringSubmatch(line); m != nil {
+		st.durationSec = hmsToSeconds(m[1], m[2], m[3])
+		return
+	}
+
+	for _, m := range progressKV.FindAllStringSubmatch(line, -1) {
+		key, val := m[1], m[2]
+		switch key {
+		case "out_time_ms":
+			// Despite the name, ffmpeg's -progress out_time_ms field is
+			// microseconds, not milliseconds — a long-standing ffmpeg quirk.
+			if us, err := strconv.ParseInt(val, 10, 64); err == nil {
+				st.currentTimeSec = float64(us) / 1e6
+			}
+		case "time":
+			if parts := strings.SplitN(val, ":", 3); len(parts) == 3 {
+				st.currentTimeSec = hmsToSeconds(parts[0], parts[1], parts[2])
+			}
+		case "speed":
+			v := strings.TrimSuffix(val, "x")
+			if v == "N/A" {
+				st.speedX = 0
+				continue
+			}
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				st.speedX = f
+			}
+		case "progress":
+			if val == "end" {
+				st.done = true
+			}
+		}
+	}
+}
+
+func hmsToSeconds(h, m, s string) float64 {
+	hh, _ := strconv.ParseFloat(h, 64)
+	mm, _ := strconv.ParseFloat(m, 64)
+	ss, _ := strconv.ParseFloat(s, 64)
+	return hh*3600 + mm*60 + ss
+}
+
+// Handler receives unsolicited events broadcast by the converter over the
+// persistent connection, mirroring the shape of overseer.Client's Handler.
+type Handler struct {
+	OnStarted func(taskID, file string)
+	OnOutput  func(taskID, stream, data string)
+	OnExited  func(taskID, state string)
+	OnErrored func(taskID, message string)
+}
+
+// inbound is the superset of all messages sent by the converter.
+type inbound struct {
+	Type    string           `json:"type"`
+	ID      string           `json:"id,omitempty"`
+	TaskID  string           `json:"task_id,omitempty"`
+	File    string           `json:"file,omitempty"`
+	State   string           `json:"state,omitempty"`
+	Stream  string           `json:"stream,omitempty"`
+	Data    string           `json:"data,omitempty"`
+	Message string           `json:"message,omitempty"`
+	Global  *json.RawMessage `json:"global,omitempty"`
+	Pool    *json.RawMessage `json:"pool,omitempty"`
+	Tasks   []taskInfo       `json:"tasks,omitempty"`
+	TS      time.Time        `json:"ts,omitempty"`
+}
+
+// ClientOptions tunes dial/request timeouts.
+type ClientOptions struct {
+	DialTimeout    time.Duration
+	RequestTimeout time.Duration
+}
+
+func defaultClientOptions() ClientOptions {
+	return ClientOptions{
+		DialTimeout:    5 * time.Second,
+		RequestTimeout: 5 * time.Second,
+	}
+}
+
+// Client maintains a single long-lived WebSocket connection to a
+// sticky-converter instance. A reader goroutine (started by Run) demuxes
+// responses by id into per-request channels and dispatches unsolicited
+// event/output frames to Handler, so GetFiles/GetMetrics/GetPoolInfo/
+// GetAllTasks/QueueFile all send on and await the same connection instead of
+// dialing fresh each call. Unlike the previous dial-per-call client, a down
+// connection now surfaces a real error rather than silently degrading.
 type Client struct {
-	wsURL string
-	idSeq atomic.Int64
+	wsURL   string
+	handler Handler
+	opts    ClientOptions
+	idSeq   atomic.Int64
+
+	reconnectDelay time.Duration
+
+	connMu  sync.Mutex
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	pendingMu sync.Mutex
+	pending   map[string]chan json.RawMessage
+
+	subMu sync.Mutex
+	subs  map[chan Event]struct{}
+
+	tailMu        sync.Mutex
+	tailListeners map[string]map[chan OutputEvent]*tailState // task_id → listener set
+	tailRing      map[string][]OutputEvent                   // task_id → last tailRingSize lines
+
+	progressMu sync.Mutex
+	progress   map[string]*progressState // task_id → live ffmpeg progress
+
+	log *logger.Logger
 }
 
-// NewClient returns a Client targeting the given WebSocket URL (e.g. "ws://converter:8080/ws").
+// NewClient returns a Client targeting the given WebSocket URL (e.g.
+// "ws://converter:8080/ws"). Call Run in its own goroutine before issuing
+// any requests.
 func NewClient(wsURL string) *Client {
-	return &Client{wsURL: strings.TrimRight(wsURL, "/")}
+	return NewClientWithOptions(wsURL, Handler{}, defaultClientOptions())
 }
 
-func (c *Client) nextID() string {
-	return fmt.Sprintf("r%d", c.idSeq.Add(1))
+// NewClientWithOptions is like NewClient but lets the caller supply a
+// Handler for unsolicited events and override the default timeouts. Zero
+// fields in opts fall back to the package defaults.
+func NewClientWithOptions(wsURL string, h Handler, opts ClientOptions) *Client {
+	def := defaultClientOptions()
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = def.DialTimeout
+	}
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = def.RequestTimeout
+	}
+	return &Client{
+		wsURL:          strings.TrimRight(wsURL, "/"),
+		handler:        h,
+		opts:           opts,
+		reconnectDelay: 5 * time.Second,
+		pending:        make(map[string]chan json.RawMessage),
+		subs:           make(map[chan Event]struct{}),
+		tailListeners:  make(map[string]map[chan OutputEvent]*tailState),
+		tailRing:       make(map[string][]OutputEvent),
+		progress:       make(map[string]*progressState),
+		log:            logger.New("worker", "converter"),
+	}
 }
 
-// GetFiles dials the converter, lists all tasks, and returns those belonging to the given
-// driver/username source (matched on params["file"] containing "/{driver}/{username}/").
-// Returns an empty list if the converter is unreachable (graceful degradation).
-func (c *Client) GetFiles(ctx context.Context, driver, username string) ([]FileInfo, error) {
-	subpath := fmt.Sprintf("/%s/%s/", driver, username)
+// Run connects and reconnects until ctx is cancelled. Call in a dedicated
+// goroutine.
+func (c *Client) Run(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := c.runOnce(ctx); err != nil && ctx.Err() == nil {
+			c.log.Warn("reconnect", "error", err, "delay", c.reconnectDelay)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.reconnectDelay):
+		}
+	}
+}
 
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.wsURL, nil)
+func (c *Client) runOnce(ctx context.Context) error {
+	dialCtx, cancel := context.WithTimeout(ctx, c.opts.DialTimeout)
+	defer cancel()
+	conn, _, err := websocket.DefaultDialer.DialContext(dialCtx, c.wsURL, nil)
 	if err != nil {
-		// Converter unreachable — degrade gracefully.
-		return []FileInfo{}, nil
+		return fmt.Errorf("dial %s: %w", c.wsURL, err)
 	}
-	defer conn.Close()
 
-	reqID := c.nextID()
-	req, _ := json.Marshal(map[string]any{"type": "list", "id": reqID})
-	if err := conn.WriteMessage(websocket.TextMessage, req); err != nil {
-		return []FileInfo{}, nil
-	}
+	c.connMu.Lock()
+	c.conn = conn
+	c.connMu.Unlock()
+
+	c.log.Info("connected", "url", c.wsURL)
+
+	defer func() {
+		conn.Close()
+		c.connMu.Lock()
+		if c.conn == conn {
+			c.conn = nil
+		}
+		c.connMu.Unlock()
+		c.failAllPending(fmt.Errorf("converter: connection lost"))
+		c.log.Warn("disconnected", "url", c.wsURL)
+	}()
 
-	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
 	for {
+		if ctx.Err() != nil {
+			conn.WriteMessage(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return nil
+		}
 		_, raw, err := conn.ReadMessage()
 		if err != nil {
-			return []FileInfo{}, nil
+			return err
+		}
+		c.dispatch(raw)
+	}
+}
+
+// IsConnected reports whether the persistent Run connection is currently active.
+func (c *Client) IsConnected() bool {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	return c.conn != nil
+}
+
+func (c *Client) failAllPending(err error) {
+	errRaw, _ := json.Marshal(map[string]string{"__error": err.Error()})
+
+	c.pendingMu.Lock()
+	pending := c.pending
+	c.pending = make(map[string]chan json.RawMessage)
+	c.pendingMu.Unlock()
+
+	for _, ch := range pending {
+		ch <- errRaw
+	}
+}
+
+func (c *Client) dispatch(raw []byte) {
+	var msg inbound
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return
+	}
+
+	if msg.ID != "" {
+		c.pendingMu.Lock()
+		ch, ok := c.pending[msg.ID]
+		if ok {
+			delete(c.pending, msg.ID)
+		}
+		c.pendingMu.Unlock()
+		if ok {
+			ch <- raw
+			return
 		}
+	}
 
-		var msg struct {
-			Type  string     `json:"type"`
-			ID    string     `json:"id"`
-			Tasks []taskInfo `json:"tasks"`
+	switch msg.Type {
+	case "task_started":
+		if c.handler.OnStarted != nil {
+			c.handler.OnStarted(msg.TaskID, msg.File)
 		}
-		if err := json.Unmarshal(raw, &msg); err != nil {
-			continue
+		c.broadcast(Event{Type: msg.Type, TaskID: msg.TaskID, File: msg.File, State: msg.State})
+	case "output":
+		if c.handler.OnOutput != nil {
+			c.handler.OnOutput(msg.TaskID, msg.Stream, msg.Data)
+		}
+		ts := msg.TS
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		c.publishTail(OutputEvent{TaskID: msg.TaskID, Stream: msg.Stream, Data: msg.Data, TS: ts})
+		c.updateProgress(msg.TaskID, msg.Data)
+	case "task_completed":
+		if c.handler.OnExited != nil {
+			c.handler.OnExited(msg.TaskID, msg.State)
 		}
-		if msg.Type == "tasks" && msg.ID == reqID {
-			return filterTasks(msg.Tasks, subpath), nil
+		c.markProgressDone(msg.TaskID)
+		c.broadcast(Event{Type: msg.Type, TaskID: msg.TaskID, File: msg.File, State: msg.State})
+	case "task_errored":
+		if c.handler.OnErrored != nil {
+			c.handler.OnErrored(msg.TaskID, msg.Message)
 		}
+		c.markProgressDone(msg.TaskID)
+		c.broadcast(Event{Type: msg.Type, TaskID: msg.TaskID, File: msg.File, Error: msg.Message})
 	}
 }
 
-func filterTasks(tasks []taskInfo, subpath string) []FileInfo {
-	var files []FileInfo
-	for _, t := range tasks {
-		filePath := t.Params["file"]
-		if !strings.Contains(strings.ToLower(filePath), strings.ToLower(subpath)) {
+// updateProgress parses line (one line of a task's output) for ffmpeg
+// progress fields and merges them into the task's progressState.
+func (c *Client) updateProgress(taskID, line string) {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+	st := c.progress[taskID]
+	if st == nil {
+		st = &progressState{}
+		c.progress[taskID] = st
+	}
+	applyProgressLine(st, line)
+}
+
+// markProgressDone flags taskID's progress as complete, so percent/etaSec
+// report 100%/0s rather than drifting based on its last output line.
+func (c *Client) markProgressDone(taskID string) {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+	if st := c.progress[taskID]; st != nil {
+		st.done = true
+	}
+}
+
+// progressSnapshot returns a copy of taskID's current progress state, or the
+// zero value (indeterminate) if the converter hasn't reported any for it yet.
+func (c *Client) progressSnapshot(taskID string) progressState {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+	if st := c.progress[taskID]; st != nil {
+		return *st
+	}
+	return progressState{}
+}
+
+// maxAggregateETASec clamps PoolInfo.AggregateETASec so one wildly-behind
+// task can't report an ETA that's meaningless to show in a dashboard.
+const maxAggregateETASec = 24 * 60 * 60
+
+// aggregateETASec sums remaining_duration/speed across every running task
+// with known duration and speed, clamped to [0, maxAggregateETASec]. Tasks
+// with indeterminate duration, not-yet-reported speed, or already marked
+// done are excluded from the sum.
+func (c *Client) aggregateETASec() int64 {
+	c.progressMu.Lock()
+	defer c.progressMu.Unlock()
+
+	var total float64
+	for _, st := range c.progress {
+		if st.done || st.durationSec <= 0 || st.speedX <= 0 {
 			continue
 		}
-		files = append(files, FileInfo{
-			Filename:   filepath.Base(filePath),
-			Path:       filePath,
-			Status:     t.State,
-			Pipeline:   t.Action,
-			ErrorCount: t.RestartCount,
-		})
+		remaining := st.durationSec - st.currentTimeSec
+		if remaining < 0 {
+			remaining = 0
+		}
+		total += remaining / st.speedX
 	}
-	if files == nil {
-		files = []FileInfo{}
+	if total < 0 {
+		total = 0
 	}
-	return files
+	if total > maxAggregateETASec {
+		total = maxAggregateETASec
+	}
+	return int64(total)
 }
 
-// GetMetrics dials the converter and returns global aggregate counters.
-// Returns nil, nil if the converter is unreachable (graceful degradation).
-func (c *Client) GetMetrics(ctx context.Context) (*GlobalMetrics, error) {
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.wsURL, nil)
-	if err != nil {
-		return nil, nil
+// Subscribe returns a channel of task lifecycle Events broadcast over the
+// persistent connection, so callers such as manager can fan converter task
+// transitions into their own OnStarted/OnExited/OnErrored pipeline. The
+// returned channel is closed when ctx is done; callers must not close it
+// themselves.
+func (c *Client) Subscribe(ctx context.Context) (<-chan Event, error) {
+	ch := make(chan Event, 16)
+
+	c.subMu.Lock()
+	c.subs[ch] = struct{}{}
+	c.subMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.subMu.Lock()
+		if _, ok := c.subs[ch]; ok {
+			delete(c.subs, ch)
+			close(ch)
+		}
+		c.subMu.Unlock()
+	}()
+
+	return ch, nil
+}
+
+func (c *Client) broadcast(ev Event) {
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+	for ch := range c.subs {
+		select {
+		case ch <- ev:
+		default:
+			// Slow consumer — drop this event rather than block dispatch.
+		}
 	}
-	defer conn.Close()
+}
 
-	reqID := c.nextID()
-	req, _ := json.Marshal(map[string]any{"type": "metrics", "id": reqID})
-	if err := conn.WriteMessage(websocket.TextMessage, req); err != nil {
-		return nil, nil
+// SubscribeTail registers ch to receive OutputEvents for taskID. The caller
+// must eventually call UnsubscribeTail. Named distinctly from Subscribe,
+// which delivers task lifecycle Events rather than raw output lines.
+func (c *Client) SubscribeTail(taskID string, ch chan OutputEvent) {
+	c.tailMu.Lock()
+	defer c.tailMu.Unlock()
+	if c.tailListeners[taskID] == nil {
+		c.tailListeners[taskID] = make(map[chan OutputEvent]*tailState)
 	}
+	c.tailListeners[taskID][ch] = &tailState{}
+}
 
-	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
-	for {
-		_, raw, err := conn.ReadMessage()
-		if err != nil {
-			return nil, nil
+// UnsubscribeTail removes ch from taskID's listener set. Safe to call more than once.
+func (c *Client) UnsubscribeTail(taskID string, ch chan OutputEvent) {
+	c.tailMu.Lock()
+	defer c.tailMu.Unlock()
+	if m := c.tailListeners[taskID]; m != nil {
+		delete(m, ch)
+		if len(m) == 0 {
+			delete(c.tailListeners, taskID)
 		}
-		var msg struct {
-			Type   string           `json:"type"`
-			ID     string           `json:"id"`
-			Global *json.RawMessage `json:"global,omitempty"`
+	}
+}
+
+// publishTail records ev in taskID's replay ring and fans it out to every
+// subscriber of ev.TaskID, closing the channel of any subscriber that falls
+// maxTailDrops events behind.
+func (c *Client) publishTail(ev OutputEvent) {
+	c.tailMu.Lock()
+	defer c.tailMu.Unlock()
+
+	ring := append(c.tailRing[ev.TaskID], ev)
+	if len(ring) > tailRingSize {
+		ring = ring[len(ring)-tailRingSize:]
+	}
+	c.tailRing[ev.TaskID] = ring
+
+	for ch, st := range c.tailListeners[ev.TaskID] {
+		select {
+		case ch <- ev:
+			st.dropped = 0
+		default:
+			st.dropped++
+			if st.dropped >= maxTailDrops {
+				delete(c.tailListeners[ev.TaskID], ch)
+				close(ch)
+			}
 		}
-		if err := json.Unmarshal(raw, &msg); err != nil {
-			continue
+	}
+}
+
+// replayTail returns a copy of taskID's ring-buffered recent output lines.
+func (c *Client) replayTail(taskID string) []OutputEvent {
+	c.tailMu.Lock()
+	defer c.tailMu.Unlock()
+	ring := c.tailRing[taskID]
+	out := make([]OutputEvent, len(ring))
+	copy(out, ring)
+	return out
+}
+
+// maxTailBytes bounds how much output a single TailOutput session will write
+// to its sink, so a forgotten live-tail connection can't grow without bound.
+const maxTailBytes = 10 << 20 // 10 MiB
+
+// TailOutput sends a subscribe request for taskID on the shared connection,
+// then writes every output line — first the task's ring-buffered recent
+// lines so a late joiner isn't looking at a blank screen, then new lines as
+// the converter emits them — to sink, timestamp- and task-ID-prefixed via
+// NewLineWriter and capped at maxTailBytes. It returns when ctx is
+// cancelled, the tail listener is dropped for falling too far behind, or the
+// byte cap is hit.
+func (c *Client) TailOutput(ctx context.Context, taskID string, sink io.Writer) error {
+	id := c.nextID()
+	if err := c.send(map[string]any{"type": "subscribe", "id": id, "task_id": taskID}); err != nil {
+		return err
+	}
+
+	w := &limitedWriter{w: NewLineWriter(sink, taskID), max: maxTailBytes}
+
+	ch := make(chan OutputEvent, 64)
+	c.SubscribeTail(taskID, ch)
+	defer c.UnsubscribeTail(taskID, ch)
+
+	for _, ev := range c.replayTail(taskID) {
+		if _, err := io.WriteString(w, ev.Data+"\n"); err != nil {
+			return err
 		}
-		if msg.Type == "metrics" && msg.ID == reqID {
-			if msg.Global == nil {
-				return nil, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-ch:
+			if !ok {
+				return nil
 			}
-			var gm GlobalMetrics
-			if err := json.Unmarshal(*msg.Global, &gm); err != nil {
-				return nil, nil
+			if _, err := io.WriteString(w, ev.Data+"\n"); err != nil {
+				return err
 			}
-			return &gm, nil
 		}
 	}
 }
 
-// GetPoolInfo dials the converter and returns a snapshot of global pool state.
-// Returns nil, nil if the converter is unreachable (graceful degradation).
-func (c *Client) GetPoolInfo(ctx context.Context) (*PoolInfo, error) {
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.wsURL, nil)
-	if err != nil {
-		return nil, nil
-	}
-	defer conn.Close()
+// NewLineWriter returns a writer that splits whatever is written to it into
+// lines and forwards each complete line to w prefixed with an RFC3339
+// timestamp and taskID — analogous to the timestamped, line-buffered log
+// streaming CI runners use, so a multi-line chunk doesn't arrive as one
+// unlabelled blob.
+func NewLineWriter(w io.Writer, taskID string) io.Writer {
+	return &lineWriter{w: w, taskID: taskID}
+}
 
-	reqID := c.nextID()
-	req, _ := json.Marshal(map[string]any{"type": "pool_info", "id": reqID})
-	if err := conn.WriteMessage(websocket.TextMessage, req); err != nil {
-		return nil, nil
-	}
+type lineWriter struct {
+	w      io.Writer
+	taskID string
+	buf    bytes.Buffer
+}
 
-	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	lw.buf.Write(p)
 	for {
-		_, raw, err := conn.ReadMessage()
+		line, err := lw.buf.ReadString('\n')
 		if err != nil {
-			return nil, nil
+			// Incomplete line — push back and wait for more data.
+			lw.buf.WriteString(line)
+			break
 		}
-		var msg struct {
-			Type string           `json:"type"`
-			ID   string           `json:"id"`
-			Pool *json.RawMessage `json:"pool,omitempty"`
+		line = strings.TrimSuffix(line, "\n")
+		if _, err := fmt.Fprintf(lw.w, "%s %s: %s\n", time.Now().UTC().Format(time.RFC3339), lw.taskID, line); err != nil {
+			return len(p), err
 		}
-		if err := json.Unmarshal(raw, &msg); err != nil {
-			continue
+	}
+	return len(p), nil
+}
+
+// limitedWriter caps the total bytes written through it, returning an error
+// once the cap is exceeded — an io.LimitReader-style cap, but for writes.
+type limitedWriter struct {
+	w       io.Writer
+	max     int64
+	written int64
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if lw.written+int64(len(p)) > lw.max {
+		return 0, fmt.Errorf("converter: tail output exceeded %d bytes", lw.max)
+	}
+	n, err := lw.w.Write(p)
+	lw.written += int64(n)
+	return n, err
+}
+
+func (c *Client) nextID() string {
+	return fmt.Sprintf("r%d", c.idSeq.Add(1))
+}
+
+func (c *Client) send(v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.connMu.Lock()
+	conn := c.conn
+	c.connMu.Unlock()
+	if conn == nil {
+		return fmt.Errorf("not connected to converter")
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, raw)
+}
+
+// request sends v over the shared connection and waits for the reply
+// matching the given id, or an error once the connection drops or opts.RequestTimeout elapses.
+func (c *Client) request(ctx context.Context, id string, v any) (json.RawMessage, error) {
+	ch := make(chan json.RawMessage, 1)
+	c.pendingMu.Lock()
+	c.pending[id] = ch
+	c.pendingMu.Unlock()
+
+	if err := c.send(v); err != nil {
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+		return nil, err
+	}
+
+	select {
+	case raw := <-ch:
+		var probe struct {
+			Error string `json:"__error"`
 		}
-		if msg.Type == "pool_info" && msg.ID == reqID {
-			if msg.Pool == nil {
-				return nil, nil
-			}
-			var pi PoolInfo
-			if err := json.Unmarshal(*msg.Pool, &pi); err != nil {
-				return nil, nil
-			}
-			return &pi, nil
+		if json.Unmarshal(raw, &probe) == nil && probe.Error != "" {
+			return nil, fmt.Errorf("%s", probe.Error)
 		}
+		return raw, nil
+	case <-ctx.Done():
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+		return nil, ctx.Err()
+	case <-time.After(c.opts.RequestTimeout):
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+		return nil, fmt.Errorf("timeout waiting for converter response")
 	}
 }
 
-// GetAllTasks dials the converter and returns all active/queued/errored tasks.
-// Returns an empty list if the converter is unreachable (graceful degradation).
+// GetFiles lists all tasks and returns those belonging to the given
+// driver/username source (matched on params["file"] containing
+// "/{driver}/{username}/").
+func (c *Client) GetFiles(ctx context.Context, driver, username string) ([]FileInfo, error) {
+	subpath := fmt.Sprintf("/%s/%s/", driver, username)
+
+	tasks, err := c.listTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.filterTasks(tasks, subpath), nil
+}
+
+// GetAllTasks returns all active/queued/errored tasks.
 func (c *Client) GetAllTasks(ctx context.Context) ([]FileInfo, error) {
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.wsURL, nil)
+	tasks, err := c.listTasks(ctx)
 	if err != nil {
-		return []FileInfo{}, nil
+		return nil, err
 	}
-	defer conn.Close()
+	return c.filterTasks(tasks, ""), nil
+}
 
-	reqID := c.nextID()
-	req, _ := json.Marshal(map[string]any{"type": "list", "id": reqID})
-	if err := conn.WriteMessage(websocket.TextMessage, req); err != nil {
-		return []FileInfo{}, nil
+func (c *Client) listTasks(ctx context.Context) ([]taskInfo, error) {
+	id := c.nextID()
+	raw, err := c.request(ctx, id, map[string]any{"type": "list", "id": id})
+	if err != nil {
+		return nil, err
+	}
+	var msg struct {
+		Tasks []taskInfo `json:"tasks"`
 	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return nil, err
+	}
+	return msg.Tasks, nil
+}
 
-	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
-	for {
-		_, raw, err := conn.ReadMessage()
-		if err != nil {
-			return []FileInfo{}, nil
-		}
-		var msg struct {
-			Type  string     `json:"type"`
-			ID    string     `json:"id"`
-			Tasks []taskInfo `json:"tasks"`
-		}
-		if err := json.Unmarshal(raw, &msg); err != nil {
+func (c *Client) filterTasks(tasks []taskInfo, subpath string) []FileInfo {
+	files := make([]FileInfo, 0, len(tasks))
+	for _, t := range tasks {
+		filePath := t.Params["file"]
+		if subpath != "" && !strings.Contains(strings.ToLower(filePath), strings.ToLower(subpath)) {
 			continue
 		}
-		if msg.Type == "tasks" && msg.ID == reqID {
-			return filterTasks(msg.Tasks, ""), nil
-		}
+		prog := c.progressSnapshot(t.TaskID)
+		files = append(files, FileInfo{
+			Filename:        filepath.Base(filePath),
+			Path:            filePath,
+			Status:          t.State,
+			Pipeline:        t.Action,
+			ErrorCount:      t.RestartCount,
+			ProgressPercent: prog.percent(),
+			CurrentTimeSec:  prog.currentTimeSec,
+			DurationSec:     prog.durationSec,
+			SpeedX:          prog.speedX,
+			ETASec:          prog.etaSec(),
+		})
 	}
+	return files
 }
 
-// QueueFile sends a start request to the converter to queue the given file for conversion.
-func (c *Client) QueueFile(ctx context.Context, filePath string) error {
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.wsURL, nil)
+// GetMetrics returns global aggregate counters from the converter.
+func (c *Client) GetMetrics(ctx context.Context) (*GlobalMetrics, error) {
+	id := c.nextID()
+	raw, err := c.request(ctx, id, map[string]any{"type": "metrics", "id": id})
+	if err != nil {
+		return nil, err
+	}
+	var msg struct {
+		Global *json.RawMessage `json:"global,omitempty"`
+	}
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Global == nil {
+		return nil, fmt.Errorf("converter: metrics response missing global")
+	}
+	var gm GlobalMetrics
+	if err := json.Unmarshal(*msg.Global, &gm); err != nil {
+		return nil, err
+	}
+	return &gm, nil
+}
+
+// GetPoolInfo returns a snapshot of global converter pool state.
+func (c *Client) GetPoolInfo(ctx context.Context) (*PoolInfo, error) {
+	id := c.nextID()
+	raw, err := c.request(ctx, id, map[string]any{"type": "pool_info", "id": id})
 	if err != nil {
-		return fmt.Errorf("connect to converter: %w", err)
+		return nil, err
+	}
+	var msg struct {
+		Pool *json.RawMessage `json:"pool,omitempty"`
 	}
-	defer conn.Close()
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Pool == nil {
+		return nil, fmt.Errorf("converter: pool_info response missing pool")
+	}
+	var pi PoolInfo
+	if err := json.Unmarshal(*msg.Pool, &pi); err != nil {
+		return nil, err
+	}
+	pi.AggregateETASec = c.aggregateETASec()
+	return &pi, nil
+}
 
-	reqID := c.nextID()
-	req, _ := json.Marshal(map[string]any{
-		"type":    "start",
-		"id":      reqID,
-		"action":  "convert",
-		"params":  map[string]string{"file": filePath},
+// QueueFile sends a start request to the converter to queue the given file for conversion.
+func (c *Client) QueueFile(ctx context.Context, filePath string) error {
+	id := c.nextID()
+	raw, err := c.request(ctx, id, map[string]any{
+		"type":   "start",
+		"id":     id,
+		"action": "convert",
+		"params": map[string]string{"file": filePath},
 	})
-	if err := conn.WriteMessage(websocket.TextMessage, req); err != nil {
-		return fmt.Errorf("send queue request: %w", err)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
 	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return err
+	}
+	if resp.Type == "error" {
+		return fmt.Errorf("converter: %s", resp.Message)
+	}
+	return nil
+}
 
-	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
-	for {
-		_, raw, readErr := conn.ReadMessage()
-		if readErr != nil {
-			return fmt.Errorf("read response: %w", readErr)
-		}
-		var resp struct {
-			Type    string `json:"type"`
-			ID      string `json:"id"`
-			Message string `json:"message"`
-		}
-		if err := json.Unmarshal(raw, &resp); err != nil {
-			continue
+// QueueStatus is one path's outcome from QueueFiles.
+type QueueStatus string
+
+const (
+	QueueStatusQueued    QueueStatus = "queued"
+	QueueStatusDuplicate QueueStatus = "duplicate"
+	QueueStatusFailed    QueueStatus = "failed"
+)
+
+// QueueResult is one path's outcome from QueueFiles.
+type QueueResult struct {
+	Path   string
+	TaskID string
+	Status QueueStatus
+	Err    error
+}
+
+// maxQueueConcurrency bounds how many start requests QueueFiles has in
+// flight at once, so a bulk re-queue from the UI doesn't pile up hundreds
+// of simultaneous requests on the single persistent connection.
+const maxQueueConcurrency = 8
+
+// queueRetryDelays are the backoff delays between retries of a transient
+// queue failure; a path gets at most len(queueRetryDelays)+1 attempts.
+var queueRetryDelays = []time.Duration{100 * time.Millisecond, 400 * time.Millisecond, 1600 * time.Millisecond}
+
+// queueTransientSubstrings classifies a converter "error" reply's message
+// as transient (worth retrying) versus terminal (the path itself is the
+// problem, so retrying changes nothing).
+var queueTransientSubstrings = []string{"queue full", "not connected", "connection", "disconnected", "timeout"}
+
+func isTransientQueueError(msg string) bool {
+	lower := strings.ToLower(msg)
+	for _, s := range queueTransientSubstrings {
+		if strings.Contains(lower, s) {
+			return true
 		}
-		if resp.ID != reqID {
-			continue
+	}
+	return false
+}
+
+// idempotencyKey derives a stable per-(path,pipeline) key so the converter
+// can recognize a retried or re-submitted start request and reject it as a
+// duplicate instead of double-enqueuing it.
+func idempotencyKey(path, pipeline string) string {
+	sum := sha256.Sum256([]byte(path + pipeline))
+	return hex.EncodeToString(sum[:])
+}
+
+// QueueFiles queues every path for conversion over the shared connection in
+// parallel (bounded by maxQueueConcurrency), attaching an idempotency_key
+// derived from idempotencyKey so the converter's own dedup can recognize a
+// retried or re-submitted path instead of double-enqueuing it. A path whose
+// failure is classified transient (connection drop, queue full) is retried
+// with backoff; a terminal failure (invalid path, unsupported codec) is
+// not. The returned slice has one QueueResult per path, in the same order
+// as paths, so a partial failure doesn't leave the caller guessing which
+// paths actually made it in.
+func (c *Client) QueueFiles(ctx context.Context, paths []string) ([]QueueResult, error) {
+	results := make([]QueueResult, len(paths))
+	sem := make(chan struct{}, maxQueueConcurrency)
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = c.queueFileWithRetry(ctx, path)
+		}(i, path)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+func (c *Client) queueFileWithRetry(ctx context.Context, path string) QueueResult {
+	const pipeline = "convert"
+	key := idempotencyKey(path, pipeline)
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		taskID, status, transient, err := c.queueOnce(ctx, path, pipeline, key)
+		if err == nil {
+			return QueueResult{Path: path, TaskID: taskID, Status: status}
 		}
-		if resp.Type == "started" {
-			return nil
+		lastErr = err
+		if !transient || attempt >= len(queueRetryDelays) {
+			break
 		}
-		if resp.Type == "error" {
-			return fmt.Errorf("converter: %s", resp.Message)
+		select {
+		case <-ctx.Done():
+			return QueueResult{Path: path, Status: QueueStatusFailed, Err: ctx.Err()}
+		case <-time.After(queueRetryDelays[attempt]):
 		}
 	}
+	return QueueResult{Path: path, Status: QueueStatusFailed, Err: lastErr}
+}
+
+// queueOnce sends a single start request and classifies the outcome.
+// transient is only meaningful when err != nil: it tells queueFileWithRetry
+// whether the failure is worth retrying.
+func (c *Client) queueOnce(ctx context.Context, path, pipeline, idempotencyKey string) (taskID string, status QueueStatus, transient bool, err error) {
+	id := c.nextID()
+	raw, err := c.request(ctx, id, map[string]any{
+		"type":   "start",
+		"id":     id,
+		"action": pipeline,
+		"params": map[string]string{
+			"file":            path,
+			"idempotency_key": idempotencyKey,
+		},
+	})
+	if err != nil {
+		// A connection-level failure (never reached the converter, or the
+		// response never arrived) is always worth retrying.
+		return "", "", true, err
+	}
+	var resp struct {
+		Type      string `json:"type"`
+		TaskID    string `json:"task_id"`
+		Message   string `json:"message"`
+		Duplicate bool   `json:"duplicate"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return "", "", false, err
+	}
+	if resp.Type == "error" {
+		return "", "", isTransientQueueError(resp.Message), fmt.Errorf("converter: %s", resp.Message)
+	}
+	if resp.Duplicate {
+		return resp.TaskID, QueueStatusDuplicate, false, nil
+	}
+	return resp.TaskID, QueueStatusQueued, false, nil
 }
@@ -0,0 +1,44 @@
+package diagnostics
+
+import (
+	"context"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/converter"
+	"github.com/whisper-darkly/sticky-dvr/backend/overseer"
+	"github.com/whisper-darkly/sticky-dvr/backend/thumbnailer"
+)
+
+// overseerAdapter satisfies ServiceClient for *overseer.Client. Its method
+// names already match ServiceClient verbatim.
+type overseerAdapter struct{ c *overseer.Client }
+
+// NewOverseerAdapter wraps an overseer client for Registry.Register.
+func NewOverseerAdapter(c *overseer.Client) ServiceClient { return overseerAdapter{c} }
+
+func (a overseerAdapter) Name() string          { return "recorder" }
+func (a overseerAdapter) IsConnected() bool     { return a.c.IsConnected() }
+func (a overseerAdapter) PoolInfo(ctx context.Context) (any, error) { return a.c.PoolInfo(ctx) }
+func (a overseerAdapter) Metrics(ctx context.Context) (any, error)  { return a.c.Metrics(ctx) }
+
+// converterAdapter satisfies ServiceClient for *converter.Client.
+type converterAdapter struct{ c *converter.Client }
+
+// NewConverterAdapter wraps a converter client for Registry.Register.
+func NewConverterAdapter(c *converter.Client) ServiceClient { return converterAdapter{c} }
+
+func (a converterAdapter) Name() string      { return "converter" }
+func (a converterAdapter) IsConnected() bool { return a.c.IsConnected() }
+
+func (a converterAdapter) PoolInfo(ctx context.Context) (any, error) { return a.c.GetPoolInfo(ctx) }
+func (a converterAdapter) Metrics(ctx context.Context) (any, error)  { return a.c.GetMetrics(ctx) }
+
+// thumbnailerAdapter satisfies ServiceClient for *thumbnailer.Client.
+type thumbnailerAdapter struct{ c *thumbnailer.Client }
+
+// NewThumbnailerAdapter wraps a thumbnailer client for Registry.Register.
+func NewThumbnailerAdapter(c *thumbnailer.Client) ServiceClient { return thumbnailerAdapter{c} }
+
+func (a thumbnailerAdapter) Name() string          { return "thumbnailer" }
+func (a thumbnailerAdapter) IsConnected() bool     { return a.c.IsConnected() }
+func (a thumbnailerAdapter) PoolInfo(ctx context.Context) (any, error) { return a.c.GetPoolInfo(ctx) }
+func (a thumbnailerAdapter) Metrics(ctx context.Context) (any, error)  { return a.c.GetMetrics(ctx) }
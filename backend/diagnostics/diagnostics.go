@@ -0,0 +1,258 @@
+// Package diagnostics wraps the overseer/converter/thumbnailer clients
+// behind one ServiceClient interface so getDiagnostics can iterate a
+// Registry generically — adding a fourth dependency later is a Register
+// call, not a new hand-rolled goroutine in the handler. Each registered
+// client gets its own circuit breaker (so a downed backend fails fast
+// instead of re-dialing on every request) and a short TTL cache on
+// PoolInfo/Metrics (so a burst of diagnostics requests doesn't stampede it).
+package diagnostics
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ServiceClient is the subset of overseer.Client / converter.Client /
+// thumbnailer.Client that diagnostics needs. PoolInfo/Metrics return `any`
+// rather than a shared concrete type since each client's pool/metrics shape
+// is its own package's type; Registry only ever marshals the result to JSON.
+type ServiceClient interface {
+	Name() string
+	IsConnected() bool
+	PoolInfo(ctx context.Context) (any, error)
+	Metrics(ctx context.Context) (any, error)
+}
+
+// breakerState is a circuit breaker's current phase.
+type breakerState string
+
+const (
+	stateClosed   breakerState = "closed"
+	stateOpen     breakerState = "open"
+	stateHalfOpen breakerState = "half_open"
+)
+
+// circuitBreaker trips open after failureThreshold consecutive failures
+// observed within failureWindow of each other (a gap longer than that resets
+// the streak rather than trips it), stays open for cooldown, then allows one
+// half-open probe before deciding whether to close or reopen.
+type circuitBreaker struct {
+	mu sync.Mutex
+
+	state            breakerState
+	consecutiveFails int
+	lastFailureAt    time.Time
+	openedAt         time.Time
+
+	failureThreshold int
+	failureWindow    time.Duration
+	cooldown         time.Duration
+}
+
+func newCircuitBreaker(failureThreshold int, failureWindow, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		state:            stateClosed,
+		failureThreshold: failureThreshold,
+		failureWindow:    failureWindow,
+		cooldown:         cooldown,
+	}
+}
+
+// allow reports whether a call should be attempted now, flipping an open
+// breaker to half-open once cooldown has elapsed.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == stateOpen && time.Since(b.openedAt) >= b.cooldown {
+		b.state = stateHalfOpen
+	}
+	return b.state != stateOpen
+}
+
+// recordResult updates the breaker after a call allow() permitted.
+func (b *circuitBreaker) recordResult(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.consecutiveFails = 0
+		b.state = stateClosed
+		return
+	}
+
+	now := time.Now()
+	if b.consecutiveFails > 0 && now.Sub(b.lastFailureAt) > b.failureWindow {
+		b.consecutiveFails = 0
+	}
+	b.consecutiveFails++
+	b.lastFailureAt = now
+
+	// A half-open probe that fails reopens immediately rather than waiting
+	// for another failureThreshold failures.
+	if b.state == stateHalfOpen || b.consecutiveFails >= b.failureThreshold {
+		b.state = stateOpen
+		b.openedAt = now
+	}
+}
+
+func (b *circuitBreaker) snapshot() breakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// fetchFunc is the shape of ServiceClient.PoolInfo/Metrics, cached by ttlCache.
+type fetchFunc func(ctx context.Context) (any, error)
+
+// ttlCache memoizes one fetchFunc's last result for ttl, so concurrent or
+// rapid-fire diagnostics requests share a single in-flight dial instead of
+// each opening their own.
+type ttlCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	fetchedAt time.Time
+	val       any
+	err       error
+}
+
+func newTTLCache(ttl time.Duration) *ttlCache {
+	return &ttlCache{ttl: ttl}
+}
+
+func (c *ttlCache) get(ctx context.Context, fetch fetchFunc) (any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.fetchedAt) < c.ttl {
+		return c.val, c.err
+	}
+	c.val, c.err = fetch(ctx)
+	c.fetchedAt = time.Now()
+	return c.val, c.err
+}
+
+// Status is one registered service's diagnostics snapshot.
+type Status struct {
+	Name      string `json:"name"`
+	Connected bool   `json:"connected"`
+	Breaker   string `json:"breaker"` // closed | open | half_open
+	Pool      any    `json:"pool,omitempty"`
+	Metrics   any    `json:"metrics,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type registryEntry struct {
+	client      ServiceClient
+	breaker     *circuitBreaker
+	poolCache   *ttlCache
+	metricsCache *ttlCache
+}
+
+// Registry holds every registered ServiceClient keyed by Name.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]*registryEntry
+}
+
+// NewRegistry returns an empty Registry; call Register for each service.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*registryEntry)}
+}
+
+// BreakerConfig bounds a registered client's circuit breaker and cache.
+type BreakerConfig struct {
+	FailureThreshold int           // consecutive failures within FailureWindow before tripping open
+	FailureWindow    time.Duration
+	Cooldown         time.Duration // how long an open breaker stays open before a half-open probe
+	CacheTTL         time.Duration // PoolInfo/Metrics memoization window
+}
+
+// DefaultBreakerConfig is what main.go registers overseer/converter/
+// thumbnailer with.
+var DefaultBreakerConfig = BreakerConfig{
+	FailureThreshold: 3,
+	FailureWindow:    30 * time.Second,
+	Cooldown:         15 * time.Second,
+	CacheTTL:         2 * time.Second,
+}
+
+// Register wraps client in a circuit breaker and TTL cache per cfg and adds
+// it to the registry under client.Name(). Registering the same name twice
+// replaces the previous entry.
+func (reg *Registry) Register(client ServiceClient, cfg BreakerConfig) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.entries[client.Name()] = &registryEntry{
+		client:       client,
+		breaker:      newCircuitBreaker(cfg.FailureThreshold, cfg.FailureWindow, cfg.Cooldown),
+		poolCache:    newTTLCache(cfg.CacheTTL),
+		metricsCache: newTTLCache(cfg.CacheTTL),
+	}
+}
+
+// Diagnose queries every registered client concurrently, respecting each
+// one's breaker and cache, and returns a Status per service keyed by name.
+func (reg *Registry) Diagnose(ctx context.Context) map[string]Status {
+	reg.mu.RLock()
+	entries := make([]*registryEntry, 0, len(reg.entries))
+	for _, e := range reg.entries {
+		entries = append(entries, e)
+	}
+	reg.mu.RUnlock()
+
+	out := make(map[string]Status, len(entries))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		wg.Add(1)
+		go func(e *registryEntry) {
+			defer wg.Done()
+			s := e.diagnose(ctx)
+			mu.Lock()
+			out[s.Name] = s
+			mu.Unlock()
+		}(e)
+	}
+	wg.Wait()
+	return out
+}
+
+func (e *registryEntry) diagnose(ctx context.Context) Status {
+	s := Status{
+		Name:      e.client.Name(),
+		Connected: e.client.IsConnected(),
+		Breaker:   string(e.breaker.snapshot()),
+	}
+	if !e.breaker.allow() {
+		s.Error = "circuit breaker open"
+		return s
+	}
+
+	pool, poolErr := e.poolCache.get(ctx, e.client.PoolInfo)
+	metricsVal, metricsErr := e.metricsCache.get(ctx, e.client.Metrics)
+	e.breaker.recordResult(firstErr(poolErr, metricsErr))
+	// Re-read breaker state: recordResult may have just flipped it open.
+	s.Breaker = string(e.breaker.snapshot())
+
+	if poolErr != nil {
+		s.Error = poolErr.Error()
+	} else {
+		s.Pool = pool
+	}
+	if metricsErr == nil {
+		s.Metrics = metricsVal
+	} else if s.Error == "" {
+		s.Error = metricsErr.Error()
+	}
+	return s
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+// Package logger provides the structured key/value logging used by
+// backend's long-running service clients (overseer, converter,
+// thumbnailer), so operators can grep output by worker name or severity
+// instead of parsing each client's free-form log.Printf lines.
+package logger
+
+import (
+	"log/slog"
+	"os"
+)
+
+// base is the process-wide handler; every derived Logger shares its
+// destination and level filter.
+var base = slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+// Logger is a slog.Logger alias, so callers get Info/Warn/Error(msg, kv...)
+// without importing log/slog themselves.
+type Logger = slog.Logger
+
+// New returns a Logger with kv permanently attached to every line it emits,
+// e.g. New("worker", "converter").
+func New(kv ...any) *Logger {
+	return base.With(kv...)
+}
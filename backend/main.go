@@ -10,13 +10,20 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/whisper-darkly/sticky-dvr/backend/auth"
 	"github.com/whisper-darkly/sticky-dvr/backend/config"
 	"github.com/whisper-darkly/sticky-dvr/backend/converter"
+	"github.com/whisper-darkly/sticky-dvr/backend/diagnostics"
 	"github.com/whisper-darkly/sticky-dvr/backend/manager"
+	"github.com/whisper-darkly/sticky-dvr/backend/metrics"
+	"github.com/whisper-darkly/sticky-dvr/backend/notifier"
 	"github.com/whisper-darkly/sticky-dvr/backend/overseer"
 	"github.com/whisper-darkly/sticky-dvr/backend/router"
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
+	"github.com/whisper-darkly/sticky-dvr/backend/store/etcd"
 	"github.com/whisper-darkly/sticky-dvr/backend/store/postgres"
 	"github.com/whisper-darkly/sticky-dvr/backend/thumbnailer"
+	"github.com/whisper-darkly/sticky-dvr/backend/workers"
 )
 
 var version = "dev"
@@ -24,48 +31,97 @@ var version = "dev"
 func main() {
 	port := env("BACKEND_PORT", "8080")
 	overseerURL := env("OVERSEER_URL", "ws://localhost:8081/ws")
+	logDir := env("LOG_DIR", "./logs")
+	metricsPath := env("PROMETHEUS_METRICS_PATH", "/metrics")
+
+	readyzTimeout, err := time.ParseDuration(env("READYZ_TIMEOUT", "5s"))
+	if err != nil {
+		log.Fatalf("READYZ_TIMEOUT: %v", err)
+	}
 
 	dbDSN := os.Getenv("DB_DSN")
 	if dbDSN == "" {
 		log.Fatal("DB_DSN environment variable is required")
 	}
 
+	keysDir := os.Getenv("AUTH_KEYS_DIR")
 	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Fatal("JWT_SECRET environment variable is required")
+	if keysDir == "" && jwtSecret == "" {
+		log.Fatal("either AUTH_KEYS_DIR or JWT_SECRET environment variable is required")
 	}
 
 	fmt.Printf("sticky-backend %s\n", version)
 
+	// Asymmetric signing keys when AUTH_KEYS_DIR is set, so downstream
+	// services can verify tokens via /.well-known/jwks.json without sharing
+	// a secret; otherwise fall back to the single shared HS256 secret.
+	var keys *auth.KeySet
+	if keysDir != "" {
+		keys, err = auth.LoadKeySet(keysDir)
+		if err != nil {
+			log.Fatalf("auth keys: %v", err)
+		}
+		if keys.Empty() {
+			if err := keys.Rotate(auth.AccessTokenTTL()); err != nil {
+				log.Fatalf("auth: generate initial signing key: %v", err)
+			}
+			log.Printf("auth: generated initial signing key in %s", keysDir)
+		}
+	} else {
+		keys = auth.NewHMACKeySet([]byte(jwtSecret))
+	}
+
+	// Reload AUTH_KEYS_DIR on SIGHUP, so an operator can rotate (or drop in
+	// externally-issued keys) without a restart.
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	go func() {
+		for range hupCh {
+			if err := keys.Reload(); err != nil {
+				log.Printf("auth: reload keys: %v", err)
+			} else {
+				log.Println("auth: reloaded signing keys")
+			}
+		}
+	}()
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Open postgres store + run migrations.
-	db, err := postgres.Open(ctx, dbDSN)
+	// Open the configured store backend and run migrations (postgres only;
+	// etcd has no schema to migrate).
+	backend, err := openStore(ctx, env("STORE_BACKEND", "postgres"), dbDSN)
 	if err != nil {
-		log.Fatalf("database: %v", err)
+		log.Fatalf("store: %v", err)
 	}
-	defer db.Close()
+	defer backend.Close()
 
-	// Seed admin user if ADMIN_PASSWORD is set and no users exist yet.
+	// Seed admin user if ADMIN_PASSWORD is set and no users exist yet. Done
+	// against the Store interface (not a postgres-specific fast-path query)
+	// so it works identically on both backends.
 	adminUser := env("ADMIN_USERNAME", "admin")
 	adminPass := os.Getenv("ADMIN_PASSWORD")
 	if adminPass != "" {
-		if err := db.SeedAdminUser(ctx, adminUser, adminPass); err != nil {
+		if err := seedAdminUser(ctx, backend, adminUser, adminPass); err != nil {
 			log.Fatalf("seed admin user: %v", err)
 		}
-		log.Printf("seeded admin user: %s", adminUser)
 	} else {
 		log.Println("ADMIN_PASSWORD not set; skipping admin user seeding")
 	}
 
+	// Wrap the store so every query is observed by store_query_duration_seconds.
+	st := metrics.NewMetricsStore(backend)
+
 	// Load config (seeds defaults into DB if first run).
-	cfg, err := config.Load(ctx, db)
+	cfg, err := config.Load(ctx, st)
 	if err != nil {
 		log.Fatalf("config: %v", err)
 	}
 
-	mgr := manager.New(cfg, db)
+	mgr := manager.New(cfg, st, logDir)
+
+	notifyDispatcher := notifier.NewDispatcher(st)
+	mgr.SetNotifier(notifyDispatcher)
 
 	oc := overseer.NewClient(overseerURL, overseer.Handler{
 		OnStarted:    mgr.OnStarted,
@@ -73,40 +129,68 @@ func main() {
 		OnExited:     mgr.OnExited,
 		OnRestarting: mgr.OnRestarting,
 		OnErrored:    mgr.OnErrored,
-		OnConnected:  mgr.OnConnected,
+		OnConnected: func() {
+			metrics.OverseerReconnects.Inc()
+			mgr.OnConnected()
+		},
 	})
 	mgr.SetOverseerClient(oc)
 
-	go oc.Run(ctx)
+	// wrk is the Registry of long-running upstream clients (overseer,
+	// converter, thumbnailer): one place that starts each client's Run loop
+	// and logs its connect/disconnect/reconnect transitions with a
+	// worker=<name> tag, instead of every client call site repeating its own
+	// "go client.Run(ctx)" and ad hoc log.Printf line.
+	wrk := workers.NewRegistry()
+	wrk.Overseer = oc
+	wrk.Add(workers.NewOverseerWorker(oc, overseerURL))
+
+	go metrics.PollOverseer(ctx, oc, overseerURL, 15*time.Second)
+	go metrics.PollStoreCounts(ctx, st, 15*time.Second)
 
 	if err := mgr.Start(ctx); err != nil {
 		log.Fatalf("manager: %v", err)
 	}
 
-	// Converter client (optional — graceful degradation if CONVERTER_URL not set).
-	var convClient *converter.Client
+	// Converter client (optional — /files endpoint returns an error from the
+	// handler, not a silent empty list, once CONVERTER_URL is set but the
+	// converter itself is unreachable; see converter.Client's doc comment).
 	if converterURL := os.Getenv("CONVERTER_URL"); converterURL != "" {
-		convClient = converter.NewClient(converterURL)
-		log.Printf("converter client: %s", converterURL)
+		wrk.Converter = converter.NewClient(converterURL)
+		wrk.Add(workers.NewConverterWorker(wrk.Converter, converterURL))
 	} else {
 		log.Println("CONVERTER_URL not set; /files endpoint will return empty list")
 	}
 
 	// Thumbnailer client (optional — graceful degradation if THUMBNAILER_URL not set).
-	var thumbClient *thumbnailer.Client
 	if thumbnailerURL := os.Getenv("THUMBNAILER_URL"); thumbnailerURL != "" {
-		thumbClient = thumbnailer.NewClient(thumbnailerURL)
-		log.Printf("thumbnailer client: %s", thumbnailerURL)
+		wrk.Thumbnailer = thumbnailer.NewClient(thumbnailerURL)
+		wrk.Add(workers.NewThumbnailerWorker(wrk.Thumbnailer, thumbnailerURL))
+		go metrics.PollThumbnailer(ctx, wrk.Thumbnailer, 15*time.Second)
 	} else {
 		log.Println("THUMBNAILER_URL not set; thumbnailer diagnostics unavailable")
 	}
 
+	wrk.Run(ctx)
+
+	// Service registry backing /api/admin/diagnostics: each client gets its
+	// own circuit breaker + TTL cache so a downed backend fails fast instead
+	// of re-dialing on every diagnostics request.
+	services := diagnostics.NewRegistry()
+	services.Register(diagnostics.NewOverseerAdapter(oc), diagnostics.DefaultBreakerConfig)
+	if wrk.Converter != nil {
+		services.Register(diagnostics.NewConverterAdapter(wrk.Converter), diagnostics.DefaultBreakerConfig)
+	}
+	if wrk.Thumbnailer != nil {
+		services.Register(diagnostics.NewThumbnailerAdapter(wrk.Thumbnailer), diagnostics.DefaultBreakerConfig)
+	}
+
 	// Periodically delete expired sessions (every hour).
 	go func() {
 		ticker := time.NewTicker(1 * time.Hour)
 		defer ticker.Stop()
 		for range ticker.C {
-			if err := db.DeleteExpiredSessions(ctx); err != nil {
+			if err := backend.DeleteExpiredSessions(ctx); err != nil {
 				log.Printf("delete expired sessions: %v", err)
 			}
 		}
@@ -115,12 +199,15 @@ func main() {
 	srv := &http.Server{
 		Addr: ":" + port,
 		Handler: router.New(router.Deps{
-			Store:             db,
-			Manager:           mgr,
-			Config:            cfg,
-			JWTSecret:         []byte(jwtSecret),
-			ConverterClient:   convClient,
-			ThumbnailerClient: thumbClient,
+			Store:         st,
+			Manager:       mgr,
+			Config:        cfg,
+			Keys:          keys,
+			Workers:       wrk,
+			Notifier:      notifyDispatcher,
+			ReadyzTimeout: readyzTimeout,
+			Services:      services,
+			MetricsPath:   metricsPath,
 		}),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 30 * time.Second,
@@ -139,13 +226,28 @@ func main() {
 
 	<-sigCh
 	log.Println("shutting down…")
-	cancel()
 
 	shutCtx, shutCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutCancel()
 	if err := srv.Shutdown(shutCtx); err != nil {
 		log.Printf("shutdown: %v", err)
 	}
+
+	// Drain recording workers before tearing down the overseer connection, so
+	// in-progress segments get a chance to finish instead of truncating.
+	leaveCtx, leaveCancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer leaveCancel()
+	if err := mgr.Leave(leaveCtx); err != nil {
+		log.Printf("drain: %v", err)
+	}
+
+	cancel()
+
+	ocShutCtx, ocShutCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer ocShutCancel()
+	if err := oc.Shutdown(ocShutCtx); err != nil {
+		log.Printf("overseer shutdown: %v", err)
+	}
 }
 
 func env(key, def string) string {
@@ -154,3 +256,40 @@ func env(key, def string) string {
 	}
 	return def
 }
+
+// openStore dials the backend named by STORE_BACKEND ("postgres" or
+// "etcd"), both reachable via dsn: a postgres connection string for the
+// former, a comma-separated list of etcd endpoints for the latter.
+func openStore(ctx context.Context, backend, dsn string) (store.Store, error) {
+	switch backend {
+	case "postgres", "":
+		return postgres.Open(ctx, dsn, postgres.WithConfigValidator(config.Validate))
+	case "etcd":
+		return etcd.Open(ctx, dsn, etcd.WithConfigValidator(config.Validate))
+	default:
+		return nil, fmt.Errorf("unknown STORE_BACKEND %q (want \"postgres\" or \"etcd\")", backend)
+	}
+}
+
+// seedAdminUser creates an admin user with the given credentials only when
+// no user exists yet (i.e. fresh deployment), the store.Store-generic
+// replacement for postgres.DB's SQL fast-path COUNT(*) check so it works
+// the same on every backend.
+func seedAdminUser(ctx context.Context, st store.Store, username, password string) error {
+	users, err := st.ListUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("list users: %w", err)
+	}
+	if len(users) > 0 {
+		return nil // already seeded
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	if _, err := st.CreateUser(ctx, username, hash, "admin"); err != nil {
+		return err
+	}
+	log.Printf("seeded admin user: %s", username)
+	return nil
+}
@@ -0,0 +1,98 @@
+package manager
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
+)
+
+const (
+	eventQueueSize         = 16
+	defaultEventFlushDelay = 2 * time.Second
+)
+
+// enqueueEvent queues ev for coalesced persistence, dropping it rather than
+// blocking the overseer callback dispatch thread if the per-source coalescer
+// has fallen behind.
+func (s *sourceState) enqueueEvent(ev store.WorkerEventInput) {
+	select {
+	case s.eventCh <- ev:
+	default:
+		log.Printf("manager: event queue full for %s/%s, dropping %s event", s.source.Driver, s.source.Username, ev.EventType)
+	}
+}
+
+// isTerminalEvent reports whether ev should flush the pending batch
+// immediately rather than waiting out EventFlushDelay for more to arrive.
+func isTerminalEvent(ev store.WorkerEventInput) bool {
+	return ev.EventType == store.EventErrored || (ev.EventType == store.EventExited && ev.Intentional)
+}
+
+// eventCoalesceLoop drains state.eventCh, batching events that arrive within
+// EventFlushDelay of each other into one RecordWorkerEvents call. Terminal
+// events flush whatever is pending (including themselves) right away. One of
+// these runs per tracked source for the lifetime of the manager; it exits and
+// flushes any remainder when m.ctx is cancelled (manager shutdown) or the
+// channel is closed.
+func (m *Manager) eventCoalesceLoop(sourceID int64, state *sourceState) {
+	var pending []store.WorkerEventInput
+	var timer *time.Timer
+	var timerC <-chan time.Time
+
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		batch := pending
+		pending = nil
+		if err := m.st.RecordWorkerEvents(context.Background(), sourceID, batch); err != nil {
+			log.Printf("manager: record coalesced worker events source=%d: %v", sourceID, err)
+		}
+	}
+	stopTimer := func() {
+		if timer == nil {
+			return
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timerC = nil
+	}
+
+	for {
+		select {
+		case ev, ok := <-state.eventCh:
+			if !ok {
+				flush()
+				return
+			}
+			pending = append(pending, ev)
+			if isTerminalEvent(ev) {
+				stopTimer()
+				flush()
+				continue
+			}
+			delay := parseDuration(m.cfg.Get().EventFlushDelay, defaultEventFlushDelay)
+			if timer == nil {
+				timer = time.NewTimer(delay)
+			} else {
+				stopTimer()
+				timer.Reset(delay)
+			}
+			timerC = timer.C
+
+		case <-timerC:
+			timerC = nil
+			flush()
+
+		case <-m.ctx.Done():
+			flush()
+			return
+		}
+	}
+}
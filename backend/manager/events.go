@@ -0,0 +1,228 @@
+package manager
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType identifies the shape of an Event's populated payload field.
+type EventType string
+
+const (
+	EventWorkerStateChanged         EventType = "worker_state_changed"
+	EventSessionStarted             EventType = "session_started"
+	EventSessionEnded               EventType = "session_ended"
+	EventRecordingStateChanged      EventType = "recording_state_changed"
+	EventTaskClaimed                EventType = "task_claimed"
+	EventSubscriptionPostureChanged EventType = "subscription_posture_changed"
+	EventRestartScheduled           EventType = "restart_scheduled"
+	EventConfigChanged              EventType = "config_changed"
+)
+
+// Event is one state transition published through Manager.emit. Exactly the
+// payload field matching Type is non-nil; callers switch on Type rather than
+// nil-checking every field. ID is assigned by eventBus.publish in emission
+// order and is what SSE clients send back as Last-Event-ID to resume.
+type Event struct {
+	ID   uint64    `json:"id"`
+	Type EventType `json:"type"`
+	TS   time.Time `json:"ts"`
+
+	WorkerStateChanged         *WorkerStateChangedPayload         `json:"worker_state_changed,omitempty"`
+	SessionStarted             *SessionStartedPayload             `json:"session_started,omitempty"`
+	SessionEnded               *SessionEndedPayload               `json:"session_ended,omitempty"`
+	RecordingStateChanged      *RecordingStateChangedPayload      `json:"recording_state_changed,omitempty"`
+	TaskClaimed                *TaskClaimedPayload                `json:"task_claimed,omitempty"`
+	SubscriptionPostureChanged *SubscriptionPostureChangedPayload `json:"subscription_posture_changed,omitempty"`
+	RestartScheduled           *RestartScheduledPayload           `json:"restart_scheduled,omitempty"`
+	ConfigChanged              *ConfigChangedPayload              `json:"config_changed,omitempty"`
+}
+
+type WorkerStateChangedPayload struct {
+	SourceID int64  `json:"source_id"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+type SessionStartedPayload struct {
+	SourceID int64 `json:"source_id"`
+}
+
+type SessionEndedPayload struct {
+	SourceID int64 `json:"source_id"`
+}
+
+type RecordingStateChangedPayload struct {
+	SourceID int64  `json:"source_id"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+type TaskClaimedPayload struct {
+	SourceID int64  `json:"source_id"`
+	TaskID   string `json:"task_id"`
+	PID      int    `json:"pid"`
+}
+
+type SubscriptionPostureChangedPayload struct {
+	SubID int64  `json:"sub_id"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type RestartScheduledPayload struct {
+	SourceID   int64     `json:"source_id"`
+	DelayUntil time.Time `json:"delay_until"`
+}
+
+// ConfigChangedPayload is manager-wide rather than per-source, so workers
+// pick up new segment lengths/retention windows without a restart; it isn't
+// scoped by eventSourceID and so only reaches admin-wide subscribers
+// (streamManagerEvents), never Manager.SubscribeSource.
+type ConfigChangedPayload struct {
+	Fingerprint string `json:"fingerprint"`
+}
+
+// eventSubscriber pairs a subscriber's channel with a counter of events
+// dropped for it since its last successful delivery, mirroring logfile.go's
+// tailSubscriber.
+type eventSubscriber struct {
+	ch      chan Event
+	dropped int64
+}
+
+// eventBus is a non-blocking fan-out queue for Events, modeled on Swarmkit's
+// watch.Queue: publish never blocks on a slow subscriber. Unlike Swarmkit,
+// a full subscriber channel drops its OLDEST queued event rather than the
+// new one, since a reconnecting SSE client cares about the latest state far
+// more than a stale one it would discard anyway; replay backfills anything
+// it missed from the shared ring buffer instead.
+type eventBus struct {
+	mu   sync.RWMutex
+	subs map[chan Event]*eventSubscriber
+
+	ringMu sync.Mutex
+	nextID uint64
+	ring   []Event // most-recent eventRingSize events, oldest first
+}
+
+const eventChanBuffer = 64
+
+// eventRingSize bounds how far back Last-Event-ID resume can reach; past
+// that a client is told to re-fetch a fresh snapshot instead (e.g. via
+// adminGetUserSubscriptions/listWorkers) rather than replay forever.
+const eventRingSize = 256
+
+func newEventBus() *eventBus {
+	return &eventBus{subs: make(map[chan Event]*eventSubscriber)}
+}
+
+func (b *eventBus) subscribe() (chan Event, func()) {
+	ch := make(chan Event, eventChanBuffer)
+	b.mu.Lock()
+	b.subs[ch] = &eventSubscriber{ch: ch}
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		delete(b.subs, ch)
+		b.mu.Unlock()
+	}
+	return ch, cancel
+}
+
+// publish assigns ev the next sequence ID, records it in the replay ring,
+// and fans it out to every subscriber, evicting a slow subscriber's oldest
+// queued event to make room rather than dropping ev itself.
+func (b *eventBus) publish(ev Event) {
+	b.ringMu.Lock()
+	b.nextID++
+	ev.ID = b.nextID
+	if len(b.ring) >= eventRingSize {
+		b.ring = b.ring[1:]
+	}
+	b.ring = append(b.ring, ev)
+	b.ringMu.Unlock()
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subs {
+		select {
+		case sub.ch <- ev:
+			continue
+		default:
+		}
+		// Full: drop the oldest queued event for this subscriber, then retry.
+		select {
+		case <-sub.ch:
+			atomic.AddInt64(&sub.dropped, 1)
+		default:
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			atomic.AddInt64(&sub.dropped, 1)
+		}
+	}
+}
+
+// eventsSince returns every ringed event with ID > lastID, oldest first. If
+// lastID is older than anything still in the ring, ok is false so the caller
+// knows some events were lost to eviction rather than silently skipped.
+func (b *eventBus) eventsSince(lastID uint64) (events []Event, ok bool) {
+	b.ringMu.Lock()
+	defer b.ringMu.Unlock()
+	if len(b.ring) == 0 {
+		return nil, true
+	}
+	oldest := b.ring[0].ID
+	if lastID != 0 && lastID < oldest-1 {
+		return nil, false
+	}
+	for _, ev := range b.ring {
+		if ev.ID > lastID {
+			events = append(events, ev)
+		}
+	}
+	return events, true
+}
+
+// EventSubscription is a live handle on the event bus returned by
+// Manager.SubscribeEvents. Callers must call Close when done to free the
+// subscriber slot.
+type EventSubscription struct {
+	ch     chan Event
+	cancel func()
+}
+
+func (s *EventSubscription) C() <-chan Event { return s.ch }
+func (s *EventSubscription) Close()          { s.cancel() }
+
+// SubscribeEvents registers a new subscriber on the manager's event bus. The
+// name avoids colliding with the existing Manager.Subscribe (which creates a
+// user's subscription to a source, not an event stream).
+func (m *Manager) SubscribeEvents() *EventSubscription {
+	ch, cancel := m.events.subscribe()
+	return &EventSubscription{ch: ch, cancel: cancel}
+}
+
+// EventsSince returns buffered events published after lastID, for an SSE
+// client resuming via Last-Event-ID. ok is false if lastID has already
+// fallen out of the ring, meaning the caller should warn it may have missed
+// events rather than replay a gap silently.
+func (m *Manager) EventsSince(lastID uint64) (events []Event, ok bool) {
+	return m.events.eventsSince(lastID)
+}
+
+// emit stamps ev with the current time if unset and publishes it; every
+// event on the bus goes through here. Not every state change is published:
+// the overseer callbacks (OnStarted, OnOutput, OnExited, OnErrored) update
+// worker and recording state without calling emit, and no call site emits
+// EventSessionStarted, EventSessionEnded or EventRecordingStateChanged.
+func (m *Manager) emit(ev Event) {
+	if ev.TS.IsZero() {
+		ev.TS = time.Now()
+	}
+	m.events.publish(ev)
+}
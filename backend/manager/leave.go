@@ -0,0 +1,188 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// leaveCounts tracks in-flight drain progress, guarded by Manager.leaveMu.
+type leaveCounts struct {
+	draining     int
+	drained      int
+	forceStopped int
+}
+
+// LeaveStatus is the API-facing snapshot of Leave's progress.
+type LeaveStatus struct {
+	Draining     int `json:"draining"`
+	Drained      int `json:"drained"`
+	ForceStopped int `json:"force_stopped"`
+}
+
+func (m *Manager) isLeaving() bool {
+	m.leaveMu.Lock()
+	defer m.leaveMu.Unlock()
+	return m.leaving
+}
+
+// Undrain reverses a prior Leave call, letting Subscribe/Resume and the admin
+// restart endpoints accept requests again and allowing reconcileLoop and
+// bulkStart to queue new starts. Any drainSource goroutines still waiting out
+// their LeaveTimeout abandon their force-stop instead of stopping a worker an
+// operator just asked to keep running. It does not resume or restart sources
+// that were already force-stopped before Undrain was called — operators do
+// that explicitly. Safe to call whether or not Leave is in progress or
+// already finished.
+func (m *Manager) Undrain() {
+	m.leaveMu.Lock()
+	defer m.leaveMu.Unlock()
+	if !m.leaving {
+		return
+	}
+	m.leaving = false
+	m.leaveGen++
+	m.leaveCounts = leaveCounts{}
+	m.leaveDone = make(chan struct{})
+}
+
+// LeaveStatus returns a point-in-time count of sources still draining,
+// cleanly drained, and force-stopped since Leave was called.
+func (m *Manager) LeaveStatus() LeaveStatus {
+	m.leaveMu.Lock()
+	defer m.leaveMu.Unlock()
+	return LeaveStatus{
+		Draining:     m.leaveCounts.draining,
+		Drained:      m.leaveCounts.drained,
+		ForceStopped: m.leaveCounts.forceStopped,
+	}
+}
+
+// LeaveDone returns a channel that is closed once every running source has
+// either drained cleanly or been force-stopped. main can block on this
+// before closing the store and overseer client.
+func (m *Manager) LeaveDone() <-chan struct{} {
+	return m.leaveDone
+}
+
+// Leave puts the manager into drain mode: Subscribe and Resume start
+// returning ErrDraining, and every currently running source is given up to
+// the configured LeaveTimeout to reach the end of its current recording
+// session before being force-stopped. It blocks until draining completes or
+// ctx is cancelled — in the latter case drainSource goroutines keep running
+// in the background and LeaveDone still closes once they finish.
+// Calling Leave more than once just waits on the first call's completion.
+func (m *Manager) Leave(ctx context.Context) error {
+	m.leaveMu.Lock()
+	if m.leaving {
+		done := m.leaveDone
+		m.leaveMu.Unlock()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	m.leaving = true
+	gen := m.leaveGen
+	m.leaveMu.Unlock()
+
+	m.mu.RLock()
+	states := make([]*sourceState, 0, len(m.states))
+	for _, s := range m.states {
+		states = append(states, s)
+	}
+	m.mu.RUnlock()
+
+	var running []*sourceState
+	for _, s := range states {
+		s.mu.Lock()
+		isRunning := s.workerState == "running"
+		s.mu.Unlock()
+		if isRunning {
+			running = append(running, s)
+		}
+	}
+
+	m.leaveMu.Lock()
+	m.leaveCounts.draining = len(running)
+	done := m.leaveDone
+	m.leaveMu.Unlock()
+
+	if len(running) == 0 {
+		close(done)
+	} else {
+		timeout := parseDuration(m.cfg.Get().LeaveTimeout, defaultLeaveTimeout)
+		var wg sync.WaitGroup
+		for _, s := range running {
+			wg.Add(1)
+			go func(state *sourceState) {
+				defer wg.Done()
+				m.drainSource(state, timeout, gen)
+			}(s)
+		}
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drainSource waits for state's recording session to end (or the process to
+// exit on its own) up to timeout, falling back to a hard stopWorker. gen is
+// the leaveGen captured when Leave launched this goroutine; if Undrain has
+// since bumped it, the deadline branch abandons the force-stop instead of
+// killing a worker the operator asked to keep running.
+func (m *Manager) drainSource(state *sourceState, timeout time.Duration, gen int) {
+	state.mu.Lock()
+	pid := state.pid
+	state.mu.Unlock()
+	state.addLog("[system] draining — waiting for session end")
+	state.logSystem(pid, "draining — waiting for session end")
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		state.mu.Lock()
+		sessionOver := !state.sessionActive || state.workerState != "running"
+		state.mu.Unlock()
+		if sessionOver {
+			m.leaveMu.Lock()
+			if m.leaveGen == gen {
+				m.leaveCounts.draining--
+				m.leaveCounts.drained++
+			}
+			m.leaveMu.Unlock()
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			m.leaveMu.Lock()
+			stillDraining := m.leaveGen == gen
+			m.leaveMu.Unlock()
+			if !stillDraining {
+				state.addLog("[system] drain cancelled (undrain) — leaving worker running")
+				return
+			}
+			m.stopWorker(state)
+			m.leaveMu.Lock()
+			m.leaveCounts.draining--
+			m.leaveCounts.forceStopped++
+			m.leaveMu.Unlock()
+			return
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package manager
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultLogMaxBytes   = 50 * 1024 * 1024 // rotate at 50MB
+	defaultLogMaxBackups = 5
+	logReopenInterval    = 10 * time.Second
+)
+
+// LogLine is one JSON envelope in a source's durable log file. Event carries
+// the raw recorder JSON event object when OnOutput successfully parsed one;
+// otherwise Text carries the wrapped non-JSON line.
+type LogLine struct {
+	TS     time.Time       `json:"ts"`
+	Stream string          `json:"stream"` // stdout | stderr | system
+	PID    int             `json:"pid,omitempty"`
+	Event  json.RawMessage `json:"event,omitempty"`
+	Text   string          `json:"text,omitempty"`
+}
+
+// LogStreamMsg is one message delivered to a live tail subscriber: the line
+// itself, plus Dropped — how many earlier lines this subscriber missed
+// because it fell behind and its buffer filled (see tailSubscriber). Zero
+// in the common case.
+type LogStreamMsg struct {
+	Line    LogLine `json:"line"`
+	Dropped int     `json:"dropped,omitempty"`
+}
+
+// tailSubscriber pairs a live-tail channel with a counter of lines dropped
+// for it since the last successful delivery. dropped is accessed with
+// atomics so broadcast (holding tailMu for read only) can bump it without
+// contending with concurrent (un)subscribes.
+type tailSubscriber struct {
+	ch      chan LogStreamMsg
+	dropped int64
+}
+
+// sourceLog is a durable, rotating, append-only JSONL log file for one
+// source's recorder output. Unlike sourceState's in-memory ring buffer, it
+// survives manager restarts and can be grepped directly on disk.
+type sourceLog struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+
+	tailMu   sync.RWMutex
+	tailSubs map[chan LogStreamMsg]*tailSubscriber
+}
+
+// newSourceLog opens (creating if necessary) dir/driver/username.jsonl for append.
+func newSourceLog(dir, driver, username string, maxBytes int64, maxBackups int) (*sourceLog, error) {
+	if maxBytes <= 0 {
+		maxBytes = defaultLogMaxBytes
+	}
+	if maxBackups <= 0 {
+		maxBackups = defaultLogMaxBackups
+	}
+	subdir := filepath.Join(dir, driver)
+	if err := os.MkdirAll(subdir, 0o755); err != nil {
+		return nil, fmt.Errorf("create log dir %s: %w", subdir, err)
+	}
+	sl := &sourceLog{
+		path:       filepath.Join(subdir, username+".jsonl"),
+		maxBytes:   maxBytes,
+		maxBackups: maxBackups,
+		tailSubs:   make(map[chan LogStreamMsg]*tailSubscriber),
+	}
+	if err := sl.open(); err != nil {
+		return nil, err
+	}
+	return sl, nil
+}
+
+func (sl *sourceLog) open() error {
+	f, err := os.OpenFile(sl.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file %s: %w", sl.path, err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("stat log file %s: %w", sl.path, err)
+	}
+	sl.f = f
+	sl.size = fi.Size()
+	return nil
+}
+
+// Reopen closes and reopens the log file, so an external logrotate renaming
+// the current file out from under us is picked up on the next Append.
+func (sl *sourceLog) Reopen() error {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	if sl.f != nil {
+		sl.f.Close()
+	}
+	return sl.open()
+}
+
+// Append writes line as a single JSON line, rotating first if the current
+// file has grown past maxBytes, and fans it out to any live tail subscribers.
+func (sl *sourceLog) Append(line LogLine) error {
+	b, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	sl.mu.Lock()
+	if sl.size+int64(len(b)) > sl.maxBytes {
+		if err := sl.rotateLocked(); err != nil {
+			sl.mu.Unlock()
+			return err
+		}
+	}
+	n, err := sl.f.Write(b)
+	sl.size += int64(n)
+	sl.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	sl.broadcast(line)
+	return nil
+}
+
+// rotateLocked shifts path.(K-1) -> path.K ... current path -> path.1 and
+// reopens a fresh file at path. Must be called with sl.mu held.
+func (sl *sourceLog) rotateLocked() error {
+	if sl.f != nil {
+		sl.f.Close()
+	}
+	for i := sl.maxBackups; i >= 1; i-- {
+		if i == sl.maxBackups {
+			os.Remove(sl.backupPath(i))
+			continue
+		}
+		src := sl.backupPath(i)
+		if _, err := os.Stat(src); err == nil {
+			os.Rename(src, sl.backupPath(i+1))
+		}
+	}
+	if _, err := os.Stat(sl.path); err == nil {
+		if err := os.Rename(sl.path, sl.backupPath(1)); err != nil {
+			return fmt.Errorf("rotate %s: %w", sl.path, err)
+		}
+	}
+	return sl.open()
+}
+
+func (sl *sourceLog) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", sl.path, n)
+}
+
+// Close closes the underlying file handle. Live tail subscribers are left
+// alone — the caller is expected to already have drained them down.
+func (sl *sourceLog) Close() error {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	if sl.f == nil {
+		return nil
+	}
+	return sl.f.Close()
+}
+
+// SubscribeTail registers ch to receive every line appended from now on,
+// wrapped in a LogStreamMsg carrying how many lines it has dropped since its
+// last successful delivery. The caller must eventually call UnsubscribeTail.
+func (sl *sourceLog) SubscribeTail(ch chan LogStreamMsg) {
+	sl.tailMu.Lock()
+	defer sl.tailMu.Unlock()
+	sl.tailSubs[ch] = &tailSubscriber{ch: ch}
+}
+
+// UnsubscribeTail removes ch. Safe to call more than once.
+func (sl *sourceLog) UnsubscribeTail(ch chan LogStreamMsg) {
+	sl.tailMu.Lock()
+	defer sl.tailMu.Unlock()
+	delete(sl.tailSubs, ch)
+}
+
+// broadcast fans line out to every live subscriber without blocking on any
+// of them: a subscriber whose buffer is full has this line counted against
+// its dropped total instead, delivered as soon as a later send succeeds.
+func (sl *sourceLog) broadcast(line LogLine) {
+	sl.tailMu.RLock()
+	defer sl.tailMu.RUnlock()
+	for _, sub := range sl.tailSubs {
+		dropped := atomic.SwapInt64(&sub.dropped, 0)
+		select {
+		case sub.ch <- LogStreamMsg{Line: line, Dropped: int(dropped)}:
+		default:
+			atomic.AddInt64(&sub.dropped, dropped+1)
+		}
+	}
+}
+
+// ReadSince returns up to limit lines with TS after since (zero since means
+// no lower bound), read from the current file and rotated backups, oldest
+// first. Missing/already-evicted backup files are skipped rather than erroring.
+func (sl *sourceLog) ReadSince(since time.Time, limit int) ([]LogLine, error) {
+	if limit <= 0 {
+		limit = 200
+	}
+
+	sl.mu.Lock()
+	maxBackups := sl.maxBackups
+	sl.mu.Unlock()
+
+	paths := make([]string, 0, maxBackups+1)
+	for i := maxBackups; i >= 1; i-- {
+		paths = append(paths, sl.backupPath(i))
+	}
+	paths = append(paths, sl.path)
+
+	var out []LogLine
+	for _, p := range paths {
+		lines, err := readLinesSince(p, since)
+		if err != nil {
+			continue
+		}
+		out = append(out, lines...)
+	}
+	if len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out, nil
+}
+
+func readLinesSince(path string, since time.Time) ([]LogLine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []LogLine
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		var ln LogLine
+		if err := json.Unmarshal(sc.Bytes(), &ln); err != nil {
+			continue
+		}
+		if !since.IsZero() && !ln.TS.After(since) {
+			continue
+		}
+		out = append(out, ln)
+	}
+	return out, sc.Err()
+}
@@ -13,6 +13,7 @@ package manager
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -20,12 +21,35 @@ import (
 	"time"
 
 	"github.com/whisper-darkly/sticky-dvr/backend/config"
+	"github.com/whisper-darkly/sticky-dvr/backend/notifier"
 	"github.com/whisper-darkly/sticky-dvr/backend/overseer"
 	"github.com/whisper-darkly/sticky-dvr/backend/store"
 )
 
 const maxLogs = 200
 
+// ErrDraining is returned by Subscribe/Resume once Leave has been called.
+var ErrDraining = errors.New("manager: draining, not accepting new workers")
+
+// defaultLeaveTimeout bounds how long Leave waits for a source's current
+// segment to finish before falling back to a hard stopWorker.
+const defaultLeaveTimeout = 30 * time.Second
+
+// defaultMinRestartInterval is how soon after a start Restart refuses to
+// fire again, absent an explicit config.Data.MinRestartInterval.
+const defaultMinRestartInterval = 10 * time.Second
+
+// RestartCooldownError is returned by Restart when called again before
+// MinRestartInterval has elapsed since the worker's last start, so the UI can
+// show "please wait Ns" instead of spamming the overseer with Stop/Start pairs.
+type RestartCooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RestartCooldownError) Error() string {
+	return fmt.Sprintf("restart: please wait %s before restarting again", e.RetryAfter.Round(time.Second))
+}
+
 // sourceState holds in-memory runtime state for one source.
 type sourceState struct {
 	source *store.Source
@@ -35,6 +59,10 @@ type sourceState struct {
 	workerState  string
 	errorMessage string
 	logs         []string
+	// log is the durable rotating JSONL log file for this source. Writes to
+	// it happen outside state.mu (sourceLog has its own locking) so a slow
+	// disk never blocks readers of the in-memory ring buffer above.
+	log *sourceLog
 	// Recording-level state derived from recorder JSON output events.
 	recordingState  string    // recording | sleeping | idle
 	sessionDuration string    // last known session duration from HEARTBEAT
@@ -45,6 +73,38 @@ type sourceState struct {
 	// and SLEEP events, so the UI can show the source as "in session" without debounce logic.
 	sessionActive    bool
 	sessionStartedAt time.Time // wall-clock time of the first RECORDING START in this session
+
+	// Resource sampling (see stats.go). statsMu guards stats independently of
+	// mu so a slow /proc read never blocks the hot addLog/dispatch path above.
+	statsMu      sync.Mutex
+	stats        []WorkerResourceUsage // rolling window, oldest first, capped at maxStatsSamples
+	sampleCancel context.CancelFunc    // stops the running sampler goroutine, if any
+
+	// lastStart and restartCount track startWorker successes for Restart's
+	// cooldown and for display. restartCount is since this process started,
+	// not persisted.
+	lastStart    time.Time
+	restartCount int
+
+	// eventCh feeds this source's worker-event coalescer (see eventqueue.go).
+	eventCh chan store.WorkerEventInput
+
+	// restarts gates startWorker attempts against a crash loop (see restartpolicy.go).
+	restarts *restartTracker
+	// startErr is the error from the most recent failed m.oc.Start call, if any.
+	startErr error
+}
+
+func (s *sourceState) setLastStartErr(err error) {
+	s.mu.Lock()
+	s.startErr = err
+	s.mu.Unlock()
+}
+
+func (s *sourceState) lastStartErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startErr
 }
 
 func (s *sourceState) addLog(line string) {
@@ -64,6 +124,33 @@ func (s *sourceState) getLogs() []string {
 	return out
 }
 
+// logSystem appends a system-stream line to the durable log, if one is open.
+func (s *sourceState) logSystem(pid int, text string) {
+	if s.log == nil {
+		return
+	}
+	if err := s.log.Append(LogLine{TS: time.Now(), Stream: "system", PID: pid, Text: text}); err != nil {
+		log.Printf("manager: append durable log for %s/%s: %v", s.source.Driver, s.source.Username, err)
+	}
+}
+
+// logOutput appends a stdout/stderr-stream line to the durable log, if one is
+// open. data is stored as a raw JSON event when it parses as one, else as text.
+func (s *sourceState) logOutput(pid int, stream, data string) {
+	if s.log == nil {
+		return
+	}
+	line := LogLine{TS: time.Now(), Stream: stream, PID: pid}
+	if json.Valid([]byte(data)) {
+		line.Event = json.RawMessage(data)
+	} else {
+		line.Text = data
+	}
+	if err := s.log.Append(line); err != nil {
+		log.Printf("manager: append durable log for %s/%s: %v", s.source.Driver, s.source.Username, err)
+	}
+}
+
 // SubscriptionStatus is the API-facing combined view of a source + subscription
 // + runtime state, scoped to one user's subscription.
 type SubscriptionStatus struct {
@@ -95,6 +182,21 @@ type SubscriptionStatus struct {
 	SessionActive    bool       `json:"session_active"`
 	SessionStartedAt *time.Time `json:"session_started_at,omitempty"`
 
+	// Latest resource-usage sample, if any (see stats.go).
+	Stats *WorkerResourceUsage `json:"stats,omitempty"`
+
+	// LastStart and RestartCount track startWorker successes since this
+	// process started (used by Restart to enforce MinRestartInterval).
+	LastStart    *time.Time `json:"last_start,omitempty"`
+	RestartCount int        `json:"restart_count"`
+
+	// RestartAttemptsInWindow and NextAllowedStart reflect this source's
+	// restartTracker: how many start attempts have landed within the
+	// configured RestartPolicy interval, and — if currently backing off —
+	// when the next automatic attempt is allowed. See restartpolicy.go.
+	RestartAttemptsInWindow int        `json:"restart_attempts_in_window"`
+	NextAllowedStart        *time.Time `json:"next_allowed_start,omitempty"`
+
 	// Derived fields
 	CanonicalURL string `json:"canonical_url,omitempty"`
 }
@@ -105,22 +207,64 @@ type Manager struct {
 	states  map[int64]*sourceState // sourceID → runtime state
 	taskIdx map[string]int64       // overseer task_id → sourceID
 
-	cfg *config.Global
-	st  store.Store
-	oc  *overseer.Client
-	ctx context.Context
+	cfg    *config.Global
+	st     store.Store
+	oc     *overseer.Client
+	ctx    context.Context
+	logDir string // base directory for per-source durable JSONL logs
+
+	// notif dispatches lifecycle Events to configured notification channels
+	// (see SetNotifier). Nil until wired, in which case notify is a no-op —
+	// notifications are an optional add-on, not load-bearing for recording.
+	notif *notifier.Dispatcher
+
+	// events is the fan-out bus for state-transition Events (see events.go).
+	events *eventBus
+
+	// Leave/drain state (see leave.go). leaveMu guards all of it.
+	leaveMu     sync.Mutex
+	leaving     bool
+	leaveDone   chan struct{}
+	leaveCounts leaveCounts
+	// leaveGen increments on every Leave/Undrain transition. drainSource
+	// goroutines capture it at launch and abandon their force-stop if it has
+	// moved on, so an Undrain mid-drain doesn't stop a worker an operator
+	// just asked to keep running.
+	leaveGen int
 }
 
 // New creates a Manager. Call SetOverseerClient then Start before use.
-func New(cfg *config.Global, st store.Store) *Manager {
+// logDir is the base directory under which each source gets a
+// {driver}/{username}.jsonl durable log file (see logfile.go).
+func New(cfg *config.Global, st store.Store, logDir string) *Manager {
 	return &Manager{
-		states:  make(map[int64]*sourceState),
-		taskIdx: make(map[string]int64),
-		cfg:     cfg,
-		st:      st,
+		states:    make(map[int64]*sourceState),
+		taskIdx:   make(map[string]int64),
+		leaveDone: make(chan struct{}),
+		events:    newEventBus(),
+		cfg:       cfg,
+		st:        st,
+		logDir:    logDir,
 	}
 }
 
+// newSourceState builds a sourceState for src, opening its durable log file.
+// A failure to open the log is logged but non-fatal: the source still works
+// normally, it just has no durable history until the next successful open.
+func (m *Manager) newSourceState(src *store.Source) *sourceState {
+	state := &sourceState{source: src, workerState: "idle"}
+	sl, err := newSourceLog(m.logDir, src.Driver, src.Username, defaultLogMaxBytes, defaultLogMaxBackups)
+	if err != nil {
+		log.Printf("manager: open durable log for %s/%s: %v", src.Driver, src.Username, err)
+	} else {
+		state.log = sl
+	}
+	state.eventCh = make(chan store.WorkerEventInput, eventQueueSize)
+	go m.eventCoalesceLoop(src.ID, state)
+	state.restarts = &restartTracker{}
+	return state
+}
+
 // SetOverseerClient wires in the overseer client. Must be called before Start.
 func (m *Manager) SetOverseerClient(oc *overseer.Client) {
 	m.oc = oc
@@ -129,6 +273,30 @@ func (m *Manager) SetOverseerClient(oc *overseer.Client) {
 // GetOverseerClient returns the overseer client (may be nil).
 func (m *Manager) GetOverseerClient() *overseer.Client { return m.oc }
 
+// SetNotifier wires in the notification dispatcher. Call before Start.
+// Leaving it unset is fine — notify becomes a no-op.
+func (m *Manager) SetNotifier(n *notifier.Dispatcher) {
+	m.notif = n
+}
+
+// notify publishes ev to the configured notification channels for sourceID,
+// resolving its current subscribers so per-user channels only fire for
+// people actually watching that source. A nil dispatcher (SetNotifier never
+// called) is ignored and a subscriber lookup failure is logged and dropped: notifications
+// are best-effort and must never affect the recording path.
+func (m *Manager) notify(ctx context.Context, sourceID int64, ev notifier.Event) {
+	if m.notif == nil {
+		return
+	}
+	ev.SourceID = sourceID
+	userIDs, err := m.st.GetSourceSubscriberUserIDs(ctx, sourceID)
+	if err != nil {
+		log.Printf("manager: notify: list subscribers for source=%d: %v", sourceID, err)
+		return
+	}
+	m.notif.Publish(ctx, ev, userIDs...)
+}
+
 // Start loads active sources, reconciles with the overseer, and launches
 // the periodic reconciler.
 func (m *Manager) Start(ctx context.Context) error {
@@ -162,7 +330,7 @@ func (m *Manager) Start(ctx context.Context) error {
 		if !seen[src.ID] {
 			continue
 		}
-		state := &sourceState{source: src, workerState: "idle"}
+		state := m.newSourceState(src)
 		m.states[src.ID] = state
 		if src.OverseerTaskID != "" {
 			m.taskIdx[src.OverseerTaskID] = src.ID
@@ -174,9 +342,37 @@ func (m *Manager) Start(ctx context.Context) error {
 	m.reconcileStartup(ctx)
 
 	go m.reconcileLoop(ctx)
+	go m.logReopenLoop(ctx)
 	return nil
 }
 
+// logReopenLoop periodically reopens every source's durable log file so an
+// external logrotate renaming the file out from under us is picked up.
+func (m *Manager) logReopenLoop(ctx context.Context) {
+	ticker := time.NewTicker(logReopenInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.mu.RLock()
+			logs := make([]*sourceLog, 0, len(m.states))
+			for _, s := range m.states {
+				if s.log != nil {
+					logs = append(logs, s.log)
+				}
+			}
+			m.mu.RUnlock()
+			for _, sl := range logs {
+				if err := sl.Reopen(); err != nil {
+					log.Printf("manager: reopen durable log: %v", err)
+				}
+			}
+		}
+	}
+}
+
 // reconcileStartup claims already-running overseer tasks and starts workers
 // for active sources that have no task running.
 func (m *Manager) reconcileStartup(ctx context.Context) {
@@ -249,15 +445,24 @@ func (m *Manager) OnStarted(taskID string, pid int, restartOf int, ts time.Time)
 	state.workerState = "running"
 	state.mu.Unlock()
 
+	state.mu.Lock()
+	driver, username := state.source.Driver, state.source.Username
+	state.mu.Unlock()
+
+	notifyKind := notifier.EventWorkerStarted
 	if restartOf > 0 {
-		state.addLog(fmt.Sprintf("[system] restarted (pid=%d, was %d)", pid, restartOf))
+		msg := fmt.Sprintf("[system] restarted (pid=%d, was %d)", pid, restartOf)
+		state.addLog(msg)
+		state.logSystem(pid, msg)
+		notifyKind = notifier.EventWorkerRecovered
 	} else {
-		state.addLog(fmt.Sprintf("[system] started (pid=%d)", pid))
+		msg := fmt.Sprintf("[system] started (pid=%d)", pid)
+		state.addLog(msg)
+		state.logSystem(pid, msg)
 	}
+	m.notify(context.Background(), sourceID, notifier.Event{Kind: notifyKind, Driver: driver, Username: username})
 
-	if err := m.st.RecordWorkerEvent(context.Background(), sourceID, pid, store.EventStarted, nil); err != nil {
-		log.Printf("manager: record started event source=%d: %v", sourceID, err)
-	}
+	state.enqueueEvent(store.WorkerEventInput{EventType: store.EventStarted, PID: pid, TS: ts})
 }
 
 // OnOutput routes a stdout/stderr line to the source's log buffer,
@@ -272,6 +477,7 @@ func (m *Manager) OnOutput(taskID string, pid int, stream, data string, ts time.
 		return
 	}
 	stateObj.addLog(fmt.Sprintf("[%s] %s", stream, data))
+	stateObj.logOutput(pid, stream, data)
 
 	// Parse JSON recorder events to derive recording state.
 	var ev map[string]string
@@ -290,7 +496,6 @@ func (m *Manager) OnOutput(taskID string, pid int, stream, data string, ts time.
 	}
 
 	stateObj.mu.Lock()
-	defer stateObj.mu.Unlock()
 
 	switch event {
 	case "RECORDING START":
@@ -319,6 +524,14 @@ func (m *Manager) OnOutput(taskID string, pid int, stream, data string, ts time.
 			stateObj.sessionDuration = d
 		}
 	}
+	driver, username := stateObj.source.Driver, stateObj.source.Username
+	stateObj.mu.Unlock()
+
+	if event == "SESSION END" {
+		m.notify(context.Background(), sourceID, notifier.Event{
+			Kind: notifier.EventRecordingFinished, Driver: driver, Username: username,
+		})
+	}
 }
 
 
@@ -347,7 +560,11 @@ func (m *Manager) OnExited(taskID string, pid int, exitCode int, intentional boo
 	username := state.source.Username
 	state.mu.Unlock()
 
-	state.addLog(fmt.Sprintf("[system] process pid=%d exited (code=%d intentional=%v)", pid, exitCode, intentional))
+	m.stopSampler(state)
+
+	exitMsg := fmt.Sprintf("[system] process pid=%d exited (code=%d intentional=%v)", pid, exitCode, intentional)
+	state.addLog(exitMsg)
+	state.logSystem(pid, exitMsg)
 	log.Printf("manager: worker pid=%d exited for %s/%s (code=%d intentional=%v)", pid, driver, username, exitCode, intentional)
 
 	// On unexpected non-zero exit, dump the last few log lines for context.
@@ -363,10 +580,14 @@ func (m *Manager) OnExited(taskID string, pid int, exitCode int, intentional boo
 		}
 	}
 
-	et := store.EventExited
-	if err := m.st.RecordWorkerEvent(context.Background(), sourceID, pid, et, &exitCode); err != nil {
-		log.Printf("manager: record exited event source=%d: %v", sourceID, err)
-	}
+	ec := exitCode
+	state.enqueueEvent(store.WorkerEventInput{
+		EventType:   store.EventExited,
+		PID:         pid,
+		ExitCode:    &ec,
+		Intentional: intentional,
+		TS:          ts,
+	})
 }
 
 // OnRestarting is called when the overseer is scheduling a restart.
@@ -379,7 +600,10 @@ func (m *Manager) OnRestarting(taskID string, pid int, attempt int, ts time.Time
 	if state == nil {
 		return
 	}
-	state.addLog(fmt.Sprintf("[system] restarting (attempt %d)", attempt))
+	msg := fmt.Sprintf("[system] restarting (attempt %d)", attempt)
+	state.addLog(msg)
+	state.logSystem(pid, msg)
+	state.enqueueEvent(store.WorkerEventInput{EventType: store.EventRestarting, PID: pid, TS: ts})
 }
 
 // OnErrored is called when the overseer gives up retrying.
@@ -397,15 +621,25 @@ func (m *Manager) OnErrored(taskID string, pid int, exitCount int, ts time.Time)
 	state.mu.Lock()
 	state.workerState = "errored"
 	state.errorMessage = msg
+	driver, username := state.source.Driver, state.source.Username
 	state.mu.Unlock()
 
 	state.addLog("[system] error threshold reached — use reset-error to retry")
+	state.logSystem(pid, "[system] error threshold reached — use reset-error to retry")
 	log.Printf("manager: source=%d errored: %s", sourceID, msg)
+	m.notify(context.Background(), sourceID, notifier.Event{
+		Kind: notifier.EventWorkerErrored, Driver: driver, Username: username, Message: msg,
+	})
+	state.enqueueEvent(store.WorkerEventInput{EventType: store.EventErrored, PID: pid, TS: ts})
 }
 
 // ---- worker lifecycle ----
 
-func (m *Manager) startWorker(sourceID int64) {
+// startWorker launches a source's overseer task, subject to its restartTracker.
+// force resets the tracker's window first, so explicit operator actions
+// (ResetError, Restart, AdminRestartSource, RestartAll) always get an
+// immediate attempt instead of inheriting backoff from an earlier crash loop.
+func (m *Manager) startWorker(sourceID int64, force bool) {
 	state := m.stateByID(sourceID)
 	if state == nil {
 		return
@@ -429,6 +663,32 @@ func (m *Manager) startWorker(sourceID int64) {
 		state.mu.Unlock()
 	}()
 
+	now := time.Now()
+	policy := resolveRestartPolicy(m.cfg.Get(), src.Driver)
+	if force {
+		state.restarts.reset()
+	}
+	switch decision, wait := state.restarts.shouldRestart(now, policy, state.lastStartErr()); decision {
+	case RestartFail:
+		state.mu.Lock()
+		state.workerState = "errored"
+		state.errorMessage = "restart policy: too many attempts in window, not retrying automatically"
+		state.mu.Unlock()
+		msg := "[system] restart policy: attempts exhausted — use restart to retry"
+		state.addLog(msg)
+		state.logSystem(0, msg)
+		return
+	case RestartWithDelay:
+		msg := fmt.Sprintf("[system] restart policy: backing off %s before next attempt", wait.Round(time.Second))
+		state.addLog(msg)
+		state.logSystem(0, msg)
+		delayUntil := now.Add(wait)
+		m.emit(Event{Type: EventRestartScheduled, RestartScheduled: &RestartScheduledPayload{SourceID: sourceID, DelayUntil: delayUntil}})
+		time.AfterFunc(wait, func() { m.startWorker(sourceID, false) })
+		return
+	}
+	state.restarts.recordStart(now, policy)
+
 	g := m.cfg.Get()
 	rp := &overseer.RetryPolicy{
 		RestartDelay:   g.RestartDelay,
@@ -478,8 +738,10 @@ func (m *Manager) startWorker(sourceID int64) {
 	if err != nil {
 		log.Printf("manager: start worker for %s/%s: %v", src.Driver, src.Username, err)
 		state.addLog(fmt.Sprintf("[system] start failed: %v", err))
+		state.setLastStartErr(err)
 		return
 	}
+	state.setLastStartErr(nil)
 
 	// Persist the task_id if it's new.
 	if gotTaskID != taskID {
@@ -501,8 +763,12 @@ func (m *Manager) startWorker(sourceID int64) {
 	state.mu.Lock()
 	state.pid = pid
 	state.workerState = "running"
+	state.lastStart = time.Now()
+	state.restartCount++
 	state.mu.Unlock()
 
+	m.startSampler(sourceID, state)
+
 	log.Printf("manager: started worker task=%s pid=%d for %s/%s", gotTaskID, pid, src.Driver, src.Username)
 }
 
@@ -525,6 +791,10 @@ func (m *Manager) stopWorker(state *sourceState) {
 // Subscribe creates or reactivates a subscription for userID → driver/username.
 // Starts a worker if this is the first active subscriber for the source.
 func (m *Manager) Subscribe(ctx context.Context, userID int64, driver, username string) (*SubscriptionStatus, error) {
+	if m.isLeaving() {
+		return nil, ErrDraining
+	}
+
 	src, err := m.st.GetOrCreateSource(ctx, driver, username)
 	if err != nil {
 		return nil, err
@@ -538,7 +808,7 @@ func (m *Manager) Subscribe(ctx context.Context, userID int64, driver, username
 	// Ensure the source has an in-memory state entry.
 	m.mu.Lock()
 	if _, exists := m.states[src.ID]; !exists {
-		m.states[src.ID] = &sourceState{source: src, workerState: "idle"}
+		m.states[src.ID] = m.newSourceState(src)
 		if src.OverseerTaskID != "" {
 			m.taskIdx[src.OverseerTaskID] = src.ID
 		}
@@ -551,7 +821,7 @@ func (m *Manager) Subscribe(ctx context.Context, userID int64, driver, username
 		return nil, err
 	}
 	if count == 1 {
-		go m.startWorker(src.ID)
+		go m.startWorker(src.ID, false)
 	}
 
 	return m.statusFor(src, sub), nil
@@ -611,6 +881,10 @@ func (m *Manager) Pause(ctx context.Context, userID int64, driver, username stri
 
 // Resume sets the subscription active; starts worker if it's the first active sub.
 func (m *Manager) Resume(ctx context.Context, userID int64, driver, username string) (*SubscriptionStatus, error) {
+	if m.isLeaving() {
+		return nil, ErrDraining
+	}
+
 	src, sub, err := m.lookupSub(ctx, userID, driver, username)
 	if err != nil {
 		return nil, err
@@ -629,7 +903,7 @@ func (m *Manager) Resume(ctx context.Context, userID int64, driver, username str
 		return nil, err
 	}
 	if count == 1 {
-		go m.startWorker(src.ID)
+		go m.startWorker(src.ID, false)
 	}
 	return m.statusFor(src, sub), nil
 }
@@ -704,7 +978,67 @@ func (m *Manager) ResetError(ctx context.Context, userID int64, driver, username
 	state.mu.Unlock()
 	state.addLog("[system] reset — restarting worker with current configuration")
 
-	go m.startWorker(src.ID)
+	go m.startWorker(src.ID, true)
+
+	return m.statusFor(src, sub), nil
+}
+
+// Restart stops and restarts a subscription's worker regardless of its current
+// workerState (running, idle, or errored) — unlike ResetError, which only
+// accepts errored. Requests arriving within MinRestartInterval of the last
+// start are rejected with *RestartCooldownError instead of bouncing another
+// Stop/Start pair off the overseer. reason is free text from the operator and
+// is recorded on the resulting worker_events row for auditability.
+func (m *Manager) Restart(ctx context.Context, userID int64, driver, username, reason string) (*SubscriptionStatus, error) {
+	src, sub, err := m.lookupSub(ctx, userID, driver, username)
+	if err != nil {
+		return nil, err
+	}
+
+	state := m.stateByID(src.ID)
+	if state == nil {
+		return nil, fmt.Errorf("source %s/%s not tracked", driver, username)
+	}
+
+	state.mu.Lock()
+	taskID := state.source.OverseerTaskID
+	pid := state.pid
+	lastStart := state.lastStart
+	state.mu.Unlock()
+
+	if minInterval := parseDuration(m.cfg.Get().MinRestartInterval, defaultMinRestartInterval); !lastStart.IsZero() {
+		if elapsed := time.Since(lastStart); elapsed < minInterval {
+			return nil, &RestartCooldownError{RetryAfter: minInterval - elapsed}
+		}
+	}
+
+	if taskID != "" {
+		_ = m.oc.Stop(taskID)
+		if dbErr := m.st.SetSourceTaskID(context.Background(), src.ID, ""); dbErr != nil {
+			log.Printf("manager: clear task_id source=%d: %v", src.ID, dbErr)
+		}
+		m.mu.Lock()
+		delete(m.taskIdx, taskID)
+		m.mu.Unlock()
+		state.mu.Lock()
+		state.source.OverseerTaskID = ""
+		state.mu.Unlock()
+	}
+
+	state.mu.Lock()
+	state.workerState = "idle"
+	state.errorMessage = ""
+	state.mu.Unlock()
+	msg := fmt.Sprintf("[system] restart requested by user=%d: %s", userID, reason)
+	state.addLog(msg)
+	state.logSystem(pid, msg)
+
+	uid := userID
+	if err := m.st.RecordWorkerEvent(context.Background(), src.ID, pid, store.EventRestartRequested, nil, &uid, &reason); err != nil {
+		log.Printf("manager: record restart requested event source=%d: %v", src.ID, err)
+	}
+
+	go m.startWorker(src.ID, true)
 
 	return m.statusFor(src, sub), nil
 }
@@ -787,6 +1121,115 @@ func (m *Manager) GetWorkerEvents(ctx context.Context, userID int64, isAdmin boo
 	return m.st.RecentWorkerEvents(ctx, src.ID, limit)
 }
 
+// GetResourceHistory returns persisted resource-usage samples for a source,
+// most recent first, for graphing.
+func (m *Manager) GetResourceHistory(ctx context.Context, userID int64, isAdmin bool, driver, username string, limit int) ([]store.ResourceSample, error) {
+	src, err := m.st.GetSourceByKey(ctx, driver, username)
+	if err != nil || src == nil {
+		return nil, fmt.Errorf("source %s/%s not found", driver, username)
+	}
+	if !isAdmin {
+		sub, err := m.st.GetSubscription(ctx, userID, src.ID)
+		if err != nil || sub == nil {
+			return nil, fmt.Errorf("source %s/%s not found", driver, username)
+		}
+	}
+	return m.st.RecentResourceSamples(ctx, src.ID, limit)
+}
+
+// GetLogHistory returns durable log lines for a source since the given time
+// (zero since means no lower bound), oldest first.
+func (m *Manager) GetLogHistory(ctx context.Context, userID int64, isAdmin bool, driver, username string, since time.Time, limit int) ([]LogLine, error) {
+	src, err := m.st.GetSourceByKey(ctx, driver, username)
+	if err != nil || src == nil {
+		return nil, fmt.Errorf("source %s/%s not found", driver, username)
+	}
+	if !isAdmin {
+		sub, err := m.st.GetSubscription(ctx, userID, src.ID)
+		if err != nil || sub == nil {
+			return nil, fmt.Errorf("source %s/%s not found", driver, username)
+		}
+	}
+	state := m.stateByID(src.ID)
+	if state == nil || state.log == nil {
+		return []LogLine{}, nil
+	}
+	return state.log.ReadSince(since, limit)
+}
+
+// defaultLogTail is how many durable log lines SubscribeLogs replays when
+// opts.Tail is unset.
+const defaultLogTail = 25
+
+// logTailChanBuffer sizes each live-tail subscriber's channel (see
+// sourceLog.broadcast, which drops a new line for a full subscriber and
+// counts it in that subscriber's Dropped).
+const logTailChanBuffer = 64
+
+// LogSubscribeOptions configures SubscribeLogs.
+type LogSubscribeOptions struct {
+	// Follow, if true, also returns a live channel of lines appended after
+	// the replay. If false, only the replay is populated and the returned
+	// channel is nil.
+	Follow bool
+	// Tail caps how many replayed lines are returned, most recent end of the
+	// window; zero uses defaultLogTail.
+	Tail int
+	// Since, if non-zero, drops replayed lines at or before this time.
+	Since time.Time
+}
+
+// SubscribeLogs looks up a source's durable log, replays buffered lines per
+// opts, and — if opts.Follow — attaches a live tail channel before reading
+// the replay so no line appended in between is lost (a line landing in that
+// window may appear in both replay and the live stream; callers dedupe on TS
+// if that matters to them). Multiple concurrent callers are each given their
+// own independent channel; a slow consumer only drops lines for itself (see
+// sourceLog.broadcast). The returned cancel func must always be called once
+// the caller is done, whether or not Follow was set.
+func (m *Manager) SubscribeLogs(ctx context.Context, userID int64, isAdmin bool, driver, username string, opts LogSubscribeOptions) (replay []LogLine, live <-chan LogStreamMsg, cancel func(), err error) {
+	src, err := m.st.GetSourceByKey(ctx, driver, username)
+	if err != nil || src == nil {
+		return nil, nil, nil, fmt.Errorf("source %s/%s not found", driver, username)
+	}
+	if !isAdmin {
+		sub, err := m.st.GetSubscription(ctx, userID, src.ID)
+		if err != nil || sub == nil {
+			return nil, nil, nil, fmt.Errorf("source %s/%s not found", driver, username)
+		}
+	}
+
+	state := m.stateByID(src.ID)
+	if state == nil || state.log == nil {
+		return []LogLine{}, nil, func() {}, nil
+	}
+
+	tail := opts.Tail
+	if tail <= 0 {
+		tail = defaultLogTail
+	}
+
+	var ch chan LogStreamMsg
+	if opts.Follow {
+		ch = make(chan LogStreamMsg, logTailChanBuffer)
+		state.log.SubscribeTail(ch)
+	}
+
+	lines, err := state.log.ReadSince(opts.Since, tail)
+	if err != nil {
+		if ch != nil {
+			state.log.UnsubscribeTail(ch)
+		}
+		return nil, nil, nil, err
+	}
+
+	cancelFn := func() {}
+	if ch != nil {
+		cancelFn = func() { state.log.UnsubscribeTail(ch) }
+	}
+	return lines, ch, cancelFn, nil
+}
+
 // ---- admin helpers ----
 
 // lookupSubByID fetches a subscription by its ID along with the parent source.
@@ -820,7 +1263,9 @@ func (m *Manager) AdminPause(ctx context.Context, subID int64) (*SubscriptionSta
 	if err := m.st.SetPosture(ctx, sub.ID, store.PosturePaused); err != nil {
 		return nil, err
 	}
+	from := string(sub.Posture)
 	sub.Posture = store.PosturePaused
+	m.emit(Event{Type: EventSubscriptionPostureChanged, SubscriptionPostureChanged: &SubscriptionPostureChangedPayload{SubID: sub.ID, From: from, To: string(store.PosturePaused)}})
 	count, err := m.st.GetSourceActiveSubscriberCount(ctx, src.ID)
 	if err != nil {
 		return nil, err
@@ -835,6 +1280,9 @@ func (m *Manager) AdminPause(ctx context.Context, subID int64) (*SubscriptionSta
 
 // AdminResume resumes any subscription by its ID (admin only).
 func (m *Manager) AdminResume(ctx context.Context, subID int64) (*SubscriptionStatus, error) {
+	if m.isLeaving() {
+		return nil, ErrDraining
+	}
 	src, sub, err := m.lookupSubByID(ctx, subID)
 	if err != nil {
 		return nil, err
@@ -845,12 +1293,14 @@ func (m *Manager) AdminResume(ctx context.Context, subID int64) (*SubscriptionSt
 	if err := m.st.SetPosture(ctx, sub.ID, store.PostureActive); err != nil {
 		return nil, err
 	}
+	from := string(sub.Posture)
 	sub.Posture = store.PostureActive
+	m.emit(Event{Type: EventSubscriptionPostureChanged, SubscriptionPostureChanged: &SubscriptionPostureChangedPayload{SubID: sub.ID, From: from, To: string(store.PostureActive)}})
 
 	// Ensure source has in-memory state.
 	m.mu.Lock()
 	if _, exists := m.states[src.ID]; !exists {
-		m.states[src.ID] = &sourceState{source: src, workerState: "idle"}
+		m.states[src.ID] = m.newSourceState(src)
 		if src.OverseerTaskID != "" {
 			m.taskIdx[src.OverseerTaskID] = src.ID
 		}
@@ -862,7 +1312,7 @@ func (m *Manager) AdminResume(ctx context.Context, subID int64) (*SubscriptionSt
 		return nil, err
 	}
 	if count == 1 {
-		go m.startWorker(src.ID)
+		go m.startWorker(src.ID, false)
 	}
 	return m.statusFor(src, sub), nil
 }
@@ -876,7 +1326,9 @@ func (m *Manager) AdminArchive(ctx context.Context, subID int64) (*SubscriptionS
 	if err := m.st.SetPosture(ctx, sub.ID, store.PostureArchived); err != nil {
 		return nil, err
 	}
+	from := string(sub.Posture)
 	sub.Posture = store.PostureArchived
+	m.emit(Event{Type: EventSubscriptionPostureChanged, SubscriptionPostureChanged: &SubscriptionPostureChangedPayload{SubID: sub.ID, From: from, To: string(store.PostureArchived)}})
 	count, err := m.st.GetSourceActiveSubscriberCount(ctx, src.ID)
 	if err != nil {
 		return nil, err
@@ -942,11 +1394,13 @@ func (m *Manager) AdminResetError(ctx context.Context, subID int64) (*Subscripti
 		state.mu.Unlock()
 	}
 	state.mu.Lock()
+	from := state.workerState
 	state.workerState = "idle"
 	state.errorMessage = ""
 	state.mu.Unlock()
+	m.emit(Event{Type: EventWorkerStateChanged, WorkerStateChanged: &WorkerStateChangedPayload{SourceID: src.ID, From: from, To: "idle"}})
 	state.addLog("[system] reset — restarting worker with current configuration")
-	go m.startWorker(src.ID)
+	go m.startWorker(src.ID, true)
 	return m.statusFor(src, sub), nil
 }
 
@@ -954,6 +1408,9 @@ func (m *Manager) AdminResetError(ctx context.Context, subID int64) (*Subscripti
 // Applies the same stop+clear+startWorker pattern as RestartAll so the latest configuration
 // (cookies, user_agent, etc.) is always picked up. Works on any worker state including errored.
 func (m *Manager) AdminRestartSource(ctx context.Context, subID int64) (*SubscriptionStatus, error) {
+	if m.isLeaving() {
+		return nil, ErrDraining
+	}
 	src, sub, err := m.lookupSubByID(ctx, subID)
 	if err != nil {
 		return nil, err
@@ -970,7 +1427,7 @@ func (m *Manager) AdminRestartSource(ctx context.Context, subID int64) (*Subscri
 	// Ensure in-memory state exists (may not be tracked if subscription was just resumed).
 	m.mu.Lock()
 	if _, exists := m.states[src.ID]; !exists {
-		m.states[src.ID] = &sourceState{source: src, workerState: "idle"}
+		m.states[src.ID] = m.newSourceState(src)
 	}
 	m.mu.Unlock()
 
@@ -994,15 +1451,17 @@ func (m *Manager) AdminRestartSource(ctx context.Context, subID int64) (*Subscri
 	}
 
 	state.mu.Lock()
+	from := state.workerState
 	state.workerState = "idle"
 	state.errorMessage = ""
 	state.sessionActive = false
 	state.sessionStartedAt = time.Time{}
 	state.recordingState = ""
 	state.mu.Unlock()
+	m.emit(Event{Type: EventWorkerStateChanged, WorkerStateChanged: &WorkerStateChangedPayload{SourceID: src.ID, From: from, To: "idle"}})
 	state.addLog("[system] restarting (manual restart — applying current configuration)")
 
-	go m.startWorker(src.ID)
+	go m.startWorker(src.ID, true)
 	return m.statusFor(src, sub), nil
 }
 
@@ -1026,6 +1485,10 @@ func (m *Manager) RestartAll(ctx context.Context, includeErrored bool) (restarte
 	}
 	m.mu.RUnlock()
 
+	if m.isLeaving() {
+		return 0, len(ids)
+	}
+
 	for _, id := range ids {
 		state := m.stateByID(id)
 		if state == nil {
@@ -1074,7 +1537,7 @@ func (m *Manager) RestartAll(ctx context.Context, includeErrored bool) (restarte
 		state.mu.Unlock()
 		state.addLog("[system] restarting (restart-all — applying current configuration)")
 
-		go m.startWorker(id)
+		go m.startWorker(id, true)
 		restarted++
 	}
 	return
@@ -1212,18 +1675,71 @@ func (m *Manager) claimTask(ctx context.Context, sourceID int64, state *sourceSt
 		state.mu.Unlock()
 	}
 	state.mu.Lock()
+	from := state.workerState
 	state.pid = t.CurrentPID
 	state.workerState = "running"
 	state.mu.Unlock()
+	m.emit(Event{Type: EventWorkerStateChanged, WorkerStateChanged: &WorkerStateChangedPayload{SourceID: sourceID, From: from, To: "running"}})
+	m.emit(Event{Type: EventTaskClaimed, TaskClaimed: &TaskClaimedPayload{SourceID: sourceID, TaskID: t.TaskID, PID: t.CurrentPID}})
 	state.addLog(fmt.Sprintf("[system] claimed running task=%s pid=%d", t.TaskID, t.CurrentPID))
 	if err := m.oc.Subscribe(t.TaskID); err != nil {
 		log.Printf("manager: claimTask: subscribe task=%s: %v", t.TaskID, err)
 	}
+	m.startSampler(sourceID, state)
 }
 
-func (m *Manager) GetConfig() config.Data        { return m.cfg.Get() }
-func (m *Manager) SetConfig(ctx context.Context, d config.Data) error {
-	return m.cfg.Set(ctx, d)
+func (m *Manager) GetConfig() config.Data { return m.cfg.Get() }
+
+// GetConfigFingerprint returns the SHA-256 ETag of the current config, for
+// GET /api/config's ETag header and PUT /api/config's If-Match check.
+func (m *Manager) GetConfigFingerprint() string { return m.cfg.Fingerprint() }
+
+func (m *Manager) SetConfig(ctx context.Context, d config.Data, authorID *int64, comment string) error {
+	if err := m.cfg.Set(ctx, d, authorID, comment); err != nil {
+		return err
+	}
+	m.emitConfigChanged()
+	return nil
+}
+
+// SetConfigIfMatch behaves like SetConfig, but fails with
+// config.ErrFingerprintMismatch if the config changed since
+// expectedFingerprint was read, so PUT /api/config can reply 412
+// Precondition Failed instead of silently clobbering a concurrent edit.
+func (m *Manager) SetConfigIfMatch(ctx context.Context, expectedFingerprint string, d config.Data, authorID *int64, comment string) error {
+	if err := m.cfg.SetIfMatch(ctx, expectedFingerprint, d, authorID, comment); err != nil {
+		return err
+	}
+	m.emitConfigChanged()
+	return nil
+}
+
+// PatchConfigPath updates the single field addressed by path (see
+// config.Global.UnmarshalJSONPath) and persists it as a new version, for
+// PATCH /api/config?path=....
+func (m *Manager) PatchConfigPath(ctx context.Context, path string, value json.RawMessage, authorID *int64, comment string) error {
+	if err := m.cfg.UnmarshalJSONPath(ctx, path, value, authorID, comment); err != nil {
+		return err
+	}
+	m.emitConfigChanged()
+	return nil
+}
+
+// ReloadConfig refreshes the in-memory config cache from the store, for
+// callers (e.g. RollbackConfig) that changed it directly through the store
+// rather than via SetConfig.
+func (m *Manager) ReloadConfig(ctx context.Context) error {
+	if err := m.cfg.Reload(ctx); err != nil {
+		return err
+	}
+	m.emitConfigChanged()
+	return nil
+}
+
+// emitConfigChanged publishes EventConfigChanged so subscribed workers and
+// the admin UI pick up the new config without a restart.
+func (m *Manager) emitConfigChanged() {
+	m.emit(Event{Type: EventConfigChanged, ConfigChanged: &ConfigChangedPayload{Fingerprint: m.cfg.Fingerprint()}})
 }
 
 // ---- periodic reconciliation ----
@@ -1243,6 +1759,10 @@ func (m *Manager) reconcileLoop(ctx context.Context) {
 }
 
 func (m *Manager) reconcile(ctx context.Context) {
+	if m.isLeaving() {
+		return
+	}
+
 	tasks, err := m.oc.List(ctx)
 	if err != nil {
 		log.Printf("manager: reconcile: overseer list: %v", err)
@@ -1290,8 +1810,9 @@ func (m *Manager) reconcile(ctx context.Context) {
 			state.pid = 0
 			state.workerState = "idle"
 			state.mu.Unlock()
+			m.emit(Event{Type: EventWorkerStateChanged, WorkerStateChanged: &WorkerStateChangedPayload{SourceID: id, From: "running", To: "idle"}})
 			state.addLog("[system] worker gone (detected by reconciler), restarting")
-			go m.startWorker(id)
+			go m.startWorker(id, false)
 			continue
 		}
 
@@ -1344,20 +1865,29 @@ func taskMaps(tasks []overseer.TaskInfo) (byTaskID map[string]overseer.TaskInfo,
 
 // bulkStart launches startWorker for each id with bounded concurrency so that
 // a large number of simultaneous starts doesn't flood the overseer's confirmation
-// queue and trigger timeouts. Returns immediately; dispatch runs in the background.
+// queue and trigger timeouts. Dispatch order is scored by scoreCandidates
+// (see schedule.go) so sources that were actively recording or had more
+// subscribers at disconnect get started before unrelated idle ones, instead
+// of starving on slice order during a cold reconnect. Returns immediately;
+// dispatch runs in the background.
 func (m *Manager) bulkStart(ids []int64) {
 	concurrency := m.cfg.Get().StartConcurrency
 	if concurrency <= 0 {
 		concurrency = 5
 	}
 	go func() {
+		candidates := m.scoreCandidates(context.Background(), ids)
 		sem := make(chan struct{}, concurrency)
-		for _, id := range ids {
-			id := id
+		for i, c := range candidates {
+			if m.isLeaving() {
+				log.Printf("manager: bulkStart: draining, not starting remaining %d source(s)", len(candidates)-i)
+				return
+			}
+			id := c.id
 			sem <- struct{}{} // block until a slot is free
 			go func() {
 				defer func() { <-sem }()
-				m.startWorker(id)
+				m.startWorker(id, false)
 			}()
 		}
 	}()
@@ -1434,13 +1964,30 @@ func (m *Manager) statusFor(src *store.Source, sub *store.Subscription) *Subscri
 		t := state.sessionStartedAt
 		s.SessionStartedAt = &t
 	}
+	if !state.lastStart.IsZero() {
+		t := state.lastStart
+		s.LastStart = &t
+	}
+	s.RestartCount = state.restartCount
+	restarts := state.restarts
 	state.mu.Unlock()
 
+	if restarts != nil {
+		policy := resolveRestartPolicy(m.cfg.Get(), src.Driver)
+		attempts, next := restarts.status(time.Now(), policy)
+		s.RestartAttemptsInWindow = attempts
+		if !next.IsZero() {
+			s.NextAllowedStart = &next
+		}
+	}
+
 	// Populate canonical URL from config driver_urls.
 	if tmpl := m.cfg.Get().DriverURLs[src.Driver]; tmpl != "" {
 		s.CanonicalURL = strings.ReplaceAll(tmpl, "{{.Username}}", src.Username)
 	}
 
+	s.Stats = state.latestStats()
+
 	return s
 }
 
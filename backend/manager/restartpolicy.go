@@ -0,0 +1,163 @@
+package manager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/config"
+)
+
+const (
+	defaultRestartAttempts = 5
+	defaultRestartInterval = 5 * time.Minute
+	defaultRestartDelay    = 15 * time.Second
+	defaultMaxRestartDelay = 5 * time.Minute
+)
+
+// RestartDecision is the result of consulting a restartTracker before
+// launching a worker.
+type RestartDecision int
+
+const (
+	// RestartNow means the caller should proceed with the attempt immediately.
+	RestartNow RestartDecision = iota
+	// RestartWithDelay means the caller must wait the returned duration first.
+	RestartWithDelay
+	// RestartFail means the attempt window is exhausted and mode=fail —
+	// the caller should mark the source errored instead of retrying.
+	RestartFail
+)
+
+// RestartPolicy is the resolved (duration-typed) form of config.RestartPolicyConfig
+// for one source, after applying its driver's override if any.
+type RestartPolicy struct {
+	Attempts int
+	Interval time.Duration
+	Delay    time.Duration
+	MaxDelay time.Duration
+	Mode     string // "delay" | "fail"
+}
+
+// resolveRestartPolicy picks driver's override from cfg.RestartPolicy.PerDriver
+// if present, else cfg.RestartPolicy.Default, and parses its durations,
+// falling back to the package defaults for anything unset or unparsable.
+func resolveRestartPolicy(cfg config.Data, driver string) RestartPolicy {
+	c := cfg.RestartPolicy.Default
+	if override, ok := cfg.RestartPolicy.PerDriver[driver]; ok {
+		c = override
+	}
+
+	attempts := c.Attempts
+	if attempts <= 0 {
+		attempts = defaultRestartAttempts
+	}
+	mode := c.Mode
+	if mode != "fail" {
+		mode = "delay"
+	}
+	return RestartPolicy{
+		Attempts: attempts,
+		Interval: parseDuration(c.Interval, defaultRestartInterval),
+		Delay:    parseDuration(c.Delay, defaultRestartDelay),
+		MaxDelay: parseDuration(c.MaxDelay, defaultMaxRestartDelay),
+		Mode:     mode,
+	}
+}
+
+// restartTracker decides whether a source's worker may start right now,
+// after a backoff delay, or not at all, based on how many start attempts
+// have landed within the policy's Interval — modeled on Nomad's task-runner
+// restart policy. Guarded by its own mutex so callers can consult it without
+// holding sourceState.mu.
+type restartTracker struct {
+	mu sync.Mutex
+
+	// starts is a ring of attempt timestamps within the last Interval, oldest
+	// first. Entries older than Interval age out on every call, which is what
+	// gives a clean run longer than Interval a reset window for free.
+	starts []time.Time
+	// overflow counts consecutive times the window has been full, driving the
+	// exponential backoff (delay * 2^overflow, capped at MaxDelay).
+	overflow    int
+	nextAllowed time.Time
+}
+
+// shouldRestart reports whether a start attempt may proceed now, must wait
+// (with the wait duration), or should fail outright. lastErr is the error
+// from the most recent failed attempt, if any — surfaced in logs by the
+// caller so an operator can see why a source is backing off or erroring.
+func (t *restartTracker) shouldRestart(now time.Time, policy RestartPolicy, lastErr error) (RestartDecision, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pruneLocked(now, policy.Interval)
+
+	if !t.nextAllowed.IsZero() && now.Before(t.nextAllowed) {
+		return RestartWithDelay, t.nextAllowed.Sub(now)
+	}
+
+	if policy.Attempts > 0 && len(t.starts) >= policy.Attempts {
+		if policy.Mode == "fail" {
+			return RestartFail, 0
+		}
+		delay := policy.Delay * time.Duration(int64(1)<<uint(t.overflow))
+		if delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+		t.overflow++
+		t.nextAllowed = now.Add(delay)
+		return RestartWithDelay, delay
+	}
+
+	_ = lastErr // logged by the caller; the policy itself doesn't branch on error content
+	t.overflow = 0 // the window has room again, so the next full window backs off from Delay
+	return RestartNow, 0
+}
+
+// recordStart appends now to the window after a permitted attempt was made.
+func (t *restartTracker) recordStart(now time.Time, policy RestartPolicy) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pruneLocked(now, policy.Interval)
+	t.starts = append(t.starts, now)
+}
+
+// reset clears the window and backoff state entirely — used by force restarts
+// (ResetError, Restart, AdminRestartSource, RestartAll) so an explicit
+// operator action always gets an immediate attempt.
+func (t *restartTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.starts = nil
+	t.overflow = 0
+	t.nextAllowed = time.Time{}
+}
+
+// status returns the current window size and next-allowed-start time for
+// display (SubscriptionStatus.RestartAttemptsInWindow / NextAllowedStart).
+func (t *restartTracker) status(now time.Time, policy RestartPolicy) (attemptsInWindow int, nextAllowedStart time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pruneLocked(now, policy.Interval)
+	if t.nextAllowed.After(now) {
+		nextAllowedStart = t.nextAllowed
+	}
+	return len(t.starts), nextAllowedStart
+}
+
+// pruneLocked drops timestamps older than interval. Must be called with t.mu held.
+func (t *restartTracker) pruneLocked(now time.Time, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultRestartInterval
+	}
+	cutoff := now.Add(-interval)
+	i := 0
+	for ; i < len(t.starts); i++ {
+		if t.starts[i].After(cutoff) {
+			break
+		}
+	}
+	if i > 0 {
+		t.starts = t.starts[i:]
+	}
+}
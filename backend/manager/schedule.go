@@ -0,0 +1,125 @@
+package manager
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/config"
+)
+
+const (
+	defaultInSessionBonus        = 100
+	defaultRecentRecordingBonus  = 50
+	defaultRecentRecordingWindow = time.Hour
+	defaultPerSubscriberWeight   = 10
+	defaultFlappingPenalty       = -20
+	defaultFlappingWindow        = 5 * time.Minute
+
+	// scoreLogTopK caps how many scored candidates bulkStart logs per dispatch.
+	scoreLogTopK = 10
+)
+
+// startSchedulerWeights is the resolved (duration-typed) form of
+// config.StartSchedulerConfig.
+type startSchedulerWeights struct {
+	InSessionBonus        int
+	RecentRecordingBonus  int
+	RecentRecordingWindow time.Duration
+	PerSubscriberWeight   int
+	FlappingPenalty       int
+	FlappingWindow        time.Duration
+}
+
+// resolveStartScheduler reads cfg.StartScheduler, falling back to the
+// package defaults for any zero or unparsable field.
+func resolveStartScheduler(cfg config.Data) startSchedulerWeights {
+	c := cfg.StartScheduler
+	w := startSchedulerWeights{
+		InSessionBonus:        c.InSessionBonus,
+		RecentRecordingBonus:  c.RecentRecordingBonus,
+		RecentRecordingWindow: parseDuration(c.RecentRecordingWindow, defaultRecentRecordingWindow),
+		PerSubscriberWeight:   c.PerSubscriberWeight,
+		FlappingPenalty:       c.FlappingPenalty,
+		FlappingWindow:        parseDuration(c.FlappingWindow, defaultFlappingWindow),
+	}
+	if w.InSessionBonus == 0 {
+		w.InSessionBonus = defaultInSessionBonus
+	}
+	if w.RecentRecordingBonus == 0 {
+		w.RecentRecordingBonus = defaultRecentRecordingBonus
+	}
+	if w.PerSubscriberWeight == 0 {
+		w.PerSubscriberWeight = defaultPerSubscriberWeight
+	}
+	if w.FlappingPenalty == 0 {
+		w.FlappingPenalty = defaultFlappingPenalty
+	}
+	return w
+}
+
+// candidate is one source queued for bulkStart, scored so sources most worth
+// reviving first — an in-progress recording at disconnect, recent activity,
+// more subscribers — get their overseer Start call dispatched ahead of
+// unrelated idle sources, instead of starving on slice order. Modeled on
+// Skia's task-scheduler candidate scoring.
+type candidate struct {
+	id    int64
+	score int
+}
+
+// scoreCandidates builds and sorts the dispatch order for bulkStart(ids):
+// highest score first, ties broken by ascending source ID so ordering is
+// deterministic across runs (and in tests) even when every factor matches.
+func (m *Manager) scoreCandidates(ctx context.Context, ids []int64) []candidate {
+	w := resolveStartScheduler(m.cfg.Get())
+	now := time.Now()
+
+	candidates := make([]candidate, 0, len(ids))
+	for _, id := range ids {
+		state := m.stateByID(id)
+		if state == nil {
+			candidates = append(candidates, candidate{id: id})
+			continue
+		}
+
+		state.mu.Lock()
+		sessionActive := state.sessionActive
+		lastRecordingAt := state.lastRecordingAt
+		restartCount := state.restartCount
+		lastStart := state.lastStart
+		state.mu.Unlock()
+
+		score := 0
+		if sessionActive {
+			score += w.InSessionBonus
+		}
+		if !lastRecordingAt.IsZero() && now.Sub(lastRecordingAt) < w.RecentRecordingWindow {
+			score += w.RecentRecordingBonus
+		}
+		if count, err := m.st.GetSourceActiveSubscriberCount(ctx, id); err == nil {
+			score += w.PerSubscriberWeight * count
+		}
+		if restartCount > 0 && now.Sub(lastStart) < w.FlappingWindow {
+			score += w.FlappingPenalty
+		}
+
+		candidates = append(candidates, candidate{id: id, score: score})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].id < candidates[j].id
+	})
+
+	logK := scoreLogTopK
+	if logK > len(candidates) {
+		logK = len(candidates)
+	}
+	log.Printf("manager: bulkStart: scored %d candidate(s), top %d: %v", len(candidates), logK, candidates[:logK])
+
+	return candidates
+}
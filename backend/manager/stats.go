@@ -0,0 +1,321 @@
+package manager
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/notifier"
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
+)
+
+const (
+	sampleInterval    = 5 * time.Second
+	maxStatsSamples   = 60 // 5 minutes of history at sampleInterval
+	defaultStallAfter = 60 * time.Second
+)
+
+// WorkerResourceUsage is one point-in-time snapshot of a worker's resource
+// usage, as surfaced on SubscriptionStatus. See store.ResourceSample for the
+// persisted equivalent used for historical graphs.
+type WorkerResourceUsage struct {
+	TS            time.Time `json:"ts"`
+	CPUPercent    float64   `json:"cpu_percent"`
+	RSSBytes      int64     `json:"rss_bytes"`
+	SegmentBytes  int64     `json:"segment_bytes"`
+	BytesPerSec   float64   `json:"bytes_per_sec"`
+	DiskFreeBytes int64     `json:"disk_free_bytes"`
+}
+
+// recordSample appends sample to the rolling window, evicting the oldest
+// entry once maxStatsSamples is reached.
+func (s *sourceState) recordSample(sample WorkerResourceUsage) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	if len(s.stats) >= maxStatsSamples {
+		s.stats = s.stats[1:]
+	}
+	s.stats = append(s.stats, sample)
+}
+
+// latestStats returns the most recent sample, or nil if none have been taken yet.
+func (s *sourceState) latestStats() *WorkerResourceUsage {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	if len(s.stats) == 0 {
+		return nil
+	}
+	latest := s.stats[len(s.stats)-1]
+	return &latest
+}
+
+// startSampler launches a per-source resource-sampling goroutine, replacing
+// any sampler already running for this state. Safe to call more than once
+// (e.g. from both startWorker and claimTask).
+func (m *Manager) startSampler(sourceID int64, state *sourceState) {
+	m.stopSampler(state)
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	state.mu.Lock()
+	state.sampleCancel = cancel
+	state.mu.Unlock()
+
+	go m.sampleLoop(ctx, sourceID, state)
+}
+
+// stopSampler stops state's sampler goroutine, if one is running.
+func (m *Manager) stopSampler(state *sourceState) {
+	state.mu.Lock()
+	cancel := state.sampleCancel
+	state.sampleCancel = nil
+	state.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// sampleLoop samples state's current pid every sampleInterval until ctx is
+// cancelled. It re-reads state.pid on every tick, so it keeps tracking a
+// source across overseer-driven restarts without needing to be restarted.
+func (m *Manager) sampleLoop(ctx context.Context, sourceID int64, state *sourceState) {
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+
+	var lastSegmentBytes int64 = -1
+	var lastSegmentAt time.Time
+	var zeroRateSince time.Time
+	var quotaNotified bool
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		state.mu.Lock()
+		pid := state.pid
+		driver := state.source.Driver
+		username := state.source.Username
+		recording := state.recordingState == "recording"
+		state.mu.Unlock()
+		if pid == 0 {
+			continue
+		}
+
+		now := time.Now()
+		cpuPct, rss, err := readProcStats(pid)
+		if err != nil {
+			continue
+		}
+
+		root := m.cfg.Get().RecordingsRoot
+		segBytes, segPath := latestSegmentFile(root, driver, username)
+		var bytesPerSec float64
+		if lastSegmentBytes >= 0 && !lastSegmentAt.IsZero() {
+			dt := now.Sub(lastSegmentAt).Seconds()
+			if dt > 0 {
+				bytesPerSec = float64(segBytes-lastSegmentBytes) / dt
+			}
+		}
+		lastSegmentBytes = segBytes
+		lastSegmentAt = now
+
+		diskFree := diskFreeBytes(segPath, root)
+
+		sample := WorkerResourceUsage{
+			TS:            now,
+			CPUPercent:    cpuPct,
+			RSSBytes:      rss,
+			SegmentBytes:  segBytes,
+			BytesPerSec:   bytesPerSec,
+			DiskFreeBytes: diskFree,
+		}
+		state.recordSample(sample)
+
+		rec := store.ResourceSample{
+			SourceID:      sourceID,
+			CPUPercent:    sample.CPUPercent,
+			RSSBytes:      sample.RSSBytes,
+			SegmentBytes:  sample.SegmentBytes,
+			BytesPerSec:   sample.BytesPerSec,
+			DiskFreeBytes: sample.DiskFreeBytes,
+		}
+		if err := m.st.RecordResourceSample(context.Background(), sourceID, rec); err != nil {
+			log.Printf("manager: record resource sample source=%d: %v", sourceID, err)
+		}
+
+		m.checkStall(sourceID, state, recording, bytesPerSec, now, &zeroRateSince)
+		m.checkDiskQuota(sourceID, driver, username, diskFree, &quotaNotified)
+	}
+}
+
+// checkDiskQuota notifies the first time diskFree drops at or below the
+// configured threshold, then stays quiet (via *notified) until diskFree rises
+// back above the threshold or this sampler restarts, so a source sitting on a
+// full disk doesn't fire a notification every sampleInterval.
+func (m *Manager) checkDiskQuota(sourceID int64, driver, username string, diskFree int64, notified *bool) {
+	threshold := m.cfg.Get().DiskFreeThresholdBytes
+	if threshold <= 0 || diskFree > threshold {
+		*notified = false
+		return
+	}
+	if *notified {
+		return
+	}
+	*notified = true
+
+	log.Printf("manager: source=%d disk free %d bytes at or below threshold %d", sourceID, diskFree, threshold)
+	m.notify(context.Background(), sourceID, notifier.Event{
+		Kind:     notifier.EventDiskQuotaReached,
+		Driver:   driver,
+		Username: username,
+		Message:  fmt.Sprintf("disk free %d bytes at or below configured threshold %d", diskFree, threshold),
+	})
+}
+
+// checkStall emits an EventStalled worker event the first time a recording
+// source's byte rate has been zero for longer than the configured threshold.
+func (m *Manager) checkStall(sourceID int64, state *sourceState, recording bool, bytesPerSec float64, now time.Time, zeroRateSince *time.Time) {
+	if !recording || bytesPerSec > 0 {
+		*zeroRateSince = time.Time{}
+		return
+	}
+	if zeroRateSince.IsZero() {
+		*zeroRateSince = now
+		return
+	}
+
+	threshold := parseDuration(m.cfg.Get().StallThreshold, defaultStallAfter)
+	if now.Sub(*zeroRateSince) < threshold {
+		return
+	}
+
+	state.mu.Lock()
+	pid := state.pid
+	state.mu.Unlock()
+
+	state.addLog("[system] recording appears stalled — process alive but segment not growing")
+	state.logSystem(pid, "recording appears stalled — process alive but segment not growing")
+	if err := m.st.RecordWorkerEvent(context.Background(), sourceID, pid, store.EventStalled, nil, nil, nil); err != nil {
+		log.Printf("manager: record stalled event source=%d: %v", sourceID, err)
+	}
+
+	// Push the start forward by threshold so a stall that continues is
+	// reported again every 2×threshold rather than every tick.
+	*zeroRateSince = now.Add(threshold)
+}
+
+// readProcStats reads CPU% (since process start, not instantaneous) and RSS
+// for pid from /proc. Linux-only, matching the rest of this deployment.
+func readProcStats(pid int) (cpuPercent float64, rssBytes int64, err error) {
+	statBytes, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(string(statBytes))
+	// comm (fields[1]) can theoretically contain whitespace, which would throw
+	// off these fixed offsets — recorder process names don't, so we accept that.
+	if len(fields) < 24 {
+		return 0, 0, fmt.Errorf("unexpected /proc/%d/stat format", pid)
+	}
+	utime, _ := strconv.ParseFloat(fields[13], 64)
+	stime, _ := strconv.ParseFloat(fields[14], 64)
+	starttime, _ := strconv.ParseFloat(fields[21], 64)
+
+	clk := float64(clockTicksPerSec)
+	uptimeSec := systemUptimeSeconds()
+	processUptime := uptimeSec - starttime/clk
+	if processUptime <= 0 {
+		return 0, 0, nil
+	}
+	cpuPercent = 100 * ((utime + stime) / clk) / processUptime
+
+	statusFile, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return cpuPercent, 0, nil
+	}
+	defer statusFile.Close()
+	sc := bufio.NewScanner(statusFile)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "VmRSS:") {
+			parts := strings.Fields(line)
+			if len(parts) >= 2 {
+				kb, _ := strconv.ParseInt(parts[1], 10, 64)
+				rssBytes = kb * 1024
+			}
+			break
+		}
+	}
+	return cpuPercent, rssBytes, nil
+}
+
+const clockTicksPerSec = 100 // USER_HZ on virtually every Linux target we run on
+
+func systemUptimeSeconds() float64 {
+	b, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return 0
+	}
+	v, _ := strconv.ParseFloat(fields[0], 64)
+	return v
+}
+
+// latestSegmentFile returns the size and path of the most recently modified
+// regular file under root/driver/username, or (0, "") if none is found.
+func latestSegmentFile(root, driver, username string) (int64, string) {
+	if root == "" {
+		return 0, ""
+	}
+	dir := filepath.Join(root, driver, username)
+	var (
+		bestPath string
+		bestMod  time.Time
+		bestSize int64
+	)
+	_ = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().After(bestMod) {
+			bestMod = info.ModTime()
+			bestPath = path
+			bestSize = info.Size()
+		}
+		return nil
+	})
+	return bestSize, bestPath
+}
+
+// diskFreeBytes returns free space on the filesystem backing path, falling
+// back to root if path is empty (no segment file found yet).
+func diskFreeBytes(path, root string) int64 {
+	target := path
+	if target == "" {
+		target = root
+	}
+	if target == "" {
+		return 0
+	}
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(target, &st); err != nil {
+		return 0
+	}
+	return int64(st.Bavail) * int64(st.Bsize)
+}
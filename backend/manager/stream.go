@@ -0,0 +1,122 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+)
+
+// StreamEventKind identifies which field of a StreamEvent is populated.
+type StreamEventKind string
+
+const (
+	StreamEventWorker StreamEventKind = "worker" // from the manager event bus, see eventSourceID
+	StreamEventLog    StreamEventKind = "log"
+)
+
+// StreamEvent is one message delivered by SubscribeSource: either a manager
+// Event concerning this source, or an appended log line. Exactly one of
+// Worker/Log is non-nil, matching Kind.
+type StreamEvent struct {
+	Kind   StreamEventKind `json:"kind"`
+	Worker *Event          `json:"worker,omitempty"`
+	Log    *LogLine        `json:"log,omitempty"`
+}
+
+// streamChanBuffer sizes the channel returned by SubscribeSource. It's sized
+// like eventChanBuffer/logTailChanBuffer since it fans in from both.
+const streamChanBuffer = 64
+
+// eventSourceID returns the source ID an Event concerns and true, or
+// (0, false) for event types that aren't scoped to a single source (e.g.
+// EventSubscriptionPostureChanged, which only carries a subscription ID).
+func eventSourceID(ev Event) (int64, bool) {
+	switch ev.Type {
+	case EventWorkerStateChanged:
+		return ev.WorkerStateChanged.SourceID, true
+	case EventSessionStarted:
+		return ev.SessionStarted.SourceID, true
+	case EventSessionEnded:
+		return ev.SessionEnded.SourceID, true
+	case EventRecordingStateChanged:
+		return ev.RecordingStateChanged.SourceID, true
+	case EventTaskClaimed:
+		return ev.TaskClaimed.SourceID, true
+	case EventRestartScheduled:
+		return ev.RestartScheduled.SourceID, true
+	default:
+		return 0, false
+	}
+}
+
+// SubscribeSource multiplexes one source's worker-state events (filtered out
+// of the manager-wide event bus by source ID) and its live log tail into a
+// single channel, for the SSE handler backing
+// GET /api/sources/{driver}/{username}/stream. It applies the same ownership
+// check as GetWorkerEvents/SubscribeLogs. The returned cancel func must
+// always be called once the caller is done.
+func (m *Manager) SubscribeSource(ctx context.Context, userID int64, isAdmin bool, driver, username string) (<-chan StreamEvent, func(), error) {
+	src, err := m.st.GetSourceByKey(ctx, driver, username)
+	if err != nil || src == nil {
+		return nil, nil, fmt.Errorf("source %s/%s not found", driver, username)
+	}
+	if !isAdmin {
+		sub, err := m.st.GetSubscription(ctx, userID, src.ID)
+		if err != nil || sub == nil {
+			return nil, nil, fmt.Errorf("source %s/%s not found", driver, username)
+		}
+	}
+
+	evSub := m.SubscribeEvents()
+
+	var logCh chan LogStreamMsg
+	if state := m.stateByID(src.ID); state != nil && state.log != nil {
+		logCh = make(chan LogStreamMsg, logTailChanBuffer)
+		state.log.SubscribeTail(logCh)
+	}
+
+	out := make(chan StreamEvent, streamChanBuffer)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-done:
+				return
+			case ev, ok := <-evSub.C():
+				if !ok {
+					return
+				}
+				if id, scoped := eventSourceID(ev); !scoped || id != src.ID {
+					continue
+				}
+				evCopy := ev
+				select {
+				case out <- StreamEvent{Kind: StreamEventWorker, Worker: &evCopy}:
+				default:
+				}
+			case msg, ok := <-logCh:
+				if !ok {
+					logCh = nil
+					continue
+				}
+				line := msg.Line
+				select {
+				case out <- StreamEvent{Kind: StreamEventLog, Log: &line}:
+				default:
+				}
+			}
+		}
+	}()
+
+	cancel := func() {
+		close(done)
+		evSub.Close()
+		if logCh != nil {
+			if state := m.stateByID(src.ID); state != nil && state.log != nil {
+				state.log.UnsubscribeTail(logCh)
+			}
+		}
+	}
+	return out, cancel, nil
+}
@@ -0,0 +1,135 @@
+// Package metrics exposes Prometheus instrumentation for the backend: HTTP
+// request latency, store query timings, and gauges scraped from the
+// overseer and thumbnailer WebSocket clients.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "http_request_duration_seconds",
+		Help: "HTTP request latency in seconds, by route and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPRequestsTotal is incremented alongside httpRequestDuration, labeled
+	// the same way, for callers that want a request-rate query without
+	// deriving it from the histogram's _count series.
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests handled, by method, route, and status code.",
+	}, []string{"method", "route", "status"})
+
+	storeQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "store_query_duration_seconds",
+		Help: "Store method call latency in seconds, by method name.",
+	}, []string{"method"})
+
+	// OverseerConnected is 1 while the persistent overseer WebSocket
+	// connection is up, 0 otherwise, labeled by the dialed URL.
+	OverseerConnected = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "overseer_connected",
+		Help: "Whether the persistent overseer WebSocket connection is up (1) or down (0).",
+	}, []string{"url"})
+
+	// OverseerReconnects counts every (re-)established overseer connection,
+	// including the first. A rising rate indicates a flapping connection.
+	OverseerReconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "overseer_reconnect_total",
+		Help: "Number of times the overseer WebSocket connection was (re-)established.",
+	})
+
+	// OverseerStartInflight and OverseerListInflight track concurrent
+	// in-flight Start/List calls against the overseer client.
+	OverseerStartInflight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "overseer_start_requests_inflight",
+		Help: "Number of in-flight Start() requests to the overseer.",
+	})
+	OverseerListInflight = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "overseer_list_requests_inflight",
+		Help: "Number of in-flight List() requests to the overseer.",
+	})
+
+	// ThumbnailerPoolRunning and ThumbnailerQueueDepth reflect the last
+	// successful GetPoolInfo poll; see PollThumbnailer.
+	ThumbnailerPoolRunning = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "thumbnailer_pool_running",
+		Help: "Thumbnailer tasks currently running, per the last successful poll.",
+	})
+	ThumbnailerQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "thumbnailer_queue_depth",
+		Help: "Thumbnailer task queue depth, per the last successful poll.",
+	})
+
+	// ActiveSubscriptions reflects the last successful PollStoreCounts scrape,
+	// labeled by posture (active/paused/archived).
+	ActiveSubscriptions = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "subscriptions",
+		Help: "Subscriptions by posture, per the last successful store poll.",
+	}, []string{"posture"})
+
+	// SourcesByDriver reflects the last successful PollStoreCounts scrape,
+	// labeled by driver.
+	SourcesByDriver = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sources",
+		Help: "Sources by driver, per the last successful store poll.",
+	}, []string{"driver"})
+
+	// AdminActions counts admin-initiated subscription actions, labeled by
+	// action (pause/resume/archive/reset). Router handlers increment this
+	// directly rather than going through a poll, since it's event-driven.
+	AdminActions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "admin_actions_total",
+		Help: "Admin subscription actions performed, by action.",
+	}, []string{"action"})
+)
+
+// Handler serves the Prometheus exposition format for scraping.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Instrument wraps next, recording request latency and count labeled by
+// method, route, and response status code.
+func Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		route := routeLabel(r)
+		status := strconv.Itoa(sw.status)
+		httpRequestDuration.WithLabelValues(r.Method, route, status).Observe(time.Since(start).Seconds())
+		HTTPRequestsTotal.WithLabelValues(r.Method, route, status).Inc()
+	})
+}
+
+// routeLabel returns the registered mux pattern for r (e.g.
+// "GET /api/sources/{id}") rather than the raw path, so a metric series
+// stays bounded per-route instead of growing one series per distinct ID.
+// r.Pattern is only populated once net/http's ServeMux has matched the
+// request, which Instrument wrapping the mux guarantees.
+func routeLabel(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	return "unmatched"
+}
+
+// statusWriter captures the status code passed to WriteHeader so Instrument
+// can label the histogram with it; http.ResponseWriter has no getter for it.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
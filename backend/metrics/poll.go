@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/overseer"
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
+	"github.com/whisper-darkly/sticky-dvr/backend/thumbnailer"
+)
+
+// PollOverseer periodically sets OverseerConnected{url} from c.IsConnected()
+// and the Start/List inflight gauges from c.StartInflight/c.ListInflight,
+// until ctx is cancelled. Call in a dedicated goroutine.
+func PollOverseer(ctx context.Context, c *overseer.Client, url string, interval time.Duration) {
+	connected := OverseerConnected.WithLabelValues(url)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if c.IsConnected() {
+			connected.Set(1)
+		} else {
+			connected.Set(0)
+		}
+		OverseerStartInflight.Set(float64(c.StartInflight()))
+		OverseerListInflight.Set(float64(c.ListInflight()))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PollStoreCounts periodically sets ActiveSubscriptions and SourcesByDriver
+// from st, until ctx is cancelled. A failed poll leaves the gauges at their
+// last known values, same as PollThumbnailer. Call in a dedicated goroutine.
+func PollStoreCounts(ctx context.Context, st store.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if subs, err := st.ListAllSubscriptions(ctx); err == nil {
+			byPosture := map[store.Posture]int{}
+			for _, sub := range subs {
+				byPosture[sub.Posture]++
+			}
+			for _, posture := range []store.Posture{store.PostureActive, store.PosturePaused, store.PostureArchived} {
+				ActiveSubscriptions.WithLabelValues(string(posture)).Set(float64(byPosture[posture]))
+			}
+		}
+		if sources, err := st.ListSources(ctx); err == nil {
+			byDriver := map[string]int{}
+			for _, src := range sources {
+				byDriver[src.Driver]++
+			}
+			for driver, n := range byDriver {
+				SourcesByDriver.WithLabelValues(driver).Set(float64(n))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PollThumbnailer periodically scrapes c.GetPoolInfo and updates
+// ThumbnailerPoolRunning/ThumbnailerQueueDepth until ctx is cancelled. A
+// failed or unreachable poll leaves the gauges at their last known values.
+// Call in a dedicated goroutine.
+func PollThumbnailer(ctx context.Context, c *thumbnailer.Client, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if pi, err := c.GetPoolInfo(ctx); err == nil && pi != nil {
+			ThumbnailerPoolRunning.Set(float64(pi.Running))
+			ThumbnailerQueueDepth.Set(float64(pi.QueueDepth))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
+)
+
+// MetricsStore wraps a store.Store, recording a store_query_duration_seconds
+// observation (labeled by method name) around every call before delegating.
+type MetricsStore struct {
+	inner store.Store
+}
+
+// NewMetricsStore wraps inner so its query latencies are observed.
+func NewMetricsStore(inner store.Store) *MetricsStore {
+	return &MetricsStore{inner: inner}
+}
+
+func (s *MetricsStore) timed(method string) func() {
+	start := time.Now()
+	return func() { storeQueryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds()) }
+}
+
+// ---- users ----
+
+func (s *MetricsStore) CreateUser(ctx context.Context, username, passwordHash, role string) (*store.User, error) {
+	defer s.timed("CreateUser")()
+	return s.inner.CreateUser(ctx, username, passwordHash, role)
+}
+
+func (s *MetricsStore) GetUser(ctx context.Context, id int64) (*store.User, error) {
+	defer s.timed("GetUser")()
+	return s.inner.GetUser(ctx, id)
+}
+
+func (s *MetricsStore) GetUserByUsername(ctx context.Context, username string) (*store.User, error) {
+	defer s.timed("GetUserByUsername")()
+	return s.inner.GetUserByUsername(ctx, username)
+}
+
+func (s *MetricsStore) ListUsers(ctx context.Context) ([]*store.User, error) {
+	defer s.timed("ListUsers")()
+	return s.inner.ListUsers(ctx)
+}
+
+func (s *MetricsStore) UpdateUser(ctx context.Context, id int64, fields store.UserUpdate) (*store.User, error) {
+	defer s.timed("UpdateUser")()
+	return s.inner.UpdateUser(ctx, id, fields)
+}
+
+func (s *MetricsStore) DeleteUser(ctx context.Context, id int64) error {
+	defer s.timed("DeleteUser")()
+	return s.inner.DeleteUser(ctx, id)
+}
+
+// ---- sessions ----
+
+func (s *MetricsStore) CreateSession(ctx context.Context, userID int64, refreshToken string, expiresAt time.Time) (*store.Session, error) {
+	defer s.timed("CreateSession")()
+	return s.inner.CreateSession(ctx, userID, refreshToken, expiresAt)
+}
+
+func (s *MetricsStore) GetSessionByRefreshToken(ctx context.Context, refreshToken string) (*store.Session, error) {
+	defer s.timed("GetSessionByRefreshToken")()
+	return s.inner.GetSessionByRefreshToken(ctx, refreshToken)
+}
+
+func (s *MetricsStore) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	defer s.timed("DeleteSession")()
+	return s.inner.DeleteSession(ctx, id)
+}
+
+func (s *MetricsStore) DeleteExpiredSessions(ctx context.Context) error {
+	defer s.timed("DeleteExpiredSessions")()
+	return s.inner.DeleteExpiredSessions(ctx)
+}
+
+func (s *MetricsStore) RotateSession(ctx context.Context, oldRefreshToken string) (*store.Session, error) {
+	defer s.timed("RotateSession")()
+	return s.inner.RotateSession(ctx, oldRefreshToken)
+}
+
+func (s *MetricsStore) RevokeFamily(ctx context.Context, familyID uuid.UUID, reason string) error {
+	defer s.timed("RevokeFamily")()
+	return s.inner.RevokeFamily(ctx, familyID, reason)
+}
+
+// ---- sources ----
+
+func (s *MetricsStore) GetOrCreateSource(ctx context.Context, driver, username string) (*store.Source, error) {
+	defer s.timed("GetOrCreateSource")()
+	return s.inner.GetOrCreateSource(ctx, driver, username)
+}
+
+func (s *MetricsStore) GetSourceByKey(ctx context.Context, driver, username string) (*store.Source, error) {
+	defer s.timed("GetSourceByKey")()
+	return s.inner.GetSourceByKey(ctx, driver, username)
+}
+
+func (s *MetricsStore) ListSources(ctx context.Context) ([]*store.Source, error) {
+	defer s.timed("ListSources")()
+	return s.inner.ListSources(ctx)
+}
+
+func (s *MetricsStore) SetSourceTaskID(ctx context.Context, sourceID int64, taskID string) error {
+	defer s.timed("SetSourceTaskID")()
+	return s.inner.SetSourceTaskID(ctx, sourceID, taskID)
+}
+
+// ---- subscriptions ----
+
+func (s *MetricsStore) CreateSubscription(ctx context.Context, userID, sourceID int64) (*store.Subscription, error) {
+	defer s.timed("CreateSubscription")()
+	return s.inner.CreateSubscription(ctx, userID, sourceID)
+}
+
+func (s *MetricsStore) GetSubscription(ctx context.Context, userID, sourceID int64) (*store.Subscription, error) {
+	defer s.timed("GetSubscription")()
+	return s.inner.GetSubscription(ctx, userID, sourceID)
+}
+
+func (s *MetricsStore) ListSubscriptionsByUser(ctx context.Context, userID int64) ([]*store.Subscription, error) {
+	defer s.timed("ListSubscriptionsByUser")()
+	return s.inner.ListSubscriptionsByUser(ctx, userID)
+}
+
+func (s *MetricsStore) ListActiveSubscriptions(ctx context.Context) ([]*store.Subscription, error) {
+	defer s.timed("ListActiveSubscriptions")()
+	return s.inner.ListActiveSubscriptions(ctx)
+}
+
+func (s *MetricsStore) ListAllSubscriptions(ctx context.Context) ([]*store.Subscription, error) {
+	defer s.timed("ListAllSubscriptions")()
+	return s.inner.ListAllSubscriptions(ctx)
+}
+
+func (s *MetricsStore) SetPosture(ctx context.Context, id int64, posture store.Posture) error {
+	defer s.timed("SetPosture")()
+	return s.inner.SetPosture(ctx, id, posture)
+}
+
+func (s *MetricsStore) GetSourceActiveSubscriberCount(ctx context.Context, sourceID int64) (int, error) {
+	defer s.timed("GetSourceActiveSubscriberCount")()
+	return s.inner.GetSourceActiveSubscriberCount(ctx, sourceID)
+}
+
+// ---- worker events ----
+
+func (s *MetricsStore) RecordWorkerEvent(ctx context.Context, sourceID int64, pid int, eventType store.EventType, exitCode *int, userID *int64, reason *string) error {
+	defer s.timed("RecordWorkerEvent")()
+	return s.inner.RecordWorkerEvent(ctx, sourceID, pid, eventType, exitCode, userID, reason)
+}
+
+func (s *MetricsStore) RecordWorkerEvents(ctx context.Context, sourceID int64, events []store.WorkerEventInput) error {
+	defer s.timed("RecordWorkerEvents")()
+	return s.inner.RecordWorkerEvents(ctx, sourceID, events)
+}
+
+func (s *MetricsStore) RecentWorkerEvents(ctx context.Context, sourceID int64, limit int) ([]store.WorkerEvent, error) {
+	defer s.timed("RecentWorkerEvents")()
+	return s.inner.RecentWorkerEvents(ctx, sourceID, limit)
+}
+
+func (s *MetricsStore) RecordResourceSample(ctx context.Context, sourceID int64, sample store.ResourceSample) error {
+	defer s.timed("RecordResourceSample")()
+	return s.inner.RecordResourceSample(ctx, sourceID, sample)
+}
+
+func (s *MetricsStore) RecentResourceSamples(ctx context.Context, sourceID int64, limit int) ([]store.ResourceSample, error) {
+	defer s.timed("RecentResourceSamples")()
+	return s.inner.RecentResourceSamples(ctx, sourceID, limit)
+}
+
+// ---- config ----
+
+func (s *MetricsStore) GetConfig(ctx context.Context) (map[string]any, error) {
+	defer s.timed("GetConfig")()
+	return s.inner.GetConfig(ctx)
+}
+
+func (s *MetricsStore) SetConfig(ctx context.Context, data map[string]any, authorID *int64, comment string) (*store.ConfigVersion, error) {
+	defer s.timed("SetConfig")()
+	return s.inner.SetConfig(ctx, data, authorID, comment)
+}
+
+func (s *MetricsStore) ListConfigVersions(ctx context.Context, limit, offset int) ([]*store.ConfigVersion, error) {
+	defer s.timed("ListConfigVersions")()
+	return s.inner.ListConfigVersions(ctx, limit, offset)
+}
+
+func (s *MetricsStore) GetConfigVersion(ctx context.Context, id int64) (*store.ConfigVersion, error) {
+	defer s.timed("GetConfigVersion")()
+	return s.inner.GetConfigVersion(ctx, id)
+}
+
+func (s *MetricsStore) RollbackConfig(ctx context.Context, versionID int64, authorID *int64, comment string) (*store.ConfigVersion, error) {
+	defer s.timed("RollbackConfig")()
+	return s.inner.RollbackConfig(ctx, versionID, authorID, comment)
+}
+
+// ---- roles ----
+
+func (s *MetricsStore) CreateRole(ctx context.Context, name string, permissions []string) (*store.Role, error) {
+	defer s.timed("CreateRole")()
+	return s.inner.CreateRole(ctx, name, permissions)
+}
+
+func (s *MetricsStore) AssignRole(ctx context.Context, userID int64, roleName string) error {
+	defer s.timed("AssignRole")()
+	return s.inner.AssignRole(ctx, userID, roleName)
+}
+
+func (s *MetricsStore) ListRoles(ctx context.Context) ([]*store.Role, error) {
+	defer s.timed("ListRoles")()
+	return s.inner.ListRoles(ctx)
+}
+
+// ---- client certs (mTLS enrolment) ----
+
+func (s *MetricsStore) EnrollClientCert(ctx context.Context, fingerprint string, userID int64, label string) (*store.ClientCert, error) {
+	defer s.timed("EnrollClientCert")()
+	return s.inner.EnrollClientCert(ctx, fingerprint, userID, label)
+}
+
+func (s *MetricsStore) GetClientCertByFingerprint(ctx context.Context, fingerprint string) (*store.ClientCert, error) {
+	defer s.timed("GetClientCertByFingerprint")()
+	return s.inner.GetClientCertByFingerprint(ctx, fingerprint)
+}
+
+func (s *MetricsStore) RevokeClientCert(ctx context.Context, fingerprint string) error {
+	defer s.timed("RevokeClientCert")()
+	return s.inner.RevokeClientCert(ctx, fingerprint)
+}
+
+func (s *MetricsStore) ListClientCerts(ctx context.Context) ([]*store.ClientCert, error) {
+	defer s.timed("ListClientCerts")()
+	return s.inner.ListClientCerts(ctx)
+}
+
+// ---- admin tasks ----
+
+func (s *MetricsStore) CreateAdminTask(ctx context.Context, kind string, doerID int64, payload json.RawMessage, total int) (*store.AdminTask, error) {
+	defer s.timed("CreateAdminTask")()
+	return s.inner.CreateAdminTask(ctx, kind, doerID, payload, total)
+}
+
+func (s *MetricsStore) UpdateAdminTaskProgress(ctx context.Context, id uuid.UUID, progress int, message string) error {
+	defer s.timed("UpdateAdminTaskProgress")()
+	return s.inner.UpdateAdminTaskProgress(ctx, id, progress, message)
+}
+
+func (s *MetricsStore) MarkAdminTaskDone(ctx context.Context, id uuid.UUID, status store.AdminTaskStatus, taskErr error) error {
+	defer s.timed("MarkAdminTaskDone")()
+	return s.inner.MarkAdminTaskDone(ctx, id, status, taskErr)
+}
+
+func (s *MetricsStore) ListAdminTasks(ctx context.Context, filter store.AdminTaskFilter) ([]*store.AdminTask, error) {
+	defer s.timed("ListAdminTasks")()
+	return s.inner.ListAdminTasks(ctx, filter)
+}
+
+func (s *MetricsStore) GetAdminTask(ctx context.Context, id uuid.UUID) (*store.AdminTask, error) {
+	defer s.timed("GetAdminTask")()
+	return s.inner.GetAdminTask(ctx, id)
+}
+
+// ---- oauth2 apps ----
+
+func (s *MetricsStore) CreateOAuthApp(ctx context.Context, name string, ownerID int64, redirectURI, clientID, clientSecretHash string) (*store.OAuthApp, error) {
+	defer s.timed("CreateOAuthApp")()
+	return s.inner.CreateOAuthApp(ctx, name, ownerID, redirectURI, clientID, clientSecretHash)
+}
+
+func (s *MetricsStore) GetOAuthApp(ctx context.Context, id uuid.UUID) (*store.OAuthApp, error) {
+	defer s.timed("GetOAuthApp")()
+	return s.inner.GetOAuthApp(ctx, id)
+}
+
+func (s *MetricsStore) GetOAuthAppByClientID(ctx context.Context, clientID string) (*store.OAuthApp, error) {
+	defer s.timed("GetOAuthAppByClientID")()
+	return s.inner.GetOAuthAppByClientID(ctx, clientID)
+}
+
+func (s *MetricsStore) ListOAuthApps(ctx context.Context) ([]*store.OAuthApp, error) {
+	defer s.timed("ListOAuthApps")()
+	return s.inner.ListOAuthApps(ctx)
+}
+
+func (s *MetricsStore) DeleteOAuthApp(ctx context.Context, id uuid.UUID) error {
+	defer s.timed("DeleteOAuthApp")()
+	return s.inner.DeleteOAuthApp(ctx, id)
+}
+
+// ---- oauth2 authorization codes ----
+
+func (s *MetricsStore) CreateOAuthAuthorization(ctx context.Context, a store.OAuthAuthorization) (*store.OAuthAuthorization, error) {
+	defer s.timed("CreateOAuthAuthorization")()
+	return s.inner.CreateOAuthAuthorization(ctx, a)
+}
+
+func (s *MetricsStore) ConsumeOAuthAuthorization(ctx context.Context, code string) (*store.OAuthAuthorization, error) {
+	defer s.timed("ConsumeOAuthAuthorization")()
+	return s.inner.ConsumeOAuthAuthorization(ctx, code)
+}
+
+// ---- oauth2 grants (refresh tokens) ----
+
+func (s *MetricsStore) CreateOAuthGrant(ctx context.Context, appID uuid.UUID, userID int64, refreshToken, scope string, expiresAt time.Time) (*store.OAuthGrant, error) {
+	defer s.timed("CreateOAuthGrant")()
+	return s.inner.CreateOAuthGrant(ctx, appID, userID, refreshToken, scope, expiresAt)
+}
+
+func (s *MetricsStore) GetOAuthGrantByRefreshToken(ctx context.Context, refreshToken string) (*store.OAuthGrant, error) {
+	defer s.timed("GetOAuthGrantByRefreshToken")()
+	return s.inner.GetOAuthGrantByRefreshToken(ctx, refreshToken)
+}
+
+func (s *MetricsStore) RevokeOAuthGrant(ctx context.Context, id uuid.UUID) error {
+	defer s.timed("RevokeOAuthGrant")()
+	return s.inner.RevokeOAuthGrant(ctx, id)
+}
+
+// ---- notification channels ----
+
+func (s *MetricsStore) CreateNotificationChannel(ctx context.Context, userID *int64, typ store.NotificationChannelType, target, secret string, events []string, template string) (*store.NotificationChannel, error) {
+	defer s.timed("CreateNotificationChannel")()
+	return s.inner.CreateNotificationChannel(ctx, userID, typ, target, secret, events, template)
+}
+
+func (s *MetricsStore) GetNotificationChannel(ctx context.Context, id uuid.UUID) (*store.NotificationChannel, error) {
+	defer s.timed("GetNotificationChannel")()
+	return s.inner.GetNotificationChannel(ctx, id)
+}
+
+func (s *MetricsStore) ListNotificationChannelsByUser(ctx context.Context, userID int64) ([]*store.NotificationChannel, error) {
+	defer s.timed("ListNotificationChannelsByUser")()
+	return s.inner.ListNotificationChannelsByUser(ctx, userID)
+}
+
+func (s *MetricsStore) ListGlobalNotificationChannels(ctx context.Context) ([]*store.NotificationChannel, error) {
+	defer s.timed("ListGlobalNotificationChannels")()
+	return s.inner.ListGlobalNotificationChannels(ctx)
+}
+
+func (s *MetricsStore) UpdateNotificationChannel(ctx context.Context, id uuid.UUID, target, secret string, events []string, template string) (*store.NotificationChannel, error) {
+	defer s.timed("UpdateNotificationChannel")()
+	return s.inner.UpdateNotificationChannel(ctx, id, target, secret, events, template)
+}
+
+func (s *MetricsStore) DeleteNotificationChannel(ctx context.Context, id uuid.UUID) error {
+	defer s.timed("DeleteNotificationChannel")()
+	return s.inner.DeleteNotificationChannel(ctx, id)
+}
+
+func (s *MetricsStore) GetSourceSubscriberUserIDs(ctx context.Context, sourceID int64) ([]int64, error) {
+	defer s.timed("GetSourceSubscriberUserIDs")()
+	return s.inner.GetSourceSubscriberUserIDs(ctx, sourceID)
+}
+
+// ---- lifecycle ----
+
+func (s *MetricsStore) Close() error {
+	defer s.timed("Close")()
+	return s.inner.Close()
+}
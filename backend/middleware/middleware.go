@@ -3,25 +3,50 @@ package middleware
 
 import (
 	"context"
+	"crypto/x509"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/whisper-darkly/sticky-dvr/backend/auth"
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
 )
 
+// Permission is a capability that RequirePermission checks for on the
+// request's role. Roles and their granted permissions are stored via
+// store.Store's role methods (seeded at migration time).
+type Permission string
+
+const (
+	PermManageUsers          Permission = "manage_users"
+	PermManageSources        Permission = "manage_sources"
+	PermViewAllSubscriptions Permission = "view_all_subscriptions"
+	PermEditConfig           Permission = "edit_config"
+	PermViewMetrics          Permission = "view_metrics"
+)
+
+// RoleStore is the subset of store.Store needed to resolve a role's granted permissions.
+type RoleStore interface {
+	ListRoles(ctx context.Context) ([]*store.Role, error)
+}
+
 type contextKey int
 
 const (
 	ctxUserID    contextKey = iota
 	ctxUserRole  contextKey = iota
 	ctxSessionID contextKey = iota
+	ctxClientID  contextKey = iota
+	ctxScope     contextKey = iota
 )
 
-// RequireAuth validates the Bearer JWT and injects userID + role into context.
-// Returns 401 on missing/invalid token, 403 on expired.
-func RequireAuth(secret []byte) func(http.Handler) http.Handler {
+// RequireAuth validates the Bearer JWT and injects userID + role into
+// context. Returns 401 on a missing, invalid or expired token. A token
+// minted by auth.IssueAppAccessToken additionally carries ClientID/Scope,
+// which are injected too (see ContextClientID/ContextScopes) so
+// RequireScope can gate routes that OAuth2 apps are allowed to touch.
+func RequireAuth(keys *auth.KeySet) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			raw := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
@@ -29,7 +54,7 @@ func RequireAuth(secret []byte) func(http.Handler) http.Handler {
 				writeError(w, http.StatusUnauthorized, "missing authorization header")
 				return
 			}
-			claims, err := auth.ParseAccessToken(secret, raw)
+			claims, err := auth.ParseAccessToken(keys, raw)
 			if err != nil {
 				writeError(w, http.StatusUnauthorized, err.Error())
 				return
@@ -42,20 +67,76 @@ func RequireAuth(secret []byte) func(http.Handler) http.Handler {
 			ctx := context.WithValue(r.Context(), ctxUserID, userID)
 			ctx = context.WithValue(ctx, ctxUserRole, claims.Role)
 			ctx = context.WithValue(ctx, ctxSessionID, claims.SessionID)
+			ctx = context.WithValue(ctx, ctxClientID, claims.ClientID)
+			ctx = context.WithValue(ctx, ctxScope, claims.Scope)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireMTLSOrJWT behaves like RequireAuth, but additionally accepts a
+// verified mTLS client certificate in place of a bearer JWT. When the
+// request arrives over TLS with a peer certificate chaining to caPool, its
+// CommonName is resolved to a user/role via cnToUserID and injected into
+// context exactly as the JWT path does. Requests with no peer certificate
+// fall back to JWT. Intended for headless recorder nodes and scripts that
+// would otherwise need to store a long-lived JWT.
+func RequireMTLSOrJWT(keys *auth.KeySet, caPool *x509.CertPool, cnToUserID func(string) (int64, string, error)) func(http.Handler) http.Handler {
+	jwtAuth := RequireAuth(keys)
+	return func(next http.Handler) http.Handler {
+		jwtNext := jwtAuth(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+				jwtNext.ServeHTTP(w, r)
+				return
+			}
+
+			cert := r.TLS.PeerCertificates[0]
+			opts := x509.VerifyOptions{
+				Roots:     caPool,
+				KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			}
+			if _, err := cert.Verify(opts); err != nil {
+				writeError(w, http.StatusUnauthorized, "invalid client certificate")
+				return
+			}
+
+			userID, role, err := cnToUserID(cert.Subject.CommonName)
+			if err != nil {
+				writeError(w, http.StatusUnauthorized, "unrecognised client certificate")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ctxUserID, userID)
+			ctx = context.WithValue(ctx, ctxUserRole, role)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-// RequireAdmin returns 403 if the request context role is not "admin".
-func RequireAdmin() func(http.Handler) http.Handler {
+// RequirePermission returns 403 unless the request's role (injected by
+// RequireAuth) has been granted perm, per rs.ListRoles.
+func RequirePermission(rs RoleStore, perm Permission) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if ContextUserRole(r) != "admin" {
-				writeError(w, http.StatusForbidden, "admin role required")
+			roles, err := rs.ListRoles(r.Context())
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, "internal error")
 				return
 			}
-			next.ServeHTTP(w, r)
+			role := ContextUserRole(r)
+			for _, rl := range roles {
+				if rl.Name != role {
+					continue
+				}
+				for _, p := range rl.Permissions {
+					if Permission(p) == perm {
+						next.ServeHTTP(w, r)
+						return
+					}
+				}
+			}
+			writeError(w, http.StatusForbidden, "missing required permission")
 		})
 	}
 }
@@ -78,6 +159,54 @@ func ContextSessionID(r *http.Request) uuid.UUID {
 	return v
 }
 
+// ContextClientID extracts the OAuth2 client_id injected by RequireAuth,
+// empty for a normal cookie/user-session token.
+func ContextClientID(r *http.Request) string {
+	v, _ := r.Context().Value(ctxClientID).(string)
+	return v
+}
+
+// ContextScopes splits the space-separated scope string injected by
+// RequireAuth into its individual scope values.
+func ContextScopes(r *http.Request) []string {
+	v, _ := r.Context().Value(ctxScope).(string)
+	if v == "" {
+		return nil
+	}
+	return strings.Fields(v)
+}
+
+// RequireScope returns 403 unless the request carries one of the given
+// scopes — or a literal "admin" scope, which grants every route — in its
+// OAuth2 access token. A user-session token (ContextClientID empty) is
+// never scope-limited, so this only restricts OAuth2 app tokens; pair it
+// with RequirePermission, not in place of it, on routes both kinds of
+// caller can reach.
+func RequireScope(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ContextClientID(r) == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			granted := ContextScopes(r)
+			for _, g := range granted {
+				if g == "admin" {
+					next.ServeHTTP(w, r)
+					return
+				}
+				for _, want := range scopes {
+					if g == want {
+						next.ServeHTTP(w, r)
+						return
+					}
+				}
+			}
+			writeError(w, http.StatusForbidden, "token missing required scope")
+		})
+	}
+}
+
 func writeError(w http.ResponseWriter, code int, msg string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -0,0 +1,175 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
+)
+
+// webhookPayload is the JSON body POSTed to a webhook channel, analogous to
+// a GitHub event payload: the caller identifies the event by X-Event-Type
+// and verifies X-Hub-Signature-256 before trusting the body.
+type webhookPayload struct {
+	Event    EventKind `json:"event"`
+	SourceID int64     `json:"source_id,omitempty"`
+	Driver   string    `json:"driver,omitempty"`
+	Username string    `json:"username,omitempty"`
+	Message  string    `json:"message"`
+	TS       string    `json:"ts"`
+}
+
+func newPayload(ev Event) webhookPayload {
+	return webhookPayload{
+		Event:    ev.Kind,
+		SourceID: ev.SourceID,
+		Driver:   ev.Driver,
+		Username: ev.Username,
+		Message:  renderMessage(ev),
+		TS:       ev.TS.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// renderMessage returns ev.Message if set, else a default
+// "<event>: <driver>/<username>" line (just the event kind when both are
+// empty). applyTemplate below is what fills ch.Template's placeholders.
+func renderMessage(ev Event) string {
+	if ev.Message != "" {
+		return ev.Message
+	}
+	if ev.Driver != "" || ev.Username != "" {
+		return fmt.Sprintf("%s: %s/%s", ev.Kind, ev.Driver, ev.Username)
+	}
+	return string(ev.Kind)
+}
+
+func applyTemplate(tmpl string, ev Event) string {
+	if tmpl == "" {
+		return renderMessage(ev)
+	}
+	r := strings.NewReplacer(
+		"{{event}}", string(ev.Kind),
+		"{{driver}}", ev.Driver,
+		"{{username}}", ev.Username,
+		"{{message}}", renderMessage(ev),
+	)
+	return r.Replace(tmpl)
+}
+
+// sendWebhook POSTs a JSON payload to ch.Target, signing the body with
+// ch.Secret the same way GitHub does: hex(hmac-sha256(body)) in
+// X-Hub-Signature-256, so the receiver can verify authenticity without a
+// shared TLS client cert.
+func (d *Dispatcher) sendWebhook(ctx context.Context, ch *store.NotificationChannel, ev Event) error {
+	body, err := json.Marshal(newPayload(ev))
+	if err != nil {
+		return &retryableError{err: err, retry: false}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ch.Target, bytes.NewReader(body))
+	if err != nil {
+		return &retryableError{err: err, retry: false}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", string(ev.Kind))
+	if ch.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(ch.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	return d.doRequest(req)
+}
+
+// sendDiscord posts to a Discord incoming-webhook URL, whose payload shape
+// is just {"content": "..."}.
+func (d *Dispatcher) sendDiscord(ctx context.Context, ch *store.NotificationChannel, ev Event) error {
+	return d.postJSON(ctx, ch.Target, map[string]string{"content": applyTemplate(ch.Template, ev)})
+}
+
+// sendSlack posts to a Slack incoming-webhook URL, whose payload shape is
+// {"text": "..."}.
+func (d *Dispatcher) sendSlack(ctx context.Context, ch *store.NotificationChannel, ev Event) error {
+	return d.postJSON(ctx, ch.Target, map[string]string{"text": applyTemplate(ch.Template, ev)})
+}
+
+// sendApprise posts to an Apprise API server (https://github.com/caronc/apprise-api)
+// listening at ch.Target, forwarding ch.Secret as the comma-separated list
+// of Apprise service URLs to notify through.
+func (d *Dispatcher) sendApprise(ctx context.Context, ch *store.NotificationChannel, ev Event) error {
+	return d.postJSON(ctx, ch.Target, map[string]string{
+		"urls": ch.Secret,
+		"body": applyTemplate(ch.Template, ev),
+	})
+}
+
+func (d *Dispatcher) postJSON(ctx context.Context, url string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return &retryableError{err: err, retry: false}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return &retryableError{err: err, retry: false}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return d.doRequest(req)
+}
+
+// doRequest issues req and classifies the result: a network error or 5xx
+// response is retryable, a 4xx is treated as a permanent misconfiguration.
+func (d *Dispatcher) doRequest(req *http.Request) error {
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return &retryableError{err: err, retry: true}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return &retryableError{err: fmt.Errorf("%s: %s", req.URL, resp.Status), retry: true}
+	}
+	if resp.StatusCode >= 400 {
+		return &retryableError{err: fmt.Errorf("%s: %s", req.URL, resp.Status), retry: false}
+	}
+	return nil
+}
+
+// sendEmail sends a plaintext message to ch.Target over SMTP, using
+// connection details from ch.Secret in the form "host:port|user|password"
+// since NotificationChannel has no dedicated SMTP-config fields, so each
+// email channel names its own relay. user and password are optional;
+// PlainAuth is used only when both are present.
+func (d *Dispatcher) sendEmail(ctx context.Context, ch *store.NotificationChannel, ev Event) error {
+	parts := strings.SplitN(ch.Secret, "|", 3)
+	if len(parts) == 0 || parts[0] == "" {
+		return &retryableError{err: fmt.Errorf("notifier: email channel %s has no SMTP host configured", ch.ID), retry: false}
+	}
+	addr := parts[0]
+	host := addr
+	if i := strings.LastIndex(addr, ":"); i >= 0 {
+		host = addr[:i]
+	}
+
+	var auth smtp.Auth
+	if len(parts) == 3 {
+		auth = smtp.PlainAuth("", parts[1], parts[2], host)
+	}
+
+	subject := fmt.Sprintf("[sticky-dvr] %s", ev.Kind)
+	body := applyTemplate(ch.Template, ev)
+	msg := fmt.Sprintf("Subject: %s\r\n\r\n%s\r\n", subject, body)
+
+	from := "sticky-dvr@" + host
+	if err := smtp.SendMail(addr, auth, from, []string{ch.Target}, []byte(msg)); err != nil {
+		return &retryableError{err: err, retry: true}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+// Package notifier dispatches Events raised by manager.Manager to the
+// user- and admin-configured channels (webhook, email, Discord/Slack,
+// Apprise) that have opted into them. It mirrors config.ConfigStore's
+// subset-interface pattern: Dispatcher only needs the handful of
+// store.Store methods below, not the whole persistence surface.
+package notifier
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
+)
+
+// EventKind classifies an Event for channel subscription matching and
+// templating; it is stored verbatim in NotificationChannel.Events.
+type EventKind string
+
+const (
+	EventWorkerStarted     EventKind = "worker_started"
+	EventWorkerRecovered   EventKind = "worker_recovered"
+	EventWorkerErrored     EventKind = "worker_errored"
+	EventRecordingFinished EventKind = "recording_finished"
+	EventDiskQuotaReached  EventKind = "disk_quota_reached"
+	EventSubscriptionAdded EventKind = "subscription_added"
+	EventErrorReset        EventKind = "error_reset"
+	EventSubscriptionEnded EventKind = "subscription_ended"
+)
+
+// Event is one notifiable occurrence. Only the fields relevant to Kind are
+// meaningful; callers building one from a source-scoped callback (OnStarted,
+// OnErrored, sampleLoop, ...) fill Driver/Username/SourceID, while a plain
+// admin action can leave them zero.
+type Event struct {
+	Kind     EventKind
+	SourceID int64
+	Driver   string
+	Username string
+	Message  string
+	TS       time.Time
+}
+
+// Store is the subset of store.Store Dispatcher needs to resolve which
+// channels should receive an Event.
+type Store interface {
+	ListGlobalNotificationChannels(ctx context.Context) ([]*store.NotificationChannel, error)
+	ListNotificationChannelsByUser(ctx context.Context, userID int64) ([]*store.NotificationChannel, error)
+}
+
+const (
+	maxSendAttempts = 4
+	sendTimeout     = 10 * time.Second
+	initialBackoff  = 2 * time.Second
+)
+
+// Dispatcher fans an Event out to every channel subscribed to its Kind:
+// every global (admin-configured) channel, plus the per-user channels of
+// whichever userIDs are passed to Publish. Sends happen in their own
+// goroutines, mirroring eventBus.publish's non-blocking fan-out — a slow or
+// down webhook target never holds up the caller (createSubscription,
+// OnStarted, ...).
+type Dispatcher struct {
+	st         Store
+	httpClient *http.Client
+}
+
+// NewDispatcher builds a Dispatcher backed by st.
+func NewDispatcher(st Store) *Dispatcher {
+	return &Dispatcher{
+		st:         st,
+		httpClient: &http.Client{Timeout: sendTimeout},
+	}
+}
+
+// Publish resolves every channel subscribed to ev.Kind — all global channels
+// plus the per-user channels of userIDs — and dispatches to each exactly
+// once, deduplicated by channel ID so a channel that is both global and
+// individually listed (shouldn't happen, but cheap to guard) doesn't fire
+// twice. It never blocks on delivery and never returns an error: a
+// misconfigured or unreachable channel is the channel owner's problem, not
+// the caller's, so failures are logged and swallowed.
+func (d *Dispatcher) Publish(ctx context.Context, ev Event, userIDs ...int64) {
+	if ev.TS.IsZero() {
+		ev.TS = time.Now()
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	var targets []*store.NotificationChannel
+
+	global, err := d.st.ListGlobalNotificationChannels(ctx)
+	if err != nil {
+		log.Printf("notifier: list global channels: %v", err)
+	}
+	for _, ch := range global {
+		if !seen[ch.ID] {
+			seen[ch.ID] = true
+			targets = append(targets, ch)
+		}
+	}
+
+	for _, userID := range userIDs {
+		chans, err := d.st.ListNotificationChannelsByUser(ctx, userID)
+		if err != nil {
+			log.Printf("notifier: list channels for user=%d: %v", userID, err)
+			continue
+		}
+		for _, ch := range chans {
+			if !seen[ch.ID] {
+				seen[ch.ID] = true
+				targets = append(targets, ch)
+			}
+		}
+	}
+
+	for _, ch := range targets {
+		if !subscribedTo(ch, ev.Kind) {
+			continue
+		}
+		go d.sendWithRetry(ch, ev)
+	}
+}
+
+// subscribedTo reports whether ch wants events of kind. An empty Events list
+// means "all events", matching the empty-permissions-means-nothing-special
+// convention would be backwards here — channels default to everything so a
+// freshly created one isn't silently inert until its owner picks events.
+func subscribedTo(ch *store.NotificationChannel, kind EventKind) bool {
+	if len(ch.Events) == 0 {
+		return true
+	}
+	for _, k := range ch.Events {
+		if k == string(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendWithRetry calls the channel-type-specific sender, retrying with
+// exponential backoff on failures that look transient (network error or 5xx
+// response) up to maxSendAttempts times. A 4xx response or malformed channel
+// config is treated as permanent and not retried.
+func (d *Dispatcher) sendWithRetry(ch *store.NotificationChannel, ev Event) {
+	backoff := initialBackoff
+	for attempt := 1; attempt <= maxSendAttempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err := d.send(ctx, ch, ev)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		retryable, ok := err.(*retryableError)
+		if !ok || !retryable.retry || attempt == maxSendAttempts {
+			log.Printf("notifier: deliver %s to channel=%s (%s) failed (attempt %d/%d): %v",
+				ev.Kind, ch.ID, ch.Type, attempt, maxSendAttempts, err)
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// retryableError distinguishes a transient delivery failure (worth a
+// backoff retry) from a permanent one (bad URL, 4xx, template error).
+type retryableError struct {
+	err   error
+	retry bool
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+
+func (d *Dispatcher) send(ctx context.Context, ch *store.NotificationChannel, ev Event) error {
+	switch ch.Type {
+	case store.NotificationChannelWebhook:
+		return d.sendWebhook(ctx, ch, ev)
+	case store.NotificationChannelEmail:
+		return d.sendEmail(ctx, ch, ev)
+	case store.NotificationChannelDiscord:
+		return d.sendDiscord(ctx, ch, ev)
+	case store.NotificationChannelSlack:
+		return d.sendSlack(ctx, ch, ev)
+	case store.NotificationChannelApprise:
+		return d.sendApprise(ctx, ch, ev)
+	default:
+		return &retryableError{err: errUnknownChannelType(ch.Type), retry: false}
+	}
+}
+
+type unknownChannelTypeError string
+
+func (e unknownChannelTypeError) Error() string { return "notifier: unknown channel type " + string(e) }
+
+func errUnknownChannelType(t store.NotificationChannelType) error {
+	return unknownChannelTypeError(t)
+}
@@ -5,19 +5,27 @@ package overseer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"math"
+	"math/rand"
 	"os"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/logger"
 )
 
 // debugLog is set to true when LOG_DEBUG=1; enables verbose overseer message logging.
 var debugLog = os.Getenv("LOG_DEBUG") == "1"
 
+// ErrShuttingDown is returned by pending Start/Reset/List/Metrics/PoolInfo
+// calls when Shutdown is invoked before they receive a response.
+var ErrShuttingDown = errors.New("overseer: client is shutting down")
+
 // RetryPolicy mirrors the overseer's RetryPolicy (duration strings).
 type RetryPolicy struct {
 	RestartDelay   string `json:"restart_delay,omitempty"`
@@ -51,6 +59,12 @@ type Handler struct {
 	// OnConnected is called each time a WebSocket connection to the overseer is established.
 	// Use it to re-subscribe to existing task IDs after reconnect.
 	OnConnected func()
+	// OnGap is called when the overseer cannot replay far enough back to
+	// satisfy a subscribe's since_seq (its event buffer has evicted some of
+	// the requested range), reporting the first sequence number it was able
+	// to resume from. The caller has definitely missed every event in
+	// (from, to].
+	OnGap func(taskID string, from, to uint64)
 }
 
 // GlobalMetrics holds aggregate counters from the overseer's in-memory state.
@@ -90,6 +104,9 @@ type inbound struct {
 	Tasks        []TaskInfo       `json:"tasks,omitempty"`
 	Global       *json.RawMessage `json:"global,omitempty"`
 	Pool         *json.RawMessage `json:"pool,omitempty"`
+	Seq          uint64           `json:"seq,omitempty"`
+	FromSeq      uint64           `json:"from_seq,omitempty"`
+	ToSeq        uint64           `json:"to_seq,omitempty"`
 	TS           time.Time        `json:"ts"`
 }
 
@@ -99,6 +116,224 @@ type startResult struct {
 	err    error
 }
 
+// OutputEvent is a single tailed output/exit line fanned out to Subscribe callers.
+type OutputEvent struct {
+	TaskID   string    `json:"task_id"`
+	PID      int       `json:"pid"`
+	Stream   string    `json:"stream,omitempty"`
+	Data     string    `json:"data,omitempty"`
+	Exited   bool      `json:"exited,omitempty"`
+	ExitCode int       `json:"exit_code,omitempty"`
+	TS       time.Time `json:"ts"`
+}
+
+// maxTailDrops is the number of consecutive dropped events before a slow
+// tail subscriber is disconnected (its channel closed).
+const maxTailDrops = 20
+
+// Event is the common interface implemented by every event type delivered
+// through Watch. It is a closed sum type — switch on the concrete type to
+// handle each kind.
+type Event interface{ isEvent() }
+
+// EventStarted reports that a task's worker process was (re-)started.
+type EventStarted struct {
+	TaskID    string
+	PID       int
+	RestartOf int
+	TS        time.Time
+}
+
+// EventOutput carries a single stdout/stderr line from a task's worker.
+type EventOutput struct {
+	TaskID string
+	PID    int
+	Stream string
+	Data   string
+	TS     time.Time
+}
+
+// EventExited reports that a task's worker process exited.
+type EventExited struct {
+	TaskID      string
+	PID         int
+	ExitCode    int
+	Intentional bool
+	TS          time.Time
+}
+
+// EventRestarting reports that the overseer is about to restart a task's worker.
+type EventRestarting struct {
+	TaskID  string
+	PID     int
+	Attempt int
+	TS      time.Time
+}
+
+// EventErrored reports that a task has been marked errored after repeated failures.
+type EventErrored struct {
+	TaskID    string
+	PID       int
+	ExitCount int
+	TS        time.Time
+}
+
+// EventConnected reports that the client (re-)established its WebSocket connection.
+type EventConnected struct{ TS time.Time }
+
+// EventDisconnected reports that the client's WebSocket connection was lost.
+type EventDisconnected struct{ TS time.Time }
+
+func (EventStarted) isEvent()      {}
+func (EventOutput) isEvent()       {}
+func (EventExited) isEvent()       {}
+func (EventRestarting) isEvent()   {}
+func (EventErrored) isEvent()      {}
+func (EventConnected) isEvent()    {}
+func (EventDisconnected) isEvent() {}
+
+// eventKind returns the wire-protocol-style kind name for ev, used to match
+// WatchFilter.Kinds.
+func eventKind(ev Event) string {
+	switch ev.(type) {
+	case EventStarted:
+		return "started"
+	case EventOutput:
+		return "output"
+	case EventExited:
+		return "exited"
+	case EventRestarting:
+		return "restarting"
+	case EventErrored:
+		return "errored"
+	case EventConnected:
+		return "connected"
+	case EventDisconnected:
+		return "disconnected"
+	default:
+		return ""
+	}
+}
+
+// eventTaskID returns the task ID ev pertains to, or "" for connection-level
+// events that aren't scoped to any task.
+func eventTaskID(ev Event) string {
+	switch e := ev.(type) {
+	case EventStarted:
+		return e.TaskID
+	case EventOutput:
+		return e.TaskID
+	case EventExited:
+		return e.TaskID
+	case EventRestarting:
+		return e.TaskID
+	case EventErrored:
+		return e.TaskID
+	default:
+		return ""
+	}
+}
+
+// SlowConsumerPolicy controls what Watch does when a subscriber's buffer is full.
+type SlowConsumerPolicy int
+
+const (
+	// DropOldest evicts the oldest buffered event to make room for the new one.
+	DropOldest SlowConsumerPolicy = iota
+	// Disconnect closes the subscriber's channel instead of delivering the event.
+	Disconnect
+)
+
+// WatchFilter scopes a Watch subscription.
+type WatchFilter struct {
+	// TaskIDs restricts delivery to events about these task IDs. Empty means all tasks.
+	TaskIDs []string
+	// Kinds restricts delivery to these event kinds (e.g. "output", "exited"). Empty means all kinds.
+	Kinds []string
+	// BufferSize sets the subscriber channel's buffer. Defaults to 32 if <= 0.
+	BufferSize int
+	// SlowConsumer controls behavior when the buffer fills. Defaults to DropOldest.
+	SlowConsumer SlowConsumerPolicy
+}
+
+// watcher is the internal bookkeeping for one Watch subscription.
+type watcher struct {
+	ch      chan Event
+	taskIDs map[string]struct{} // nil = all tasks
+	kinds   map[string]struct{} // nil = all kinds
+	policy  SlowConsumerPolicy
+}
+
+func (w *watcher) matches(ev Event) bool {
+	if w.taskIDs != nil {
+		taskID := eventTaskID(ev)
+		if taskID == "" {
+			return false
+		}
+		if _, ok := w.taskIDs[taskID]; !ok {
+			return false
+		}
+	}
+	if w.kinds != nil {
+		if _, ok := w.kinds[eventKind(ev)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ClientOptions configures dial/request timeouts, concurrency limits, and
+// reconnect/keepalive behavior. Zero-valued fields are replaced by
+// defaultClientOptions.
+type ClientOptions struct {
+	DialTimeout    time.Duration
+	RequestTimeout time.Duration
+	MaxInflight    int
+
+	// ReconnectInitialDelay is the backoff delay used after the first failed
+	// connection attempt. Each subsequent attempt multiplies the previous
+	// delay by ReconnectMultiplier, capped at ReconnectMaxDelay. If
+	// ReconnectJitter is true, the actual sleep is a random duration between
+	// 0 and the computed delay (full jitter) rather than the delay itself.
+	// The attempt counter resets to 0 once a connection has stayed up for
+	// ReconnectResetThreshold.
+	ReconnectInitialDelay   time.Duration
+	ReconnectMaxDelay       time.Duration
+	ReconnectMultiplier     float64
+	ReconnectJitter         bool
+	ReconnectResetThreshold time.Duration
+
+	// PingInterval is how often connect sends a WebSocket ping to detect a
+	// silently half-open connection. PongWait is the read-deadline extension
+	// granted whenever a pong arrives; a missed pong lets the read deadline
+	// expire, which connect treats as a connection failure and triggers the
+	// reconnect backoff above.
+	PingInterval time.Duration
+	PongWait     time.Duration
+}
+
+func defaultClientOptions() ClientOptions {
+	return ClientOptions{
+		DialTimeout:    5 * time.Second,
+		RequestTimeout: 10 * time.Second,
+		MaxInflight:    8,
+
+		ReconnectInitialDelay:   1 * time.Second,
+		ReconnectMaxDelay:       30 * time.Second,
+		ReconnectMultiplier:     2,
+		ReconnectJitter:         true,
+		ReconnectResetThreshold: 1 * time.Minute,
+
+		PingInterval: 20 * time.Second,
+		PongWait:     45 * time.Second,
+	}
+}
+
+// tailState tracks consecutive drops for a single tail listener.
+type tailState struct {
+	dropped int
+}
+
 // Client maintains a persistent WebSocket connection to a sticky-overseer instance.
 type Client struct {
 	url     string
@@ -113,36 +348,310 @@ type Client struct {
 	metricsPending sync.Map // request id → chan *GlobalMetrics
 	poolPending    sync.Map // request id → chan *PoolInfo
 
-	idSeq          atomic.Int64
-	reconnectDelay time.Duration
+	tailMu        sync.RWMutex
+	tailListeners map[string]map[chan OutputEvent]*tailState // task_id → listener set
+
+	watchMu  sync.Mutex
+	watchers map[chan Event]*watcher
+
+	seqMu   sync.Mutex
+	lastSeq map[string]uint64 // task_id → highest seq observed, for resume-on-reconnect
+
+	idSeq atomic.Int64
+
+	opts     ClientOptions
+	inflight chan struct{}
+
+	startInflight atomic.Int64
+	listInflight  atomic.Int64
+
+	// wg tracks every goroutine the Client spawns (OnConnected notifications,
+	// Watch unsubscribe waiters) so Run can block until all of them have
+	// exited before returning.
+	wg           sync.WaitGroup
+	shutdownOnce sync.Once
+	shutdownCh   chan struct{}
+
+	log *logger.Logger
 }
 
-// NewClient creates a Client targeting the given WebSocket URL.
+// NewClient creates a Client targeting the given WebSocket URL, using default
+// timeouts and concurrency limits. Use NewClientWithOptions to override them.
 func NewClient(url string, h Handler) *Client {
+	return NewClientWithOptions(url, h, defaultClientOptions())
+}
+
+// NewClientWithOptions creates a Client targeting the given WebSocket URL with
+// explicit timeout/concurrency settings. Zero-valued fields in opts fall back
+// to defaultClientOptions.
+func NewClientWithOptions(url string, h Handler, opts ClientOptions) *Client {
+	def := defaultClientOptions()
+	if opts.DialTimeout == 0 {
+		opts.DialTimeout = def.DialTimeout
+	}
+	if opts.RequestTimeout == 0 {
+		opts.RequestTimeout = def.RequestTimeout
+	}
+	if opts.MaxInflight == 0 {
+		opts.MaxInflight = def.MaxInflight
+	}
+	if opts.ReconnectInitialDelay == 0 {
+		opts.ReconnectInitialDelay = def.ReconnectInitialDelay
+	}
+	if opts.ReconnectMaxDelay == 0 {
+		opts.ReconnectMaxDelay = def.ReconnectMaxDelay
+	}
+	if opts.ReconnectMultiplier == 0 {
+		opts.ReconnectMultiplier = def.ReconnectMultiplier
+	}
+	if opts.ReconnectResetThreshold == 0 {
+		opts.ReconnectResetThreshold = def.ReconnectResetThreshold
+	}
+	if opts.PingInterval == 0 {
+		opts.PingInterval = def.PingInterval
+	}
+	if opts.PongWait == 0 {
+		opts.PongWait = def.PongWait
+	}
 	return &Client{
-		url:            url,
-		handler:        h,
-		reconnectDelay: 5 * time.Second,
+		url:           url,
+		handler:       h,
+		tailListeners: make(map[string]map[chan OutputEvent]*tailState),
+		watchers:      make(map[chan Event]*watcher),
+		lastSeq:       make(map[string]uint64),
+		opts:          opts,
+		inflight:      make(chan struct{}, opts.MaxInflight),
+		shutdownCh:    make(chan struct{}),
+		log:           logger.New("worker", "overseer"),
+	}
+}
+
+// nextBackoff computes the reconnect delay for the given zero-based attempt
+// number, following the full-jitter algorithm: the deterministic delay is
+// ReconnectInitialDelay * ReconnectMultiplier^attempt, capped at
+// ReconnectMaxDelay, and (when ReconnectJitter is set) the actual sleep is a
+// random duration uniformly chosen between 0 and that delay.
+func (c *Client) nextBackoff(attempt int) time.Duration {
+	d := float64(c.opts.ReconnectInitialDelay) * math.Pow(c.opts.ReconnectMultiplier, float64(attempt))
+	if max := float64(c.opts.ReconnectMaxDelay); d > max {
+		d = max
+	}
+	delay := time.Duration(d)
+	if !c.opts.ReconnectJitter || delay <= 0 {
+		return delay
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// Watch returns a channel of Events matching filter. The channel is closed
+// when ctx is cancelled; callers must keep draining it until then to avoid
+// triggering the slow-consumer policy. Multiple independent Watch callers do
+// not interfere with each other or with the legacy Handler callbacks, which
+// continue to fire alongside Watch delivery.
+func (c *Client) Watch(ctx context.Context, filter WatchFilter) (<-chan Event, error) {
+	bufSize := filter.BufferSize
+	if bufSize <= 0 {
+		bufSize = 32
+	}
+	w := &watcher{ch: make(chan Event, bufSize), policy: filter.SlowConsumer}
+	if len(filter.TaskIDs) > 0 {
+		w.taskIDs = make(map[string]struct{}, len(filter.TaskIDs))
+		for _, id := range filter.TaskIDs {
+			w.taskIDs[id] = struct{}{}
+		}
+	}
+	if len(filter.Kinds) > 0 {
+		w.kinds = make(map[string]struct{}, len(filter.Kinds))
+		for _, k := range filter.Kinds {
+			w.kinds[k] = struct{}{}
+		}
+	}
+
+	c.watchMu.Lock()
+	c.watchers[w.ch] = w
+	c.watchMu.Unlock()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		<-ctx.Done()
+		c.watchMu.Lock()
+		if _, ok := c.watchers[w.ch]; ok {
+			delete(c.watchers, w.ch)
+			close(w.ch)
+		}
+		c.watchMu.Unlock()
+	}()
+
+	return w.ch, nil
+}
+
+// publishEvent fans ev out to every watcher whose filter matches it, applying
+// each watcher's SlowConsumerPolicy when its buffer is full.
+func (c *Client) publishEvent(ev Event) {
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	for ch, w := range c.watchers {
+		if !w.matches(ev) {
+			continue
+		}
+		select {
+		case ch <- ev:
+			continue
+		default:
+		}
+		switch w.policy {
+		case Disconnect:
+			delete(c.watchers, ch)
+			close(ch)
+		default: // DropOldest
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+}
+
+// SubscribeTail registers ch to receive OutputEvents for taskID (output lines
+// and the terminal exit event). The caller must eventually call
+// UnsubscribeTail. Named distinctly from Subscribe, which sends the overseer
+// wire-protocol subscribe message rather than registering a local listener.
+func (c *Client) SubscribeTail(taskID string, ch chan OutputEvent) {
+	c.tailMu.Lock()
+	defer c.tailMu.Unlock()
+	if c.tailListeners[taskID] == nil {
+		c.tailListeners[taskID] = make(map[chan OutputEvent]*tailState)
+	}
+	c.tailListeners[taskID][ch] = &tailState{}
+}
+
+// UnsubscribeTail removes ch from taskID's listener set. Safe to call more than once.
+func (c *Client) UnsubscribeTail(taskID string, ch chan OutputEvent) {
+	c.tailMu.Lock()
+	defer c.tailMu.Unlock()
+	if m := c.tailListeners[taskID]; m != nil {
+		delete(m, ch)
+		if len(m) == 0 {
+			delete(c.tailListeners, taskID)
+		}
+	}
+}
+
+// publishTail fans ev out to all subscribers of ev.TaskID, closing the
+// channel of any subscriber that falls maxTailDrops events behind.
+func (c *Client) publishTail(ev OutputEvent) {
+	c.tailMu.Lock()
+	defer c.tailMu.Unlock()
+	for ch, st := range c.tailListeners[ev.TaskID] {
+		select {
+		case ch <- ev:
+			st.dropped = 0
+		default:
+			st.dropped++
+			if st.dropped >= maxTailDrops {
+				delete(c.tailListeners[ev.TaskID], ch)
+				close(ch)
+			}
+		}
 	}
 }
 
-// Run connects and reconnects until ctx is cancelled. Call in a dedicated goroutine.
+// Run connects and reconnects until ctx is cancelled or Shutdown is called.
+// It returns only once every goroutine the Client has spawned has exited, so
+// callers can safely tear down anything Run's handler depends on right after
+// it returns. Call in a dedicated goroutine.
 func (c *Client) Run(ctx context.Context) {
+	defer c.wg.Wait()
+	var attempt int
 	for {
 		if ctx.Err() != nil {
 			return
 		}
-		if err := c.connect(ctx); err != nil && ctx.Err() == nil {
-			log.Printf("overseer: %v — retrying in %s", err, c.reconnectDelay)
+		connectedAt := time.Now()
+		err := c.connect(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(connectedAt) >= c.opts.ReconnectResetThreshold {
+			attempt = 0
+		}
+		delay := c.nextBackoff(attempt)
+		attempt++
+		if err != nil {
+			c.log.Warn("reconnect", "error", err, "delay", delay, "attempt", attempt)
 		}
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(c.reconnectDelay):
+		case <-c.shutdownCh:
+			return
+		case <-time.After(delay):
 		}
 	}
 }
 
+// Shutdown sends a WebSocket close frame (if connected), fails every pending
+// Start/Reset/List/Metrics/PoolInfo call with ErrShuttingDown, and blocks
+// until Run's spawned goroutines have exited or ctx expires, whichever comes
+// first. Safe to call more than once; subsequent calls only wait.
+func (c *Client) Shutdown(ctx context.Context) error {
+	c.shutdownOnce.Do(func() { close(c.shutdownCh) })
+
+	c.connMu.Lock()
+	conn := c.conn
+	c.connMu.Unlock()
+	if conn != nil {
+		c.writeMu.Lock()
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		c.writeMu.Unlock()
+	}
+
+	c.failAllPending(ErrShuttingDown)
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// failAllPending resolves every outstanding pending-map entry with err (for
+// startResult) or nil (for the other response types, which carry no error
+// field), used on both disconnect and Shutdown.
+func (c *Client) failAllPending(err error) {
+	c.startPending.Range(func(k, v any) bool {
+		v.(chan startResult) <- startResult{err: err}
+		c.startPending.Delete(k)
+		return true
+	})
+	c.listPending.Range(func(k, v any) bool {
+		v.(chan []TaskInfo) <- nil
+		c.listPending.Delete(k)
+		return true
+	})
+	c.metricsPending.Range(func(k, v any) bool {
+		v.(chan *GlobalMetrics) <- nil
+		c.metricsPending.Delete(k)
+		return true
+	})
+	c.poolPending.Range(func(k, v any) bool {
+		v.(chan *PoolInfo) <- nil
+		c.poolPending.Delete(k)
+		return true
+	})
+}
+
 // IsConnected reports whether a connection is currently active.
 func (c *Client) IsConnected() bool {
 	c.connMu.Lock()
@@ -150,8 +659,37 @@ func (c *Client) IsConnected() bool {
 	return c.conn != nil
 }
 
+// LastSeq returns the highest per-task sequence number observed for taskID so
+// far, or 0 if none has been seen. Subscribe uses this to populate since_seq
+// so a reconnect resumes the event stream rather than re-starting it.
+func (c *Client) LastSeq(taskID string) uint64 {
+	c.seqMu.Lock()
+	defer c.seqMu.Unlock()
+	return c.lastSeq[taskID]
+}
+
+// recordSeq advances the tracked high-water mark for taskID if seq is newer.
+func (c *Client) recordSeq(taskID string, seq uint64) {
+	if taskID == "" || seq == 0 {
+		return
+	}
+	c.seqMu.Lock()
+	defer c.seqMu.Unlock()
+	if seq > c.lastSeq[taskID] {
+		c.lastSeq[taskID] = seq
+	}
+}
+
+// StartInflight reports the number of Start() calls currently awaiting a response.
+func (c *Client) StartInflight() int64 { return c.startInflight.Load() }
+
+// ListInflight reports the number of List() calls currently awaiting a response.
+func (c *Client) ListInflight() int64 { return c.listInflight.Load() }
+
 func (c *Client) connect(ctx context.Context) error {
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.url, nil)
+	dialCtx, cancel := context.WithTimeout(ctx, c.opts.DialTimeout)
+	defer cancel()
+	conn, _, err := websocket.DefaultDialer.DialContext(dialCtx, c.url, nil)
 	if err != nil {
 		return fmt.Errorf("dial %s: %w", c.url, err)
 	}
@@ -160,43 +698,62 @@ func (c *Client) connect(ctx context.Context) error {
 	c.conn = conn
 	c.connMu.Unlock()
 
-	log.Printf("overseer: connected to %s", c.url)
+	c.log.Info("connected", "url", c.url)
+
+	// Keepalive: each pong pushes the read deadline out by
+	// PongWait. If the peer stops answering pings, ReadMessage below will
+	// eventually time out and connect returns an error, which Run treats as
+	// a connection failure and retries with backoff.
+	conn.SetReadDeadline(time.Now().Add(c.opts.PongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(c.opts.PongWait))
+		return nil
+	})
+
+	pingDone := make(chan struct{})
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.opts.PingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pingDone:
+				return
+			case <-ticker.C:
+				c.writeMu.Lock()
+				err := conn.WriteMessage(websocket.PingMessage, nil)
+				c.writeMu.Unlock()
+				if err != nil {
+					return
+				}
+			}
+		}
+	}()
 
 	// Notify the handler so it can re-subscribe to any claimed tasks.
 	if c.handler.OnConnected != nil {
-		go c.handler.OnConnected()
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.handler.OnConnected()
+		}()
 	}
+	c.publishEvent(EventConnected{TS: time.Now()})
 
 	defer func() {
+		close(pingDone)
 		conn.Close()
 		c.connMu.Lock()
 		if c.conn == conn {
 			c.conn = nil
 		}
 		c.connMu.Unlock()
+		c.publishEvent(EventDisconnected{TS: time.Now()})
+
+		c.failAllPending(fmt.Errorf("overseer: connection lost"))
 
-		c.startPending.Range(func(k, v any) bool {
-			v.(chan startResult) <- startResult{err: fmt.Errorf("overseer: connection lost")}
-			c.startPending.Delete(k)
-			return true
-		})
-		c.listPending.Range(func(k, v any) bool {
-			v.(chan []TaskInfo) <- nil
-			c.listPending.Delete(k)
-			return true
-		})
-		c.metricsPending.Range(func(k, v any) bool {
-			v.(chan *GlobalMetrics) <- nil
-			c.metricsPending.Delete(k)
-			return true
-		})
-		c.poolPending.Range(func(k, v any) bool {
-			v.(chan *PoolInfo) <- nil
-			c.poolPending.Delete(k)
-			return true
-		})
-
-		log.Printf("overseer: disconnected from %s", c.url)
+		c.log.Warn("disconnected", "url", c.url)
 	}()
 
 	for {
@@ -216,14 +773,14 @@ func (c *Client) connect(ctx context.Context) error {
 func (c *Client) dispatch(raw []byte) {
 	var msg inbound
 	if err := json.Unmarshal(raw, &msg); err != nil {
-		log.Printf("overseer: bad message: %v", err)
+		c.log.Warn("bad message", "error", err)
 		return
 	}
 
 	if debugLog && msg.Type != "output" {
 		// Log all non-output events when debug mode is enabled (output is too frequent).
-		log.Printf("overseer: recv type=%q task_id=%q pid=%d exit_code=%d intentional=%v",
-			msg.Type, msg.TaskID, msg.PID, msg.ExitCode, msg.Intentional)
+		c.log.Info("recv", "type", msg.Type, "task_id", msg.TaskID, "pid", msg.PID,
+			"exit_code", msg.ExitCode, "intentional", msg.Intentional)
 	}
 
 	switch msg.Type {
@@ -234,9 +791,11 @@ func (c *Client) dispatch(raw []byte) {
 				return
 			}
 		}
+		c.recordSeq(msg.TaskID, msg.Seq)
 		if c.handler.OnStarted != nil {
 			c.handler.OnStarted(msg.TaskID, msg.PID, msg.RestartOf, msg.TS)
 		}
+		c.publishEvent(EventStarted{TaskID: msg.TaskID, PID: msg.PID, RestartOf: msg.RestartOf, TS: msg.TS})
 
 	case "tasks":
 		if ch, ok := c.listPending.LoadAndDelete(msg.ID); ok {
@@ -285,24 +844,42 @@ func (c *Client) dispatch(raw []byte) {
 		}
 
 	case "output":
+		c.recordSeq(msg.TaskID, msg.Seq)
 		if c.handler.OnOutput != nil {
 			c.handler.OnOutput(msg.TaskID, msg.PID, msg.Stream, msg.Data, msg.TS)
 		}
+		c.publishTail(OutputEvent{TaskID: msg.TaskID, PID: msg.PID, Stream: msg.Stream, Data: msg.Data, TS: msg.TS})
+		c.publishEvent(EventOutput{TaskID: msg.TaskID, PID: msg.PID, Stream: msg.Stream, Data: msg.Data, TS: msg.TS})
 
 	case "exited":
+		c.recordSeq(msg.TaskID, msg.Seq)
 		if c.handler.OnExited != nil {
 			c.handler.OnExited(msg.TaskID, msg.PID, msg.ExitCode, msg.Intentional, msg.TS)
 		}
+		c.publishTail(OutputEvent{TaskID: msg.TaskID, PID: msg.PID, Exited: true, ExitCode: msg.ExitCode, TS: msg.TS})
+		c.publishEvent(EventExited{TaskID: msg.TaskID, PID: msg.PID, ExitCode: msg.ExitCode, Intentional: msg.Intentional, TS: msg.TS})
 
 	case "restarting":
+		c.recordSeq(msg.TaskID, msg.Seq)
 		if c.handler.OnRestarting != nil {
 			c.handler.OnRestarting(msg.TaskID, msg.PID, msg.Attempt, msg.TS)
 		}
+		c.publishEvent(EventRestarting{TaskID: msg.TaskID, PID: msg.PID, Attempt: msg.Attempt, TS: msg.TS})
 
 	case "errored":
+		c.recordSeq(msg.TaskID, msg.Seq)
 		if c.handler.OnErrored != nil {
 			c.handler.OnErrored(msg.TaskID, msg.PID, msg.ExitCount, msg.TS)
 		}
+		c.publishEvent(EventErrored{TaskID: msg.TaskID, PID: msg.PID, ExitCount: msg.ExitCount, TS: msg.TS})
+
+	case "gap":
+		// The overseer's event buffer evicted some of the range we asked to
+		// resume from via since_seq; everything in (from, to] is lost.
+		c.recordSeq(msg.TaskID, msg.ToSeq)
+		if c.handler.OnGap != nil {
+			c.handler.OnGap(msg.TaskID, msg.FromSeq, msg.ToSeq)
+		}
 
 	case "subscribed", "unsubscribed":
 		// Acknowledgement only — no action needed.
@@ -332,6 +909,18 @@ func (c *Client) nextID() string {
 // Start asks the overseer to spawn a task with the given task_id, action, params, and retry policy.
 // If taskID is empty, the overseer will auto-generate one (returned in result).
 func (c *Client) Start(ctx context.Context, taskID string, action string, params map[string]string, rp *RetryPolicy) (string, int, error) {
+	select {
+	case c.inflight <- struct{}{}:
+	case <-ctx.Done():
+		return "", 0, ctx.Err()
+	case <-c.shutdownCh:
+		return "", 0, ErrShuttingDown
+	}
+	defer func() { <-c.inflight }()
+
+	c.startInflight.Add(1)
+	defer c.startInflight.Add(-1)
+
 	id := c.nextID()
 	ch := make(chan startResult, 1)
 	c.startPending.Store(id, ch)
@@ -358,7 +947,10 @@ func (c *Client) Start(ctx context.Context, taskID string, action string, params
 	case <-ctx.Done():
 		c.startPending.Delete(id)
 		return "", 0, ctx.Err()
-	case <-time.After(20 * time.Second):
+	case <-c.shutdownCh:
+		c.startPending.Delete(id)
+		return "", 0, ErrShuttingDown
+	case <-time.After(c.opts.RequestTimeout):
 		c.startPending.Delete(id)
 		return "", 0, fmt.Errorf("timeout waiting for start confirmation")
 	}
@@ -367,12 +959,17 @@ func (c *Client) Start(ctx context.Context, taskID string, action string, params
 // Subscribe registers this client as a subscriber for task-specific events (output,
 // started, exited, restarting, errored) for the given taskID.
 // The overseer only broadcasts task events to subscribed clients, so this must
-// be called after claiming an existing task via List on reconnect.
+// be called after claiming an existing task via List on reconnect. since_seq
+// is set to the highest sequence number previously observed for taskID (see
+// LastSeq), so the overseer replays anything missed while disconnected; if it
+// cannot replay that far back it responds with a "gap" message instead,
+// surfaced via Handler.OnGap.
 func (c *Client) Subscribe(taskID string) error {
 	return c.send(map[string]any{
-		"type":    "subscribe",
-		"id":      c.nextID(),
-		"task_id": taskID,
+		"type":      "subscribe",
+		"id":        c.nextID(),
+		"task_id":   taskID,
+		"since_seq": c.LastSeq(taskID),
 	})
 }
 
@@ -391,6 +988,15 @@ func (c *Client) Stop(taskID string) error {
 // The overseer responds with a "started" message on success or "error" on failure,
 // both carrying the same correlation ID, so this call blocks until one arrives.
 func (c *Client) Reset(ctx context.Context, taskID string) (int, error) {
+	select {
+	case c.inflight <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-c.shutdownCh:
+		return 0, ErrShuttingDown
+	}
+	defer func() { <-c.inflight }()
+
 	id := c.nextID()
 	ch := make(chan startResult, 1)
 	c.startPending.Store(id, ch)
@@ -410,7 +1016,10 @@ func (c *Client) Reset(ctx context.Context, taskID string) (int, error) {
 	case <-ctx.Done():
 		c.startPending.Delete(id)
 		return 0, ctx.Err()
-	case <-time.After(10 * time.Second):
+	case <-c.shutdownCh:
+		c.startPending.Delete(id)
+		return 0, ErrShuttingDown
+	case <-time.After(c.opts.RequestTimeout):
 		c.startPending.Delete(id)
 		return 0, fmt.Errorf("timeout waiting for reset confirmation")
 	}
@@ -418,6 +1027,15 @@ func (c *Client) Reset(ctx context.Context, taskID string) (int, error) {
 
 // Metrics returns global aggregate counters from the overseer.
 func (c *Client) Metrics(ctx context.Context) (*GlobalMetrics, error) {
+	select {
+	case c.inflight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.shutdownCh:
+		return nil, ErrShuttingDown
+	}
+	defer func() { <-c.inflight }()
+
 	id := c.nextID()
 	ch := make(chan *GlobalMetrics, 1)
 	c.metricsPending.Store(id, ch)
@@ -436,7 +1054,10 @@ func (c *Client) Metrics(ctx context.Context) (*GlobalMetrics, error) {
 	case <-ctx.Done():
 		c.metricsPending.Delete(id)
 		return nil, ctx.Err()
-	case <-time.After(10 * time.Second):
+	case <-c.shutdownCh:
+		c.metricsPending.Delete(id)
+		return nil, ErrShuttingDown
+	case <-time.After(c.opts.RequestTimeout):
 		c.metricsPending.Delete(id)
 		return nil, fmt.Errorf("timeout waiting for metrics")
 	}
@@ -444,6 +1065,15 @@ func (c *Client) Metrics(ctx context.Context) (*GlobalMetrics, error) {
 
 // PoolInfo returns a snapshot of the global pool state (limit, running, queue depth).
 func (c *Client) PoolInfo(ctx context.Context) (*PoolInfo, error) {
+	select {
+	case c.inflight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.shutdownCh:
+		return nil, ErrShuttingDown
+	}
+	defer func() { <-c.inflight }()
+
 	id := c.nextID()
 	ch := make(chan *PoolInfo, 1)
 	c.poolPending.Store(id, ch)
@@ -462,7 +1092,10 @@ func (c *Client) PoolInfo(ctx context.Context) (*PoolInfo, error) {
 	case <-ctx.Done():
 		c.poolPending.Delete(id)
 		return nil, ctx.Err()
-	case <-time.After(10 * time.Second):
+	case <-c.shutdownCh:
+		c.poolPending.Delete(id)
+		return nil, ErrShuttingDown
+	case <-time.After(c.opts.RequestTimeout):
 		c.poolPending.Delete(id)
 		return nil, fmt.Errorf("timeout waiting for pool info")
 	}
@@ -470,6 +1103,18 @@ func (c *Client) PoolInfo(ctx context.Context) (*PoolInfo, error) {
 
 // List returns all tasks tracked by the overseer.
 func (c *Client) List(ctx context.Context) ([]TaskInfo, error) {
+	select {
+	case c.inflight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.shutdownCh:
+		return nil, ErrShuttingDown
+	}
+	defer func() { <-c.inflight }()
+
+	c.listInflight.Add(1)
+	defer c.listInflight.Add(-1)
+
 	id := c.nextID()
 	ch := make(chan []TaskInfo, 1)
 	c.listPending.Store(id, ch)
@@ -488,8 +1133,96 @@ func (c *Client) List(ctx context.Context) ([]TaskInfo, error) {
 	case <-ctx.Done():
 		c.listPending.Delete(id)
 		return nil, ctx.Err()
-	case <-time.After(10 * time.Second):
+	case <-c.shutdownCh:
+		c.listPending.Delete(id)
+		return nil, ErrShuttingDown
+	case <-time.After(c.opts.RequestTimeout):
 		c.listPending.Delete(id)
 		return nil, fmt.Errorf("timeout waiting for task list")
 	}
 }
+
+// StartSpec describes one task to start as part of a StartBatch call.
+type StartSpec struct {
+	TaskID string
+	Action string
+	Params map[string]string
+	Retry  *RetryPolicy
+}
+
+// StartResult is the outcome of one StartSpec from a StartBatch call.
+type StartResult struct {
+	TaskID string
+	PID    int
+	Err    error
+}
+
+// BatchOptions configures StartBatch's concurrency and failure handling.
+type BatchOptions struct {
+	// MaxInFlight caps how many Start calls StartBatch runs concurrently.
+	// Defaults to len(specs) (i.e. unbounded beyond the Client-wide
+	// inflight semaphore from ClientOptions.MaxInflight) if <= 0.
+	MaxInFlight int
+	// StopOnFirstError cancels every outstanding Start call as soon as one
+	// spec fails, instead of waiting for all of them to finish.
+	StopOnFirstError bool
+	// PerRequestTimeout bounds each individual Start call. Zero means no
+	// additional bound beyond ctx.
+	PerRequestTimeout time.Duration
+}
+
+// StartBatch issues specs as concurrent Start calls over this Client's single
+// connection, limited to opts.MaxInFlight at a time, and returns one
+// StartResult per spec in a slice aligned by index — result ordering matches
+// specs regardless of completion order. Cancelling ctx (or, if
+// opts.StopOnFirstError is set, the first failing spec) aborts every
+// outstanding Start call; aborted specs still get a StartResult, with Err set
+// to the cancellation cause, and their startPending entries are cleaned up by
+// Start itself. The returned error is ctx's error if ctx was cancelled, nil
+// otherwise — per-spec failures are only reported in the StartResult slice.
+func (c *Client) StartBatch(ctx context.Context, specs []StartSpec, opts BatchOptions) ([]StartResult, error) {
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = len(specs)
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = 1
+	}
+
+	batchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]StartResult, len(specs))
+	sem := make(chan struct{}, opts.MaxInFlight)
+
+	var wg sync.WaitGroup
+	for i, spec := range specs {
+		wg.Add(1)
+		go func(i int, spec StartSpec) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+			case <-batchCtx.Done():
+				results[i] = StartResult{TaskID: spec.TaskID, Err: batchCtx.Err()}
+				return
+			}
+			defer func() { <-sem }()
+
+			reqCtx := batchCtx
+			if opts.PerRequestTimeout > 0 {
+				var reqCancel context.CancelFunc
+				reqCtx, reqCancel = context.WithTimeout(batchCtx, opts.PerRequestTimeout)
+				defer reqCancel()
+			}
+
+			taskID, pid, err := c.Start(reqCtx, spec.TaskID, spec.Action, spec.Params, spec.Retry)
+			results[i] = StartResult{TaskID: taskID, PID: pid, Err: err}
+			if err != nil && opts.StopOnFirstError {
+				cancel()
+			}
+		}(i, spec)
+	}
+	wg.Wait()
+
+	return results, ctx.Err()
+}
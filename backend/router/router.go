@@ -2,50 +2,84 @@
 package router
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"io/fs"
+	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/whisper-darkly/sticky-dvr/backend/apihandler"
 	"github.com/whisper-darkly/sticky-dvr/backend/auth"
 	"github.com/whisper-darkly/sticky-dvr/backend/config"
-	"github.com/whisper-darkly/sticky-dvr/backend/converter"
+	"github.com/whisper-darkly/sticky-dvr/backend/diagnostics"
 	"github.com/whisper-darkly/sticky-dvr/backend/manager"
+	"github.com/whisper-darkly/sticky-dvr/backend/metrics"
 	"github.com/whisper-darkly/sticky-dvr/backend/middleware"
+	"github.com/whisper-darkly/sticky-dvr/backend/notifier"
+	"github.com/whisper-darkly/sticky-dvr/backend/overseer"
 	"github.com/whisper-darkly/sticky-dvr/backend/store"
-	"github.com/whisper-darkly/sticky-dvr/backend/thumbnailer"
+	"github.com/whisper-darkly/sticky-dvr/backend/workers"
 )
 
 const refreshCookie = "refresh_token"
 const accessCookie  = "access_token"
 const sessionTTL    = 24 * time.Hour
 
+// diagnosticsTimeout and sourceFilesTimeout bound handlers that dial out to
+// the converter/thumbnailer/recorder over WebSocket, so a stuck downstream
+// service can't pile up goroutines waiting on a response that never arrives.
+const diagnosticsTimeout = 15 * time.Second
+const sourceFilesTimeout = 10 * time.Second
+
+// defaultReadyzTimeout bounds readyz's dependency checks when Deps.ReadyzTimeout is unset.
+const defaultReadyzTimeout = 5 * time.Second
+
+// defaultMetricsPath is where Prometheus scrapes when Deps.MetricsPath is
+// unset; overridable per-deployment via the PROMETHEUS_METRICS_PATH env var.
+const defaultMetricsPath = "/metrics"
+
 // Deps holds all dependencies for the router.
 type Deps struct {
-	Store             store.Store
-	Manager           *manager.Manager
-	Config            *config.Global
-	JWTSecret         []byte
-	ConverterClient   *converter.Client   // nil → files endpoint returns empty list
-	ThumbnailerClient *thumbnailer.Client // nil → thumbnailer diagnostics unavailable
+	Store         store.Store
+	Manager       *manager.Manager
+	Config        *config.Global
+	Keys          *auth.KeySet          // nil is invalid; main.go always supplies one (HMAC- or directory-backed)
+	Workers       *workers.Registry     // holds the converter/thumbnailer clients; nil fields degrade gracefully
+	Notifier      *notifier.Dispatcher  // nil → subscription lifecycle events are not dispatched
+	ReadyzTimeout time.Duration         // 0 → defaultReadyzTimeout
+	Services      *diagnostics.Registry // nil → getDiagnostics returns an empty object
+	MetricsPath   string                // "" → defaultMetricsPath
 }
 
 // New builds and returns the application HTTP handler.
 func New(d Deps) http.Handler {
 	mux := http.NewServeMux()
 
-	requireAuth := middleware.RequireAuth(d.JWTSecret)
-	requireAdmin := middleware.RequireAdmin()
+	requireAuth := middleware.RequireAuth(d.Keys)
+	requirePerm := func(perm middleware.Permission) func(http.Handler) http.Handler {
+		return middleware.RequirePermission(d.Store, perm)
+	}
 
 	// ---- auth (no auth required) ----
 	mux.HandleFunc("POST /api/auth/login", login(d))
 	mux.HandleFunc("POST /api/auth/refresh", refreshToken(d))
+	mux.HandleFunc("GET /.well-known/jwks.json", jwks(d))
 
 	// ---- auth (requires valid JWT) ----
 	mux.Handle("POST /api/auth/logout", requireAuth(http.HandlerFunc(logout(d))))
@@ -71,6 +105,8 @@ func New(d Deps) http.Handler {
 		requireAuth(http.HandlerFunc(archiveSubscription(d))))
 	mux.Handle("POST /api/subscriptions/{driver}/{username}/reset-error",
 		requireAuth(http.HandlerFunc(resetError(d))))
+	mux.Handle("POST /api/subscriptions/{driver}/{username}/restart",
+		requireAuth(http.HandlerFunc(restartSubscription(d))))
 
 	// ---- per-source data (auth + ownership) ----
 	mux.Handle("GET /api/sources/{driver}/{username}/events",
@@ -78,52 +114,191 @@ func New(d Deps) http.Handler {
 	mux.Handle("GET /api/sources/{driver}/{username}/logs",
 		requireAuth(http.HandlerFunc(getSourceLogs(d))))
 	mux.Handle("GET /api/sources/{driver}/{username}/files",
-		requireAuth(http.HandlerFunc(getSourceFiles(d))))
+		requireAuth(http.TimeoutHandler(http.HandlerFunc(getSourceFiles(d)), sourceFilesTimeout, "timed out listing source files")))
 	mux.Handle("GET /api/sources/{driver}/{username}/filestat",
 		requireAuth(http.HandlerFunc(getSourceFileStat(d))))
+	mux.Handle("GET /api/sources/{driver}/{username}/download",
+		requireAuth(http.HandlerFunc(getSourceDownload(d))))
+	mux.Handle("GET /api/sources/{driver}/{username}/tail",
+		requireAuth(http.HandlerFunc(tailSource(d))))
+	mux.Handle("GET /api/sources/{driver}/{username}/logs/history",
+		requireAuth(http.HandlerFunc(getSourceLogHistory(d))))
+	mux.Handle("GET /api/sources/{driver}/{username}/logs/tail",
+		requireAuth(http.HandlerFunc(tailSourceLog(d))))
+	mux.Handle("GET /api/sources/{driver}/{username}/stats/history",
+		requireAuth(http.HandlerFunc(getSourceStatsHistory(d))))
+	mux.Handle("GET /api/sources/{driver}/{username}/stream",
+		requireAuth(http.HandlerFunc(getSourceStream(d))))
 
 	// ---- admin: subscription management (by sub_id) ----
 	mux.Handle("POST /api/admin/subscriptions/{sub_id}/pause",
-		requireAuth(requireAdmin(http.HandlerFunc(adminPauseSubscription(d)))))
+		requireAuth(requirePerm(middleware.PermManageSources)(http.HandlerFunc(adminPauseSubscription(d)))))
 	mux.Handle("POST /api/admin/subscriptions/{sub_id}/resume",
-		requireAuth(requireAdmin(http.HandlerFunc(adminResumeSubscription(d)))))
+		requireAuth(requirePerm(middleware.PermManageSources)(http.HandlerFunc(adminResumeSubscription(d)))))
 	mux.Handle("POST /api/admin/subscriptions/{sub_id}/archive",
-		requireAuth(requireAdmin(http.HandlerFunc(adminArchiveSubscription(d)))))
+		requireAuth(requirePerm(middleware.PermManageSources)(http.HandlerFunc(adminArchiveSubscription(d)))))
 	mux.Handle("DELETE /api/admin/subscriptions/{sub_id}",
-		requireAuth(requireAdmin(http.HandlerFunc(adminDeleteSubscription(d)))))
+		requireAuth(requirePerm(middleware.PermManageSources)(http.HandlerFunc(adminDeleteSubscription(d)))))
 	mux.Handle("POST /api/admin/subscriptions/{sub_id}/reset-error",
-		requireAuth(requireAdmin(http.HandlerFunc(adminResetError(d)))))
+		requireAuth(requirePerm(middleware.PermManageSources)(http.HandlerFunc(adminResetError(d)))))
 
 	// ---- admin: bulk source operations ----
 	mux.Handle("POST /api/admin/sources/restart-all",
-		requireAuth(requireAdmin(http.HandlerFunc(adminRestartAllSources(d)))))
+		requireAuth(requirePerm(middleware.PermManageSources)(http.HandlerFunc(adminRestartAllSources(d)))))
+	mux.Handle("POST /api/admin/subscriptions:batch",
+		requireAuth(requirePerm(middleware.PermManageSources)(http.HandlerFunc(adminBatchSubscriptions(d)))))
 
 	// ---- admin: source subscribers + user subscriptions ----
 	mux.Handle("GET /api/admin/sources/{driver}/{username}/subscribers",
-		requireAuth(requireAdmin(http.HandlerFunc(adminGetSourceSubscribers(d)))))
+		requireAuth(requirePerm(middleware.PermViewAllSubscriptions)(http.HandlerFunc(adminGetSourceSubscribers(d)))))
 	mux.Handle("GET /api/admin/users/{id}/subscriptions",
-		requireAuth(requireAdmin(http.HandlerFunc(adminGetUserSubscriptions(d)))))
+		requireAuth(requirePerm(middleware.PermViewAllSubscriptions)(http.HandlerFunc(adminGetUserSubscriptions(d)))))
 
 	// ---- admin: config ----
-	mux.Handle("GET /api/config", requireAuth(requireAdmin(http.HandlerFunc(getConfig(d)))))
-	mux.Handle("PUT /api/config", requireAuth(requireAdmin(http.HandlerFunc(putConfig(d)))))
+	mux.Handle("GET /api/config", requireAuth(requirePerm(middleware.PermEditConfig)(http.HandlerFunc(getConfig(d)))))
+	mux.Handle("PUT /api/config", requireAuth(requirePerm(middleware.PermEditConfig)(http.HandlerFunc(putConfig(d)))))
+	mux.Handle("PATCH /api/config", requireAuth(requirePerm(middleware.PermEditConfig)(http.HandlerFunc(patchConfig(d)))))
+	mux.Handle("POST /api/config/reload", requireAuth(requirePerm(middleware.PermEditConfig)(http.HandlerFunc(reloadConfig(d)))))
+	mux.Handle("GET /api/config/versions", requireAuth(requirePerm(middleware.PermEditConfig)(http.HandlerFunc(listConfigVersions(d)))))
+	mux.Handle("GET /api/config/versions/{id}", requireAuth(requirePerm(middleware.PermEditConfig)(http.HandlerFunc(getConfigVersion(d)))))
+	mux.Handle("POST /api/config/versions/{id}/rollback", requireAuth(requirePerm(middleware.PermEditConfig)(http.HandlerFunc(rollbackConfig(d)))))
 
 	// ---- admin: users ----
-	mux.Handle("GET /api/users", requireAuth(requireAdmin(http.HandlerFunc(listUsers(d)))))
-	mux.Handle("POST /api/users", requireAuth(requireAdmin(http.HandlerFunc(createUser(d)))))
-	mux.Handle("GET /api/users/{id}", requireAuth(requireAdmin(http.HandlerFunc(getUser(d)))))
-	mux.Handle("PUT /api/users/{id}", requireAuth(requireAdmin(http.HandlerFunc(updateUser(d)))))
-	mux.Handle("DELETE /api/users/{id}", requireAuth(requireAdmin(http.HandlerFunc(deleteUser(d)))))
+	mux.Handle("GET /api/users", requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(listUsers(d)))))
+	mux.Handle("POST /api/users", requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(createUser(d)))))
+	mux.Handle("GET /api/users/{id}", requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(getUser(d)))))
+	mux.Handle("PUT /api/users/{id}", requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(updateUser(d)))))
+	mux.Handle("DELETE /api/users/{id}", requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(deleteUser(d)))))
+	mux.Handle("POST /api/admin/users/{id}/rehash", requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(rehashUserPassword(d)))))
 
 	// ---- admin: diagnostics ----
 	mux.Handle("GET /api/admin/diagnostics",
-		requireAuth(requireAdmin(http.HandlerFunc(getDiagnostics(d)))))
+		requireAuth(requirePerm(middleware.PermViewAllSubscriptions)(http.TimeoutHandler(http.HandlerFunc(getDiagnostics(d)), diagnosticsTimeout, "timed out collecting diagnostics"))))
+
+	// ---- admin: worker clients (overseer/converter/thumbnailer connection status) ----
+	mux.Handle("GET /api/admin/workers",
+		requireAuth(requirePerm(middleware.PermViewAllSubscriptions)(http.HandlerFunc(getWorkerStatus(d)))))
+
+	// ---- admin: client certs (mTLS enrolment) ----
+	mux.Handle("GET /api/admin/client-certs",
+		requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(listClientCerts(d)))))
+	mux.Handle("POST /api/admin/client-certs",
+		requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(enrollClientCert(d)))))
+	mux.Handle("DELETE /api/admin/client-certs/{fingerprint}",
+		requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(revokeClientCert(d)))))
+
+	// ---- oauth2 provider ----
+	mux.Handle("POST /api/oauth/apps",
+		requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(createOAuthApp(d)))))
+	mux.Handle("GET /api/oauth/apps/{id}",
+		requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(getOAuthApp(d)))))
+	mux.Handle("DELETE /api/oauth/apps/{id}",
+		requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(deleteOAuthApp(d)))))
+	mux.Handle("GET /api/oauth/authorize", requireAuth(http.HandlerFunc(getOAuthAuthorize(d))))
+	mux.Handle("POST /api/oauth/authorize", requireAuth(http.HandlerFunc(postOAuthAuthorize(d))))
+	// /api/oauth/token and /api/oauth/revoke authenticate the caller via the
+	// client_id/client_secret in the request body, not a bearer JWT, so they
+	// sit outside requireAuth like /api/auth/login.
+	mux.HandleFunc("POST /api/oauth/token", oauthToken(d))
+	mux.HandleFunc("POST /api/oauth/revoke", oauthRevoke(d))
+
+	// ---- notification channels ----
+	mux.Handle("GET /api/me/notifications", requireAuth(http.HandlerFunc(listMyNotificationChannels(d))))
+	mux.Handle("POST /api/me/notifications", requireAuth(http.HandlerFunc(createMyNotificationChannel(d))))
+	mux.Handle("PUT /api/me/notifications/{id}", requireAuth(http.HandlerFunc(updateMyNotificationChannel(d))))
+	mux.Handle("DELETE /api/me/notifications/{id}", requireAuth(http.HandlerFunc(deleteMyNotificationChannel(d))))
+	mux.Handle("GET /api/admin/notifications",
+		requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(listGlobalNotificationChannels(d)))))
+	mux.Handle("POST /api/admin/notifications",
+		requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(createGlobalNotificationChannel(d)))))
+	mux.Handle("PUT /api/admin/notifications/{id}",
+		requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(updateGlobalNotificationChannel(d)))))
+	mux.Handle("DELETE /api/admin/notifications/{id}",
+		requireAuth(requirePerm(middleware.PermManageUsers)(http.HandlerFunc(deleteGlobalNotificationChannel(d)))))
 
 	// ---- system ----
+	// /api/health is kept as an alias of /readyz for callers that haven't
+	// moved to the split probes yet.
 	mux.HandleFunc("GET /api/health", health(d))
-	mux.Handle("GET /api/workers", requireAuth(requireAdmin(http.HandlerFunc(listWorkers(d)))))
+	mux.HandleFunc("GET /livez", livez(d))
+	mux.HandleFunc("GET /readyz", readyz(d))
+	mux.HandleFunc("GET /debug/vars", debugVars(d))
+	mux.Handle("GET /api/workers", requireAuth(requirePerm(middleware.PermViewAllSubscriptions)(http.HandlerFunc(listWorkers(d)))))
+	mux.Handle("GET /api/admin/leave-status",
+		requireAuth(requirePerm(middleware.PermViewAllSubscriptions)(http.HandlerFunc(getLeaveStatus(d)))))
+	mux.Handle("POST /api/admin/drain",
+		requireAuth(requirePerm(middleware.PermManageSources)(http.HandlerFunc(setDrain(d)))))
+
+	// ---- streaming ----
+	mux.Handle("GET /api/thumbnailer/events",
+		requireAuth(http.HandlerFunc(streamThumbnailerEvents(d))))
+	mux.Handle("GET /api/files/{task_id}/logs",
+		requireAuth(http.HandlerFunc(tailConverterOutput(d))))
+	mux.Handle("GET /api/pool/progress",
+		requireAuth(http.HandlerFunc(getPoolProgress(d))))
+	mux.Handle("GET /api/admin/events",
+		requireAuth(requirePerm(middleware.PermViewAllSubscriptions)(http.HandlerFunc(streamManagerEvents(d)))))
+	// /api/admin/stream is the admin-wide sibling of getSourceStream, named to
+	// match it; it reuses streamManagerEvents rather than fanning the
+	// per-source log/file-stat feeds in across every source at once, which
+	// isn't bounded enough to stream cheaply.
+	mux.Handle("GET /api/admin/stream",
+		requireAuth(requirePerm(middleware.PermViewAllSubscriptions)(http.HandlerFunc(streamManagerEvents(d)))))
+	// /admin/events is an unprefixed alias of /api/admin/events, for SSE
+	// clients/proxies that route by top-level path the same way /livez and
+	// /readyz are unprefixed.
+	mux.Handle("GET /admin/events",
+		requireAuth(requirePerm(middleware.PermViewAllSubscriptions)(http.HandlerFunc(streamManagerEvents(d)))))
+
+	// ---- observability ----
+	metricsPath := d.MetricsPath
+	if metricsPath == "" {
+		metricsPath = defaultMetricsPath
+	}
+	mux.Handle("GET "+metricsPath, requireAuth(requirePerm(middleware.PermViewMetrics)(metrics.Handler())))
+
+	// ---- v2 (envelope responses) ----
+	// New v2 endpoints land here as they're written; everything above is
+	// "v1" and stays on the freeform {"error": msg} shape. Both APIVersion
+	// and aliasV1Prefix exist so a breaking v2 response change never forces
+	// existing /api/... callers to migrate on our schedule.
+	mux.Handle("GET /api/v2/me", requireAuth(http.HandlerFunc(getMeV2(d))))
+	mux.Handle("GET /api/v2/subscriptions", requireAuth(http.HandlerFunc(listSubscriptionsV2(d))))
+
+	return aliasV1Prefix(metrics.Instrument(mux))
+}
 
-	return mux
+// APIVersion reports which response shape a request wants: "v2" if the
+// path is already under /api/v2/, or if the caller asked for it via
+// Accept: application/vnd.sticky-dvr.v2+json without using the versioned
+// path (e.g. a v1 path kept working past its alias window for a client
+// that negotiates by header instead). Everything else is "v1".
+func APIVersion(r *http.Request) string {
+	if strings.HasPrefix(r.URL.Path, "/api/v2/") {
+		return "v2"
+	}
+	for _, mt := range strings.Split(r.Header.Get("Accept"), ",") {
+		if strings.TrimSpace(mt) == "application/vnd.sticky-dvr.v2+json" {
+			return "v2"
+		}
+	}
+	return "v1"
+}
+
+// aliasV1Prefix rewrites /api/v1/... requests to /api/... before they reach
+// next, so the un-versioned routes registered above also answer under an
+// explicit /api/v1 prefix. Planned to be removed one release after v2 ships
+// enough endpoints to be a real alternative, per the versioning plan.
+func aliasV1Prefix(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rest, ok := strings.CutPrefix(r.URL.Path, "/api/v1/"); ok {
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = "/api/" + rest
+			next.ServeHTTP(w, r2)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
 // ---- response helpers ----
@@ -138,8 +313,65 @@ func writeError(w http.ResponseWriter, code int, msg string) {
 	writeJSON(w, code, map[string]string{"error": msg})
 }
 
+// ValidationError is one field-level problem found while decoding/validating
+// a request body. Code is a stable machine-readable token (e.g. "required",
+// "invalid_enum", "invalid_type") so a frontend can render inline form
+// errors without parsing Message, which is just a human-readable fallback.
+type ValidationError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeValidationError writes a 400 response shaped {"errors": [...]}, the
+// structured counterpart to writeError's free-form {"error": msg}.
+func writeValidationError(w http.ResponseWriter, errs ...ValidationError) {
+	writeJSON(w, http.StatusBadRequest, map[string]any{"errors": errs})
+}
+
+// decodeStrict decodes r's JSON body into v (a pointer) with
+// DisallowUnknownFields, translating the decoder's error into a
+// ValidationError identifying which field failed and why, instead of the
+// free-form "invalid JSON" string most handlers used to return. Returns nil
+// on success.
+func decodeStrict(r *http.Request, v any) *ValidationError {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return &ValidationError{
+			Field:   typeErr.Field,
+			Code:    "invalid_type",
+			Message: fmt.Sprintf("must be a %s", typeErr.Type),
+		}
+	}
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &ValidationError{
+			Field:   strings.Trim(field, `"`),
+			Code:    "unknown_field",
+			Message: "unrecognized field",
+		}
+	}
+	return &ValidationError{Code: "invalid_body", Message: "request body is not valid JSON"}
+}
+
 // ---- auth handlers ----
 
+// jwks renders d.Keys' current verification keys as a JSON Web Key Set
+// (RFC 7517), so downstream services (thumbnailer, future workers) can
+// validate access tokens without sharing a secret. Deliberately
+// unauthenticated, like any JWKS endpoint.
+func jwks(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, d.Keys.JWKS())
+	}
+}
+
 func login(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var body struct {
@@ -164,6 +396,15 @@ func login(d Deps) http.HandlerFunc {
 			writeError(w, http.StatusUnauthorized, "invalid credentials")
 			return
 		}
+		if auth.NeedsRehash(u.PasswordHash) {
+			if newHash, err := auth.HashPassword(body.Password); err == nil {
+				if _, err := d.Store.UpdateUser(r.Context(), u.ID, store.UserUpdate{PasswordHash: &newHash}); err != nil {
+					log.Printf("login: rehash user=%d: %v", u.ID, err)
+				} else {
+					u.PasswordHash = newHash
+				}
+			}
+		}
 
 		refreshTok, err := auth.GenerateRefreshToken()
 		if err != nil {
@@ -177,7 +418,7 @@ func login(d Deps) http.HandlerFunc {
 			return
 		}
 
-		token, err := auth.IssueAccessToken(d.JWTSecret, u.ID, sess.ID, u.Role)
+		token, err := auth.IssueAccessToken(d.Keys, u.ID, sess.ID, u.Role)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
@@ -200,43 +441,36 @@ func refreshToken(d Deps) http.HandlerFunc {
 			return
 		}
 
-		sess, err := d.Store.GetSessionByRefreshToken(r.Context(), cookie.Value)
+		newSess, err := d.Store.RotateSession(r.Context(), cookie.Value)
+		if err == store.ErrRefreshTokenReused {
+			// The whole family is already revoked by RotateSession; clear the
+			// stolen cookie so the client doesn't keep retrying it.
+			clearRefreshCookie(w)
+			writeError(w, http.StatusUnauthorized, "refresh token reuse detected, session revoked")
+			return
+		}
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
-		if sess == nil || sess.ExpiresAt.Before(time.Now()) {
+		if newSess == nil || newSess.ExpiresAt.Before(time.Now()) {
 			writeError(w, http.StatusUnauthorized, "invalid or expired refresh token")
 			return
 		}
 
-		u, err := d.Store.GetUser(r.Context(), sess.UserID)
+		u, err := d.Store.GetUser(r.Context(), newSess.UserID)
 		if err != nil || u == nil {
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
 
-		// Rotate: delete old session, create new one.
-		_ = d.Store.DeleteSession(r.Context(), sess.ID)
-
-		newRefreshTok, err := auth.GenerateRefreshToken()
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, "internal error")
-			return
-		}
-		newSess, err := d.Store.CreateSession(r.Context(), u.ID, newRefreshTok, time.Now().Add(sessionTTL))
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, "internal error")
-			return
-		}
-
-		token, err := auth.IssueAccessToken(d.JWTSecret, u.ID, newSess.ID, u.Role)
+		token, err := auth.IssueAccessToken(d.Keys, u.ID, newSess.ID, u.Role)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "internal error")
 			return
 		}
 
-		setRefreshCookie(w, newRefreshTok)
+		setRefreshCookie(w, newSess.RefreshToken)
 		setAccessCookie(w, token)
 		writeJSON(w, http.StatusOK, map[string]any{"access_token": token})
 	}
@@ -300,6 +534,37 @@ func clearRefreshCookie(w http.ResponseWriter) {
 	})
 }
 
+// ---- v2 handlers ----
+//
+// v2 handlers are thin: decode/call/envelope, using package apihandler for
+// all three. They delegate to the same Manager/Store methods as their v1
+// counterparts — v2 changes the response shape, not the underlying behavior.
+
+func getMeV2(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.ContextUserID(r)
+		u, err := d.Store.GetUser(r.Context(), userID)
+		if err != nil || u == nil {
+			apihandler.WriteError(w, http.StatusInternalServerError, apihandler.CodeInternalError, "internal error")
+			return
+		}
+		apihandler.WriteData(w, http.StatusOK, u)
+	}
+}
+
+func listSubscriptionsV2(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.ContextUserID(r)
+		isAdmin := middleware.ContextUserRole(r) == "admin"
+		subs, err := d.Manager.ListSubscriptions(r.Context(), userID, isAdmin)
+		if err != nil {
+			apihandler.WriteError(w, http.StatusInternalServerError, apihandler.CodeInternalError, err.Error())
+			return
+		}
+		apihandler.WriteDataMeta(w, http.StatusOK, subs, map[string]int{"count": len(subs)})
+	}
+}
+
 // ---- user handlers ----
 
 func getMe(d Deps) http.HandlerFunc {
@@ -387,10 +652,19 @@ func createSubscription(d Deps) http.HandlerFunc {
 		}
 		userID := middleware.ContextUserID(r)
 		status, err := d.Manager.Subscribe(r.Context(), userID, body.Driver, body.Username)
+		if errors.Is(err, manager.ErrDraining) {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+		if d.Notifier != nil {
+			d.Notifier.Publish(r.Context(), notifier.Event{
+				Kind: notifier.EventSubscriptionAdded, Driver: body.Driver, Username: body.Username,
+			}, userID)
+		}
 		writeJSON(w, http.StatusCreated, status)
 	}
 }
@@ -447,6 +721,10 @@ func resumeSubscription(d Deps) http.HandlerFunc {
 		driver, username := r.PathValue("driver"), r.PathValue("username")
 		userID := middleware.ContextUserID(r)
 		status, err := d.Manager.Resume(r.Context(), userID, driver, username)
+		if errors.Is(err, manager.ErrDraining) {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
 		if err != nil {
 			writeError(w, http.StatusNotFound, err.Error())
 			return
@@ -464,6 +742,11 @@ func archiveSubscription(d Deps) http.HandlerFunc {
 			writeError(w, http.StatusNotFound, err.Error())
 			return
 		}
+		if d.Notifier != nil {
+			d.Notifier.Publish(r.Context(), notifier.Event{
+				Kind: notifier.EventSubscriptionEnded, Driver: driver, Username: username,
+			}, userID)
+		}
 		writeJSON(w, http.StatusOK, status)
 	}
 }
@@ -477,6 +760,39 @@ func resetError(d Deps) http.HandlerFunc {
 			writeError(w, http.StatusConflict, err.Error())
 			return
 		}
+		if d.Notifier != nil {
+			d.Notifier.Publish(r.Context(), notifier.Event{
+				Kind: notifier.EventErrorReset, Driver: driver, Username: username,
+			}, userID)
+		}
+		writeJSON(w, http.StatusOK, status)
+	}
+}
+
+func restartSubscription(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, username := r.PathValue("driver"), r.PathValue("username")
+		userID := middleware.ContextUserID(r)
+
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && r.ContentLength > 0 {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+
+		status, err := d.Manager.Restart(r.Context(), userID, driver, username, body.Reason)
+		if err != nil {
+			var cooldown *manager.RestartCooldownError
+			if errors.As(err, &cooldown) {
+				w.Header().Set("Retry-After", fmt.Sprintf("%d", int(cooldown.RetryAfter.Seconds())+1))
+				writeError(w, http.StatusTooManyRequests, err.Error())
+				return
+			}
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
 		writeJSON(w, http.StatusOK, status)
 	}
 }
@@ -519,16 +835,82 @@ func getSourceLogs(d Deps) http.HandlerFunc {
 	}
 }
 
+// getSourceLogHistory returns durable log lines for a source, optionally
+// filtered to those after ?since= (RFC3339) and capped at ?limit= (default
+// 200, per Manager.GetLogHistory).
+func getSourceLogHistory(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, username := r.PathValue("driver"), r.PathValue("username")
+		userID := middleware.ContextUserID(r)
+		isAdmin := middleware.ContextUserRole(r) == "admin"
+
+		var since time.Time
+		if s := r.URL.Query().Get("since"); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid since: "+err.Error())
+				return
+			}
+			since = t
+		}
+		limit := 200
+		if l := r.URL.Query().Get("limit"); l != "" {
+			if v, err := strconv.Atoi(l); err == nil {
+				limit = v
+			}
+		}
+
+		lines, err := d.Manager.GetLogHistory(r.Context(), userID, isAdmin, driver, username, since, limit)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"driver":   driver,
+			"username": username,
+			"logs":     lines,
+		})
+	}
+}
+
+// getSourceStatsHistory returns persisted resource-usage samples for a
+// source, capped at ?limit= (default 120), for client-side graphing.
+func getSourceStatsHistory(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, username := r.PathValue("driver"), r.PathValue("username")
+		userID := middleware.ContextUserID(r)
+		isAdmin := middleware.ContextUserRole(r) == "admin"
+
+		limit := 120
+		if l := r.URL.Query().Get("limit"); l != "" {
+			if v, err := strconv.Atoi(l); err == nil {
+				limit = v
+			}
+		}
+
+		samples, err := d.Manager.GetResourceHistory(r.Context(), userID, isAdmin, driver, username, limit)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"driver":   driver,
+			"username": username,
+			"samples":  samples,
+		})
+	}
+}
+
 func getSourceFiles(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		driver, username := r.PathValue("driver"), r.PathValue("username")
-		if d.ConverterClient == nil {
+		if d.Workers.Converter == nil {
 			writeJSON(w, http.StatusOK, map[string]any{
 				"driver": driver, "username": username, "files": []any{},
 			})
 			return
 		}
-		files, err := d.ConverterClient.GetFiles(r.Context(), driver, username)
+		files, err := d.Workers.Converter.GetFiles(r.Context(), driver, username)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, "converter error: "+err.Error())
 			return
@@ -583,24 +965,13 @@ func getSourceFileStat(d Deps) http.HandlerFunc {
 		}
 
 		reqPath := r.URL.Query().Get("path")
-		// Sanitize: reject paths with ".." components.
-		if strings.Contains(reqPath, "..") {
-			writeError(w, http.StatusBadRequest, "invalid path")
-			return
-		}
+		segLen := parseDurationSeconds(d.Manager.GetConfig().SegmentLength, 300) // default 5m
 
-		basePath := filepath.Join(mediaRoot, driver, username, filepath.FromSlash(reqPath))
-		// Clean and ensure it's within mediaRoot.
-		basePath = filepath.Clean(basePath)
-		if !strings.HasPrefix(basePath, filepath.Clean(mediaRoot)) {
+		stat, err := computeFileStat(driver, username, reqPath, segLen)
+		if errors.Is(err, errInvalidMediaPath) {
 			writeError(w, http.StatusBadRequest, "invalid path")
 			return
 		}
-
-		// Get segment length from config for duration estimation.
-		segLen := parseDurationSeconds(d.Manager.GetConfig().SegmentLength, 300) // default 5m
-
-		entries, err := os.ReadDir(basePath)
 		if os.IsNotExist(err) {
 			writeError(w, http.StatusNotFound, "path not found")
 			return
@@ -610,61 +981,100 @@ func getSourceFileStat(d Deps) http.HandlerFunc {
 			return
 		}
 
-		var children []fileStatChild
-		var totalBytes int64
-		var totalFiles int
+		writeJSON(w, http.StatusOK, stat)
+	}
+}
 
-		for _, entry := range entries {
-			child := fileStatChild{
-				Name: entry.Name(),
-				Type: "file",
-			}
-			if entry.IsDir() {
-				child.Type = "directory"
-				// Walk directory recursively to sum sizes and count .ts files.
-				childPath := filepath.Join(basePath, entry.Name())
-				filepath.WalkDir(childPath, func(p string, d fs.DirEntry, err error) error {
-					if err != nil || d.IsDir() {
-						return nil
-					}
-					info, err := d.Info()
-					if err != nil {
-						return nil
-					}
-					child.TotalBytes += info.Size()
-					child.FileCount++
-					if strings.HasSuffix(p, ".ts") {
-						child.TsCount++
-					}
+// errInvalidMediaPath is returned by resolveMediaPath for a ?path= that
+// escapes mediaRoot.
+var errInvalidMediaPath = errors.New("invalid path")
+
+// resolveMediaPath sanitizes reqPath (rejecting ".." components) and joins
+// it under mediaRoot/driver/username, double-checking the cleaned result is
+// still within mediaRoot. Shared by computeFileStat and getSourceDownload.
+func resolveMediaPath(driver, username, reqPath string) (string, error) {
+	if strings.Contains(reqPath, "..") {
+		return "", errInvalidMediaPath
+	}
+
+	basePath := filepath.Join(mediaRoot, driver, username, filepath.FromSlash(reqPath))
+	basePath = filepath.Clean(basePath)
+	if !strings.HasPrefix(basePath, filepath.Clean(mediaRoot)) {
+		return "", errInvalidMediaPath
+	}
+	return basePath, nil
+}
+
+// computeFileStat stats driver/username's recording directory under
+// mediaRoot (optionally scoped to the sub-directory reqPath), summing sizes
+// and file counts per child and estimating recorded minutes from .ts segment
+// counts using segLen (seconds). Shared by getSourceFileStat and the
+// file-stat delta polling in getSourceStream.
+func computeFileStat(driver, username, reqPath string, segLen int) (fileStatResponse, error) {
+	basePath, err := resolveMediaPath(driver, username, reqPath)
+	if err != nil {
+		return fileStatResponse{}, err
+	}
+
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		return fileStatResponse{}, err
+	}
+
+	var children []fileStatChild
+	var totalBytes int64
+	var totalFiles int
+
+	for _, entry := range entries {
+		child := fileStatChild{
+			Name: entry.Name(),
+			Type: "file",
+		}
+		if entry.IsDir() {
+			child.Type = "directory"
+			// Walk directory recursively to sum sizes and count .ts files.
+			childPath := filepath.Join(basePath, entry.Name())
+			filepath.WalkDir(childPath, func(p string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
 					return nil
-				})
-				if segLen > 0 {
-					child.EstimatedMinutes = child.TsCount * segLen / 60
 				}
-				totalBytes += child.TotalBytes
-				totalFiles += child.FileCount
-			} else {
-				info, err := entry.Info()
-				if err == nil {
-					child.TotalBytes = info.Size()
-					child.FileCount = 1
-					totalBytes += child.TotalBytes
-					totalFiles++
+				info, err := d.Info()
+				if err != nil {
+					return nil
 				}
+				child.TotalBytes += info.Size()
+				child.FileCount++
+				if strings.HasSuffix(p, ".ts") {
+					child.TsCount++
+				}
+				return nil
+			})
+			if segLen > 0 {
+				child.EstimatedMinutes = child.TsCount * segLen / 60
+			}
+			totalBytes += child.TotalBytes
+			totalFiles += child.FileCount
+		} else {
+			info, err := entry.Info()
+			if err == nil {
+				child.TotalBytes = info.Size()
+				child.FileCount = 1
+				totalBytes += child.TotalBytes
+				totalFiles++
 			}
-			children = append(children, child)
-		}
-		if children == nil {
-			children = []fileStatChild{}
 		}
-
-		writeJSON(w, http.StatusOK, fileStatResponse{
-			Path:       reqPath,
-			Children:   children,
-			TotalBytes: totalBytes,
-			FileCount:  totalFiles,
-		})
+		children = append(children, child)
 	}
+	if children == nil {
+		children = []fileStatChild{}
+	}
+
+	return fileStatResponse{
+		Path:       reqPath,
+		Children:   children,
+		TotalBytes: totalBytes,
+		FileCount:  totalFiles,
+	}, nil
 }
 
 // parseDurationSeconds parses a duration string and returns it in whole seconds.
@@ -679,141 +1089,1488 @@ func parseDurationSeconds(s string, def int) int {
 	return int(d.Seconds())
 }
 
-// ---- admin: config ----
-
-func getConfig(d Deps) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		writeJSON(w, http.StatusOK, d.Manager.GetConfig())
-	}
-}
-
-func putConfig(d Deps) http.HandlerFunc {
+// getSourceDownload streams driver/username's recorded media under ?path=
+// as a ZIP or tar archive (?format=zip|tar, default zip), or, with
+// ?concat=1, concatenates its .ts segments into a single MPEG-TS stream
+// honoring Range requests. It shares resolveMediaPath's sanitation and
+// ownership check with getSourceFileStat.
+func getSourceDownload(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var cfg config.Data
-		if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
-			writeError(w, http.StatusBadRequest, "invalid JSON")
+		if mediaRoot == "" {
+			writeError(w, http.StatusServiceUnavailable, "MEDIA_ROOT not configured")
 			return
 		}
-		if err := d.Manager.SetConfig(r.Context(), cfg); err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+		driver, username := r.PathValue("driver"), r.PathValue("username")
+		userID := middleware.ContextUserID(r)
+		isAdmin := middleware.ContextUserRole(r) == "admin"
+
+		src, err := d.Store.GetSourceByKey(r.Context(), driver, username)
+		if err != nil || src == nil {
+			writeError(w, http.StatusNotFound, "source not found")
 			return
 		}
-		writeJSON(w, http.StatusOK, d.Manager.GetConfig())
-	}
-}
-
-// ---- admin: users ----
+		if !isAdmin {
+			sub, err := d.Store.GetSubscription(r.Context(), userID, src.ID)
+			if err != nil || sub == nil {
+				writeError(w, http.StatusForbidden, "forbidden")
+				return
+			}
+		}
 
-func listUsers(d Deps) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		users, err := d.Store.ListUsers(r.Context())
+		basePath, err := resolveMediaPath(driver, username, r.URL.Query().Get("path"))
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, http.StatusBadRequest, "invalid path")
 			return
 		}
-		writeJSON(w, http.StatusOK, users)
-	}
-}
-
-func createUser(d Deps) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		var body struct {
-			Username string `json:"username"`
-			Password string `json:"password"`
-			Role     string `json:"role"`
-		}
-		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-			writeError(w, http.StatusBadRequest, "invalid JSON")
+		info, err := os.Stat(basePath)
+		if os.IsNotExist(err) {
+			writeError(w, http.StatusNotFound, "path not found")
 			return
 		}
-		if body.Username == "" || body.Password == "" {
-			writeError(w, http.StatusBadRequest, "username and password are required")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-		if body.Role == "" {
-			body.Role = "user"
-		}
-		if body.Role != "admin" && body.Role != "user" {
-			writeError(w, http.StatusBadRequest, "role must be 'admin' or 'user'")
+		if !info.IsDir() {
+			writeError(w, http.StatusBadRequest, "path is not a directory")
 			return
 		}
-		hash, err := auth.HashPassword(body.Password)
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, "internal error")
+
+		if r.URL.Query().Get("concat") == "1" {
+			serveConcatenatedSegments(w, r, basePath)
 			return
 		}
-		u, err := d.Store.CreateUser(r.Context(), body.Username, hash, body.Role)
-		if err != nil {
-			writeError(w, http.StatusConflict, "username already exists")
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "zip"
+		}
+		if format != "zip" && format != "tar" {
+			writeError(w, http.StatusBadRequest, `format must be "zip" or "tar"`)
 			return
 		}
-		writeJSON(w, http.StatusCreated, u)
+
+		name := filepath.Base(basePath)
+		contentType := "application/zip"
+		if format == "tar" {
+			contentType = "application/x-tar"
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s-%s.%s"`, driver, username, name, format))
+
+		var archiveErr error
+		if format == "tar" {
+			archiveErr = streamTar(w, basePath)
+		} else {
+			archiveErr = streamZip(w, basePath)
+		}
+		if archiveErr != nil {
+			// Headers (and likely some body bytes) are already written, so the
+			// client just gets a truncated download; log server-side for ops.
+			log.Printf("router: getSourceDownload: %s/%s: %v", driver, username, archiveErr)
+		}
 	}
 }
 
-func getUser(d Deps) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+// streamZip walks basePath and writes every regular file under it into a
+// ZIP archive written directly to w, never buffering the tree in memory.
+func streamZip(w io.Writer, basePath string) error {
+	zw := zip.NewWriter(w)
+	err := filepath.WalkDir(basePath, func(p string, de fs.DirEntry, err error) error {
+		if err != nil || de.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(basePath, p)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid user id")
-			return
+			return err
 		}
-		u, err := d.Store.GetUser(r.Context(), id)
-		if err != nil || u == nil {
-			writeError(w, http.StatusNotFound, "user not found")
+		fw, err := zw.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(fw, f)
+		return err
+	})
+	if err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// streamTar is streamZip's tar equivalent.
+func streamTar(w io.Writer, basePath string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(basePath, func(p string, de fs.DirEntry, err error) error {
+		if err != nil || de.IsDir() {
+			return err
+		}
+		info, err := de.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(basePath, p)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		tw.Close()
+		return err
+	}
+	return tw.Close()
+}
+
+// tsSegment is one .ts file making up a virtual concatenated stream, with
+// its offset (exclusive of earlier segments) resolved via its size.
+type tsSegment struct {
+	path string
+	size int64
+}
+
+// serveConcatenatedSegments concatenates every *.ts file directly under
+// basePath, in lexical order, into one virtual MPEG-TS stream and serves it
+// like a regular file — including Range support — without ever joining the
+// segments into a file on disk.
+func serveConcatenatedSegments(w http.ResponseWriter, r *http.Request, basePath string) {
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var segments []tsSegment
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".ts") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		segments = append(segments, tsSegment{path: filepath.Join(basePath, e.Name()), size: info.Size()})
+		total += info.Size()
+	}
+	if len(segments) == 0 {
+		writeError(w, http.StatusNotFound, "no .ts segments found")
+		return
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].path < segments[j].path })
+
+	start, end := int64(0), total-1
+	status := http.StatusOK
+	if rh := r.Header.Get("Range"); rh != "" {
+		s, e, ok := parseRangeHeader(rh, total)
+		if !ok {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", total))
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		start, end = s, e
+		status = http.StatusPartialContent
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, total))
+	}
+
+	w.Header().Set("Content-Type", "video/mp2t")
+	w.Header().Set("Content-Disposition", `attachment; filename="segments.ts"`)
+	w.Header().Set("Accept-Ranges", "bytes")
+	w.Header().Set("Content-Length", strconv.FormatInt(end-start+1, 10))
+	w.WriteHeader(status)
+
+	remaining := end - start + 1
+	var offset int64
+	for _, seg := range segments {
+		if remaining <= 0 {
+			break
+		}
+		segStart, segEnd := offset, offset+seg.size-1
+		offset += seg.size
+		if segEnd < start {
+			continue // entirely before the requested range
+		}
+
+		if err := copySegmentRange(w, seg, segStart, start, &remaining); err != nil {
+			log.Printf("router: serveConcatenatedSegments: %s: %v", seg.path, err)
 			return
 		}
-		writeJSON(w, http.StatusOK, u)
 	}
 }
 
-func updateUser(d Deps) http.HandlerFunc {
+// copySegmentRange opens seg and copies the portion of it starting at
+// rangeStart, up to *remaining bytes, to w, decrementing *remaining by the
+// number of bytes written.
+func copySegmentRange(w io.Writer, seg tsSegment, segStart, rangeStart int64, remaining *int64) error {
+	f, err := os.Open(seg.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	skip := int64(0)
+	if segStart < rangeStart {
+		skip = rangeStart - segStart
+	}
+	if _, err := f.Seek(skip, io.SeekStart); err != nil {
+		return err
+	}
+
+	toCopy := seg.size - skip
+	if toCopy > *remaining {
+		toCopy = *remaining
+	}
+	n, err := io.CopyN(w, f, toCopy)
+	*remaining -= n
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// parseRangeHeader parses a single-range "bytes=start-end" Range header
+// value against a resource of the given total size, per RFC 7233 §2.1.
+// Multi-range requests aren't supported; only the first range is honored.
+func parseRangeHeader(h string, total int64) (start, end int64, ok bool) {
+	h = strings.TrimPrefix(h, "bytes=")
+	spec := strings.TrimSpace(strings.SplitN(h, ",", 2)[0])
+	dash := strings.IndexByte(spec, '-')
+	if dash < 0 {
+		return 0, 0, false
+	}
+	startStr, endStr := spec[:dash], spec[dash+1:]
+
+	if startStr == "" {
+		n, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, false
+		}
+		if n > total {
+			n = total
+		}
+		return total - n, total - 1, true
+	}
+
+	s, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || s < 0 || s >= total {
+		return 0, 0, false
+	}
+	e := total - 1
+	if endStr != "" {
+		v, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || v < s {
+			return 0, 0, false
+		}
+		if v < e {
+			e = v
+		}
+	}
+	return s, e, true
+}
+
+const tailReplayLimit = 25
+
+// tailSource streams live worker output for a source as SSE. It first
+// replays the most recent persisted WorkerEvents (oldest first) so a
+// reconnecting client doesn't miss lines, then attaches a bounded channel to
+// the overseer client's per-task tail subscription for live output.
+func tailSource(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, username := r.PathValue("driver"), r.PathValue("username")
+		userID := middleware.ContextUserID(r)
+		isAdmin := middleware.ContextUserRole(r) == "admin"
+
+		src, err := d.Store.GetSourceByKey(r.Context(), driver, username)
+		if err != nil || src == nil {
+			writeError(w, http.StatusNotFound, "source not found")
+			return
+		}
+		if !isAdmin {
+			sub, err := d.Store.GetSubscription(r.Context(), userID, src.ID)
+			if err != nil || sub == nil {
+				writeError(w, http.StatusForbidden, "forbidden")
+				return
+			}
+		}
+		if src.OverseerTaskID == "" {
+			writeError(w, http.StatusServiceUnavailable, "source has no overseer task yet")
+			return
+		}
+
+		oc := d.Manager.GetOverseerClient()
+		if oc == nil {
+			writeError(w, http.StatusServiceUnavailable, "overseer client not initialised")
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, "streaming unsupported")
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		replay, err := d.Store.RecentWorkerEvents(r.Context(), src.ID, tailReplayLimit)
+		if err == nil {
+			for i := len(replay) - 1; i >= 0; i-- {
+				b, err := json.Marshal(replay[i])
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: replay\ndata: %s\n\n", b)
+			}
+			flusher.Flush()
+		}
+
+		ch := make(chan overseer.OutputEvent, 64)
+		oc.SubscribeTail(src.OverseerTaskID, ch)
+		defer oc.UnsubscribeTail(src.OverseerTaskID, ch)
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case ev, ok := <-ch:
+				if !ok {
+					return
+				}
+				b, err := json.Marshal(ev)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: output\ndata: %s\n\n", b)
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+const logTailReplayLimit = 25
+
+// tailSourceLog streams the durable per-source log as SSE: it replays the
+// most recent lines (oldest first, optionally filtered by ?since= and capped
+// by ?tail=), then — unless ?follow=false — keeps the connection open and
+// streams new lines as they're appended. Each streamed event's "dropped"
+// field is nonzero if this connection fell behind and missed earlier lines.
+// Unlike tailSource (which follows the overseer's live stdout/stderr), this
+// survives manager restarts and reconnects to the overseer.
+func tailSourceLog(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, username := r.PathValue("driver"), r.PathValue("username")
+		userID := middleware.ContextUserID(r)
+		isAdmin := middleware.ContextUserRole(r) == "admin"
+
+		opts := manager.LogSubscribeOptions{Follow: true, Tail: logTailReplayLimit}
+		if f := r.URL.Query().Get("follow"); f != "" {
+			opts.Follow = f != "false" && f != "0"
+		}
+		if t := r.URL.Query().Get("tail"); t != "" {
+			if v, err := strconv.Atoi(t); err == nil {
+				opts.Tail = v
+			}
+		}
+		if s := r.URL.Query().Get("since"); s != "" {
+			since, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid since: "+err.Error())
+				return
+			}
+			opts.Since = since
+		}
+
+		replay, ch, cancel, err := d.Manager.SubscribeLogs(r.Context(), userID, isAdmin, driver, username, opts)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		defer cancel()
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, "streaming unsupported")
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		for _, line := range replay {
+			b, err := json.Marshal(line)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: replay\ndata: %s\n\n", b)
+		}
+		flusher.Flush()
+
+		if ch == nil {
+			return
+		}
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				b, err := json.Marshal(msg)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: output\ndata: %s\n\n", b)
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// sseKeepaliveInterval is how often stream handlers emit a ": keepalive"
+// comment line, so an idle reverse proxy or load balancer in front of the
+// backend doesn't time out the connection while nothing has changed.
+const sseKeepaliveInterval = 20 * time.Second
+
+// sourceStreamFileStatInterval is how often getSourceStream polls the
+// recording directory for file-stat deltas. Bytes/file count are only sent
+// when they've changed since the last poll.
+const sourceStreamFileStatInterval = 10 * time.Second
+
+// getSourceStream pushes worker state transitions, new events, log lines,
+// and file-stat deltas for one source as SSE, so the UI can retire the
+// timers it previously used to poll /events, /logs, and /filestat. It
+// enforces the same ownership check as getSourceFileStat/getSourceEvents and
+// terminates cleanly on r.Context().Done() (client disconnect).
+func getSourceStream(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, username := r.PathValue("driver"), r.PathValue("username")
+		userID := middleware.ContextUserID(r)
+		isAdmin := middleware.ContextUserRole(r) == "admin"
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, "streaming unsupported")
+			return
+		}
+
+		ch, cancel, err := d.Manager.SubscribeSource(r.Context(), userID, isAdmin, driver, username)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		// File-stat polling is best-effort and only runs if MEDIA_ROOT is
+		// configured; otherwise the stream still carries worker/log events.
+		var statCh <-chan time.Time
+		if mediaRoot != "" {
+			statTicker := time.NewTicker(sourceStreamFileStatInterval)
+			defer statTicker.Stop()
+			statCh = statTicker.C
+		}
+		keepalive := time.NewTicker(sseKeepaliveInterval)
+		defer keepalive.Stop()
+
+		segLen := parseDurationSeconds(d.Manager.GetConfig().SegmentLength, 300)
+		lastBytes, lastFiles := int64(-1), -1
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case ev, ok := <-ch:
+				if !ok {
+					return
+				}
+				b, err := json.Marshal(ev)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Kind, b)
+				flusher.Flush()
+			case <-statCh:
+				stat, err := computeFileStat(driver, username, "", segLen)
+				if err != nil || (stat.TotalBytes == lastBytes && stat.FileCount == lastFiles) {
+					continue
+				}
+				lastBytes, lastFiles = stat.TotalBytes, stat.FileCount
+				b, err := json.Marshal(stat)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "event: filestat\ndata: %s\n\n", b)
+				flusher.Flush()
+			case <-keepalive.C:
+				fmt.Fprint(w, ": keepalive\n\n")
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// ---- admin: config ----
+
+func getConfig(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"`+d.Manager.GetConfigFingerprint()+`"`)
+		writeJSON(w, http.StatusOK, d.Manager.GetConfig())
+	}
+}
+
+func putConfig(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			config.Data
+			Comment string `json:"comment"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		ifMatch := strings.Trim(r.Header.Get("If-Match"), `"`)
+		if ifMatch == "" {
+			writeError(w, http.StatusPreconditionRequired, "If-Match header is required")
+			return
+		}
+
+		userID := middleware.ContextUserID(r)
+		err := d.Manager.SetConfigIfMatch(r.Context(), ifMatch, body.Data, &userID, body.Comment)
+		if errors.Is(err, config.ErrFingerprintMismatch) {
+			writeError(w, http.StatusPreconditionFailed, "config was modified concurrently, reload and retry")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("ETag", `"`+d.Manager.GetConfigFingerprint()+`"`)
+		writeJSON(w, http.StatusOK, d.Manager.GetConfig())
+	}
+}
+
+func patchConfig(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Query().Get("path")
+		if path == "" {
+			writeError(w, http.StatusBadRequest, "path query parameter is required")
+			return
+		}
+		value, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+
+		userID := middleware.ContextUserID(r)
+		if err := d.Manager.PatchConfigPath(r.Context(), path, value, &userID, ""); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Set("ETag", `"`+d.Manager.GetConfigFingerprint()+`"`)
+		writeJSON(w, http.StatusOK, d.Manager.GetConfig())
+	}
+}
+
+func reloadConfig(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := d.Manager.ReloadConfig(r.Context()); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("ETag", `"`+d.Manager.GetConfigFingerprint()+`"`)
+		writeJSON(w, http.StatusOK, d.Manager.GetConfig())
+	}
+}
+
+func listConfigVersions(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, offset := 20, 0
+		if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+			limit = v
+		}
+		if v, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && v > 0 {
+			offset = v
+		}
+		versions, err := d.Store.ListConfigVersions(r.Context(), limit, offset)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, versions)
+	}
+}
+
+func getConfigVersion(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid user id")
+			writeError(w, http.StatusBadRequest, "invalid config version id")
+			return
+		}
+		v, err := d.Store.GetConfigVersion(r.Context(), id)
+		if err != nil || v == nil {
+			writeError(w, http.StatusNotFound, "config version not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+func rollbackConfig(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid config version id")
 			return
 		}
 		var body struct {
-			Username *string `json:"username"`
-			Password *string `json:"password"`
-			Role     *string `json:"role"`
+			Comment string `json:"comment"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && r.ContentLength > 0 {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		userID := middleware.ContextUserID(r)
+		v, err := d.Store.RollbackConfig(r.Context(), id, &userID, body.Comment)
+		if err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if err := d.Manager.ReloadConfig(r.Context()); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+// ---- admin: users ----
+
+func listUsers(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		users, err := d.Store.ListUsers(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, users)
+	}
+}
+
+func createUser(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			Role     string `json:"role"`
+		}
+		if verr := decodeStrict(r, &body); verr != nil {
+			writeValidationError(w, *verr)
+			return
+		}
+		var errs []ValidationError
+		if body.Username == "" {
+			errs = append(errs, ValidationError{Field: "username", Code: "required", Message: "username is required"})
+		}
+		if body.Password == "" {
+			errs = append(errs, ValidationError{Field: "password", Code: "required", Message: "password is required"})
+		} else {
+			for _, v := range auth.DefaultPasswordPolicy.Check(body.Password) {
+				errs = append(errs, ValidationError{Field: "password", Code: v.Code, Message: v.Message})
+			}
+		}
+		if body.Role == "" {
+			body.Role = "user"
+		}
+		if body.Role != "admin" && body.Role != "user" {
+			errs = append(errs, ValidationError{Field: "role", Code: "invalid_enum", Message: "must be one of admin|user"})
+		}
+		if len(errs) > 0 {
+			writeValidationError(w, errs...)
+			return
+		}
+		hash, err := auth.HashPassword(body.Password)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		u, err := d.Store.CreateUser(r.Context(), body.Username, hash, body.Role)
+		if err != nil {
+			writeError(w, http.StatusConflict, "username already exists")
+			return
+		}
+		writeJSON(w, http.StatusCreated, u)
+	}
+}
+
+func getUser(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid user id")
+			return
+		}
+		u, err := d.Store.GetUser(r.Context(), id)
+		if err != nil || u == nil {
+			writeError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, u)
+	}
+}
+
+func updateUser(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeValidationError(w, ValidationError{Field: "id", Code: "invalid_type", Message: "must be an integer"})
+			return
+		}
+		var body struct {
+			Username *string `json:"username"`
+			Password *string `json:"password"`
+			Role     *string `json:"role"`
+		}
+		if verr := decodeStrict(r, &body); verr != nil {
+			writeValidationError(w, *verr)
+			return
+		}
+		if body.Role != nil && *body.Role != "admin" && *body.Role != "user" {
+			writeValidationError(w, ValidationError{Field: "role", Code: "invalid_enum", Message: "must be one of admin|user"})
+			return
+		}
+
+		fields := store.UserUpdate{
+			Username: body.Username,
+			Role:     body.Role,
+		}
+		if body.Password != nil {
+			if violations := auth.DefaultPasswordPolicy.Check(*body.Password); len(violations) > 0 {
+				errs := make([]ValidationError, len(violations))
+				for i, v := range violations {
+					errs[i] = ValidationError{Field: "password", Code: v.Code, Message: v.Message}
+				}
+				writeValidationError(w, errs...)
+				return
+			}
+			hash, err := auth.HashPassword(*body.Password)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, "internal error")
+				return
+			}
+			fields.PasswordHash = &hash
+		}
+
+		u, err := d.Store.UpdateUser(r.Context(), id, fields)
+		if err != nil || u == nil {
+			writeError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, u)
+	}
+}
+
+func deleteUser(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid user id")
+			return
+		}
+		if err := d.Store.DeleteUser(r.Context(), id); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// rehashUserPassword forces a user's password hash onto the current Argon2id
+// parameters immediately rather than waiting for their next login. Since the
+// server never stores a reversible form of the password, the caller must
+// supply it again here — typically right after an admin-assisted password
+// reset, where the plaintext is already in hand.
+func rehashUserPassword(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeValidationError(w, ValidationError{Field: "id", Code: "invalid_type", Message: "must be an integer"})
+			return
+		}
+		var body struct {
+			Password string `json:"password"`
+		}
+		if verr := decodeStrict(r, &body); verr != nil {
+			writeValidationError(w, *verr)
+			return
+		}
+
+		u, err := d.Store.GetUser(r.Context(), id)
+		if err != nil || u == nil {
+			writeError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		if !auth.CheckPassword(u.PasswordHash, body.Password) {
+			writeError(w, http.StatusUnauthorized, "password does not match current credentials")
+			return
+		}
+
+		hash, err := auth.HashPassword(body.Password)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		updated, err := d.Store.UpdateUser(r.Context(), id, store.UserUpdate{PasswordHash: &hash})
+		if err != nil || updated == nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		writeJSON(w, http.StatusOK, updated)
+	}
+}
+
+// ---- admin: client certs ----
+
+// ClientCertResolver builds an mTLS CN→user resolver backed by the enrolled
+// client_certs table. The certificate's CommonName is expected to be the
+// fingerprint returned by enrollClientCert. Pass to middleware.RequireMTLSOrJWT.
+func ClientCertResolver(st store.Store) func(cn string) (int64, string, error) {
+	return func(cn string) (int64, string, error) {
+		cert, err := st.GetClientCertByFingerprint(context.Background(), cn)
+		if err != nil {
+			return 0, "", err
+		}
+		if cert == nil || cert.RevokedAt != nil {
+			return 0, "", fmt.Errorf("no enrolled certificate for %q", cn)
+		}
+		u, err := st.GetUser(context.Background(), cert.UserID)
+		if err != nil || u == nil {
+			return 0, "", fmt.Errorf("user not found for certificate")
+		}
+		return u.ID, u.Role, nil
+	}
+}
+
+func listClientCerts(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		certs, err := d.Store.ListClientCerts(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, certs)
+	}
+}
+
+func enrollClientCert(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Fingerprint string `json:"fingerprint"`
+			UserID      int64  `json:"user_id"`
+			Label       string `json:"label"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		if body.Fingerprint == "" || body.UserID == 0 {
+			writeError(w, http.StatusBadRequest, "fingerprint and user_id are required")
+			return
+		}
+		cert, err := d.Store.EnrollClientCert(r.Context(), body.Fingerprint, body.UserID, body.Label)
+		if err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, cert)
+	}
+}
+
+func revokeClientCert(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fingerprint := r.PathValue("fingerprint")
+		if err := d.Store.RevokeClientCert(r.Context(), fingerprint); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ---- oauth2 provider ----
+
+const oauthCodeTTL = 10 * time.Minute
+const oauthGrantTTL = 30 * 24 * time.Hour // refresh tokens live as long as a session family
+
+func createOAuthApp(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Name        string `json:"name"`
+			RedirectURI string `json:"redirect_uri"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		if body.Name == "" || body.RedirectURI == "" {
+			writeError(w, http.StatusBadRequest, "name and redirect_uri are required")
+			return
+		}
+
+		clientID, err := auth.GenerateClientID()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		secret, err := auth.GenerateClientSecret()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		secretHash, err := auth.HashPassword(secret)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+
+		app, err := d.Store.CreateOAuthApp(r.Context(), body.Name, middleware.ContextUserID(r), body.RedirectURI, clientID, secretHash)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		// client_secret is only ever returned here, at registration time.
+		writeJSON(w, http.StatusCreated, map[string]any{
+			"id":            app.ID,
+			"name":          app.Name,
+			"client_id":     app.ClientID,
+			"client_secret": secret,
+			"redirect_uri":  app.RedirectURI,
+			"created_at":    app.CreatedAt,
+		})
+	}
+}
+
+func getOAuthApp(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		app, err := d.Store.GetOAuthApp(r.Context(), id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if app == nil {
+			writeError(w, http.StatusNotFound, "app not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, app)
+	}
+}
+
+func deleteOAuthApp(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		if err := d.Store.DeleteOAuthApp(r.Context(), id); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// lookupOAuthApp validates client_id/redirect_uri against the registered
+// app, the check shared by GET and POST /api/oauth/authorize.
+func lookupOAuthApp(ctx context.Context, d Deps, clientID, redirectURI string) (*store.OAuthApp, error) {
+	app, err := d.Store.GetOAuthAppByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil || app.RedirectURI != redirectURI {
+		return nil, fmt.Errorf("unknown client_id or redirect_uri mismatch")
+	}
+	return app, nil
+}
+
+// redirectWithQuery appends params to uri's query string, for building the
+// redirect_uri the frontend navigates to once the user has approved or
+// denied a POST /api/oauth/authorize request.
+func redirectWithQuery(uri string, params map[string]string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	q := u.Query()
+	for k, v := range params {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func getOAuthAuthorize(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		clientID, redirectURI, challenge := q.Get("client_id"), q.Get("redirect_uri"), q.Get("code_challenge")
+		if clientID == "" || redirectURI == "" || challenge == "" {
+			writeError(w, http.StatusBadRequest, "client_id, redirect_uri, and code_challenge are required")
+			return
+		}
+		app, err := lookupOAuthApp(r.Context(), d, clientID, redirectURI)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		// Consent screen payload: the frontend renders this, then re-submits
+		// the same parameters to POST /api/oauth/authorize once the user
+		// decides.
+		writeJSON(w, http.StatusOK, map[string]any{
+			"app_name": app.Name,
+			"scope":    q.Get("scope"),
+		})
+	}
+}
+
+func postOAuthAuthorize(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ClientID            string `json:"client_id"`
+			RedirectURI         string `json:"redirect_uri"`
+			Scope               string `json:"scope"`
+			State               string `json:"state"`
+			CodeChallenge       string `json:"code_challenge"`
+			CodeChallengeMethod string `json:"code_challenge_method"`
+			Approve             bool   `json:"approve"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+		if body.CodeChallengeMethod == "" {
+			body.CodeChallengeMethod = "S256"
+		}
+		if body.ClientID == "" || body.RedirectURI == "" || body.CodeChallenge == "" {
+			writeError(w, http.StatusBadRequest, "client_id, redirect_uri, and code_challenge are required")
+			return
+		}
+
+		app, err := lookupOAuthApp(r.Context(), d, body.ClientID, body.RedirectURI)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !body.Approve {
+			writeJSON(w, http.StatusOK, map[string]any{
+				"redirect_uri": redirectWithQuery(body.RedirectURI, map[string]string{"error": "access_denied", "state": body.State}),
+			})
+			return
+		}
+
+		code, err := auth.GenerateRefreshToken() // same opaque-random-token generator used for session refresh tokens
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		_, err = d.Store.CreateOAuthAuthorization(r.Context(), store.OAuthAuthorization{
+			Code:                code,
+			AppID:               app.ID,
+			UserID:              middleware.ContextUserID(r),
+			RedirectURI:         body.RedirectURI,
+			Scope:               body.Scope,
+			CodeChallenge:       body.CodeChallenge,
+			CodeChallengeMethod: body.CodeChallengeMethod,
+			ExpiresAt:           time.Now().Add(oauthCodeTTL),
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"redirect_uri": redirectWithQuery(body.RedirectURI, map[string]string{"code": code, "state": body.State}),
+		})
+	}
+}
+
+func oauthToken(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			GrantType    string `json:"grant_type"`
+			Code         string `json:"code"`
+			RedirectURI  string `json:"redirect_uri"`
+			CodeVerifier string `json:"code_verifier"`
+			RefreshToken string `json:"refresh_token"`
+			ClientID     string `json:"client_id"`
+			ClientSecret string `json:"client_secret"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+
+		app, err := d.Store.GetOAuthAppByClientID(r.Context(), body.ClientID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if app == nil || !auth.CheckPassword(app.ClientSecretHash, body.ClientSecret) {
+			writeError(w, http.StatusUnauthorized, "invalid client credentials")
+			return
+		}
+
+		switch body.GrantType {
+		case "authorization_code":
+			issueOAuthTokenFromCode(w, r, d, app, body.Code, body.RedirectURI, body.CodeVerifier)
+		case "refresh_token":
+			issueOAuthTokenFromRefresh(w, r, d, app, body.RefreshToken)
+		default:
+			writeError(w, http.StatusBadRequest, "unsupported grant_type")
+		}
+	}
+}
+
+func issueOAuthTokenFromCode(w http.ResponseWriter, r *http.Request, d Deps, app *store.OAuthApp, code, redirectURI, codeVerifier string) {
+	a, err := d.Store.ConsumeOAuthAuthorization(r.Context(), code)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if a == nil || a.AppID != app.ID || a.RedirectURI != redirectURI {
+		writeError(w, http.StatusBadRequest, "invalid or expired code")
+		return
+	}
+	if !auth.VerifyPKCE(a.CodeChallengeMethod, a.CodeChallenge, codeVerifier) {
+		writeError(w, http.StatusBadRequest, "invalid code_verifier")
+		return
+	}
+	issueOAuthGrant(w, r, d, app, a.UserID, a.Scope)
+}
+
+func issueOAuthTokenFromRefresh(w http.ResponseWriter, r *http.Request, d Deps, app *store.OAuthApp, refreshToken string) {
+	g, err := d.Store.GetOAuthGrantByRefreshToken(r.Context(), refreshToken)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if g == nil || g.AppID != app.ID || g.RevokedAt != nil || g.ExpiresAt.Before(time.Now()) {
+		writeError(w, http.StatusUnauthorized, "invalid or expired refresh token")
+		return
+	}
+
+	token, err := auth.IssueAppAccessToken(d.Keys, g.UserID, app.ClientID, g.Scope)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token":  token,
+		"refresh_token": g.RefreshToken,
+		"token_type":    "Bearer",
+		"scope":         g.Scope,
+	})
+}
+
+// issueOAuthGrant mints a fresh access token and a new refresh-token grant,
+// the shared last step of the authorization_code flow (the refresh_token
+// flow reuses the existing grant's refresh token instead of rotating it).
+func issueOAuthGrant(w http.ResponseWriter, r *http.Request, d Deps, app *store.OAuthApp, userID int64, scope string) {
+	token, err := auth.IssueAppAccessToken(d.Keys, userID, app.ClientID, scope)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	refreshTok, err := auth.GenerateRefreshToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if _, err := d.Store.CreateOAuthGrant(r.Context(), app.ID, userID, refreshTok, scope, time.Now().Add(oauthGrantTTL)); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token":  token,
+		"refresh_token": refreshTok,
+		"token_type":    "Bearer",
+		"scope":         scope,
+	})
+}
+
+func oauthRevoke(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ClientID     string `json:"client_id"`
+			ClientSecret string `json:"client_secret"`
+			RefreshToken string `json:"refresh_token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
+
+		app, err := d.Store.GetOAuthAppByClientID(r.Context(), body.ClientID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if app == nil || !auth.CheckPassword(app.ClientSecretHash, body.ClientSecret) {
+			writeError(w, http.StatusUnauthorized, "invalid client credentials")
+			return
+		}
+
+		g, err := d.Store.GetOAuthGrantByRefreshToken(r.Context(), body.RefreshToken)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		if g == nil || g.AppID != app.ID {
+			// RFC 7009: revoking an unknown token is still a success.
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if err := d.Store.RevokeOAuthGrant(r.Context(), g.ID); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// ---- notification channels ----
+
+// validChannelTypes are the NotificationChannelType values notifier.Dispatcher
+// knows how to send; decodeNotificationChannelBody rejects anything else
+// before it ever reaches the store.
+var validChannelTypes = map[store.NotificationChannelType]bool{
+	store.NotificationChannelWebhook: true,
+	store.NotificationChannelEmail:   true,
+	store.NotificationChannelDiscord: true,
+	store.NotificationChannelSlack:   true,
+	store.NotificationChannelApprise: true,
+}
+
+type notificationChannelBody struct {
+	Type     store.NotificationChannelType `json:"type"`
+	Target   string                        `json:"target"`
+	Secret   string                        `json:"secret"`
+	Events   []string                      `json:"events"`
+	Template string                        `json:"template"`
+}
+
+func decodeNotificationChannelBody(r *http.Request, requireType bool) (*notificationChannelBody, string) {
+	var body notificationChannelBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, "invalid JSON"
+	}
+	if requireType && !validChannelTypes[body.Type] {
+		return nil, "type must be one of webhook, email, discord, slack, apprise"
+	}
+	if body.Target == "" {
+		return nil, "target is required"
+	}
+	return &body, ""
+}
+
+func listMyNotificationChannels(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := middleware.ContextUserID(r)
+		channels, err := d.Store.ListNotificationChannelsByUser(r.Context(), userID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, channels)
+	}
+}
+
+func createMyNotificationChannel(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, errMsg := decodeNotificationChannelBody(r, true)
+		if errMsg != "" {
+			writeError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		userID := middleware.ContextUserID(r)
+		ch, err := d.Store.CreateNotificationChannel(r.Context(), &userID, body.Type, body.Target, body.Secret, body.Events, body.Template)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, ch)
+	}
+}
+
+func updateMyNotificationChannel(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		body, errMsg := decodeNotificationChannelBody(r, false)
+		if errMsg != "" {
+			writeError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		userID := middleware.ContextUserID(r)
+		existing, err := d.Store.GetNotificationChannel(r.Context(), id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if existing == nil || existing.UserID == nil || *existing.UserID != userID {
+			writeError(w, http.StatusNotFound, "notification channel not found")
+			return
+		}
+		ch, err := d.Store.UpdateNotificationChannel(r.Context(), id, body.Target, body.Secret, body.Events, body.Template)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, ch)
+	}
+}
+
+func deleteMyNotificationChannel(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid id")
+			return
+		}
+		userID := middleware.ContextUserID(r)
+		existing, err := d.Store.GetNotificationChannel(r.Context(), id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if existing == nil || existing.UserID == nil || *existing.UserID != userID {
+			writeError(w, http.StatusNotFound, "notification channel not found")
+			return
+		}
+		if err := d.Store.DeleteNotificationChannel(r.Context(), id); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func listGlobalNotificationChannels(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		channels, err := d.Store.ListGlobalNotificationChannels(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, channels)
+	}
+}
+
+func createGlobalNotificationChannel(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, errMsg := decodeNotificationChannelBody(r, true)
+		if errMsg != "" {
+			writeError(w, http.StatusBadRequest, errMsg)
+			return
+		}
+		ch, err := d.Store.CreateNotificationChannel(r.Context(), nil, body.Type, body.Target, body.Secret, body.Events, body.Template)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, ch)
+	}
+}
+
+func updateGlobalNotificationChannel(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid id")
+			return
 		}
-		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-			writeError(w, http.StatusBadRequest, "invalid JSON")
+		body, errMsg := decodeNotificationChannelBody(r, false)
+		if errMsg != "" {
+			writeError(w, http.StatusBadRequest, errMsg)
 			return
 		}
-
-		fields := store.UserUpdate{
-			Username: body.Username,
-			Role:     body.Role,
+		existing, err := d.Store.GetNotificationChannel(r.Context(), id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
 		}
-		if body.Password != nil {
-			hash, err := auth.HashPassword(*body.Password)
-			if err != nil {
-				writeError(w, http.StatusInternalServerError, "internal error")
-				return
-			}
-			fields.PasswordHash = &hash
+		if existing == nil || existing.UserID != nil {
+			writeError(w, http.StatusNotFound, "notification channel not found")
+			return
 		}
-
-		u, err := d.Store.UpdateUser(r.Context(), id, fields)
-		if err != nil || u == nil {
-			writeError(w, http.StatusNotFound, "user not found")
+		ch, err := d.Store.UpdateNotificationChannel(r.Context(), id, body.Target, body.Secret, body.Events, body.Template)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-		writeJSON(w, http.StatusOK, u)
+		writeJSON(w, http.StatusOK, ch)
 	}
 }
 
-func deleteUser(d Deps) http.HandlerFunc {
+func deleteGlobalNotificationChannel(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		id, err := uuid.Parse(r.PathValue("id"))
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid user id")
+			writeError(w, http.StatusBadRequest, "invalid id")
 			return
 		}
-		if err := d.Store.DeleteUser(r.Context(), id); err != nil {
+		existing, err := d.Store.GetNotificationChannel(r.Context(), id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if existing == nil || existing.UserID != nil {
+			writeError(w, http.StatusNotFound, "notification channel not found")
+			return
+		}
+		if err := d.Store.DeleteNotificationChannel(r.Context(), id); err != nil {
 			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
@@ -824,23 +2581,121 @@ func deleteUser(d Deps) http.HandlerFunc {
 // ---- system ----
 
 func health(d Deps) http.HandlerFunc {
+	return readyz(d)
+}
+
+// livez reports whether the process itself is able to serve requests at
+// all — no dependency checks, so a flapping overseer connection never takes
+// the pod out of rotation via the liveness probe (that's readyz's job).
+func livez(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		oc := d.Manager.GetOverseerClient()
-		connected := oc != nil && oc.IsConnected()
+		writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+	}
+}
+
+// depStatus is one dependency's readyz result.
+type depStatus struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// readyz aggregates overseer/converter/thumbnailer reachability, each
+// bounded by d.ReadyzTimeout (defaultReadyzTimeout if unset), and reports
+// 503 if any configured dependency is unreachable — unconfigured clients
+// (d.Workers.Converter/Thumbnailer nil) are reported ok since there's
+// nothing to be unready for.
+func readyz(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		timeout := d.ReadyzTimeout
+		if timeout <= 0 {
+			timeout = defaultReadyzTimeout
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		deps := map[string]depStatus{}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			oc := d.Manager.GetOverseerClient()
+			s := depStatus{OK: oc != nil && oc.IsConnected()}
+			if !s.OK {
+				s.Error = "overseer disconnected"
+			}
+			mu.Lock()
+			deps["overseer"] = s
+			mu.Unlock()
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := depStatus{OK: true}
+			if d.Workers.Converter != nil {
+				if pi, _ := d.Workers.Converter.GetPoolInfo(ctx); pi == nil {
+					s = depStatus{Error: "converter unreachable"}
+				}
+			}
+			mu.Lock()
+			deps["converter"] = s
+			mu.Unlock()
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s := depStatus{OK: true}
+			if d.Workers.Thumbnailer != nil {
+				if pi, _ := d.Workers.Thumbnailer.GetPoolInfo(ctx); pi == nil {
+					s = depStatus{Error: "thumbnailer unreachable"}
+				}
+			}
+			mu.Lock()
+			deps["thumbnailer"] = s
+			mu.Unlock()
+		}()
+
+		wg.Wait()
 
 		code := http.StatusOK
 		status := "ok"
-		if !connected {
-			code = http.StatusServiceUnavailable
-			status = "overseer_disconnected"
+		for _, s := range deps {
+			if !s.OK {
+				code = http.StatusServiceUnavailable
+				status = "not_ready"
+				break
+			}
 		}
 		writeJSON(w, code, map[string]any{
 			"status":             status,
-			"overseer_connected": connected,
+			"dependencies":       deps,
+			"overseer_connected": deps["overseer"].OK,
 		})
 	}
 }
 
+// debugVars exposes the manager's in-flight goroutine and queue depths
+// gathered for getDiagnostics, in the same spirit as net/http/pprof's
+// expvar handler but scoped to what operators actually page on here rather
+// than the full process-wide expvar registry.
+func debugVars(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		oc := d.Manager.GetOverseerClient()
+		vars := map[string]any{}
+		if oc != nil && oc.IsConnected() {
+			vars["overseer_start_inflight"] = oc.StartInflight()
+			vars["overseer_list_inflight"] = oc.ListInflight()
+			if pi, err := oc.PoolInfo(r.Context()); err == nil {
+				vars["overseer_pool"] = pi
+			}
+		}
+		writeJSON(w, http.StatusOK, vars)
+	}
+}
+
 // ---- admin: subscription management handlers ----
 
 func parseSubID(r *http.Request) (int64, error) {
@@ -851,7 +2706,7 @@ func adminPauseSubscription(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		subID, err := parseSubID(r)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid sub_id")
+			writeValidationError(w, ValidationError{Field: "sub_id", Code: "invalid_type", Message: "must be an integer"})
 			return
 		}
 		status, err := d.Manager.AdminPause(r.Context(), subID)
@@ -859,6 +2714,7 @@ func adminPauseSubscription(d Deps) http.HandlerFunc {
 			writeError(w, http.StatusNotFound, err.Error())
 			return
 		}
+		metrics.AdminActions.WithLabelValues("pause").Inc()
 		writeJSON(w, http.StatusOK, status)
 	}
 }
@@ -867,7 +2723,7 @@ func adminResumeSubscription(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		subID, err := parseSubID(r)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid sub_id")
+			writeValidationError(w, ValidationError{Field: "sub_id", Code: "invalid_type", Message: "must be an integer"})
 			return
 		}
 		status, err := d.Manager.AdminResume(r.Context(), subID)
@@ -875,6 +2731,7 @@ func adminResumeSubscription(d Deps) http.HandlerFunc {
 			writeError(w, http.StatusNotFound, err.Error())
 			return
 		}
+		metrics.AdminActions.WithLabelValues("resume").Inc()
 		writeJSON(w, http.StatusOK, status)
 	}
 }
@@ -883,7 +2740,7 @@ func adminArchiveSubscription(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		subID, err := parseSubID(r)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid sub_id")
+			writeValidationError(w, ValidationError{Field: "sub_id", Code: "invalid_type", Message: "must be an integer"})
 			return
 		}
 		status, err := d.Manager.AdminArchive(r.Context(), subID)
@@ -891,6 +2748,7 @@ func adminArchiveSubscription(d Deps) http.HandlerFunc {
 			writeError(w, http.StatusNotFound, err.Error())
 			return
 		}
+		metrics.AdminActions.WithLabelValues("archive").Inc()
 		writeJSON(w, http.StatusOK, status)
 	}
 }
@@ -899,7 +2757,7 @@ func adminDeleteSubscription(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		subID, err := parseSubID(r)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid sub_id")
+			writeValidationError(w, ValidationError{Field: "sub_id", Code: "invalid_type", Message: "must be an integer"})
 			return
 		}
 		if err := d.Manager.AdminUnsubscribe(r.Context(), subID); err != nil {
@@ -914,7 +2772,7 @@ func adminResetError(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		subID, err := parseSubID(r)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid sub_id")
+			writeValidationError(w, ValidationError{Field: "sub_id", Code: "invalid_type", Message: "must be an integer"})
 			return
 		}
 		status, err := d.Manager.AdminResetError(r.Context(), subID)
@@ -922,19 +2780,205 @@ func adminResetError(d Deps) http.HandlerFunc {
 			writeError(w, http.StatusConflict, err.Error())
 			return
 		}
+		metrics.AdminActions.WithLabelValues("reset").Inc()
 		writeJSON(w, http.StatusOK, status)
 	}
 }
 
-func adminRestartAllSources(d Deps) http.HandlerFunc {
+// batchSubscriptionOp is one entry in POST /api/admin/subscriptions:batch's
+// "ops" array — either a direct SubID or a Filter selecting a set of
+// subscriptions to apply Op to, never both.
+type batchSubscriptionOp struct {
+	Op     string                   `json:"op"` // pause | resume | archive | delete | reset_error
+	SubID  int64                    `json:"sub_id,omitempty"`
+	Filter *batchSubscriptionFilter `json:"filter,omitempty"`
+}
+
+type batchSubscriptionFilter struct {
+	Driver   string `json:"driver,omitempty"`
+	Username string `json:"username,omitempty"`
+	UserID   int64  `json:"user_id,omitempty"`
+	State    string `json:"state,omitempty"` // matches either Posture or WorkerState
+}
+
+type batchOpResult struct {
+	Op     string `json:"op"`
+	SubID  int64  `json:"sub_id"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+	DryRun bool   `json:"dry_run,omitempty"`
+}
+
+// batchConcurrency bounds how many ops adminBatchSubscriptions runs at once,
+// same role as bulkStart's per-call semaphore.
+const batchConcurrency = 8
+
+// subscriptionSetFingerprint returns a SHA-256 hex digest of subs' sorted
+// (sub_id, updated_at) pairs, used as POST .../subscriptions:batch's
+// If-Match value so a UI holding a stale subscription list can't fan a
+// filter-based op out over subscriptions it never saw — mirrors
+// config.Global.Fingerprint's role for PUT /api/config.
+func subscriptionSetFingerprint(subs []*manager.SubscriptionStatus) string {
+	sorted := make([]*manager.SubscriptionStatus, len(subs))
+	copy(sorted, subs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].SubID < sorted[j].SubID })
+	var sb strings.Builder
+	for _, s := range sorted {
+		fmt.Fprintf(&sb, "%d:%d;", s.SubID, s.UpdatedAt.UnixNano())
+	}
+	sum := sha256.Sum256([]byte(sb.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+func matchesBatchFilter(s *manager.SubscriptionStatus, f *batchSubscriptionFilter) bool {
+	if f.Driver != "" && s.Driver != f.Driver {
+		return false
+	}
+	if f.Username != "" && s.Username != f.Username {
+		return false
+	}
+	if f.UserID != 0 && s.UserID != f.UserID {
+		return false
+	}
+	if f.State != "" && string(s.Posture) != f.State && s.WorkerState != f.State {
+		return false
+	}
+	return true
+}
+
+// runBatchOp executes (or, if dryRun, just reports a match for) op against
+// subID, mapping errors to the same status codes as the equivalent
+// single-subscription admin handler.
+func runBatchOp(ctx context.Context, d Deps, op string, subID int64, dryRun bool) batchOpResult {
+	res := batchOpResult{Op: op, SubID: subID, DryRun: dryRun}
+	if dryRun {
+		res.Status = http.StatusOK
+		return res
+	}
+
+	var err error
+	metricLabel := op
+	switch op {
+	case "pause":
+		_, err = d.Manager.AdminPause(ctx, subID)
+	case "resume":
+		_, err = d.Manager.AdminResume(ctx, subID)
+	case "archive":
+		_, err = d.Manager.AdminArchive(ctx, subID)
+	case "delete":
+		err = d.Manager.AdminUnsubscribe(ctx, subID)
+	case "reset_error":
+		_, err = d.Manager.AdminResetError(ctx, subID)
+		metricLabel = "reset"
+	default:
+		res.Status = http.StatusBadRequest
+		res.Error = "unknown op: " + op
+		return res
+	}
+	if err != nil {
+		res.Status = http.StatusNotFound
+		if op == "reset_error" {
+			res.Status = http.StatusConflict
+		}
+		res.Error = err.Error()
+		return res
+	}
+	metrics.AdminActions.WithLabelValues(metricLabel).Inc()
+	res.Status = http.StatusOK
+	return res
+}
+
+// adminBatchSubscriptions runs a list of pause/resume/archive/delete/
+// reset_error ops — each targeting either a direct sub_id or a filter
+// selector — concurrently over a bounded worker pool, so an admin dashboard
+// doesn't need one round trip per subscription. Requires If-Match against
+// subscriptionSetFingerprint of the current subscription set, so a stale UI
+// can't fan a filter op out over subscriptions it never saw. dry_run reports
+// which subscriptions would be affected without calling through to Manager.
+func adminBatchSubscriptions(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var body struct {
-			IncludeErrored bool `json:"include_errored"`
+			Ops    []batchSubscriptionOp `json:"ops"`
+			DryRun bool                  `json:"dry_run"`
 		}
-		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 			writeError(w, http.StatusBadRequest, "invalid JSON")
 			return
 		}
+		if len(body.Ops) == 0 {
+			writeError(w, http.StatusBadRequest, "ops must be non-empty")
+			return
+		}
+
+		all, err := d.Manager.ListSubscriptions(r.Context(), 0, true)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		ifMatch := strings.Trim(r.Header.Get("If-Match"), `"`)
+		if ifMatch == "" {
+			writeError(w, http.StatusPreconditionRequired, "If-Match header is required")
+			return
+		}
+		if current := subscriptionSetFingerprint(all); current != ifMatch {
+			writeError(w, http.StatusPreconditionFailed, "subscription set was modified concurrently, reload and retry")
+			return
+		}
+
+		type target struct {
+			op    string
+			subID int64
+		}
+		var targets []target
+		for _, op := range body.Ops {
+			switch {
+			case op.Filter != nil:
+				for _, s := range all {
+					if matchesBatchFilter(s, op.Filter) {
+						targets = append(targets, target{op: op.Op, subID: s.SubID})
+					}
+				}
+			case op.SubID != 0:
+				targets = append(targets, target{op: op.Op, subID: op.SubID})
+			default:
+				writeError(w, http.StatusBadRequest, "each op requires sub_id or filter")
+				return
+			}
+		}
+
+		results := make([]batchOpResult, len(targets))
+		sem := make(chan struct{}, batchConcurrency)
+		var wg sync.WaitGroup
+		for i, t := range targets {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, t target) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				results[i] = runBatchOp(r.Context(), d, t.op, t.subID, body.DryRun)
+			}(i, t)
+		}
+		wg.Wait()
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"fingerprint": ifMatch,
+			"results":     results,
+		})
+	}
+}
+
+func adminRestartAllSources(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			IncludeErrored bool `json:"include_errored"`
+		}
+		if r.ContentLength > 0 {
+			if verr := decodeStrict(r, &body); verr != nil {
+				writeValidationError(w, *verr)
+				return
+			}
+		}
 		restarted, skipped := d.Manager.RestartAll(r.Context(), body.IncludeErrored)
 		writeJSON(w, http.StatusOK, map[string]int{
 			"restarted": restarted,
@@ -994,103 +3038,284 @@ func listWorkers(d Deps) http.HandlerFunc {
 	}
 }
 
-// svcInfo is the per-service diagnostics payload.
-type svcInfo struct {
-	Connected bool   `json:"connected"`
-	Error     string `json:"error,omitempty"`
-	Pool      any    `json:"pool,omitempty"`
-	Metrics   any    `json:"metrics,omitempty"`
+// getLeaveStatus reports drain progress during a coordinated shutdown, so an
+// operator rolling out a deploy can watch sources finish their sessions
+// instead of killing the pod blind.
+func getLeaveStatus(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, d.Manager.LeaveStatus())
+	}
 }
 
-func getDiagnostics(d Deps) http.HandlerFunc {
+// setDrain toggles graceful drain mode for rolling restarts: enable=true
+// starts draining in the background (poll /api/admin/leave-status for
+// progress) and rejects new subscribes/resumes in the meantime, while
+// restart-all skips every source (a single-subscription restart is not gated);
+// enable=false reverses it via Manager.Undrain.
+func setDrain(d Deps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx := r.Context()
+		var body struct {
+			Enable bool `json:"enable"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON")
+			return
+		}
 
-		var (
-			recorderInfo   svcInfo
-			converterInfo  svcInfo
-			thumbnailerInfo svcInfo
-			wg             sync.WaitGroup
-		)
+		if !body.Enable {
+			d.Manager.Undrain()
+			writeJSON(w, http.StatusOK, d.Manager.LeaveStatus())
+			return
+		}
 
-		// ---- Recorder (persistent client) ----
-		wg.Add(1)
 		go func() {
-			defer wg.Done()
-			oc := d.Manager.GetOverseerClient()
-			if oc == nil {
-				recorderInfo = svcInfo{Error: "overseer client not initialised"}
-				return
+			if err := d.Manager.Leave(context.Background()); err != nil {
+				log.Printf("router: drain: %v", err)
 			}
-			if !oc.IsConnected() {
-				recorderInfo = svcInfo{Error: "overseer disconnected"}
+		}()
+		writeJSON(w, http.StatusAccepted, d.Manager.LeaveStatus())
+	}
+}
+
+// ---- streaming ----
+
+// streamThumbnailerEvents attaches a listener to the thumbnailer client's
+// event hub and pipes task lifecycle updates to the caller as SSE, removing
+// the need for the frontend to poll the thumbnailer diagnostics endpoint.
+func streamThumbnailerEvents(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if d.Workers.Thumbnailer == nil {
+			writeError(w, http.StatusServiceUnavailable, "THUMBNAILER_URL not configured")
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, "streaming unsupported")
+			return
+		}
+
+		ch := d.Workers.Thumbnailer.Subscribe()
+		defer d.Workers.Thumbnailer.Unsubscribe(ch)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
 				return
-			}
-			recorderInfo.Connected = true
-			var innerWg sync.WaitGroup
-			innerWg.Add(2)
-			go func() {
-				defer innerWg.Done()
-				if pi, err := oc.PoolInfo(ctx); err == nil {
-					recorderInfo.Pool = pi
+			case ev, ok := <-ch:
+				if !ok {
+					return
 				}
-			}()
-			go func() {
-				defer innerWg.Done()
-				if gm, err := oc.Metrics(ctx); err == nil {
-					recorderInfo.Metrics = gm
+				b, err := json.Marshal(ev)
+				if err != nil {
+					continue
 				}
-			}()
-			innerWg.Wait()
-		}()
+				fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, b)
+				flusher.Flush()
+			}
+		}
+	}
+}
 
-		// ---- Converter (per-request dial) ----
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if d.ConverterClient == nil {
-				converterInfo = svcInfo{Error: "CONVERTER_URL not configured"}
-				return
+// tailConverterOutput streams a single converter task's stdout/stderr as
+// SSE, analogous to tailSource's live recording tail: TailOutput replays the
+// task's ring-buffered recent lines first so a late-joining client doesn't
+// see a blank screen, then streams new lines as the converter emits them.
+func tailConverterOutput(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		taskID := r.PathValue("task_id")
+		if d.Workers.Converter == nil {
+			writeError(w, http.StatusServiceUnavailable, "CONVERTER_URL not configured")
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, "streaming unsupported")
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		sink := &sseLineSink{w: w, flusher: flusher}
+		if err := d.Workers.Converter.TailOutput(r.Context(), taskID, sink); err != nil && r.Context().Err() == nil {
+			log.Printf("router: tail converter output for %s: %v", taskID, err)
+		}
+	}
+}
+
+// sseLineSink adapts converter.TailOutput's timestamp-prefixed line writes
+// (one Write call per complete line) to SSE framing, flushing after every
+// line so the client sees it as soon as it's written.
+type sseLineSink struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (s *sseLineSink) Write(p []byte) (int, error) {
+	fmt.Fprintf(s.w, "event: output\ndata: %s\n\n", strings.TrimRight(string(p), "\n"))
+	s.flusher.Flush()
+	return len(p), nil
+}
+
+// getPoolProgress returns a rolled-up conversion progress bar for the
+// dashboard: pool-wide state plus every task's per-task progress, and an
+// overall percent averaged across tasks that have reported a determinate
+// duration (tasks still indeterminate, e.g. just queued, are excluded from
+// the average rather than counted as 0%).
+func getPoolProgress(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if d.Workers.Converter == nil {
+			writeError(w, http.StatusServiceUnavailable, "CONVERTER_URL not configured")
+			return
+		}
+
+		pi, err := d.Workers.Converter.GetPoolInfo(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "converter error: "+err.Error())
+			return
+		}
+		tasks, err := d.Workers.Converter.GetAllTasks(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "converter error: "+err.Error())
+			return
+		}
+
+		var sum float64
+		var determinate int
+		for _, t := range tasks {
+			if t.ProgressPercent < 0 {
+				continue
 			}
-			// Use pool info reachability as the connected signal (metrics may be zero).
-			pi, _ := d.ConverterClient.GetPoolInfo(ctx)
-			if pi == nil {
-				converterInfo = svcInfo{Error: "converter unreachable"}
-				return
+			sum += t.ProgressPercent
+			determinate++
+		}
+		rollupPercent := -1.0
+		if determinate > 0 {
+			rollupPercent = sum / float64(determinate)
+		}
+
+		writeJSON(w, http.StatusOK, map[string]any{
+			"pool":           pi,
+			"tasks":          tasks,
+			"rollup_percent": rollupPercent,
+		})
+	}
+}
+
+// eventStreamHeartbeat is how often streamManagerEvents writes a comment
+// line to keep the connection alive through idle proxies/load balancers
+// that otherwise time out a quiet SSE stream.
+const eventStreamHeartbeat = 15 * time.Second
+
+// streamManagerEvents streams Manager state-transition events (worker state,
+// subscription posture, task claims, scheduled restarts) as SSE, for
+// push-based admin UIs and webhook relays that would otherwise have to poll
+// statusFor/listWorkers. A reconnecting client sends back the last event's
+// id as Last-Event-ID (header or query param, matching the EventSource
+// spec's own behavior for the header) to replay anything missed while
+// disconnected, bounded by the manager's event ring buffer.
+func streamManagerEvents(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, "streaming unsupported")
+			return
+		}
+
+		sub := d.Manager.SubscribeEvents()
+		defer sub.Close()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		if lastID := lastEventID(r); lastID != 0 {
+			replay, ok := d.Manager.EventsSince(lastID)
+			if !ok {
+				fmt.Fprintf(w, ": missed events older than the replay buffer\n\n")
 			}
-			converterInfo.Connected = true
-			converterInfo.Pool = pi
-			if gm, _ := d.ConverterClient.GetMetrics(ctx); gm != nil {
-				converterInfo.Metrics = gm
+			for _, ev := range replay {
+				writeSSEEvent(w, ev)
 			}
-		}()
+		}
+		flusher.Flush()
 
-		// ---- Thumbnailer (per-request dial) ----
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			if d.ThumbnailerClient == nil {
-				thumbnailerInfo = svcInfo{Error: "THUMBNAILER_URL not configured"}
-				return
-			}
-			pi, _ := d.ThumbnailerClient.GetPoolInfo(ctx)
-			if pi == nil {
-				thumbnailerInfo = svcInfo{Error: "thumbnailer unreachable"}
+		heartbeat := time.NewTicker(eventStreamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
 				return
+			case <-heartbeat.C:
+				fmt.Fprintf(w, ": heartbeat\n\n")
+				flusher.Flush()
+			case ev, ok := <-sub.C():
+				if !ok {
+					return
+				}
+				writeSSEEvent(w, ev)
+				flusher.Flush()
 			}
-			thumbnailerInfo.Connected = true
-			thumbnailerInfo.Pool = pi
-			if gm, _ := d.ThumbnailerClient.GetMetrics(ctx); gm != nil {
-				thumbnailerInfo.Metrics = gm
-			}
-		}()
+		}
+	}
+}
 
-		wg.Wait()
+// lastEventID reads the client's resume point, preferring the standard
+// Last-Event-ID header but falling back to a ?last_event_id= query param
+// since some proxies/browsers strip custom headers on reconnect.
+func lastEventID(r *http.Request) uint64 {
+	raw := r.Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = r.URL.Query().Get("last_event_id")
+	}
+	id, _ := strconv.ParseUint(raw, 10, 64)
+	return id
+}
 
-		writeJSON(w, http.StatusOK, map[string]any{
-			"recorder":   recorderInfo,
-			"converter":  converterInfo,
-			"thumbnailer": thumbnailerInfo,
-		})
+func writeSSEEvent(w http.ResponseWriter, ev manager.Event) {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, b)
+}
+
+// getDiagnostics iterates d.Services generically — each registered client's
+// breaker/cache state is handled by the diagnostics package itself, so
+// adding a fourth service later is a Register call in main.go, not a new
+// hand-rolled goroutine here. Response keys are unchanged from before the
+// registry existed ("recorder"/"converter"/"thumbnailer") for compatibility
+// with existing dashboards.
+func getDiagnostics(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if d.Services == nil {
+			writeJSON(w, http.StatusOK, map[string]any{})
+			return
+		}
+		writeJSON(w, http.StatusOK, d.Services.Diagnose(r.Context()))
+	}
+}
+
+// getWorkerStatus reports each registered worker client's connection state,
+// distinct from getDiagnostics: this is a plain connected/disconnected read
+// off the Registry, with no circuit breaker or cache in front of it.
+func getWorkerStatus(d Deps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if d.Workers == nil {
+			writeJSON(w, http.StatusOK, map[string]any{"workers": []any{}})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"workers": d.Workers.Snapshot()})
 	}
 }
@@ -0,0 +1,199 @@
+// Package conformance is a table-driven store.Store behavior suite run
+// against every implementation (postgres, etcd) from each package's own
+// _test.go, so the two backends can't silently drift apart on the
+// semantics callers depend on (not-found returns nil/nil rather than an
+// error, GetOrCreateSource is idempotent, SetPosture actually persists).
+// It is not exhaustive over every Store method — see Run's doc comment
+// for what's covered.
+package conformance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
+)
+
+// Run exercises the user/source/subscription/worker-event/config slice of
+// store.Store against st, failing t on any deviation from the documented
+// contract in store.go. st is expected to be a store.Store pointed at a
+// throwaway keyspace/schema so tests can run repeatedly without colliding
+// with previous runs or each other.
+func Run(t *testing.T, st store.Store) {
+	t.Helper()
+	ctx := context.Background()
+
+	t.Run("users", func(t *testing.T) { testUsers(t, ctx, st) })
+	t.Run("sources", func(t *testing.T) { testSources(t, ctx, st) })
+	t.Run("subscriptions", func(t *testing.T) { testSubscriptions(t, ctx, st) })
+	t.Run("worker_events", func(t *testing.T) { testWorkerEvents(t, ctx, st) })
+	t.Run("config", func(t *testing.T) { testConfig(t, ctx, st) })
+}
+
+func testUsers(t *testing.T, ctx context.Context, st store.Store) {
+	t.Helper()
+
+	u, err := st.CreateUser(ctx, uniqueName(t, "user"), "hash", "viewer")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if u.ID == 0 {
+		t.Fatal("CreateUser: want non-zero ID")
+	}
+
+	got, err := st.GetUser(ctx, u.ID)
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if got == nil || got.Username != u.Username {
+		t.Fatalf("GetUser(%d) = %+v, want username %q", u.ID, got, u.Username)
+	}
+
+	missing, err := st.GetUser(ctx, -1)
+	if err != nil || missing != nil {
+		t.Fatalf("GetUser(-1) = %+v, %v, want nil, nil", missing, err)
+	}
+
+	newRole := "admin"
+	updated, err := st.UpdateUser(ctx, u.ID, store.UserUpdate{Role: &newRole})
+	if err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if updated.Role != newRole {
+		t.Fatalf("UpdateUser: Role = %q, want %q", updated.Role, newRole)
+	}
+
+	if err := st.DeleteUser(ctx, u.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if got, err := st.GetUser(ctx, u.ID); err != nil || got != nil {
+		t.Fatalf("GetUser after DeleteUser = %+v, %v, want nil, nil", got, err)
+	}
+}
+
+func testSources(t *testing.T, ctx context.Context, st store.Store) {
+	t.Helper()
+
+	driver, username := "twitch", uniqueName(t, "streamer")
+	src, err := st.GetOrCreateSource(ctx, driver, username)
+	if err != nil {
+		t.Fatalf("GetOrCreateSource: %v", err)
+	}
+
+	again, err := st.GetOrCreateSource(ctx, driver, username)
+	if err != nil {
+		t.Fatalf("GetOrCreateSource (idempotent): %v", err)
+	}
+	if again.ID != src.ID {
+		t.Fatalf("GetOrCreateSource returned a second source (%d) for the same key, want %d", again.ID, src.ID)
+	}
+
+	if err := st.SetSourceTaskID(ctx, src.ID, "task-123"); err != nil {
+		t.Fatalf("SetSourceTaskID: %v", err)
+	}
+	byKey, err := st.GetSourceByKey(ctx, driver, username)
+	if err != nil {
+		t.Fatalf("GetSourceByKey: %v", err)
+	}
+	if byKey.OverseerTaskID != "task-123" {
+		t.Fatalf("GetSourceByKey: OverseerTaskID = %q, want task-123", byKey.OverseerTaskID)
+	}
+}
+
+func testSubscriptions(t *testing.T, ctx context.Context, st store.Store) {
+	t.Helper()
+
+	u, err := st.CreateUser(ctx, uniqueName(t, "subuser"), "hash", "viewer")
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	src, err := st.GetOrCreateSource(ctx, "twitch", uniqueName(t, "subsource"))
+	if err != nil {
+		t.Fatalf("GetOrCreateSource: %v", err)
+	}
+
+	sub, err := st.CreateSubscription(ctx, u.ID, src.ID)
+	if err != nil {
+		t.Fatalf("CreateSubscription: %v", err)
+	}
+	if sub.Posture != store.PostureActive {
+		t.Fatalf("CreateSubscription: Posture = %q, want active", sub.Posture)
+	}
+
+	if err := st.SetPosture(ctx, sub.ID, store.PosturePaused); err != nil {
+		t.Fatalf("SetPosture: %v", err)
+	}
+	got, err := st.GetSubscription(ctx, u.ID, src.ID)
+	if err != nil {
+		t.Fatalf("GetSubscription: %v", err)
+	}
+	if got.Posture != store.PosturePaused {
+		t.Fatalf("GetSubscription after SetPosture: Posture = %q, want paused", got.Posture)
+	}
+
+	count, err := st.GetSourceActiveSubscriberCount(ctx, src.ID)
+	if err != nil {
+		t.Fatalf("GetSourceActiveSubscriberCount: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("GetSourceActiveSubscriberCount = %d, want 0 (subscription is paused)", count)
+	}
+}
+
+func testWorkerEvents(t *testing.T, ctx context.Context, st store.Store) {
+	t.Helper()
+
+	src, err := st.GetOrCreateSource(ctx, "twitch", uniqueName(t, "eventsource"))
+	if err != nil {
+		t.Fatalf("GetOrCreateSource: %v", err)
+	}
+
+	if err := st.RecordWorkerEvent(ctx, src.ID, 1234, store.EventStarted, nil, nil, nil); err != nil {
+		t.Fatalf("RecordWorkerEvent: %v", err)
+	}
+	exitCode := 1
+	if err := st.RecordWorkerEvent(ctx, src.ID, 1234, store.EventExited, &exitCode, nil, nil); err != nil {
+		t.Fatalf("RecordWorkerEvent: %v", err)
+	}
+
+	events, err := st.RecentWorkerEvents(ctx, src.ID, 10)
+	if err != nil {
+		t.Fatalf("RecentWorkerEvents: %v", err)
+	}
+	if len(events) < 2 {
+		t.Fatalf("RecentWorkerEvents returned %d events, want at least 2", len(events))
+	}
+}
+
+func testConfig(t *testing.T, ctx context.Context, st store.Store) {
+	t.Helper()
+
+	v, err := st.SetConfig(ctx, map[string]any{"segment_seconds": float64(10)}, nil, "conformance test")
+	if err != nil {
+		t.Fatalf("SetConfig: %v", err)
+	}
+
+	data, err := st.GetConfig(ctx)
+	if err != nil {
+		t.Fatalf("GetConfig: %v", err)
+	}
+	if data["segment_seconds"] != float64(10) {
+		t.Fatalf("GetConfig: segment_seconds = %v, want 10", data["segment_seconds"])
+	}
+
+	rolled, err := st.RollbackConfig(ctx, v.ID, nil, "rollback in conformance test")
+	if err != nil {
+		t.Fatalf("RollbackConfig: %v", err)
+	}
+	if string(rolled.Data) != string(v.Data) {
+		t.Fatalf("RollbackConfig: Data = %s, want %s", rolled.Data, v.Data)
+	}
+}
+
+// uniqueName keeps concurrent/repeated test runs from colliding on a
+// username or driver/username key that both backends treat as unique.
+func uniqueName(t *testing.T, prefix string) string {
+	t.Helper()
+	return prefix + "-" + time.Now().Format("20060102T150405.000000000")
+}
@@ -0,0 +1,27 @@
+package etcd
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/store/conformance"
+)
+
+// TestConformance runs the shared store.Store suite against a real etcd
+// cluster. Set TEST_ETCD_ENDPOINTS (comma-separated) to run it; otherwise
+// it's skipped, matching postgres's conformance_test.go.
+func TestConformance(t *testing.T) {
+	endpoints := os.Getenv("TEST_ETCD_ENDPOINTS")
+	if endpoints == "" {
+		t.Skip("TEST_ETCD_ENDPOINTS not set; skipping etcd conformance suite")
+	}
+
+	db, err := Open(context.Background(), endpoints)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+
+	conformance.Run(t, db)
+}
@@ -0,0 +1,1131 @@
+// Package etcd provides an etcd v3-backed store.Store implementation, as an
+// alternative to store/postgres for deployments that want to run several
+// sticky-backend replicas against shared state without operating a
+// PostgreSQL instance. It targets the subsystems that matter for
+// multi-replica operation today — subscriptions, sources, and worker
+// events — plus every other store.Store method needed to satisfy the
+// interface. nextID, SetPosture and ConsumeOAuthAuthorization use
+// compare-and-swap Txns; every other write is a plain Put, and writes that
+// touch several keys (a record plus its lookup key) are not atomic together.
+//
+// Keys are namespaced under /sticky/ and JSON-encode store's own domain
+// types directly, so there is no separate row/column schema to keep in
+// sync with store.go. Auto-increment int64 IDs (matching the shape
+// postgres.DB hands callers today) come from a per-entity counter key
+// advanced via a compare-and-swap Txn, since etcd has no SERIAL column.
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"github.com/google/uuid"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
+)
+
+const dialTimeout = 5 * time.Second
+
+// DB implements store.Store against an etcd v3 cluster.
+type DB struct {
+	cli      *clientv3.Client
+	validate func(data map[string]any) error
+}
+
+// Option configures optional behavior on Open, mirroring postgres.Option.
+type Option func(*DB)
+
+// WithConfigValidator registers a hook that SetConfig runs against a
+// candidate payload before writing a new config version, exactly like
+// postgres.WithConfigValidator.
+func WithConfigValidator(fn func(data map[string]any) error) Option {
+	return func(d *DB) { d.validate = fn }
+}
+
+// Open dials the etcd cluster at endpoints (comma-separated in the
+// DB_DSN passed to it by main.go) and returns a ready DB.
+func Open(ctx context.Context, endpoints string, opts ...Option) (*DB, error) {
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints:   strings.Split(endpoints, ","),
+		DialTimeout: dialTimeout,
+		Context:     ctx,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("etcd.New: %w", err)
+	}
+	if _, err := cli.Status(ctx, cli.Endpoints()[0]); err != nil {
+		cli.Close()
+		return nil, fmt.Errorf("etcd status: %w", err)
+	}
+
+	db := &DB{cli: cli}
+	for _, opt := range opts {
+		opt(db)
+	}
+	return db, nil
+}
+
+func (d *DB) Close() error { return d.cli.Close() }
+
+// ---- generic KV helpers ----
+
+func putJSON(ctx context.Context, cli *clientv3.Client, key string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = cli.Put(ctx, key, string(raw))
+	return err
+}
+
+// getJSON reports found=false (not an error) when key doesn't exist, the
+// same "missing row" contract postgres.queries' pgx.ErrNoRows handling
+// gives callers.
+func getJSON(ctx context.Context, cli *clientv3.Client, key string, v any) (found bool, err error) {
+	resp, err := cli.Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if len(resp.Kvs) == 0 {
+		return false, nil
+	}
+	return true, json.Unmarshal(resp.Kvs[0].Value, v)
+}
+
+func deleteKey(ctx context.Context, cli *clientv3.Client, key string) error {
+	_, err := cli.Delete(ctx, key)
+	return err
+}
+
+// listPrefix decodes every value under prefix via decode, in key order
+// (etcd range results are lexicographic; for keys built with idKey below
+// that is ID order, like postgres's ORDER BY id lists, while lists keyed by
+// UUID or fingerprint do not follow postgres's created_at or other ordering).
+func listPrefix(ctx context.Context, cli *clientv3.Client, prefix string, decode func(v []byte) error) error {
+	resp, err := cli.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return err
+	}
+	for _, kv := range resp.Kvs {
+		if err := decode(kv.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextID advances the per-entity counter at /sticky/ids/{entity} with a
+// compare-and-swap retry loop, since etcd has no auto-increment column.
+func nextID(ctx context.Context, cli *clientv3.Client, entity string) (int64, error) {
+	key := "/sticky/ids/" + entity
+	for {
+		resp, err := cli.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		var cur int64
+		var modRev int64
+		if len(resp.Kvs) > 0 {
+			cur, err = strconv.ParseInt(string(resp.Kvs[0].Value), 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("etcd: malformed counter %s: %w", key, err)
+			}
+			modRev = resp.Kvs[0].ModRevision
+		}
+		next := cur + 1
+		txn := cli.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", modRev)).
+			Then(clientv3.OpPut(key, strconv.FormatInt(next, 10)))
+		txnResp, err := txn.Commit()
+		if err != nil {
+			return 0, err
+		}
+		if txnResp.Succeeded {
+			return next, nil
+		}
+		// Lost the race against a concurrent nextID call; retry.
+	}
+}
+
+// idKey zero-pads id so prefix-range listing sorts numerically, not
+// lexicographically ("10" would otherwise sort before "2").
+func idKey(id int64) string { return fmt.Sprintf("%020d", id) }
+
+// ---- users ----
+
+func (d *DB) CreateUser(ctx context.Context, username, passwordHash, role string) (*store.User, error) {
+	id, err := nextID(ctx, d.cli, "users")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	u := &store.User{ID: id, Username: username, PasswordHash: passwordHash, Role: role, CreatedAt: now, UpdatedAt: now}
+	if err := putJSON(ctx, d.cli, "/sticky/users/by_id/"+idKey(id), u); err != nil {
+		return nil, err
+	}
+	if _, err := d.cli.Put(ctx, "/sticky/users/by_username/"+username, idKey(id)); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *DB) GetUser(ctx context.Context, id int64) (*store.User, error) {
+	var u store.User
+	found, err := getJSON(ctx, d.cli, "/sticky/users/by_id/"+idKey(id), &u)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (d *DB) GetUserByUsername(ctx context.Context, username string) (*store.User, error) {
+	resp, err := d.cli.Get(ctx, "/sticky/users/by_username/"+username)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	var u store.User
+	found, err := getJSON(ctx, d.cli, "/sticky/users/by_id/"+string(resp.Kvs[0].Value), &u)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (d *DB) ListUsers(ctx context.Context) ([]*store.User, error) {
+	var users []*store.User
+	err := listPrefix(ctx, d.cli, "/sticky/users/by_id/", func(v []byte) error {
+		var u store.User
+		if err := json.Unmarshal(v, &u); err != nil {
+			return err
+		}
+		users = append(users, &u)
+		return nil
+	})
+	return users, err
+}
+
+func (d *DB) UpdateUser(ctx context.Context, id int64, fields store.UserUpdate) (*store.User, error) {
+	u, err := d.GetUser(ctx, id)
+	if err != nil || u == nil {
+		return nil, err
+	}
+	if fields.Username != nil && *fields.Username != u.Username {
+		if _, err := d.cli.Delete(ctx, "/sticky/users/by_username/"+u.Username); err != nil {
+			return nil, err
+		}
+		if _, err := d.cli.Put(ctx, "/sticky/users/by_username/"+*fields.Username, idKey(id)); err != nil {
+			return nil, err
+		}
+		u.Username = *fields.Username
+	}
+	if fields.PasswordHash != nil {
+		u.PasswordHash = *fields.PasswordHash
+	}
+	if fields.Role != nil {
+		u.Role = *fields.Role
+	}
+	u.UpdatedAt = time.Now().UTC()
+	if err := putJSON(ctx, d.cli, "/sticky/users/by_id/"+idKey(id), u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *DB) DeleteUser(ctx context.Context, id int64) error {
+	u, err := d.GetUser(ctx, id)
+	if err != nil || u == nil {
+		return err
+	}
+	if _, err := d.cli.Delete(ctx, "/sticky/users/by_username/"+u.Username); err != nil {
+		return err
+	}
+	return deleteKey(ctx, d.cli, "/sticky/users/by_id/"+idKey(id))
+}
+
+// ---- sessions ----
+
+func (d *DB) CreateSession(ctx context.Context, userID int64, refreshToken string, expiresAt time.Time) (*store.Session, error) {
+	s := &store.Session{
+		ID: uuid.New(), UserID: userID, RefreshToken: refreshToken,
+		ExpiresAt: expiresAt, CreatedAt: time.Now().UTC(), FamilyID: uuid.New(),
+	}
+	if err := putJSON(ctx, d.cli, "/sticky/sessions/by_id/"+s.ID.String(), s); err != nil {
+		return nil, err
+	}
+	if _, err := d.cli.Put(ctx, "/sticky/sessions/by_token/"+refreshToken, s.ID.String()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (d *DB) getSessionByID(ctx context.Context, id uuid.UUID) (*store.Session, error) {
+	var s store.Session
+	found, err := getJSON(ctx, d.cli, "/sticky/sessions/by_id/"+id.String(), &s)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (d *DB) GetSessionByRefreshToken(ctx context.Context, refreshToken string) (*store.Session, error) {
+	resp, err := d.cli.Get(ctx, "/sticky/sessions/by_token/"+refreshToken)
+	if err != nil || len(resp.Kvs) == 0 {
+		return nil, err
+	}
+	id, err := uuid.Parse(string(resp.Kvs[0].Value))
+	if err != nil {
+		return nil, err
+	}
+	return d.getSessionByID(ctx, id)
+}
+
+func (d *DB) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	s, err := d.getSessionByID(ctx, id)
+	if err != nil || s == nil {
+		return err
+	}
+	if _, err := d.cli.Delete(ctx, "/sticky/sessions/by_token/"+s.RefreshToken); err != nil {
+		return err
+	}
+	return deleteKey(ctx, d.cli, "/sticky/sessions/by_id/"+id.String())
+}
+
+func (d *DB) DeleteExpiredSessions(ctx context.Context) error {
+	now := time.Now().UTC()
+	var stale []*store.Session
+	if err := listPrefix(ctx, d.cli, "/sticky/sessions/by_id/", func(v []byte) error {
+		var s store.Session
+		if err := json.Unmarshal(v, &s); err != nil {
+			return err
+		}
+		if s.ExpiresAt.Before(now) {
+			stale = append(stale, &s)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, s := range stale {
+		if err := d.DeleteSession(ctx, s.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RotateSession exchanges oldRefreshToken for a new session in the same
+// family, mirroring postgres.DB's reuse-detection semantics.
+func (d *DB) RotateSession(ctx context.Context, oldRefreshToken string) (*store.Session, error) {
+	old, err := d.GetSessionByRefreshToken(ctx, oldRefreshToken)
+	if err != nil || old == nil {
+		return nil, err
+	}
+	if old.RotatedAt != nil || old.RevokedReason != nil {
+		if err := d.RevokeFamily(ctx, old.FamilyID, "refresh token reuse detected"); err != nil {
+			return nil, err
+		}
+		return nil, store.ErrRefreshTokenReused
+	}
+
+	newToken := uuid.NewString()
+	next := &store.Session{
+		ID: uuid.New(), UserID: old.UserID, RefreshToken: newToken,
+		ExpiresAt: old.ExpiresAt, CreatedAt: time.Now().UTC(),
+		FamilyID: old.FamilyID, ParentID: &old.ID,
+	}
+	if err := putJSON(ctx, d.cli, "/sticky/sessions/by_id/"+next.ID.String(), next); err != nil {
+		return nil, err
+	}
+	if _, err := d.cli.Put(ctx, "/sticky/sessions/by_token/"+newToken, next.ID.String()); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	old.RotatedAt = &now
+	if err := putJSON(ctx, d.cli, "/sticky/sessions/by_id/"+old.ID.String(), old); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+func (d *DB) RevokeFamily(ctx context.Context, familyID uuid.UUID, reason string) error {
+	var family []*store.Session
+	if err := listPrefix(ctx, d.cli, "/sticky/sessions/by_id/", func(v []byte) error {
+		var s store.Session
+		if err := json.Unmarshal(v, &s); err != nil {
+			return err
+		}
+		if s.FamilyID == familyID {
+			family = append(family, &s)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, s := range family {
+		if s.RevokedReason != nil {
+			continue
+		}
+		s.RevokedReason = &reason
+		if err := putJSON(ctx, d.cli, "/sticky/sessions/by_id/"+s.ID.String(), s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ---- sources ----
+
+func (d *DB) GetOrCreateSource(ctx context.Context, driver, username string) (*store.Source, error) {
+	if src, err := d.GetSourceByKey(ctx, driver, username); err != nil || src != nil {
+		return src, err
+	}
+	id, err := nextID(ctx, d.cli, "sources")
+	if err != nil {
+		return nil, err
+	}
+	src := &store.Source{ID: id, Driver: driver, Username: username, CreatedAt: time.Now().UTC()}
+	if err := putJSON(ctx, d.cli, "/sticky/sources/by_id/"+idKey(id), src); err != nil {
+		return nil, err
+	}
+	if _, err := d.cli.Put(ctx, "/sticky/sources/by_key/"+driver+"/"+username, idKey(id)); err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+func (d *DB) GetSourceByKey(ctx context.Context, driver, username string) (*store.Source, error) {
+	resp, err := d.cli.Get(ctx, "/sticky/sources/by_key/"+driver+"/"+username)
+	if err != nil || len(resp.Kvs) == 0 {
+		return nil, err
+	}
+	var src store.Source
+	found, err := getJSON(ctx, d.cli, "/sticky/sources/by_id/"+string(resp.Kvs[0].Value), &src)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &src, nil
+}
+
+func (d *DB) ListSources(ctx context.Context) ([]*store.Source, error) {
+	var sources []*store.Source
+	err := listPrefix(ctx, d.cli, "/sticky/sources/by_id/", func(v []byte) error {
+		var s store.Source
+		if err := json.Unmarshal(v, &s); err != nil {
+			return err
+		}
+		sources = append(sources, &s)
+		return nil
+	})
+	return sources, err
+}
+
+func (d *DB) SetSourceTaskID(ctx context.Context, sourceID int64, taskID string) error {
+	var src store.Source
+	found, err := getJSON(ctx, d.cli, "/sticky/sources/by_id/"+idKey(sourceID), &src)
+	if err != nil || !found {
+		return err
+	}
+	src.OverseerTaskID = taskID
+	return putJSON(ctx, d.cli, "/sticky/sources/by_id/"+idKey(sourceID), &src)
+}
+
+// ---- subscriptions ----
+//
+// Subscriptions are keyed by (user_id, source_id), the same pair
+// GetSubscription/CreateSubscription take; this is a finer-grained
+// structure than the request's literal /sticky/subs/{driver}/{source}
+// (a Subscription row doesn't carry the driver/username of the source it
+// points at, only source_id — joining those two would need a second
+// lookup the KV store can't do atomically), but keeps the same "tree
+// of prefixes replaces SQL WHERE clauses" shape: every List* method below
+// is a prefix or full-table range scan with a client-side filter.
+
+func subKey(userID, sourceID int64) string {
+	return fmt.Sprintf("/sticky/subs/by_user_source/%s/%s", idKey(userID), idKey(sourceID))
+}
+
+func (d *DB) CreateSubscription(ctx context.Context, userID, sourceID int64) (*store.Subscription, error) {
+	if sub, err := d.GetSubscription(ctx, userID, sourceID); err != nil {
+		return nil, err
+	} else if sub != nil {
+		sub.Posture = store.PostureActive
+		sub.UpdatedAt = time.Now().UTC()
+		if err := d.putSubscription(ctx, sub); err != nil {
+			return nil, err
+		}
+		return sub, nil
+	}
+	id, err := nextID(ctx, d.cli, "subscriptions")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	sub := &store.Subscription{ID: id, UserID: userID, SourceID: sourceID, Posture: store.PostureActive, CreatedAt: now, UpdatedAt: now}
+	if err := d.putSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (d *DB) putSubscription(ctx context.Context, sub *store.Subscription) error {
+	if err := putJSON(ctx, d.cli, "/sticky/subs/by_id/"+idKey(sub.ID), sub); err != nil {
+		return err
+	}
+	_, err := d.cli.Put(ctx, subKey(sub.UserID, sub.SourceID), idKey(sub.ID))
+	return err
+}
+
+func (d *DB) GetSubscription(ctx context.Context, userID, sourceID int64) (*store.Subscription, error) {
+	resp, err := d.cli.Get(ctx, subKey(userID, sourceID))
+	if err != nil || len(resp.Kvs) == 0 {
+		return nil, err
+	}
+	var sub store.Subscription
+	found, err := getJSON(ctx, d.cli, "/sticky/subs/by_id/"+string(resp.Kvs[0].Value), &sub)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (d *DB) listSubs(ctx context.Context, keep func(*store.Subscription) bool) ([]*store.Subscription, error) {
+	var subs []*store.Subscription
+	err := listPrefix(ctx, d.cli, "/sticky/subs/by_id/", func(v []byte) error {
+		var s store.Subscription
+		if err := json.Unmarshal(v, &s); err != nil {
+			return err
+		}
+		if keep(&s) {
+			subs = append(subs, &s)
+		}
+		return nil
+	})
+	return subs, err
+}
+
+func (d *DB) ListSubscriptionsByUser(ctx context.Context, userID int64) ([]*store.Subscription, error) {
+	return d.listSubs(ctx, func(s *store.Subscription) bool { return s.UserID == userID })
+}
+
+func (d *DB) ListActiveSubscriptions(ctx context.Context) ([]*store.Subscription, error) {
+	return d.listSubs(ctx, func(s *store.Subscription) bool { return s.Posture == store.PostureActive })
+}
+
+func (d *DB) ListAllSubscriptions(ctx context.Context) ([]*store.Subscription, error) {
+	return d.listSubs(ctx, func(*store.Subscription) bool { return true })
+}
+
+// SetPosture uses a compare-and-swap Txn on the subscription's ModRevision,
+// the optimistic-concurrency pattern the request asked for: two concurrent
+// admin actions racing on the same subscription can't silently clobber one
+// another's posture change.
+func (d *DB) SetPosture(ctx context.Context, id int64, posture store.Posture) error {
+	key := "/sticky/subs/by_id/" + idKey(id)
+	for {
+		resp, err := d.cli.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if len(resp.Kvs) == 0 {
+			return nil
+		}
+		var sub store.Subscription
+		if err := json.Unmarshal(resp.Kvs[0].Value, &sub); err != nil {
+			return err
+		}
+		sub.Posture = posture
+		sub.UpdatedAt = time.Now().UTC()
+		raw, err := json.Marshal(&sub)
+		if err != nil {
+			return err
+		}
+		txn := d.cli.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", resp.Kvs[0].ModRevision)).
+			Then(clientv3.OpPut(key, string(raw)))
+		txnResp, err := txn.Commit()
+		if err != nil {
+			return err
+		}
+		if txnResp.Succeeded {
+			return nil
+		}
+		// Another writer updated this subscription between our Get and
+		// Commit; retry against the new revision.
+	}
+}
+
+func (d *DB) GetSourceActiveSubscriberCount(ctx context.Context, sourceID int64) (int, error) {
+	subs, err := d.listSubs(ctx, func(s *store.Subscription) bool {
+		return s.SourceID == sourceID && s.Posture == store.PostureActive
+	})
+	return len(subs), err
+}
+
+func (d *DB) GetSourceSubscriberUserIDs(ctx context.Context, sourceID int64) ([]int64, error) {
+	subs, err := d.listSubs(ctx, func(s *store.Subscription) bool {
+		return s.SourceID == sourceID && s.Posture != store.PostureArchived
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, len(subs))
+	for i, s := range subs {
+		ids[i] = s.UserID
+	}
+	return ids, nil
+}
+
+// ---- worker events ----
+//
+// Keyed /sticky/events/{source_id}/{rfc3339_ts}_{pid}_{event_type}, as the
+// request asked for (substituting source_id for its "sub_id", since
+// RecordWorkerEvent is keyed by source in this Store, not by subscription):
+// RecentWorkerEvents becomes a reverse range scan over one source's prefix
+// instead of the SQL "source_id = $1 ORDER BY ts DESC LIMIT $2" query.
+
+func eventKey(sourceID int64, ts time.Time, pid int, eventType store.EventType) string {
+	return fmt.Sprintf("/sticky/events/%s/%s_%d_%s", idKey(sourceID), ts.UTC().Format(time.RFC3339Nano), pid, eventType)
+}
+
+func (d *DB) RecordWorkerEvent(ctx context.Context, sourceID int64, pid int, eventType store.EventType, exitCode *int, userID *int64, reason *string) error {
+	id, err := nextID(ctx, d.cli, "worker_events")
+	if err != nil {
+		return err
+	}
+	ts := time.Now().UTC()
+	ev := &store.WorkerEvent{ID: id, SourceID: sourceID, PID: pid, EventType: eventType, ExitCode: exitCode, UserID: userID, Reason: reason, TS: ts}
+	return putJSON(ctx, d.cli, eventKey(sourceID, ts, pid, eventType), ev)
+}
+
+func (d *DB) RecordWorkerEvents(ctx context.Context, sourceID int64, events []store.WorkerEventInput) error {
+	if len(events) == 0 {
+		return nil
+	}
+	last := events[len(events)-1]
+	id, err := nextID(ctx, d.cli, "worker_events")
+	if err != nil {
+		return err
+	}
+	detail, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	ev := &store.WorkerEvent{
+		ID: id, SourceID: sourceID, PID: last.PID, EventType: last.EventType,
+		ExitCode: last.ExitCode, UserID: last.UserID, Reason: last.Reason, TS: last.TS,
+	}
+	if len(events) > 1 {
+		ev.Detail = json.RawMessage(detail)
+	}
+	return putJSON(ctx, d.cli, eventKey(sourceID, last.TS, last.PID, last.EventType), ev)
+}
+
+func (d *DB) RecentWorkerEvents(ctx context.Context, sourceID int64, limit int) ([]store.WorkerEvent, error) {
+	resp, err := d.cli.Get(ctx, "/sticky/events/"+idKey(sourceID)+"/",
+		clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend), clientv3.WithLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	events := make([]store.WorkerEvent, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var ev store.WorkerEvent
+		if err := json.Unmarshal(kv.Value, &ev); err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// ---- resource samples ----
+
+func sampleKey(sourceID int64, ts time.Time) string {
+	return fmt.Sprintf("/sticky/samples/%s/%s", idKey(sourceID), ts.UTC().Format(time.RFC3339Nano))
+}
+
+func (d *DB) RecordResourceSample(ctx context.Context, sourceID int64, sample store.ResourceSample) error {
+	id, err := nextID(ctx, d.cli, "resource_samples")
+	if err != nil {
+		return err
+	}
+	sample.ID = id
+	sample.SourceID = sourceID
+	if sample.TS.IsZero() {
+		sample.TS = time.Now().UTC()
+	}
+	return putJSON(ctx, d.cli, sampleKey(sourceID, sample.TS), &sample)
+}
+
+func (d *DB) RecentResourceSamples(ctx context.Context, sourceID int64, limit int) ([]store.ResourceSample, error) {
+	resp, err := d.cli.Get(ctx, "/sticky/samples/"+idKey(sourceID)+"/",
+		clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend), clientv3.WithLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]store.ResourceSample, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var s store.ResourceSample
+		if err := json.Unmarshal(kv.Value, &s); err != nil {
+			return nil, err
+		}
+		samples = append(samples, s)
+	}
+	return samples, nil
+}
+
+// ---- config ----
+
+func (d *DB) GetConfig(ctx context.Context) (map[string]any, error) {
+	resp, err := d.cli.Get(ctx, "/sticky/config_versions/", clientv3.WithPrefix(),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend), clientv3.WithLimit(1))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return map[string]any{}, nil
+	}
+	var v store.ConfigVersion
+	if err := json.Unmarshal(resp.Kvs[0].Value, &v); err != nil {
+		return nil, err
+	}
+	var data map[string]any
+	if err := json.Unmarshal(v.Data, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (d *DB) SetConfig(ctx context.Context, data map[string]any, authorID *int64, comment string) (*store.ConfigVersion, error) {
+	if d.validate != nil {
+		if err := d.validate(data); err != nil {
+			return nil, err
+		}
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	id, err := nextID(ctx, d.cli, "config_versions")
+	if err != nil {
+		return nil, err
+	}
+	v := &store.ConfigVersion{ID: id, Data: raw, AuthorID: authorID, Comment: comment, CreatedAt: time.Now().UTC()}
+	if err := putJSON(ctx, d.cli, "/sticky/config_versions/"+idKey(id), v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (d *DB) ListConfigVersions(ctx context.Context, limit, offset int) ([]*store.ConfigVersion, error) {
+	resp, err := d.cli.Get(ctx, "/sticky/config_versions/", clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend))
+	if err != nil {
+		return nil, err
+	}
+	var versions []*store.ConfigVersion
+	for i, kv := range resp.Kvs {
+		if i < offset {
+			continue
+		}
+		if limit > 0 && len(versions) >= limit {
+			break
+		}
+		var v store.ConfigVersion
+		if err := json.Unmarshal(kv.Value, &v); err != nil {
+			return nil, err
+		}
+		versions = append(versions, &v)
+	}
+	return versions, nil
+}
+
+func (d *DB) GetConfigVersion(ctx context.Context, id int64) (*store.ConfigVersion, error) {
+	var v store.ConfigVersion
+	found, err := getJSON(ctx, d.cli, "/sticky/config_versions/"+idKey(id), &v)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (d *DB) RollbackConfig(ctx context.Context, versionID int64, authorID *int64, comment string) (*store.ConfigVersion, error) {
+	v, err := d.GetConfigVersion(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("etcd: config version %d not found", versionID)
+	}
+	var data map[string]any
+	if err := json.Unmarshal(v.Data, &data); err != nil {
+		return nil, err
+	}
+	return d.SetConfig(ctx, data, authorID, comment)
+}
+
+// ---- roles ----
+
+func (d *DB) CreateRole(ctx context.Context, name string, permissions []string) (*store.Role, error) {
+	r := &store.Role{Name: name, Permissions: permissions}
+	if err := putJSON(ctx, d.cli, "/sticky/roles/"+name, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (d *DB) AssignRole(ctx context.Context, userID int64, roleName string) error {
+	u, err := d.GetUser(ctx, userID)
+	if err != nil || u == nil {
+		return err
+	}
+	u.Role = roleName
+	return putJSON(ctx, d.cli, "/sticky/users/by_id/"+idKey(userID), u)
+}
+
+func (d *DB) ListRoles(ctx context.Context) ([]*store.Role, error) {
+	var roles []*store.Role
+	err := listPrefix(ctx, d.cli, "/sticky/roles/", func(v []byte) error {
+		var r store.Role
+		if err := json.Unmarshal(v, &r); err != nil {
+			return err
+		}
+		roles = append(roles, &r)
+		return nil
+	})
+	return roles, err
+}
+
+// ---- client certs ----
+
+func (d *DB) EnrollClientCert(ctx context.Context, fingerprint string, userID int64, label string) (*store.ClientCert, error) {
+	c := &store.ClientCert{Fingerprint: fingerprint, UserID: userID, Label: label, CreatedAt: time.Now().UTC()}
+	if err := putJSON(ctx, d.cli, "/sticky/client_certs/"+fingerprint, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (d *DB) GetClientCertByFingerprint(ctx context.Context, fingerprint string) (*store.ClientCert, error) {
+	var c store.ClientCert
+	found, err := getJSON(ctx, d.cli, "/sticky/client_certs/"+fingerprint, &c)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (d *DB) RevokeClientCert(ctx context.Context, fingerprint string) error {
+	c, err := d.GetClientCertByFingerprint(ctx, fingerprint)
+	if err != nil || c == nil {
+		return err
+	}
+	now := time.Now().UTC()
+	c.RevokedAt = &now
+	return putJSON(ctx, d.cli, "/sticky/client_certs/"+fingerprint, c)
+}
+
+func (d *DB) ListClientCerts(ctx context.Context) ([]*store.ClientCert, error) {
+	var certs []*store.ClientCert
+	err := listPrefix(ctx, d.cli, "/sticky/client_certs/", func(v []byte) error {
+		var c store.ClientCert
+		if err := json.Unmarshal(v, &c); err != nil {
+			return err
+		}
+		certs = append(certs, &c)
+		return nil
+	})
+	return certs, err
+}
+
+// ---- admin tasks ----
+
+func (d *DB) CreateAdminTask(ctx context.Context, kind string, doerID int64, payload json.RawMessage, total int) (*store.AdminTask, error) {
+	t := &store.AdminTask{
+		ID: uuid.New(), Kind: kind, DoerID: doerID, Payload: payload,
+		Status: store.AdminTaskPending, Total: total, CreatedAt: time.Now().UTC(),
+	}
+	if err := putJSON(ctx, d.cli, "/sticky/admin_tasks/"+t.ID.String(), t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (d *DB) UpdateAdminTaskProgress(ctx context.Context, id uuid.UUID, progress int, message string) error {
+	t, err := d.GetAdminTask(ctx, id)
+	if err != nil || t == nil {
+		return err
+	}
+	t.Progress = progress
+	t.Message = message
+	if t.Status == store.AdminTaskPending {
+		now := time.Now().UTC()
+		t.Status = store.AdminTaskRunning
+		t.StartedAt = &now
+	}
+	return putJSON(ctx, d.cli, "/sticky/admin_tasks/"+id.String(), t)
+}
+
+func (d *DB) MarkAdminTaskDone(ctx context.Context, id uuid.UUID, status store.AdminTaskStatus, taskErr error) error {
+	t, err := d.GetAdminTask(ctx, id)
+	if err != nil || t == nil {
+		return err
+	}
+	now := time.Now().UTC()
+	t.Status = status
+	t.FinishedAt = &now
+	if taskErr != nil {
+		msg := taskErr.Error()
+		t.Error = &msg
+	}
+	return putJSON(ctx, d.cli, "/sticky/admin_tasks/"+id.String(), t)
+}
+
+func (d *DB) ListAdminTasks(ctx context.Context, filter store.AdminTaskFilter) ([]*store.AdminTask, error) {
+	var tasks []*store.AdminTask
+	err := listPrefix(ctx, d.cli, "/sticky/admin_tasks/", func(v []byte) error {
+		var t store.AdminTask
+		if err := json.Unmarshal(v, &t); err != nil {
+			return err
+		}
+		if filter.Kind != "" && t.Kind != filter.Kind {
+			return nil
+		}
+		if filter.Status != "" && t.Status != filter.Status {
+			return nil
+		}
+		tasks = append(tasks, &t)
+		return nil
+	})
+	return tasks, err
+}
+
+func (d *DB) GetAdminTask(ctx context.Context, id uuid.UUID) (*store.AdminTask, error) {
+	var t store.AdminTask
+	found, err := getJSON(ctx, d.cli, "/sticky/admin_tasks/"+id.String(), &t)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ---- oauth2 apps ----
+
+func (d *DB) CreateOAuthApp(ctx context.Context, name string, ownerID int64, redirectURI, clientID, clientSecretHash string) (*store.OAuthApp, error) {
+	a := &store.OAuthApp{
+		ID: uuid.New(), Name: name, ClientID: clientID, ClientSecretHash: clientSecretHash,
+		RedirectURI: redirectURI, OwnerID: ownerID, CreatedAt: time.Now().UTC(),
+	}
+	if err := putJSON(ctx, d.cli, "/sticky/oauth_apps/by_id/"+a.ID.String(), a); err != nil {
+		return nil, err
+	}
+	if _, err := d.cli.Put(ctx, "/sticky/oauth_apps/by_client_id/"+clientID, a.ID.String()); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (d *DB) GetOAuthApp(ctx context.Context, id uuid.UUID) (*store.OAuthApp, error) {
+	var a store.OAuthApp
+	found, err := getJSON(ctx, d.cli, "/sticky/oauth_apps/by_id/"+id.String(), &a)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (d *DB) GetOAuthAppByClientID(ctx context.Context, clientID string) (*store.OAuthApp, error) {
+	resp, err := d.cli.Get(ctx, "/sticky/oauth_apps/by_client_id/"+clientID)
+	if err != nil || len(resp.Kvs) == 0 {
+		return nil, err
+	}
+	id, err := uuid.Parse(string(resp.Kvs[0].Value))
+	if err != nil {
+		return nil, err
+	}
+	return d.GetOAuthApp(ctx, id)
+}
+
+func (d *DB) ListOAuthApps(ctx context.Context) ([]*store.OAuthApp, error) {
+	var apps []*store.OAuthApp
+	err := listPrefix(ctx, d.cli, "/sticky/oauth_apps/by_id/", func(v []byte) error {
+		var a store.OAuthApp
+		if err := json.Unmarshal(v, &a); err != nil {
+			return err
+		}
+		apps = append(apps, &a)
+		return nil
+	})
+	return apps, err
+}
+
+func (d *DB) DeleteOAuthApp(ctx context.Context, id uuid.UUID) error {
+	a, err := d.GetOAuthApp(ctx, id)
+	if err != nil || a == nil {
+		return err
+	}
+	if _, err := d.cli.Delete(ctx, "/sticky/oauth_apps/by_client_id/"+a.ClientID); err != nil {
+		return err
+	}
+	return deleteKey(ctx, d.cli, "/sticky/oauth_apps/by_id/"+id.String())
+}
+
+// ---- oauth2 authorization codes ----
+
+func (d *DB) CreateOAuthAuthorization(ctx context.Context, a store.OAuthAuthorization) (*store.OAuthAuthorization, error) {
+	if err := putJSON(ctx, d.cli, "/sticky/oauth_authz/"+a.Code, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ConsumeOAuthAuthorization uses the same CAS-retry shape as SetPosture so
+// two concurrent token exchanges for the same code can't both succeed.
+func (d *DB) ConsumeOAuthAuthorization(ctx context.Context, code string) (*store.OAuthAuthorization, error) {
+	key := "/sticky/oauth_authz/" + code
+	for {
+		resp, err := d.cli.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Kvs) == 0 {
+			return nil, nil
+		}
+		var a store.OAuthAuthorization
+		if err := json.Unmarshal(resp.Kvs[0].Value, &a); err != nil {
+			return nil, err
+		}
+		if a.UsedAt != nil || time.Now().After(a.ExpiresAt) {
+			return nil, nil
+		}
+		now := time.Now().UTC()
+		a.UsedAt = &now
+		raw, err := json.Marshal(&a)
+		if err != nil {
+			return nil, err
+		}
+		txn := d.cli.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", resp.Kvs[0].ModRevision)).
+			Then(clientv3.OpPut(key, string(raw)))
+		txnResp, err := txn.Commit()
+		if err != nil {
+			return nil, err
+		}
+		if txnResp.Succeeded {
+			return &a, nil
+		}
+	}
+}
+
+// ---- oauth2 grants ----
+
+func (d *DB) CreateOAuthGrant(ctx context.Context, appID uuid.UUID, userID int64, refreshToken, scope string, expiresAt time.Time) (*store.OAuthGrant, error) {
+	g := &store.OAuthGrant{
+		ID: uuid.New(), AppID: appID, UserID: userID, RefreshToken: refreshToken,
+		Scope: scope, ExpiresAt: expiresAt, CreatedAt: time.Now().UTC(),
+	}
+	if err := putJSON(ctx, d.cli, "/sticky/oauth_grants/by_id/"+g.ID.String(), g); err != nil {
+		return nil, err
+	}
+	if _, err := d.cli.Put(ctx, "/sticky/oauth_grants/by_token/"+refreshToken, g.ID.String()); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (d *DB) GetOAuthGrantByRefreshToken(ctx context.Context, refreshToken string) (*store.OAuthGrant, error) {
+	resp, err := d.cli.Get(ctx, "/sticky/oauth_grants/by_token/"+refreshToken)
+	if err != nil || len(resp.Kvs) == 0 {
+		return nil, err
+	}
+	var g store.OAuthGrant
+	found, err := getJSON(ctx, d.cli, "/sticky/oauth_grants/by_id/"+string(resp.Kvs[0].Value), &g)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (d *DB) RevokeOAuthGrant(ctx context.Context, id uuid.UUID) error {
+	var g store.OAuthGrant
+	found, err := getJSON(ctx, d.cli, "/sticky/oauth_grants/by_id/"+id.String(), &g)
+	if err != nil || !found {
+		return err
+	}
+	now := time.Now().UTC()
+	g.RevokedAt = &now
+	return putJSON(ctx, d.cli, "/sticky/oauth_grants/by_id/"+id.String(), &g)
+}
+
+// ---- notification channels ----
+
+func (d *DB) CreateNotificationChannel(ctx context.Context, userID *int64, typ store.NotificationChannelType, target, secret string, events []string, template string) (*store.NotificationChannel, error) {
+	c := &store.NotificationChannel{
+		ID: uuid.New(), UserID: userID, Type: typ, Target: target, Secret: secret,
+		Events: events, Template: template, CreatedAt: time.Now().UTC(),
+	}
+	if err := putJSON(ctx, d.cli, "/sticky/notification_channels/"+c.ID.String(), c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (d *DB) GetNotificationChannel(ctx context.Context, id uuid.UUID) (*store.NotificationChannel, error) {
+	var c store.NotificationChannel
+	found, err := getJSON(ctx, d.cli, "/sticky/notification_channels/"+id.String(), &c)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (d *DB) listNotificationChannels(ctx context.Context, keep func(*store.NotificationChannel) bool) ([]*store.NotificationChannel, error) {
+	var channels []*store.NotificationChannel
+	err := listPrefix(ctx, d.cli, "/sticky/notification_channels/", func(v []byte) error {
+		var c store.NotificationChannel
+		if err := json.Unmarshal(v, &c); err != nil {
+			return err
+		}
+		if keep(&c) {
+			channels = append(channels, &c)
+		}
+		return nil
+	})
+	return channels, err
+}
+
+func (d *DB) ListNotificationChannelsByUser(ctx context.Context, userID int64) ([]*store.NotificationChannel, error) {
+	return d.listNotificationChannels(ctx, func(c *store.NotificationChannel) bool {
+		return c.UserID != nil && *c.UserID == userID
+	})
+}
+
+func (d *DB) ListGlobalNotificationChannels(ctx context.Context) ([]*store.NotificationChannel, error) {
+	return d.listNotificationChannels(ctx, func(c *store.NotificationChannel) bool { return c.UserID == nil })
+}
+
+func (d *DB) UpdateNotificationChannel(ctx context.Context, id uuid.UUID, target, secret string, events []string, template string) (*store.NotificationChannel, error) {
+	c, err := d.GetNotificationChannel(ctx, id)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	c.Target = target
+	c.Secret = secret
+	c.Events = events
+	c.Template = template
+	if err := putJSON(ctx, d.cli, "/sticky/notification_channels/"+id.String(), c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (d *DB) DeleteNotificationChannel(ctx context.Context, id uuid.UUID) error {
+	return deleteKey(ctx, d.cli, "/sticky/notification_channels/"+id.String())
+}
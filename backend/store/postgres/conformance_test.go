@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/store/conformance"
+)
+
+// TestConformance runs the shared store.Store suite against a real
+// PostgreSQL instance. Set TEST_POSTGRES_DSN to run it; otherwise it's
+// skipped, since CI/dev sandboxes without a database configured shouldn't
+// fail the rest of the suite.
+func TestConformance(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN not set; skipping postgres conformance suite")
+	}
+
+	db, err := Open(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+
+	conformance.Run(t, db)
+}
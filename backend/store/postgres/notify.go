@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
+)
+
+const (
+	notifyChannel = "sticky_dvr_events"
+
+	notifyReconnectInitialDelay = 1 * time.Second
+	notifyReconnectMaxDelay     = 30 * time.Second
+
+	// notifyCoalesceWindow is how long notifications are collected after the
+	// first one of a batch arrives; those for the same table+id within that
+	// window are delivered as a single event (the last one received), so
+	// a burst of UPDATEs (e.g. a subscription's posture flipping a few times
+	// in quick succession) doesn't thrash downstream consumers.
+	notifyCoalesceWindow = 200 * time.Millisecond
+
+	notifyChanBuffer = 64
+)
+
+// Subscribe acquires a dedicated pool connection, issues LISTEN on the
+// sticky_dvr_events channel (see migration 000008), and dispatches decoded
+// store.ChangeEvents on the returned channel until ctx is cancelled. The
+// connection is reacquired with exponential backoff if it drops; the
+// returned channel is closed once ctx is done.
+func (d *DB) Subscribe(ctx context.Context) (<-chan store.ChangeEvent, error) {
+	out := make(chan store.ChangeEvent, notifyChanBuffer)
+	go d.listenLoop(ctx, out)
+	return out, nil
+}
+
+func (d *DB) listenLoop(ctx context.Context, out chan<- store.ChangeEvent) {
+	defer close(out)
+	delay := notifyReconnectInitialDelay
+	for {
+		err := d.listenOnce(ctx, out)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			return
+		}
+		log.Printf("postgres: listen %s: %v, reconnecting in %s", notifyChannel, err, delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > notifyReconnectMaxDelay {
+			delay = notifyReconnectMaxDelay
+		}
+	}
+}
+
+// listenOnce holds one dedicated connection LISTENing until it errors or ctx
+// is cancelled (returning nil in the latter case so listenLoop doesn't log a
+// spurious reconnect). Notifications are coalesced per table+id over
+// notifyCoalesceWindow before being sent to out, so a rapid run of updates to
+// the same row is delivered once per window instead of once per notification.
+func (d *DB) listenOnce(ctx context.Context, out chan<- store.ChangeEvent) error {
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return err
+	}
+
+	raw := make(chan *pgconn.Notification)
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			n, err := conn.Conn().WaitForNotification(ctx)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			select {
+			case raw <- n:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	pending := make(map[string]store.ChangeEvent)
+	var timer *time.Timer
+	var flushAt <-chan time.Time
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errCh:
+			return err
+		case n := <-raw:
+			var ev store.ChangeEvent
+			if err := json.Unmarshal([]byte(n.Payload), &ev); err != nil {
+				log.Printf("postgres: listen: decode notify payload: %v", err)
+				continue
+			}
+			pending[fmt.Sprintf("%s:%d", ev.Table, ev.ID)] = ev
+			if timer == nil {
+				timer = time.NewTimer(notifyCoalesceWindow)
+				flushAt = timer.C
+			}
+		case <-flushAt:
+			for key, ev := range pending {
+				select {
+				case out <- ev:
+				default:
+					log.Printf("postgres: listen: subscriber channel full, dropping change event table=%s id=%d", ev.Table, ev.ID)
+				}
+				delete(pending, key)
+			}
+			timer = nil
+			flushAt = nil
+		}
+	}
+}
@@ -7,6 +7,10 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,6 +19,7 @@ import (
 	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/whisper-darkly/sticky-dvr/backend/auth"
@@ -24,13 +29,44 @@ import (
 //go:embed migrations/*.sql
 var migrationsFS embed.FS
 
+// pgxConn is the subset of *pgxpool.Pool and pgx.Tx that the query methods
+// below need. Every store.Store method is implemented once, against this
+// interface, so the same SQL runs unchanged whether DB dispatches it to the
+// pool or WithTx dispatches it to a transaction (see tx.go).
+type pgxConn interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// queries implements store.Store's SQL against whatever pgxConn it is
+// handed. DB embeds one bound to the pool; txStore embeds one bound to a
+// pgx.Tx. validate, if set, is consulted by SetConfig (see
+// WithConfigValidator) and is carried over verbatim when a queries is
+// rebound to a transaction.
+type queries struct {
+	conn     pgxConn
+	validate func(data map[string]any) error
+}
+
 // DB implements store.Store using PostgreSQL via pgx/v5.
 type DB struct {
 	pool *pgxpool.Pool
+	*queries
+}
+
+// Option configures optional behavior on Open.
+type Option func(*DB)
+
+// WithConfigValidator registers a hook that SetConfig runs against a
+// candidate payload before inserting it as a new config_versions row,
+// rejecting malformed config before it ever reaches the database.
+func WithConfigValidator(fn func(data map[string]any) error) Option {
+	return func(d *DB) { d.queries.validate = fn }
 }
 
 // Open creates a connection pool, runs migrations, and returns a ready DB.
-func Open(ctx context.Context, dsn string) (*DB, error) {
+func Open(ctx context.Context, dsn string, opts ...Option) (*DB, error) {
 	pool, err := pgxpool.New(ctx, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("pgxpool.New: %w", err)
@@ -46,7 +82,11 @@ func Open(ctx context.Context, dsn string) (*DB, error) {
 		return nil, fmt.Errorf("migrations: %w", err)
 	}
 
-	return &DB{pool: pool}, nil
+	db := &DB{pool: pool, queries: &queries{conn: pool}}
+	for _, opt := range opts {
+		opt(db)
+	}
+	return db, nil
 }
 
 // RunMigrations applies all pending up-migrations against dsn.
@@ -54,12 +94,40 @@ func Open(ctx context.Context, dsn string) (*DB, error) {
 // Called by initdb (as exported) and by Open (internally).
 func RunMigrations(dsn string) error { return runMigrations(dsn) }
 
+// migrationsTable is the table golang-migrate's postgres driver uses to
+// track applied versions — its name and (version bigint, dirty boolean)
+// shape are fixed by that driver, not chosen here.
+const migrationsTable = "schema_migrations"
+
 func runMigrations(dsn string) error {
+	// Check whether we're already at the latest embedded version using only
+	// SELECTs, so a role with no CREATE on the schema can run this as a
+	// no-op against an already-migrated database instead of failing on
+	// golang-migrate's internal CREATE TABLE IF NOT EXISTS schema_migrations.
+	current, err := isAlreadyAtLatestVersion(dsn)
+	if err != nil {
+		return fmt.Errorf("check migration version: %w", err)
+	}
+	if current {
+		return nil
+	}
+
 	src, err := iofs.New(migrationsFS, "migrations")
 	if err != nil {
 		return fmt.Errorf("iofs source: %w", err)
 	}
 	migrateURL := toMigrateURL(dsn)
+
+	// DB_MIGRATION_ROLE lets migrations run as a shared DDL role distinct
+	// from whatever DSN user initdb connects as, so object ownership stays
+	// stable across password rotations or DSN-user changes.
+	if role := os.Getenv("DB_MIGRATION_ROLE"); role != "" {
+		migrateURL, err = withSessionRole(migrateURL, role)
+		if err != nil {
+			return fmt.Errorf("DB_MIGRATION_ROLE: %w", err)
+		}
+	}
+
 	m, err := migrate.NewWithSourceInstance("iofs", src, migrateURL)
 	if err != nil {
 		return fmt.Errorf("migrate.New: %w", err)
@@ -70,6 +138,120 @@ func runMigrations(dsn string) error {
 	return nil
 }
 
+// isAlreadyAtLatestVersion reports whether dsn's database is already at the
+// highest version found in the embedded migrations, using only SELECTs.
+func isAlreadyAtLatestVersion(dsn string) (bool, error) {
+	latest, err := latestEmbeddedVersion()
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	version, dirty, err := CurrentVersion(ctx, dsn)
+	if err != nil {
+		return false, err
+	}
+	return !dirty && version == latest, nil
+}
+
+// latestEmbeddedVersion returns the highest version prefix among the
+// embedded migrations/<version>_<name>.up.sql files.
+func latestEmbeddedVersion() (uint, error) {
+	entries, err := migrationsFS.ReadDir("migrations")
+	if err != nil {
+		return 0, fmt.Errorf("read embedded migrations: %w", err)
+	}
+	var latest uint
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		if uint(n) > latest {
+			latest = uint(n)
+		}
+	}
+	return latest, nil
+}
+
+// CurrentVersion reports the applied migration version and dirty flag by
+// querying schema_migrations directly, issuing only SELECTs — safe to call
+// as a read-only or least-privilege role. Returns (0, false, nil) if
+// schema_migrations doesn't exist yet, i.e. no migration has ever run.
+// Intended for health checks and readiness probes that need to verify
+// schema version without DDL privileges.
+func CurrentVersion(ctx context.Context, dsn string) (version uint, dirty bool, err error) {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return 0, false, fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	var exists bool
+	err = conn.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1)`,
+		migrationsTable,
+	).Scan(&exists)
+	if err != nil {
+		return 0, false, fmt.Errorf("check %s existence: %w", migrationsTable, err)
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	var v int64
+	err = conn.QueryRow(ctx, fmt.Sprintf(`SELECT version, dirty FROM %s LIMIT 1`, migrationsTable)).Scan(&v, &dirty)
+	if err == pgx.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read %s: %w", migrationsTable, err)
+	}
+	return uint(v), dirty, nil
+}
+
+// migrationRolePattern matches a bare, unqualified SQL identifier: letters,
+// digits, and underscores, not starting with a digit. DB_MIGRATION_ROLE is
+// rejected if it doesn't match, since it's interpolated directly into a
+// connection-string option and (in cmd/initdb) into an ALTER DEFAULT
+// PRIVILEGES statement — neither accepts a bind parameter for a role name.
+var migrationRolePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateMigrationRole rejects anything that isn't a bare SQL identifier.
+// Exported so cmd/initdb can apply the same check before using
+// DB_MIGRATION_ROLE in its own ALTER DEFAULT PRIVILEGES FOR ROLE statement.
+func ValidateMigrationRole(role string) error {
+	if role != "" && !migrationRolePattern.MatchString(role) {
+		return fmt.Errorf("DB_MIGRATION_ROLE %q is not a valid identifier", role)
+	}
+	return nil
+}
+
+// withSessionRole appends libpq's "options=-c role=<role>" connection
+// parameter to migrateURL, so every statement golang-migrate issues over
+// that connection runs under role for its duration — equivalent to
+// executing SET ROLE immediately after connecting, but expressible entirely
+// in the DSN since golang-migrate owns the connection itself and gives
+// callers no hook to run a statement before Up().
+func withSessionRole(migrateURL, role string) (string, error) {
+	if err := ValidateMigrationRole(role); err != nil {
+		return "", err
+	}
+	u, err := url.Parse(migrateURL)
+	if err != nil {
+		return "", fmt.Errorf("parse migrate url: %w", err)
+	}
+	q := u.Query()
+	q.Set("options", "-c role="+role)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
 // toMigrateURL converts a postgres:// or postgresql:// DSN to the pgx5:// scheme
 // expected by golang-migrate's pgx/v5 driver.
 func toMigrateURL(dsn string) string {
@@ -107,9 +289,9 @@ func (d *DB) SeedAdminUser(ctx context.Context, username, password string) error
 
 // ---- users ----
 
-func (d *DB) CreateUser(ctx context.Context, username, passwordHash, role string) (*store.User, error) {
+func (q *queries) CreateUser(ctx context.Context, username, passwordHash, role string) (*store.User, error) {
 	var u store.User
-	err := d.pool.QueryRow(ctx, `
+	err := q.conn.QueryRow(ctx, `
 		INSERT INTO users (username, password_hash, role)
 		VALUES ($1, $2, $3)
 		RETURNING id, username, password_hash, role, created_at, updated_at
@@ -122,9 +304,9 @@ func (d *DB) CreateUser(ctx context.Context, username, passwordHash, role string
 	return &u, nil
 }
 
-func (d *DB) GetUser(ctx context.Context, id int64) (*store.User, error) {
+func (q *queries) GetUser(ctx context.Context, id int64) (*store.User, error) {
 	var u store.User
-	err := d.pool.QueryRow(ctx,
+	err := q.conn.QueryRow(ctx,
 		`SELECT id, username, password_hash, role, created_at, updated_at FROM users WHERE id = $1`, id,
 	).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt)
 	if err == pgx.ErrNoRows {
@@ -133,9 +315,9 @@ func (d *DB) GetUser(ctx context.Context, id int64) (*store.User, error) {
 	return &u, err
 }
 
-func (d *DB) GetUserByUsername(ctx context.Context, username string) (*store.User, error) {
+func (q *queries) GetUserByUsername(ctx context.Context, username string) (*store.User, error) {
 	var u store.User
-	err := d.pool.QueryRow(ctx,
+	err := q.conn.QueryRow(ctx,
 		`SELECT id, username, password_hash, role, created_at, updated_at FROM users WHERE username = $1`, username,
 	).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt)
 	if err == pgx.ErrNoRows {
@@ -144,8 +326,8 @@ func (d *DB) GetUserByUsername(ctx context.Context, username string) (*store.Use
 	return &u, err
 }
 
-func (d *DB) ListUsers(ctx context.Context) ([]*store.User, error) {
-	rows, err := d.pool.Query(ctx,
+func (q *queries) ListUsers(ctx context.Context) ([]*store.User, error) {
+	rows, err := q.conn.Query(ctx,
 		`SELECT id, username, password_hash, role, created_at, updated_at FROM users ORDER BY id`)
 	if err != nil {
 		return nil, err
@@ -163,9 +345,9 @@ func (d *DB) ListUsers(ctx context.Context) ([]*store.User, error) {
 	return users, rows.Err()
 }
 
-func (d *DB) UpdateUser(ctx context.Context, id int64, fields store.UserUpdate) (*store.User, error) {
+func (q *queries) UpdateUser(ctx context.Context, id int64, fields store.UserUpdate) (*store.User, error) {
 	var u store.User
-	err := d.pool.QueryRow(ctx, `
+	err := q.conn.QueryRow(ctx, `
 		UPDATE users SET
 			username      = COALESCE($2, username),
 			password_hash = COALESCE($3, password_hash),
@@ -181,50 +363,125 @@ func (d *DB) UpdateUser(ctx context.Context, id int64, fields store.UserUpdate)
 	return &u, err
 }
 
-func (d *DB) DeleteUser(ctx context.Context, id int64) error {
-	_, err := d.pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+func (q *queries) DeleteUser(ctx context.Context, id int64) error {
+	_, err := q.conn.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
 	return err
 }
 
 // ---- sessions ----
 
-func (d *DB) CreateSession(ctx context.Context, userID int64, refreshToken string, expiresAt time.Time) (*store.Session, error) {
+func (q *queries) CreateSession(ctx context.Context, userID int64, refreshToken string, expiresAt time.Time) (*store.Session, error) {
 	var s store.Session
-	err := d.pool.QueryRow(ctx, `
+	err := q.conn.QueryRow(ctx, `
 		INSERT INTO sessions (user_id, refresh_token, expires_at)
 		VALUES ($1, $2, $3)
-		RETURNING id, user_id, refresh_token, expires_at, created_at
+		RETURNING id, user_id, refresh_token, expires_at, created_at, family_id, parent_id, rotated_at, revoked_reason
 	`, userID, refreshToken, expiresAt).
-		Scan(&s.ID, &s.UserID, &s.RefreshToken, &s.ExpiresAt, &s.CreatedAt)
+		Scan(&s.ID, &s.UserID, &s.RefreshToken, &s.ExpiresAt, &s.CreatedAt, &s.FamilyID, &s.ParentID, &s.RotatedAt, &s.RevokedReason)
 	return &s, err
 }
 
-func (d *DB) GetSessionByRefreshToken(ctx context.Context, refreshToken string) (*store.Session, error) {
+func (q *queries) GetSessionByRefreshToken(ctx context.Context, refreshToken string) (*store.Session, error) {
 	var s store.Session
-	err := d.pool.QueryRow(ctx,
-		`SELECT id, user_id, refresh_token, expires_at, created_at FROM sessions WHERE refresh_token = $1`,
+	err := q.conn.QueryRow(ctx,
+		`SELECT id, user_id, refresh_token, expires_at, created_at, family_id, parent_id, rotated_at, revoked_reason
+		 FROM sessions WHERE refresh_token = $1`,
 		refreshToken,
-	).Scan(&s.ID, &s.UserID, &s.RefreshToken, &s.ExpiresAt, &s.CreatedAt)
+	).Scan(&s.ID, &s.UserID, &s.RefreshToken, &s.ExpiresAt, &s.CreatedAt, &s.FamilyID, &s.ParentID, &s.RotatedAt, &s.RevokedReason)
 	if err == pgx.ErrNoRows {
 		return nil, nil
 	}
 	return &s, err
 }
 
-func (d *DB) DeleteSession(ctx context.Context, id uuid.UUID) error {
-	_, err := d.pool.Exec(ctx, `DELETE FROM sessions WHERE id = $1`, id)
+func (q *queries) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	_, err := q.conn.Exec(ctx, `DELETE FROM sessions WHERE id = $1`, id)
+	return err
+}
+
+func (q *queries) DeleteExpiredSessions(ctx context.Context) error {
+	_, err := q.conn.Exec(ctx, `DELETE FROM sessions WHERE expires_at < now()`)
 	return err
 }
 
-func (d *DB) DeleteExpiredSessions(ctx context.Context) error {
-	_, err := d.pool.Exec(ctx, `DELETE FROM sessions WHERE expires_at < now()`)
+// RotateSession exchanges oldRefreshToken for a new session row in the same
+// family. The UPDATE ... WHERE rotated_at IS NULL AND revoked_reason IS NULL
+// is the atomicity boundary: only one concurrent caller can ever win it for
+// a given token, so two requests racing to rotate the same token can't both
+// succeed.
+func (q *queries) RotateSession(ctx context.Context, oldRefreshToken string) (*store.Session, error) {
+	var parentID uuid.UUID
+	var familyID uuid.UUID
+	var userID int64
+	var parentExpiresAt, parentCreatedAt time.Time
+	err := q.conn.QueryRow(ctx, `
+		UPDATE sessions SET rotated_at = now()
+		WHERE refresh_token = $1 AND rotated_at IS NULL AND revoked_reason IS NULL
+		RETURNING id, user_id, family_id, expires_at, created_at
+	`, oldRefreshToken).Scan(&parentID, &userID, &familyID, &parentExpiresAt, &parentCreatedAt)
+
+	if err == pgx.ErrNoRows {
+		return q.handleRotateMiss(ctx, oldRefreshToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	newToken, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	// Preserve the remaining TTL of the family rather than resetting it, so
+	// rotation alone can't be used to extend a session indefinitely.
+	newExpiresAt := parentExpiresAt
+
+	var s store.Session
+	err = q.conn.QueryRow(ctx, `
+		INSERT INTO sessions (user_id, refresh_token, expires_at, family_id, parent_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, user_id, refresh_token, expires_at, created_at, family_id, parent_id, rotated_at, revoked_reason
+	`, userID, newToken, newExpiresAt, familyID, parentID).
+		Scan(&s.ID, &s.UserID, &s.RefreshToken, &s.ExpiresAt, &s.CreatedAt, &s.FamilyID, &s.ParentID, &s.RotatedAt, &s.RevokedReason)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// handleRotateMiss runs when RotateSession's atomic update matched no row:
+// either the token doesn't exist, or it exists but was already rotated or
+// revoked — i.e. reuse of a token that's no longer the family's current one.
+func (q *queries) handleRotateMiss(ctx context.Context, oldRefreshToken string) (*store.Session, error) {
+	var familyID uuid.UUID
+	var rotatedAt *time.Time
+	var revokedReason *string
+	err := q.conn.QueryRow(ctx,
+		`SELECT family_id, rotated_at, revoked_reason FROM sessions WHERE refresh_token = $1`,
+		oldRefreshToken,
+	).Scan(&familyID, &rotatedAt, &revokedReason)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if revokeErr := q.RevokeFamily(ctx, familyID, "refresh token reuse detected"); revokeErr != nil {
+		return nil, revokeErr
+	}
+	return nil, store.ErrRefreshTokenReused
+}
+
+func (q *queries) RevokeFamily(ctx context.Context, familyID uuid.UUID, reason string) error {
+	_, err := q.conn.Exec(ctx,
+		`UPDATE sessions SET revoked_reason = $2 WHERE family_id = $1 AND revoked_reason IS NULL`,
+		familyID, reason)
 	return err
 }
 
 // ---- sources ----
 
-func (d *DB) GetOrCreateSource(ctx context.Context, driver, username string) (*store.Source, error) {
-	_, err := d.pool.Exec(ctx, `
+func (q *queries) GetOrCreateSource(ctx context.Context, driver, username string) (*store.Source, error) {
+	_, err := q.conn.Exec(ctx, `
 		INSERT INTO sources (driver, username)
 		VALUES ($1, $2)
 		ON CONFLICT (driver, username) DO NOTHING
@@ -232,13 +489,13 @@ func (d *DB) GetOrCreateSource(ctx context.Context, driver, username string) (*s
 	if err != nil {
 		return nil, err
 	}
-	return d.GetSourceByKey(ctx, driver, username)
+	return q.GetSourceByKey(ctx, driver, username)
 }
 
-func (d *DB) GetSourceByKey(ctx context.Context, driver, username string) (*store.Source, error) {
+func (q *queries) GetSourceByKey(ctx context.Context, driver, username string) (*store.Source, error) {
 	var s store.Source
 	var taskID *string
-	err := d.pool.QueryRow(ctx,
+	err := q.conn.QueryRow(ctx,
 		`SELECT id, driver, username, overseer_task_id, created_at FROM sources WHERE driver = $1 AND username = $2`,
 		driver, username,
 	).Scan(&s.ID, &s.Driver, &s.Username, &taskID, &s.CreatedAt)
@@ -254,8 +511,8 @@ func (d *DB) GetSourceByKey(ctx context.Context, driver, username string) (*stor
 	return &s, nil
 }
 
-func (d *DB) ListSources(ctx context.Context) ([]*store.Source, error) {
-	rows, err := d.pool.Query(ctx,
+func (q *queries) ListSources(ctx context.Context) ([]*store.Source, error) {
+	rows, err := q.conn.Query(ctx,
 		`SELECT id, driver, username, overseer_task_id, created_at FROM sources ORDER BY driver, username`)
 	if err != nil {
 		return nil, err
@@ -277,17 +534,17 @@ func (d *DB) ListSources(ctx context.Context) ([]*store.Source, error) {
 	return sources, rows.Err()
 }
 
-func (d *DB) SetSourceTaskID(ctx context.Context, sourceID int64, taskID string) error {
-	_, err := d.pool.Exec(ctx,
+func (q *queries) SetSourceTaskID(ctx context.Context, sourceID int64, taskID string) error {
+	_, err := q.conn.Exec(ctx,
 		`UPDATE sources SET overseer_task_id = $2 WHERE id = $1`, sourceID, taskID)
 	return err
 }
 
 // ---- subscriptions ----
 
-func (d *DB) CreateSubscription(ctx context.Context, userID, sourceID int64) (*store.Subscription, error) {
+func (q *queries) CreateSubscription(ctx context.Context, userID, sourceID int64) (*store.Subscription, error) {
 	var sub store.Subscription
-	err := d.pool.QueryRow(ctx, `
+	err := q.conn.QueryRow(ctx, `
 		INSERT INTO subscriptions (user_id, source_id, posture)
 		VALUES ($1, $2, 'active')
 		ON CONFLICT (user_id, source_id) DO UPDATE
@@ -298,9 +555,9 @@ func (d *DB) CreateSubscription(ctx context.Context, userID, sourceID int64) (*s
 	return &sub, err
 }
 
-func (d *DB) GetSubscription(ctx context.Context, userID, sourceID int64) (*store.Subscription, error) {
+func (q *queries) GetSubscription(ctx context.Context, userID, sourceID int64) (*store.Subscription, error) {
 	var sub store.Subscription
-	err := d.pool.QueryRow(ctx, `
+	err := q.conn.QueryRow(ctx, `
 		SELECT id, user_id, source_id, posture, created_at, updated_at
 		FROM subscriptions WHERE user_id = $1 AND source_id = $2
 	`, userID, sourceID).
@@ -311,43 +568,62 @@ func (d *DB) GetSubscription(ctx context.Context, userID, sourceID int64) (*stor
 	return &sub, err
 }
 
-func (d *DB) ListSubscriptionsByUser(ctx context.Context, userID int64) ([]*store.Subscription, error) {
-	return d.querySubs(ctx, `
+func (q *queries) ListSubscriptionsByUser(ctx context.Context, userID int64) ([]*store.Subscription, error) {
+	return q.querySubs(ctx, `
 		SELECT id, user_id, source_id, posture, created_at, updated_at
 		FROM subscriptions WHERE user_id = $1 ORDER BY id
 	`, userID)
 }
 
-func (d *DB) ListActiveSubscriptions(ctx context.Context) ([]*store.Subscription, error) {
-	return d.querySubs(ctx, `
+func (q *queries) ListActiveSubscriptions(ctx context.Context) ([]*store.Subscription, error) {
+	return q.querySubs(ctx, `
 		SELECT id, user_id, source_id, posture, created_at, updated_at
 		FROM subscriptions WHERE posture = 'active' ORDER BY source_id, user_id
 	`)
 }
 
-func (d *DB) ListAllSubscriptions(ctx context.Context) ([]*store.Subscription, error) {
-	return d.querySubs(ctx, `
+func (q *queries) ListAllSubscriptions(ctx context.Context) ([]*store.Subscription, error) {
+	return q.querySubs(ctx, `
 		SELECT id, user_id, source_id, posture, created_at, updated_at
 		FROM subscriptions ORDER BY id
 	`)
 }
 
-func (d *DB) SetPosture(ctx context.Context, id int64, posture store.Posture) error {
-	_, err := d.pool.Exec(ctx,
+func (q *queries) SetPosture(ctx context.Context, id int64, posture store.Posture) error {
+	_, err := q.conn.Exec(ctx,
 		`UPDATE subscriptions SET posture = $2, updated_at = now() WHERE id = $1`, id, string(posture))
 	return err
 }
 
-func (d *DB) GetSourceActiveSubscriberCount(ctx context.Context, sourceID int64) (int, error) {
+func (q *queries) GetSourceActiveSubscriberCount(ctx context.Context, sourceID int64) (int, error) {
 	var count int
-	err := d.pool.QueryRow(ctx,
+	err := q.conn.QueryRow(ctx,
 		`SELECT COUNT(*) FROM subscriptions WHERE source_id = $1 AND posture = 'active'`, sourceID,
 	).Scan(&count)
 	return count, err
 }
 
-func (d *DB) querySubs(ctx context.Context, q string, args ...any) ([]*store.Subscription, error) {
-	rows, err := d.pool.Query(ctx, q, args...)
+func (q *queries) GetSourceSubscriberUserIDs(ctx context.Context, sourceID int64) ([]int64, error) {
+	rows, err := q.conn.Query(ctx,
+		`SELECT user_id FROM subscriptions WHERE source_id = $1 AND posture != 'archived'`, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (q *queries) querySubs(ctx context.Context, sql string, args ...any) ([]*store.Subscription, error) {
+	rows, err := q.conn.Query(ctx, sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -366,17 +642,43 @@ func (d *DB) querySubs(ctx context.Context, q string, args ...any) ([]*store.Sub
 
 // ---- worker events ----
 
-func (d *DB) RecordWorkerEvent(ctx context.Context, sourceID int64, pid int, eventType store.EventType, exitCode *int) error {
-	_, err := d.pool.Exec(ctx, `
-		INSERT INTO worker_events (source_id, pid, event_type, exit_code)
-		VALUES ($1, $2, $3, $4)
-	`, sourceID, pid, string(eventType), exitCode)
+func (q *queries) RecordWorkerEvent(ctx context.Context, sourceID int64, pid int, eventType store.EventType, exitCode *int, userID *int64, reason *string) error {
+	_, err := q.conn.Exec(ctx, `
+		INSERT INTO worker_events (source_id, pid, event_type, exit_code, user_id, reason)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, sourceID, pid, string(eventType), exitCode, userID, reason)
+	return err
+}
+
+// RecordWorkerEvents persists a coalesced batch: a single input is inserted
+// plainly, while a run of several is collapsed into one row carrying the last
+// input's fields plus the full sequence (minus the transient Intentional flag)
+// as a JSON array in detail.
+func (q *queries) RecordWorkerEvents(ctx context.Context, sourceID int64, events []store.WorkerEventInput) error {
+	if len(events) == 0 {
+		return nil
+	}
+	last := events[len(events)-1]
+
+	var detail []byte
+	if len(events) > 1 {
+		b, err := json.Marshal(events)
+		if err != nil {
+			return err
+		}
+		detail = b
+	}
+
+	_, err := q.conn.Exec(ctx, `
+		INSERT INTO worker_events (source_id, pid, event_type, exit_code, user_id, reason, detail)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, sourceID, last.PID, string(last.EventType), last.ExitCode, last.UserID, last.Reason, detail)
 	return err
 }
 
-func (d *DB) RecentWorkerEvents(ctx context.Context, sourceID int64, limit int) ([]store.WorkerEvent, error) {
-	rows, err := d.pool.Query(ctx, `
-		SELECT id, source_id, pid, event_type, exit_code, ts
+func (q *queries) RecentWorkerEvents(ctx context.Context, sourceID int64, limit int) ([]store.WorkerEvent, error) {
+	rows, err := q.conn.Query(ctx, `
+		SELECT id, source_id, pid, event_type, exit_code, user_id, reason, detail, ts
 		FROM worker_events
 		WHERE source_id = $1
 		ORDER BY ts DESC, id DESC
@@ -391,7 +693,7 @@ func (d *DB) RecentWorkerEvents(ctx context.Context, sourceID int64, limit int)
 	for rows.Next() {
 		var ev store.WorkerEvent
 		var et string
-		if err := rows.Scan(&ev.ID, &ev.SourceID, &ev.PID, &et, &ev.ExitCode, &ev.TS); err != nil {
+		if err := rows.Scan(&ev.ID, &ev.SourceID, &ev.PID, &et, &ev.ExitCode, &ev.UserID, &ev.Reason, &ev.Detail, &ev.TS); err != nil {
 			return nil, err
 		}
 		ev.EventType = store.EventType(et)
@@ -400,11 +702,138 @@ func (d *DB) RecentWorkerEvents(ctx context.Context, sourceID int64, limit int)
 	return events, rows.Err()
 }
 
+// ---- resource samples ----
+
+func (q *queries) RecordResourceSample(ctx context.Context, sourceID int64, sample store.ResourceSample) error {
+	_, err := q.conn.Exec(ctx, `
+		INSERT INTO resource_samples (source_id, cpu_percent, rss_bytes, segment_bytes, bytes_per_sec, disk_free_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, sourceID, sample.CPUPercent, sample.RSSBytes, sample.SegmentBytes, sample.BytesPerSec, sample.DiskFreeBytes)
+	return err
+}
+
+func (q *queries) RecentResourceSamples(ctx context.Context, sourceID int64, limit int) ([]store.ResourceSample, error) {
+	rows, err := q.conn.Query(ctx, `
+		SELECT id, source_id, cpu_percent, rss_bytes, segment_bytes, bytes_per_sec, disk_free_bytes, ts
+		FROM resource_samples
+		WHERE source_id = $1
+		ORDER BY ts DESC, id DESC
+		LIMIT $2
+	`, sourceID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []store.ResourceSample
+	for rows.Next() {
+		var s store.ResourceSample
+		if err := rows.Scan(&s.ID, &s.SourceID, &s.CPUPercent, &s.RSSBytes, &s.SegmentBytes, &s.BytesPerSec, &s.DiskFreeBytes, &s.TS); err != nil {
+			return nil, err
+		}
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
+
+// ---- roles ----
+
+func (q *queries) CreateRole(ctx context.Context, name string, permissions []string) (*store.Role, error) {
+	_, err := q.conn.Exec(ctx, `
+		INSERT INTO roles (name, permissions) VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET permissions = $2
+	`, name, permissions)
+	if err != nil {
+		return nil, err
+	}
+	return &store.Role{Name: name, Permissions: permissions}, nil
+}
+
+func (q *queries) AssignRole(ctx context.Context, userID int64, roleName string) error {
+	_, err := q.conn.Exec(ctx,
+		`UPDATE users SET role = $2, updated_at = now() WHERE id = $1`, userID, roleName)
+	return err
+}
+
+func (q *queries) ListRoles(ctx context.Context) ([]*store.Role, error) {
+	rows, err := q.conn.Query(ctx, `SELECT name, permissions FROM roles ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []*store.Role
+	for rows.Next() {
+		var r store.Role
+		if err := rows.Scan(&r.Name, &r.Permissions); err != nil {
+			return nil, err
+		}
+		roles = append(roles, &r)
+	}
+	return roles, rows.Err()
+}
+
+// ---- client certs ----
+
+func (q *queries) EnrollClientCert(ctx context.Context, fingerprint string, userID int64, label string) (*store.ClientCert, error) {
+	var c store.ClientCert
+	err := q.conn.QueryRow(ctx, `
+		INSERT INTO client_certs (fingerprint, user_id, label)
+		VALUES ($1, $2, $3)
+		RETURNING fingerprint, user_id, label, created_at, revoked_at
+	`, fingerprint, userID, label).
+		Scan(&c.Fingerprint, &c.UserID, &c.Label, &c.CreatedAt, &c.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (q *queries) GetClientCertByFingerprint(ctx context.Context, fingerprint string) (*store.ClientCert, error) {
+	var c store.ClientCert
+	err := q.conn.QueryRow(ctx,
+		`SELECT fingerprint, user_id, label, created_at, revoked_at FROM client_certs WHERE fingerprint = $1`,
+		fingerprint,
+	).Scan(&c.Fingerprint, &c.UserID, &c.Label, &c.CreatedAt, &c.RevokedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (q *queries) RevokeClientCert(ctx context.Context, fingerprint string) error {
+	_, err := q.conn.Exec(ctx,
+		`UPDATE client_certs SET revoked_at = now() WHERE fingerprint = $1`, fingerprint)
+	return err
+}
+
+func (q *queries) ListClientCerts(ctx context.Context) ([]*store.ClientCert, error) {
+	rows, err := q.conn.Query(ctx,
+		`SELECT fingerprint, user_id, label, created_at, revoked_at FROM client_certs ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var certs []*store.ClientCert
+	for rows.Next() {
+		var c store.ClientCert
+		if err := rows.Scan(&c.Fingerprint, &c.UserID, &c.Label, &c.CreatedAt, &c.RevokedAt); err != nil {
+			return nil, err
+		}
+		certs = append(certs, &c)
+	}
+	return certs, rows.Err()
+}
+
 // ---- config ----
 
-func (d *DB) GetConfig(ctx context.Context) (map[string]any, error) {
+func (q *queries) GetConfig(ctx context.Context) (map[string]any, error) {
 	var raw []byte
-	err := d.pool.QueryRow(ctx, `SELECT data FROM config WHERE id = 1`).Scan(&raw)
+	err := q.conn.QueryRow(ctx, `SELECT data FROM config_current`).Scan(&raw)
 	if err == pgx.ErrNoRows {
 		return map[string]any{}, nil
 	}
@@ -418,14 +847,386 @@ func (d *DB) GetConfig(ctx context.Context) (map[string]any, error) {
 	return m, nil
 }
 
-func (d *DB) SetConfig(ctx context.Context, data map[string]any) error {
+func (q *queries) SetConfig(ctx context.Context, data map[string]any, authorID *int64, comment string) (*store.ConfigVersion, error) {
+	if q.validate != nil {
+		if err := q.validate(data); err != nil {
+			return nil, fmt.Errorf("config validation: %w", err)
+		}
+	}
 	raw, err := json.Marshal(data)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = d.pool.Exec(ctx, `
-		INSERT INTO config (id, data) VALUES (1, $1)
-		ON CONFLICT (id) DO UPDATE SET data = $1
-	`, raw)
+	return q.insertConfigVersion(ctx, raw, authorID, comment)
+}
+
+func (q *queries) insertConfigVersion(ctx context.Context, raw []byte, authorID *int64, comment string) (*store.ConfigVersion, error) {
+	var v store.ConfigVersion
+	err := q.conn.QueryRow(ctx, `
+		INSERT INTO config_versions (data, author_id, comment)
+		VALUES ($1, $2, $3)
+		RETURNING id, data, author_id, comment, created_at
+	`, raw, authorID, comment).
+		Scan(&v.ID, &v.Data, &v.AuthorID, &v.Comment, &v.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (q *queries) ListConfigVersions(ctx context.Context, limit, offset int) ([]*store.ConfigVersion, error) {
+	rows, err := q.conn.Query(ctx, `
+		SELECT id, data, author_id, comment, created_at FROM config_versions
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []*store.ConfigVersion
+	for rows.Next() {
+		var v store.ConfigVersion
+		if err := rows.Scan(&v.ID, &v.Data, &v.AuthorID, &v.Comment, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, &v)
+	}
+	return versions, rows.Err()
+}
+
+func (q *queries) GetConfigVersion(ctx context.Context, id int64) (*store.ConfigVersion, error) {
+	var v store.ConfigVersion
+	err := q.conn.QueryRow(ctx,
+		`SELECT id, data, author_id, comment, created_at FROM config_versions WHERE id = $1`, id,
+	).Scan(&v.ID, &v.Data, &v.AuthorID, &v.Comment, &v.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (q *queries) RollbackConfig(ctx context.Context, versionID int64, authorID *int64, comment string) (*store.ConfigVersion, error) {
+	old, err := q.GetConfigVersion(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if old == nil {
+		return nil, fmt.Errorf("config version %d not found", versionID)
+	}
+	if comment == "" {
+		comment = fmt.Sprintf("rollback to version %d", versionID)
+	}
+	return q.insertConfigVersion(ctx, old.Data, authorID, comment)
+}
+
+// ---- admin tasks ----
+
+func (q *queries) CreateAdminTask(ctx context.Context, kind string, doerID int64, payload json.RawMessage, total int) (*store.AdminTask, error) {
+	if payload == nil {
+		payload = json.RawMessage("{}")
+	}
+	var t store.AdminTask
+	err := q.conn.QueryRow(ctx, `
+		INSERT INTO admin_tasks (kind, doer_id, payload, total)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, kind, doer_id, payload, status, progress, total, message, started_at, finished_at, error, created_at
+	`, kind, doerID, payload, total).
+		Scan(&t.ID, &t.Kind, &t.DoerID, &t.Payload, &t.Status, &t.Progress, &t.Total, &t.Message, &t.StartedAt, &t.FinishedAt, &t.Error, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (q *queries) UpdateAdminTaskProgress(ctx context.Context, id uuid.UUID, progress int, message string) error {
+	_, err := q.conn.Exec(ctx, `
+		UPDATE admin_tasks SET
+			status     = 'running',
+			progress   = $2,
+			message    = $3,
+			started_at = COALESCE(started_at, now())
+		WHERE id = $1
+	`, id, progress, message)
+	return err
+}
+
+func (q *queries) MarkAdminTaskDone(ctx context.Context, id uuid.UUID, status store.AdminTaskStatus, taskErr error) error {
+	var errText *string
+	if taskErr != nil {
+		s := taskErr.Error()
+		errText = &s
+	}
+	_, err := q.conn.Exec(ctx, `
+		UPDATE admin_tasks SET
+			status      = $2,
+			error       = $3,
+			finished_at = now()
+		WHERE id = $1
+	`, id, status, errText)
+	return err
+}
+
+func (q *queries) ListAdminTasks(ctx context.Context, filter store.AdminTaskFilter) ([]*store.AdminTask, error) {
+	rows, err := q.conn.Query(ctx, `
+		SELECT id, kind, doer_id, payload, status, progress, total, message, started_at, finished_at, error, created_at
+		FROM admin_tasks
+		WHERE ($1 = '' OR kind = $1) AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+	`, filter.Kind, filter.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*store.AdminTask
+	for rows.Next() {
+		var t store.AdminTask
+		if err := rows.Scan(&t.ID, &t.Kind, &t.DoerID, &t.Payload, &t.Status, &t.Progress, &t.Total, &t.Message, &t.StartedAt, &t.FinishedAt, &t.Error, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, &t)
+	}
+	return tasks, rows.Err()
+}
+
+func (q *queries) GetAdminTask(ctx context.Context, id uuid.UUID) (*store.AdminTask, error) {
+	var t store.AdminTask
+	err := q.conn.QueryRow(ctx, `
+		SELECT id, kind, doer_id, payload, status, progress, total, message, started_at, finished_at, error, created_at
+		FROM admin_tasks WHERE id = $1
+	`, id).Scan(&t.ID, &t.Kind, &t.DoerID, &t.Payload, &t.Status, &t.Progress, &t.Total, &t.Message, &t.StartedAt, &t.FinishedAt, &t.Error, &t.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ---- oauth2 apps ----
+
+func (q *queries) CreateOAuthApp(ctx context.Context, name string, ownerID int64, redirectURI, clientID, clientSecretHash string) (*store.OAuthApp, error) {
+	var a store.OAuthApp
+	err := q.conn.QueryRow(ctx, `
+		INSERT INTO oauth_apps (name, owner_id, redirect_uri, client_id, client_secret_hash)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, name, client_id, client_secret_hash, redirect_uri, owner_id, created_at
+	`, name, ownerID, redirectURI, clientID, clientSecretHash).
+		Scan(&a.ID, &a.Name, &a.ClientID, &a.ClientSecretHash, &a.RedirectURI, &a.OwnerID, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (q *queries) GetOAuthApp(ctx context.Context, id uuid.UUID) (*store.OAuthApp, error) {
+	var a store.OAuthApp
+	err := q.conn.QueryRow(ctx,
+		`SELECT id, name, client_id, client_secret_hash, redirect_uri, owner_id, created_at FROM oauth_apps WHERE id = $1`,
+		id,
+	).Scan(&a.ID, &a.Name, &a.ClientID, &a.ClientSecretHash, &a.RedirectURI, &a.OwnerID, &a.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (q *queries) GetOAuthAppByClientID(ctx context.Context, clientID string) (*store.OAuthApp, error) {
+	var a store.OAuthApp
+	err := q.conn.QueryRow(ctx,
+		`SELECT id, name, client_id, client_secret_hash, redirect_uri, owner_id, created_at FROM oauth_apps WHERE client_id = $1`,
+		clientID,
+	).Scan(&a.ID, &a.Name, &a.ClientID, &a.ClientSecretHash, &a.RedirectURI, &a.OwnerID, &a.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (q *queries) ListOAuthApps(ctx context.Context) ([]*store.OAuthApp, error) {
+	rows, err := q.conn.Query(ctx,
+		`SELECT id, name, client_id, client_secret_hash, redirect_uri, owner_id, created_at FROM oauth_apps ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	apps := []*store.OAuthApp{}
+	for rows.Next() {
+		var a store.OAuthApp
+		if err := rows.Scan(&a.ID, &a.Name, &a.ClientID, &a.ClientSecretHash, &a.RedirectURI, &a.OwnerID, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		apps = append(apps, &a)
+	}
+	return apps, rows.Err()
+}
+
+func (q *queries) DeleteOAuthApp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.conn.Exec(ctx, `DELETE FROM oauth_apps WHERE id = $1`, id)
+	return err
+}
+
+// ---- oauth2 authorization codes ----
+
+func (q *queries) CreateOAuthAuthorization(ctx context.Context, a store.OAuthAuthorization) (*store.OAuthAuthorization, error) {
+	_, err := q.conn.Exec(ctx, `
+		INSERT INTO oauth_authorizations (code, app_id, user_id, redirect_uri, scope, code_challenge, code_challenge_method, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, a.Code, a.AppID, a.UserID, a.RedirectURI, a.Scope, a.CodeChallenge, a.CodeChallengeMethod, a.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ConsumeOAuthAuthorization's UPDATE ... WHERE used_at IS NULL AND
+// expires_at > now() is the atomicity boundary, mirroring RotateSession: the
+// first caller to hit this for a given code wins it, so a code can never be
+// exchanged for more than one token pair even if two requests race.
+func (q *queries) ConsumeOAuthAuthorization(ctx context.Context, code string) (*store.OAuthAuthorization, error) {
+	var a store.OAuthAuthorization
+	err := q.conn.QueryRow(ctx, `
+		UPDATE oauth_authorizations SET used_at = now()
+		WHERE code = $1 AND used_at IS NULL AND expires_at > now()
+		RETURNING code, app_id, user_id, redirect_uri, scope, code_challenge, code_challenge_method, expires_at, used_at
+	`, code).Scan(&a.Code, &a.AppID, &a.UserID, &a.RedirectURI, &a.Scope, &a.CodeChallenge, &a.CodeChallengeMethod, &a.ExpiresAt, &a.UsedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ---- oauth2 grants (refresh tokens) ----
+
+func (q *queries) CreateOAuthGrant(ctx context.Context, appID uuid.UUID, userID int64, refreshToken, scope string, expiresAt time.Time) (*store.OAuthGrant, error) {
+	var g store.OAuthGrant
+	err := q.conn.QueryRow(ctx, `
+		INSERT INTO oauth_grants (app_id, user_id, refresh_token, scope, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, app_id, user_id, refresh_token, scope, expires_at, created_at, revoked_at
+	`, appID, userID, refreshToken, scope, expiresAt).
+		Scan(&g.ID, &g.AppID, &g.UserID, &g.RefreshToken, &g.Scope, &g.ExpiresAt, &g.CreatedAt, &g.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (q *queries) GetOAuthGrantByRefreshToken(ctx context.Context, refreshToken string) (*store.OAuthGrant, error) {
+	var g store.OAuthGrant
+	err := q.conn.QueryRow(ctx,
+		`SELECT id, app_id, user_id, refresh_token, scope, expires_at, created_at, revoked_at FROM oauth_grants WHERE refresh_token = $1`,
+		refreshToken,
+	).Scan(&g.ID, &g.AppID, &g.UserID, &g.RefreshToken, &g.Scope, &g.ExpiresAt, &g.CreatedAt, &g.RevokedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (q *queries) RevokeOAuthGrant(ctx context.Context, id uuid.UUID) error {
+	_, err := q.conn.Exec(ctx,
+		`UPDATE oauth_grants SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, id)
+	return err
+}
+
+// ---- notification channels ----
+
+func (q *queries) CreateNotificationChannel(ctx context.Context, userID *int64, typ store.NotificationChannelType, target, secret string, events []string, template string) (*store.NotificationChannel, error) {
+	var c store.NotificationChannel
+	err := q.conn.QueryRow(ctx, `
+		INSERT INTO notification_channels (user_id, type, target, secret, events, template)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, user_id, type, target, secret, events, template, created_at
+	`, userID, string(typ), target, secret, events, template).
+		Scan(&c.ID, &c.UserID, &c.Type, &c.Target, &c.Secret, &c.Events, &c.Template, &c.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (q *queries) GetNotificationChannel(ctx context.Context, id uuid.UUID) (*store.NotificationChannel, error) {
+	var c store.NotificationChannel
+	err := q.conn.QueryRow(ctx,
+		`SELECT id, user_id, type, target, secret, events, template, created_at FROM notification_channels WHERE id = $1`,
+		id,
+	).Scan(&c.ID, &c.UserID, &c.Type, &c.Target, &c.Secret, &c.Events, &c.Template, &c.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (q *queries) ListNotificationChannelsByUser(ctx context.Context, userID int64) ([]*store.NotificationChannel, error) {
+	return q.queryNotificationChannels(ctx,
+		`SELECT id, user_id, type, target, secret, events, template, created_at FROM notification_channels WHERE user_id = $1 ORDER BY created_at`,
+		userID)
+}
+
+func (q *queries) ListGlobalNotificationChannels(ctx context.Context) ([]*store.NotificationChannel, error) {
+	return q.queryNotificationChannels(ctx,
+		`SELECT id, user_id, type, target, secret, events, template, created_at FROM notification_channels WHERE user_id IS NULL ORDER BY created_at`)
+}
+
+func (q *queries) queryNotificationChannels(ctx context.Context, sql string, args ...any) ([]*store.NotificationChannel, error) {
+	rows, err := q.conn.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	channels := []*store.NotificationChannel{}
+	for rows.Next() {
+		var c store.NotificationChannel
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Type, &c.Target, &c.Secret, &c.Events, &c.Template, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		channels = append(channels, &c)
+	}
+	return channels, rows.Err()
+}
+
+func (q *queries) UpdateNotificationChannel(ctx context.Context, id uuid.UUID, target, secret string, events []string, template string) (*store.NotificationChannel, error) {
+	var c store.NotificationChannel
+	err := q.conn.QueryRow(ctx, `
+		UPDATE notification_channels SET target = $2, secret = $3, events = $4, template = $5
+		WHERE id = $1
+		RETURNING id, user_id, type, target, secret, events, template, created_at
+	`, id, target, secret, events, template).
+		Scan(&c.ID, &c.UserID, &c.Type, &c.Target, &c.Secret, &c.Events, &c.Template, &c.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (q *queries) DeleteNotificationChannel(ctx context.Context, id uuid.UUID) error {
+	_, err := q.conn.Exec(ctx, `DELETE FROM notification_channels WHERE id = $1`, id)
 	return err
 }
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/store"
+)
+
+// pgSerializationFailure is the SQLSTATE pgx/postgres return when a
+// transaction at Repeatable Read or Serializable isolation can't be
+// committed because of a conflicting concurrent transaction.
+const pgSerializationFailure = "40001"
+
+const (
+	txMaxAttempts  = 5
+	txInitialDelay = 20 * time.Millisecond
+	txMaxDelay     = 2 * time.Second
+)
+
+// txStore implements store.Tx by running every query method against a
+// single pgx.Tx instead of the pool, via the pgxConn interface shared with
+// DB (see postgres.go). Close is a no-op: WithTx owns the transaction's
+// commit/rollback.
+type txStore struct {
+	*queries
+}
+
+func (t *txStore) Close() error { return nil }
+
+// WithTx opens a pgx.Tx from the pool, runs fn against a store.Tx backed by
+// it, and commits on a nil return or rolls back otherwise. A transaction
+// that fails to commit with a serialization failure (SQLSTATE 40001) —
+// possible for callers running at Repeatable Read or Serializable isolation
+// — is retried with exponential backoff rather than surfaced to the
+// caller, since that failure means "retry me", not "this failed".
+func (d *DB) WithTx(ctx context.Context, fn func(store.Tx) error) error {
+	delay := txInitialDelay
+	var lastErr error
+	for attempt := 1; attempt <= txMaxAttempts; attempt++ {
+		err := d.runTx(ctx, fn)
+		if err == nil {
+			return nil
+		}
+		if !isSerializationFailure(err) {
+			return err
+		}
+		lastErr = err
+		if attempt == txMaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > txMaxDelay {
+			delay = txMaxDelay
+		}
+	}
+	return fmt.Errorf("postgres: WithTx: giving up after %d attempts on serialization failure: %w", txMaxAttempts, lastErr)
+}
+
+func (d *DB) runTx(ctx context.Context, fn func(store.Tx) error) (err error) {
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	if err = fn(&txStore{queries: &queries{conn: tx, validate: d.queries.validate}}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgSerializationFailure
+	}
+	return false
+}
@@ -3,11 +3,20 @@ package store
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrRefreshTokenReused is returned by RotateSession when the presented
+// refresh token was already rotated (or its session already revoked) —
+// someone is replaying a stolen token. Callers should treat this as a
+// signal to force re-authentication; the whole session family has already
+// been revoked by the time this is returned.
+var ErrRefreshTokenReused = errors.New("store: refresh token already rotated or revoked")
+
 // ---- posture ----
 
 // Posture is the user-level intent for a subscription.
@@ -28,6 +37,17 @@ const (
 	EventStarted EventType = "started"
 	EventExited  EventType = "exited"
 	EventStopped EventType = "stopped"
+	// EventStalled marks a process that is still alive but whose output segment
+	// has stopped growing while recording — a failure mode overseer's exit-code
+	// retry policy can't see on its own.
+	EventStalled EventType = "stalled"
+	// EventRestartRequested marks an operator-initiated restart via Manager.Restart,
+	// as distinct from an overseer-driven restart after a crash.
+	EventRestartRequested EventType = "restart_requested"
+	// EventRestarting marks the overseer scheduling an automatic restart after a crash.
+	EventRestarting EventType = "restarting"
+	// EventErrored marks the overseer giving up retrying after its error threshold.
+	EventErrored EventType = "errored"
 )
 
 // ---- domain types ----
@@ -53,6 +73,16 @@ type Session struct {
 	RefreshToken string    `json:"-"`
 	ExpiresAt    time.Time `json:"expires_at"`
 	CreatedAt    time.Time `json:"created_at"`
+
+	// FamilyID groups a login and every session it is rotated into. ParentID
+	// is the session this one was rotated from (nil for the session created
+	// at login). RotatedAt is set once this session's refresh token has been
+	// exchanged for a child session; a second presentation of that token
+	// after RotatedAt is set is refresh-token reuse (see RotateSession).
+	FamilyID      uuid.UUID  `json:"family_id"`
+	ParentID      *uuid.UUID `json:"parent_id,omitempty"`
+	RotatedAt     *time.Time `json:"rotated_at,omitempty"`
+	RevokedReason *string    `json:"revoked_reason,omitempty"`
 }
 
 type Source struct {
@@ -72,13 +102,207 @@ type Subscription struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Role is a named set of permissions that can be assigned to a user via
+// UserUpdate.Role / AssignRole. Permission values are opaque strings here to
+// avoid a dependency on the middleware package; middleware.Permission is the
+// typed equivalent used when checking access.
+type Role struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+// ClientCert is an enrolled mTLS client certificate used as an alternative
+// to a long-lived JWT for headless recorder nodes and scripts.
+type ClientCert struct {
+	Fingerprint string     `json:"fingerprint"`
+	UserID      int64      `json:"user_id"`
+	Label       string     `json:"label"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+}
+
 type WorkerEvent struct {
 	ID        int64     `json:"id"`
 	SourceID  int64     `json:"source_id"`
 	PID       int       `json:"pid"`
 	EventType EventType `json:"event_type"`
 	ExitCode  *int      `json:"exit_code,omitempty"`
-	TS        time.Time `json:"ts"`
+	// UserID and Reason are only set on operator-initiated events (e.g.
+	// EventRestartRequested) for audit purposes.
+	UserID *int64 `json:"user_id,omitempty"`
+	Reason *string `json:"reason,omitempty"`
+	// Detail holds the full sequence of coalesced WorkerEventInputs as a JSON
+	// array when RecordWorkerEvents collapsed more than one event into this
+	// row (see manager/eventqueue.go). Nil for a row recorded from a single event.
+	Detail json.RawMessage `json:"detail,omitempty"`
+	TS     time.Time       `json:"ts"`
+}
+
+// WorkerEventInput is one raw lifecycle occurrence queued for persistence,
+// possibly alongside others in the same source's coalescing window. Intentional
+// is only used to decide whether an exited event should flush immediately
+// (see manager/eventqueue.go); it is not persisted.
+type WorkerEventInput struct {
+	EventType   EventType `json:"event_type"`
+	PID         int       `json:"pid"`
+	ExitCode    *int      `json:"exit_code,omitempty"`
+	UserID      *int64    `json:"user_id,omitempty"`
+	Reason      *string   `json:"reason,omitempty"`
+	Intentional bool      `json:"-"`
+	TS          time.Time `json:"ts"`
+}
+
+// ResourceSample is one point-in-time snapshot of a worker's resource usage,
+// persisted for historical graphing.
+type ResourceSample struct {
+	ID            int64     `json:"id"`
+	SourceID      int64     `json:"source_id"`
+	CPUPercent    float64   `json:"cpu_percent"`
+	RSSBytes      int64     `json:"rss_bytes"`
+	SegmentBytes  int64     `json:"segment_bytes"`
+	BytesPerSec   float64   `json:"bytes_per_sec"`
+	DiskFreeBytes int64     `json:"disk_free_bytes"`
+	TS            time.Time `json:"ts"`
+}
+
+// ---- admin tasks ----
+
+// AdminTaskStatus is the lifecycle state of a long-running admin operation.
+type AdminTaskStatus string
+
+const (
+	AdminTaskPending   AdminTaskStatus = "pending"
+	AdminTaskRunning   AdminTaskStatus = "running"
+	AdminTaskSucceeded AdminTaskStatus = "succeeded"
+	AdminTaskFailed    AdminTaskStatus = "failed"
+	AdminTaskCancelled AdminTaskStatus = "cancelled"
+)
+
+// AdminTask tracks the progress of a long-running operator-initiated
+// operation (bulk source import, overseer_task_id backfill, subscription
+// migration, ...) so it can be observed and resumed instead of running as a
+// silent goroutine. See package admin for the runner that drives one.
+type AdminTask struct {
+	ID         uuid.UUID       `json:"id"`
+	Kind       string          `json:"kind"`
+	DoerID     int64           `json:"doer_id"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Status     AdminTaskStatus `json:"status"`
+	Progress   int             `json:"progress"`
+	Total      int             `json:"total"`
+	Message    string          `json:"message,omitempty"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Error      *string         `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AdminTaskFilter narrows ListAdminTasks; a zero-value field means "don't
+// filter on this".
+type AdminTaskFilter struct {
+	Kind   string
+	Status AdminTaskStatus
+}
+
+// ConfigVersion is one append-only entry in config_versions. GetConfig reads
+// the newest version (config_current); SetConfig inserts a new one rather
+// than overwriting, so every change to the live config is recoverable via
+// RollbackConfig.
+type ConfigVersion struct {
+	ID        int64           `json:"id"`
+	Data      json.RawMessage `json:"data"`
+	AuthorID  *int64          `json:"author_id,omitempty"`
+	Comment   string          `json:"comment,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ChangeEvent is a row-level change notification decoded from the
+// sticky_dvr_events LISTEN/NOTIFY channel (see postgres migration 000008 and
+// DB.Subscribe). Table is the triggering table name, Op is "INSERT",
+// "UPDATE", or "DELETE", and ID is the affected row's id.
+type ChangeEvent struct {
+	Table string `json:"table"`
+	Op    string `json:"op"`
+	ID    int64  `json:"id"`
+}
+
+// ---- OAuth2 provider ----
+
+// OAuthApp is a third-party client app registered via POST /api/oauth/apps.
+// ClientSecretHash is never serialised; the plaintext secret is returned to
+// the caller once, at registration time, exactly like a user password.
+type OAuthApp struct {
+	ID               uuid.UUID `json:"id"`
+	Name             string    `json:"name"`
+	ClientID         string    `json:"client_id"`
+	ClientSecretHash string    `json:"-"`
+	RedirectURI      string    `json:"redirect_uri"`
+	OwnerID          int64     `json:"owner_id"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// OAuthAuthorization is an outstanding authorization code from the
+// GET/POST /api/oauth/authorize consent flow, consumed exactly once by
+// ConsumeOAuthAuthorization. CodeChallenge/CodeChallengeMethod carry the
+// PKCE parameters the authorization_code token grant must verify.
+type OAuthAuthorization struct {
+	Code                string     `json:"-"`
+	AppID               uuid.UUID  `json:"app_id"`
+	UserID              int64      `json:"user_id"`
+	RedirectURI         string     `json:"redirect_uri"`
+	Scope               string     `json:"scope"`
+	CodeChallenge       string     `json:"-"`
+	CodeChallengeMethod string     `json:"-"`
+	ExpiresAt           time.Time  `json:"expires_at"`
+	UsedAt              *time.Time `json:"used_at,omitempty"`
+}
+
+// OAuthGrant is the OAuth2 analogue of Session: a persisted refresh token an
+// app exchanges for new access tokens via POST /api/oauth/token until
+// RevokeOAuthGrant marks it revoked or it expires.
+type OAuthGrant struct {
+	ID           uuid.UUID  `json:"id"`
+	AppID        uuid.UUID  `json:"app_id"`
+	UserID       int64      `json:"user_id"`
+	RefreshToken string     `json:"-"`
+	Scope        string     `json:"scope"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ---- notification channels ----
+
+// NotificationChannelType is the dispatch mechanism a NotificationChannel
+// uses; see package notifier for the senders.
+type NotificationChannelType string
+
+const (
+	NotificationChannelWebhook NotificationChannelType = "webhook"
+	NotificationChannelEmail   NotificationChannelType = "email"
+	NotificationChannelDiscord NotificationChannelType = "discord"
+	NotificationChannelSlack   NotificationChannelType = "slack"
+	NotificationChannelApprise NotificationChannelType = "apprise"
+)
+
+// NotificationChannel is one configured destination for notifier.Dispatcher
+// to deliver Events to. UserID is nil for a global (admin-configured)
+// channel that receives every matching event regardless of who owns the
+// subscription; otherwise it is one user's personal channel. Target is the
+// webhook/Apprise URL or the destination for email/Discord/Slack. Secret
+// carries whatever per-channel-type credential the sender needs (HMAC key
+// for webhook, SMTP connection string for email, Apprise service URLs) —
+// never serialised, exactly like OAuthApp.ClientSecretHash. Events is the
+// subset of notifier.EventKind values this channel wants; empty means all.
+type NotificationChannel struct {
+	ID        uuid.UUID               `json:"id"`
+	UserID    *int64                  `json:"user_id,omitempty"`
+	Type      NotificationChannelType `json:"type"`
+	Target    string                  `json:"target"`
+	Secret    string                  `json:"-"`
+	Events    []string                `json:"events"`
+	Template  string                  `json:"template,omitempty"`
+	CreatedAt time.Time               `json:"created_at"`
 }
 
 // ---- store interface ----
@@ -98,6 +322,17 @@ type Store interface {
 	GetSessionByRefreshToken(ctx context.Context, refreshToken string) (*Session, error)
 	DeleteSession(ctx context.Context, id uuid.UUID) error
 	DeleteExpiredSessions(ctx context.Context) error
+	// RotateSession exchanges oldRefreshToken for a new session in the same
+	// family: the old session is marked rotated_at and the new one points at
+	// it via ParentID, inheriting its remaining TTL. Returns (nil, nil) if
+	// oldRefreshToken doesn't match any session. Returns ErrRefreshTokenReused
+	// — after revoking every session in the family — if oldRefreshToken was
+	// already rotated or its session already revoked.
+	RotateSession(ctx context.Context, oldRefreshToken string) (*Session, error)
+	// RevokeFamily marks every non-revoked session in familyID with
+	// revoked_reason, so none of its refresh tokens can be rotated again.
+	// RotateSession's reuse-detection path calls it.
+	RevokeFamily(ctx context.Context, familyID uuid.UUID, reason string) error
 
 	// ---- sources ----
 	GetOrCreateSource(ctx context.Context, driver, username string) (*Source, error)
@@ -115,13 +350,95 @@ type Store interface {
 	GetSourceActiveSubscriberCount(ctx context.Context, sourceID int64) (int, error)
 
 	// ---- worker events ----
-	RecordWorkerEvent(ctx context.Context, sourceID int64, pid int, eventType EventType, exitCode *int) error
+	RecordWorkerEvent(ctx context.Context, sourceID int64, pid int, eventType EventType, exitCode *int, userID *int64, reason *string) error
+	// RecordWorkerEvents persists one or more coalesced WorkerEventInputs as a
+	// single worker_events row: a lone input is recorded plainly, while a run of
+	// several is collapsed into one row (fields from the last input) with the
+	// full sequence preserved in Detail for the UI timeline.
+	RecordWorkerEvents(ctx context.Context, sourceID int64, events []WorkerEventInput) error
 	RecentWorkerEvents(ctx context.Context, sourceID int64, limit int) ([]WorkerEvent, error)
 
+	// ---- resource samples ----
+	RecordResourceSample(ctx context.Context, sourceID int64, sample ResourceSample) error
+	RecentResourceSamples(ctx context.Context, sourceID int64, limit int) ([]ResourceSample, error)
+
 	// ---- config ----
+	// GetConfig returns the data of the newest config_versions row (the
+	// config_current view), or an empty map if none has been written yet.
 	GetConfig(ctx context.Context) (map[string]any, error)
-	SetConfig(ctx context.Context, data map[string]any) error
+	// SetConfig inserts a new config_versions row, rejecting data that fails
+	// the validator registered via WithConfigValidator at Open time. authorID
+	// is nil for system-initiated writes (e.g. seeding defaults on first boot).
+	SetConfig(ctx context.Context, data map[string]any, authorID *int64, comment string) (*ConfigVersion, error)
+	ListConfigVersions(ctx context.Context, limit, offset int) ([]*ConfigVersion, error)
+	GetConfigVersion(ctx context.Context, id int64) (*ConfigVersion, error)
+	// RollbackConfig inserts a new config_versions row whose data equals
+	// versionID's, so "rollback" is itself just another recorded version.
+	RollbackConfig(ctx context.Context, versionID int64, authorID *int64, comment string) (*ConfigVersion, error)
+
+	// ---- roles ----
+	CreateRole(ctx context.Context, name string, permissions []string) (*Role, error)
+	AssignRole(ctx context.Context, userID int64, roleName string) error
+	ListRoles(ctx context.Context) ([]*Role, error)
+
+	// ---- client certs (mTLS enrolment) ----
+	EnrollClientCert(ctx context.Context, fingerprint string, userID int64, label string) (*ClientCert, error)
+	GetClientCertByFingerprint(ctx context.Context, fingerprint string) (*ClientCert, error)
+	RevokeClientCert(ctx context.Context, fingerprint string) error
+	ListClientCerts(ctx context.Context) ([]*ClientCert, error)
+
+	// ---- admin tasks ----
+	CreateAdminTask(ctx context.Context, kind string, doerID int64, payload json.RawMessage, total int) (*AdminTask, error)
+	UpdateAdminTaskProgress(ctx context.Context, id uuid.UUID, progress int, message string) error
+	// MarkAdminTaskDone transitions a task to its terminal status (Succeeded,
+	// Failed, or Cancelled), stamping FinishedAt. taskErr is stored as Error
+	// and should be nil unless status is AdminTaskFailed.
+	MarkAdminTaskDone(ctx context.Context, id uuid.UUID, status AdminTaskStatus, taskErr error) error
+	ListAdminTasks(ctx context.Context, filter AdminTaskFilter) ([]*AdminTask, error)
+	GetAdminTask(ctx context.Context, id uuid.UUID) (*AdminTask, error)
+
+	// ---- oauth2 apps ----
+	CreateOAuthApp(ctx context.Context, name string, ownerID int64, redirectURI, clientID, clientSecretHash string) (*OAuthApp, error)
+	GetOAuthApp(ctx context.Context, id uuid.UUID) (*OAuthApp, error)
+	GetOAuthAppByClientID(ctx context.Context, clientID string) (*OAuthApp, error)
+	ListOAuthApps(ctx context.Context) ([]*OAuthApp, error)
+	DeleteOAuthApp(ctx context.Context, id uuid.UUID) error
+
+	// ---- oauth2 authorization codes ----
+	CreateOAuthAuthorization(ctx context.Context, a OAuthAuthorization) (*OAuthAuthorization, error)
+	// ConsumeOAuthAuthorization atomically marks code used and returns the row,
+	// so two concurrent token-exchange requests for the same code can't both
+	// succeed. Returns (nil, nil) if code doesn't exist, is already used, or
+	// has expired.
+	ConsumeOAuthAuthorization(ctx context.Context, code string) (*OAuthAuthorization, error)
+
+	// ---- oauth2 grants (refresh tokens) ----
+	CreateOAuthGrant(ctx context.Context, appID uuid.UUID, userID int64, refreshToken, scope string, expiresAt time.Time) (*OAuthGrant, error)
+	GetOAuthGrantByRefreshToken(ctx context.Context, refreshToken string) (*OAuthGrant, error)
+	RevokeOAuthGrant(ctx context.Context, id uuid.UUID) error
+
+	// ---- notification channels ----
+	CreateNotificationChannel(ctx context.Context, userID *int64, typ NotificationChannelType, target, secret string, events []string, template string) (*NotificationChannel, error)
+	GetNotificationChannel(ctx context.Context, id uuid.UUID) (*NotificationChannel, error)
+	ListNotificationChannelsByUser(ctx context.Context, userID int64) ([]*NotificationChannel, error)
+	ListGlobalNotificationChannels(ctx context.Context) ([]*NotificationChannel, error)
+	UpdateNotificationChannel(ctx context.Context, id uuid.UUID, target, secret string, events []string, template string) (*NotificationChannel, error)
+	DeleteNotificationChannel(ctx context.Context, id uuid.UUID) error
+	// GetSourceSubscriberUserIDs returns the user IDs with a non-archived
+	// subscription to sourceID, so a source-level event (worker started,
+	// errored, disk quota) can be published to each subscriber's personal
+	// notification channels without the manager needing to know about
+	// channels at all.
+	GetSourceSubscriberUserIDs(ctx context.Context, sourceID int64) ([]int64, error)
 
 	// ---- lifecycle ----
 	Close() error
 }
+
+// Tx is the same method set as Store, scoped to a single database
+// transaction. Implementations are handed to the callback passed to
+// WithTx, which commits or rolls back based on that callback's return
+// value — Close is a no-op on a Tx.
+type Tx interface {
+	Store
+}
@@ -1,4 +1,4 @@
-// Package thumbnailer provides a per-request WebSocket client for the sticky-thumbnailer service.
+// Package thumbnailer provides a persistent WebSocket client for the sticky-thumbnailer service.
 // The thumbnailer uses sticky-overseer v2 protocol at /ws.
 package thumbnailer
 
@@ -7,10 +7,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/logger"
 )
 
 // GlobalMetrics holds aggregate counters from the thumbnailer's in-memory state.
@@ -50,145 +53,399 @@ type taskInfo struct {
 	RestartCount int               `json:"restart_count"`
 }
 
-// Client is a per-request WebSocket client for the sticky-thumbnailer service.
+// Event is a broadcastable thumbnailer task lifecycle message, fanned out to
+// subscribers of the persistent Run(ctx) connection.
+type Event struct {
+	Type   string `json:"type"` // task_started | task_completed | task_errored | metrics
+	TaskID string `json:"task_id,omitempty"`
+	File   string `json:"file,omitempty"`
+	State  string `json:"state,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// eventHub fans out Events to a dynamic set of in-process listeners.
+type eventHub struct {
+	mu        sync.Mutex
+	listeners map[chan Event]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{listeners: make(map[chan Event]struct{})}
+}
+
+// Subscribe registers a new listener and returns its channel.
+func (h *eventHub) Subscribe() chan Event {
+	ch := make(chan Event, 16)
+	h.mu.Lock()
+	h.listeners[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+// Unsubscribe removes and closes a listener previously returned by Subscribe.
+func (h *eventHub) Unsubscribe(ch chan Event) {
+	h.mu.Lock()
+	if _, ok := h.listeners[ch]; ok {
+		delete(h.listeners, ch)
+		close(ch)
+	}
+	h.mu.Unlock()
+}
+
+func (h *eventHub) broadcast(ev Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.listeners {
+		select {
+		case ch <- ev:
+		default:
+			// Slow consumer — drop this event rather than block the dispatch loop.
+		}
+	}
+}
+
+// ClientOptions tunes dial/request timeouts and per-client request
+// concurrency. The zero value is replaced field-by-field with the defaults
+// below by NewClientWithOptions.
+type ClientOptions struct {
+	DialTimeout    time.Duration
+	RequestTimeout time.Duration
+	MaxInflight    int
+}
+
+func defaultClientOptions() ClientOptions {
+	return ClientOptions{
+		DialTimeout:    5 * time.Second,
+		RequestTimeout: 5 * time.Second,
+		MaxInflight:    8,
+	}
+}
+
+// inbound is the superset of all messages sent by the thumbnailer.
+type inbound struct {
+	Type   string           `json:"type"`
+	ID     string           `json:"id,omitempty"`
+	TaskID string           `json:"task_id,omitempty"`
+	File   string           `json:"file,omitempty"`
+	State  string           `json:"state,omitempty"`
+	Error  string           `json:"error,omitempty"`
+	Global *json.RawMessage `json:"global,omitempty"`
+	Pool   *json.RawMessage `json:"pool,omitempty"`
+	Tasks  []taskInfo       `json:"tasks,omitempty"`
+}
+
+// Client maintains a persistent WebSocket connection to a sticky-thumbnailer
+// instance, used both to fan out task lifecycle events (via Subscribe) and to
+// serve GetMetrics/GetPoolInfo/GetTasks by multiplexing requests over that
+// same connection.
 type Client struct {
 	wsURL string
 	idSeq atomic.Int64
+
+	hub            *eventHub
+	reconnectDelay time.Duration
+
+	connMu  sync.Mutex
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+
+	metricsPending sync.Map // request id → chan *GlobalMetrics
+	poolPending    sync.Map // request id → chan *PoolInfo
+	listPending    sync.Map // request id → chan []taskInfo
+
+	opts     ClientOptions
+	inflight chan struct{} // semaphore bounding concurrent in-flight requests
+
+	log *logger.Logger
 }
 
 // NewClient returns a Client targeting the given WebSocket URL (e.g. "ws://thumbnailer:8080/ws").
 func NewClient(wsURL string) *Client {
-	return &Client{wsURL: strings.TrimRight(wsURL, "/")}
+	return NewClientWithOptions(wsURL, defaultClientOptions())
 }
 
-func (c *Client) nextID() string {
-	return fmt.Sprintf("r%d", c.idSeq.Add(1))
+// NewClientWithOptions is like NewClient but with explicit timeout/concurrency tuning.
+// Zero fields in opts fall back to the package defaults.
+func NewClientWithOptions(wsURL string, opts ClientOptions) *Client {
+	def := defaultClientOptions()
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = def.DialTimeout
+	}
+	if opts.RequestTimeout <= 0 {
+		opts.RequestTimeout = def.RequestTimeout
+	}
+	if opts.MaxInflight <= 0 {
+		opts.MaxInflight = def.MaxInflight
+	}
+	return &Client{
+		wsURL:          strings.TrimRight(wsURL, "/"),
+		hub:            newEventHub(),
+		reconnectDelay: 5 * time.Second,
+		opts:           opts,
+		inflight:       make(chan struct{}, opts.MaxInflight),
+		log:            logger.New("worker", "thumbnailer"),
+	}
 }
 
-// GetMetrics dials the thumbnailer and returns global aggregate counters.
-// Returns nil, nil if the thumbnailer is unreachable (graceful degradation).
-func (c *Client) GetMetrics(ctx context.Context) (*GlobalMetrics, error) {
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.wsURL, nil)
-	if err != nil {
-		return nil, nil
+// Run connects and reconnects until ctx is cancelled. Call in a dedicated goroutine.
+func (c *Client) Run(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := c.runOnce(ctx); err != nil && ctx.Err() == nil {
+			c.log.Warn("reconnect", "error", err, "delay", c.reconnectDelay)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.reconnectDelay):
+		}
 	}
-	defer conn.Close()
+}
 
-	reqID := c.nextID()
-	req, _ := json.Marshal(map[string]any{"type": "metrics", "id": reqID})
-	if err := conn.WriteMessage(websocket.TextMessage, req); err != nil {
-		return nil, nil
+func (c *Client) runOnce(ctx context.Context) error {
+	dialCtx, cancel := context.WithTimeout(ctx, c.opts.DialTimeout)
+	defer cancel()
+	conn, _, err := websocket.DefaultDialer.DialContext(dialCtx, c.wsURL, nil)
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", c.wsURL, err)
 	}
 
-	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	c.connMu.Lock()
+	c.conn = conn
+	c.connMu.Unlock()
+
+	c.log.Info("connected", "url", c.wsURL)
+
+	defer func() {
+		conn.Close()
+		c.connMu.Lock()
+		if c.conn == conn {
+			c.conn = nil
+		}
+		c.connMu.Unlock()
+
+		c.metricsPending.Range(func(k, v any) bool {
+			v.(chan *GlobalMetrics) <- nil
+			c.metricsPending.Delete(k)
+			return true
+		})
+		c.poolPending.Range(func(k, v any) bool {
+			v.(chan *PoolInfo) <- nil
+			c.poolPending.Delete(k)
+			return true
+		})
+		c.listPending.Range(func(k, v any) bool {
+			v.(chan []taskInfo) <- nil
+			c.listPending.Delete(k)
+			return true
+		})
+
+		c.log.Warn("disconnected", "url", c.wsURL)
+	}()
+
 	for {
+		if ctx.Err() != nil {
+			conn.WriteMessage(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return nil
+		}
 		_, raw, err := conn.ReadMessage()
 		if err != nil {
-			return nil, nil
-		}
-		var msg struct {
-			Type   string           `json:"type"`
-			ID     string           `json:"id"`
-			Global *json.RawMessage `json:"global,omitempty"`
+			return err
 		}
-		if err := json.Unmarshal(raw, &msg); err != nil {
-			continue
-		}
-		if msg.Type == "metrics" && msg.ID == reqID {
-			if msg.Global == nil {
-				return nil, nil
+		c.dispatch(raw)
+	}
+}
+
+func (c *Client) dispatch(raw []byte) {
+	var msg inbound
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return
+	}
+
+	switch msg.Type {
+	case "metrics":
+		if msg.ID != "" {
+			if ch, ok := c.metricsPending.LoadAndDelete(msg.ID); ok {
+				if msg.Global != nil {
+					var gm GlobalMetrics
+					if err := json.Unmarshal(*msg.Global, &gm); err == nil {
+						ch.(chan *GlobalMetrics) <- &gm
+						return
+					}
+				}
+				ch.(chan *GlobalMetrics) <- nil
+				return
 			}
-			var gm GlobalMetrics
-			if err := json.Unmarshal(*msg.Global, &gm); err != nil {
-				return nil, nil
+		}
+		c.hub.broadcast(Event{Type: msg.Type, TaskID: msg.TaskID, File: msg.File, State: msg.State, Error: msg.Error})
+
+	case "pool_info":
+		if ch, ok := c.poolPending.LoadAndDelete(msg.ID); ok {
+			if msg.Pool != nil {
+				var pi PoolInfo
+				if err := json.Unmarshal(*msg.Pool, &pi); err == nil {
+					ch.(chan *PoolInfo) <- &pi
+					return
+				}
 			}
-			return &gm, nil
+			ch.(chan *PoolInfo) <- nil
+		}
+
+	case "tasks":
+		if ch, ok := c.listPending.LoadAndDelete(msg.ID); ok {
+			ch.(chan []taskInfo) <- msg.Tasks
 		}
+
+	case "task_started", "task_completed", "task_errored":
+		c.hub.broadcast(Event{Type: msg.Type, TaskID: msg.TaskID, File: msg.File, State: msg.State, Error: msg.Error})
+	}
+}
+
+// Subscribe registers a new listener for lifecycle events broadcast by Run.
+func (c *Client) Subscribe() chan Event { return c.hub.Subscribe() }
+
+// Unsubscribe removes a listener previously returned by Subscribe.
+func (c *Client) Unsubscribe(ch chan Event) { c.hub.Unsubscribe(ch) }
+
+// IsConnected reports whether the persistent Run connection is currently active.
+func (c *Client) IsConnected() bool {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	return c.conn != nil
+}
+
+func (c *Client) nextID() string {
+	return fmt.Sprintf("r%d", c.idSeq.Add(1))
+}
+
+func (c *Client) send(v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.connMu.Lock()
+	conn := c.conn
+	c.connMu.Unlock()
+	if conn == nil {
+		return fmt.Errorf("not connected to thumbnailer")
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, raw)
+}
+
+// GetMetrics returns global aggregate counters from the thumbnailer.
+// Returns nil, nil if the thumbnailer is unreachable (graceful degradation).
+func (c *Client) GetMetrics(ctx context.Context) (*GlobalMetrics, error) {
+	if !c.IsConnected() {
+		return nil, nil
+	}
+	select {
+	case c.inflight <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil
+	}
+	defer func() { <-c.inflight }()
+
+	id := c.nextID()
+	ch := make(chan *GlobalMetrics, 1)
+	c.metricsPending.Store(id, ch)
+
+	if err := c.send(map[string]any{"type": "metrics", "id": id}); err != nil {
+		c.metricsPending.Delete(id)
+		return nil, nil
+	}
+
+	select {
+	case gm := <-ch:
+		return gm, nil
+	case <-ctx.Done():
+		c.metricsPending.Delete(id)
+		return nil, nil
+	case <-time.After(c.opts.RequestTimeout):
+		c.metricsPending.Delete(id)
+		return nil, nil
 	}
 }
 
-// GetPoolInfo dials the thumbnailer and returns a snapshot of global pool state.
+// GetPoolInfo returns a snapshot of global thumbnailer pool state.
 // Returns nil, nil if the thumbnailer is unreachable (graceful degradation).
 func (c *Client) GetPoolInfo(ctx context.Context) (*PoolInfo, error) {
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.wsURL, nil)
-	if err != nil {
+	if !c.IsConnected() {
+		return nil, nil
+	}
+	select {
+	case c.inflight <- struct{}{}:
+	case <-ctx.Done():
 		return nil, nil
 	}
-	defer conn.Close()
+	defer func() { <-c.inflight }()
+
+	id := c.nextID()
+	ch := make(chan *PoolInfo, 1)
+	c.poolPending.Store(id, ch)
 
-	reqID := c.nextID()
-	req, _ := json.Marshal(map[string]any{"type": "pool_info", "id": reqID})
-	if err := conn.WriteMessage(websocket.TextMessage, req); err != nil {
+	if err := c.send(map[string]any{"type": "pool_info", "id": id}); err != nil {
+		c.poolPending.Delete(id)
 		return nil, nil
 	}
 
-	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
-	for {
-		_, raw, err := conn.ReadMessage()
-		if err != nil {
-			return nil, nil
-		}
-		var msg struct {
-			Type string           `json:"type"`
-			ID   string           `json:"id"`
-			Pool *json.RawMessage `json:"pool,omitempty"`
-		}
-		if err := json.Unmarshal(raw, &msg); err != nil {
-			continue
-		}
-		if msg.Type == "pool_info" && msg.ID == reqID {
-			if msg.Pool == nil {
-				return nil, nil
-			}
-			var pi PoolInfo
-			if err := json.Unmarshal(*msg.Pool, &pi); err != nil {
-				return nil, nil
-			}
-			return &pi, nil
-		}
+	select {
+	case pi := <-ch:
+		return pi, nil
+	case <-ctx.Done():
+		c.poolPending.Delete(id)
+		return nil, nil
+	case <-time.After(c.opts.RequestTimeout):
+		c.poolPending.Delete(id)
+		return nil, nil
 	}
 }
 
-// GetTasks dials the thumbnailer and returns all active/queued/errored tasks.
+// GetTasks returns all active/queued/errored thumbnailer tasks.
 // Returns an empty slice if the thumbnailer is unreachable (graceful degradation).
 func (c *Client) GetTasks(ctx context.Context) ([]TaskInfo, error) {
-	conn, _, err := websocket.DefaultDialer.DialContext(ctx, c.wsURL, nil)
-	if err != nil {
+	if !c.IsConnected() {
 		return []TaskInfo{}, nil
 	}
-	defer conn.Close()
+	select {
+	case c.inflight <- struct{}{}:
+	case <-ctx.Done():
+		return []TaskInfo{}, nil
+	}
+	defer func() { <-c.inflight }()
 
-	reqID := c.nextID()
-	req, _ := json.Marshal(map[string]any{"type": "list", "id": reqID})
-	if err := conn.WriteMessage(websocket.TextMessage, req); err != nil {
+	id := c.nextID()
+	ch := make(chan []taskInfo, 1)
+	c.listPending.Store(id, ch)
+
+	if err := c.send(map[string]any{"type": "list", "id": id}); err != nil {
+		c.listPending.Delete(id)
 		return []TaskInfo{}, nil
 	}
 
-	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
-	for {
-		_, raw, err := conn.ReadMessage()
-		if err != nil {
-			return []TaskInfo{}, nil
-		}
-		var msg struct {
-			Type  string     `json:"type"`
-			ID    string     `json:"id"`
-			Tasks []taskInfo `json:"tasks"`
-		}
-		if err := json.Unmarshal(raw, &msg); err != nil {
-			continue
-		}
-		if msg.Type == "tasks" && msg.ID == reqID {
-			tasks := make([]TaskInfo, 0, len(msg.Tasks))
-			for _, t := range msg.Tasks {
-				tasks = append(tasks, TaskInfo{
-					TaskID:       t.TaskID,
-					File:         t.Params["file"],
-					State:        t.State,
-					RestartCount: t.RestartCount,
-				})
-			}
-			return tasks, nil
-		}
+	var raw []taskInfo
+	select {
+	case raw = <-ch:
+	case <-ctx.Done():
+		c.listPending.Delete(id)
+		return []TaskInfo{}, nil
+	case <-time.After(c.opts.RequestTimeout):
+		c.listPending.Delete(id)
+		return []TaskInfo{}, nil
+	}
+
+	tasks := make([]TaskInfo, 0, len(raw))
+	for _, t := range raw {
+		tasks = append(tasks, TaskInfo{
+			TaskID:       t.TaskID,
+			File:         t.Params["file"],
+			State:        t.State,
+			RestartCount: t.RestartCount,
+		})
 	}
+	return tasks, nil
 }
@@ -0,0 +1,151 @@
+// Package workers wraps backend's long-running upstream service clients —
+// overseer, converter, thumbnailer — behind one lifecycle interface, so
+// main only has to start and log each client once instead of repeating its
+// own connect-log line and goroutine per client, and router's Deps can hold
+// a single Registry instead of a field per client.
+package workers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/whisper-darkly/sticky-dvr/backend/converter"
+	"github.com/whisper-darkly/sticky-dvr/backend/logger"
+	"github.com/whisper-darkly/sticky-dvr/backend/overseer"
+	"github.com/whisper-darkly/sticky-dvr/backend/thumbnailer"
+)
+
+// Worker is a long-running backend client: it dials an upstream service and
+// reconnects with backoff on its own, and reports its own health so the
+// Registry (and GET /api/admin/workers) don't need a type switch per client.
+type Worker interface {
+	Name() string
+	Run(ctx context.Context) error
+	Healthy() bool
+	Snapshot() Status
+}
+
+// Status is the JSON shape returned for a single worker by GET /api/admin/workers.
+type Status struct {
+	Name      string `json:"name"`
+	URL       string `json:"url,omitempty"`
+	Connected bool   `json:"connected"`
+}
+
+// Registry holds the Worker wrappers main starts at boot plus the concrete
+// client pointers the router's handlers call business methods on directly
+// (GetFiles, TailOutput, Subscribe, ...) — Worker only needs to abstract
+// lifecycle and status, not each client's distinct API.
+type Registry struct {
+	Overseer    *overseer.Client    // never nil
+	Converter   *converter.Client   // nil if CONVERTER_URL unset
+	Thumbnailer *thumbnailer.Client // nil if THUMBNAILER_URL unset
+
+	mu      sync.RWMutex
+	workers []Worker
+}
+
+// NewRegistry returns an empty Registry; call Add for each worker before Run.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Add registers w. Must be called before Run.
+func (r *Registry) Add(w Worker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workers = append(r.workers, w)
+}
+
+// Run starts every registered worker's Run loop in its own goroutine and
+// returns immediately; each Worker manages its own reconnect loop, same as
+// the individual "go client.Run(ctx)" calls it replaces.
+func (r *Registry) Run(ctx context.Context) {
+	r.mu.RLock()
+	ws := append([]Worker(nil), r.workers...)
+	r.mu.RUnlock()
+
+	for _, w := range ws {
+		go func(w Worker) {
+			log := logger.New("worker", w.Name())
+			if err := w.Run(ctx); err != nil {
+				log.Error("exited", "error", err)
+			}
+		}(w)
+	}
+}
+
+// Snapshot returns every registered worker's current Status, for
+// GET /api/admin/workers.
+func (r *Registry) Snapshot() []Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Status, 0, len(r.workers))
+	for _, w := range r.workers {
+		out = append(out, w.Snapshot())
+	}
+	return out
+}
+
+// overseerWorker adapts *overseer.Client to Worker.
+type overseerWorker struct {
+	c   *overseer.Client
+	url string
+}
+
+// NewOverseerWorker wraps an overseer client for Registry.Add.
+func NewOverseerWorker(c *overseer.Client, url string) Worker {
+	return overseerWorker{c: c, url: url}
+}
+
+func (w overseerWorker) Name() string  { return "overseer" }
+func (w overseerWorker) Healthy() bool { return w.c.IsConnected() }
+func (w overseerWorker) Snapshot() Status {
+	return Status{Name: w.Name(), URL: w.url, Connected: w.c.IsConnected()}
+}
+func (w overseerWorker) Run(ctx context.Context) error {
+	w.c.Run(ctx)
+	return nil
+}
+
+// converterWorker adapts *converter.Client to Worker.
+type converterWorker struct {
+	c   *converter.Client
+	url string
+}
+
+// NewConverterWorker wraps a converter client for Registry.Add.
+func NewConverterWorker(c *converter.Client, url string) Worker {
+	return converterWorker{c: c, url: url}
+}
+
+func (w converterWorker) Name() string  { return "converter" }
+func (w converterWorker) Healthy() bool { return w.c.IsConnected() }
+func (w converterWorker) Snapshot() Status {
+	return Status{Name: w.Name(), URL: w.url, Connected: w.c.IsConnected()}
+}
+func (w converterWorker) Run(ctx context.Context) error {
+	w.c.Run(ctx)
+	return nil
+}
+
+// thumbnailerWorker adapts *thumbnailer.Client to Worker.
+type thumbnailerWorker struct {
+	c   *thumbnailer.Client
+	url string
+}
+
+// NewThumbnailerWorker wraps a thumbnailer client for Registry.Add.
+func NewThumbnailerWorker(c *thumbnailer.Client, url string) Worker {
+	return thumbnailerWorker{c: c, url: url}
+}
+
+func (w thumbnailerWorker) Name() string  { return "thumbnailer" }
+func (w thumbnailerWorker) Healthy() bool { return w.c.IsConnected() }
+func (w thumbnailerWorker) Snapshot() Status {
+	return Status{Name: w.Name(), URL: w.url, Connected: w.c.IsConnected()}
+}
+func (w thumbnailerWorker) Run(ctx context.Context) error {
+	w.c.Run(ctx)
+	return nil
+}
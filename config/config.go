@@ -29,10 +29,131 @@ type Data struct {
 	Cookies          string `json:"cookies"`
 
 	// Backend behaviour
-	RestartDelay      string `json:"restart_delay"`      // wait before auto-restart after a non-explicit exit
+	RestartDelay      string `json:"restart_delay"`      // deprecated: use RestartPolicy.Delay; kept for old config.json compatibility
 	ReconcileInterval string `json:"reconcile_interval"` // how often the manager checks workers against overseer
 	ErrorThreshold    int    `json:"error_threshold"`    // error exits within ErrorWindow before entering error state
 	ErrorWindow       string `json:"error_window"`       // rolling window for error counting (e.g. "5m")
+	ShutdownGrace     string `json:"shutdown_grace"`     // Manager.Stop's grace period before escalating to SIGKILL (e.g. "15s")
+
+	// SubscriptionLogDir, if set, persists each subscription's output and
+	// [system] lines to <dir>/<driver>_<source>.log on top of the in-memory
+	// ring buffer, rotated by size. Empty disables on-disk logging.
+	SubscriptionLogDir string `json:"subscription_log_dir,omitempty"`
+
+	// SubscriptionLogMaxSize is the byte size at which a subscription's log
+	// file rotates; 0 falls back to 10 MiB.
+	SubscriptionLogMaxSize int64 `json:"subscription_log_max_size,omitempty"`
+
+	// SubscriptionLogMaxFiles caps how many rotated backups are kept per
+	// subscription, on top of the current file; 0 falls back to 5.
+	SubscriptionLogMaxFiles int `json:"subscription_log_max_files,omitempty"`
+
+	// StallTimeout is how long an active worker can produce no output before
+	// the reconciler considers it stalled and force-restarts it. Empty
+	// disables stall detection (the default).
+	StallTimeout string `json:"stall_timeout,omitempty"`
+
+	// StallStartupGrace excludes this long right after a worker starts from
+	// StallTimeout, so slow-starting streams aren't killed; 0/empty falls
+	// back to 60s.
+	StallStartupGrace string `json:"stall_startup_grace,omitempty"`
+
+	// RestartPolicy controls the backoff Manager.OnExited applies to
+	// consecutive worker-process failures. See package manager; zero fields
+	// fall back to manager.DefaultRestartPolicy.
+	RestartPolicy RestartPolicy `json:"restart_policy,omitempty"`
+
+	// EventSinks are HTTP destinations that receive a CloudEvents envelope for
+	// every worker lifecycle event and subscription state transition. See
+	// package events for the publisher that reads this.
+	EventSinks []EventSink `json:"event_sinks,omitempty"`
+
+	// Notifiers are operator-facing alert destinations (webhook/email/noop)
+	// notified on subscription state transitions. See package notifier.
+	Notifiers []NotifierConfig `json:"notifiers,omitempty"`
+
+	// Supervisor controls the root supervisor's restart/backoff behaviour.
+	// See package supervisor; zero fields fall back to supervisor.DefaultSpec.
+	Supervisor SupervisorConfig `json:"supervisor,omitempty"`
+}
+
+// RestartPolicy configures how Manager.OnExited reschedules a subscription's
+// worker after it exits, based on how many times it has failed in a row.
+// All fields are optional; a zero value falls back to the matching field in
+// manager.DefaultRestartPolicy.
+//
+// Mode selects the delay formula ("fixed" | "exponential"); OnExhaustion is
+// a separate choice for what happens once MaxAttempts consecutive failures
+// are reached ("fail" | "delay") — the two are independent knobs, not the
+// same field reused.
+type RestartPolicy struct {
+	Mode       string  `json:"mode,omitempty"`       // "fixed" | "exponential"
+	Delay      string  `json:"delay,omitempty"`      // e.g. "30s"; base delay, or the only delay when Mode is "fixed"
+	MaxDelay   string  `json:"max_delay,omitempty"`  // e.g. "5m"; upper bound on the computed delay
+	Multiplier float64 `json:"multiplier,omitempty"` // per-failure growth factor in "exponential" mode; default 2.0
+	JitterPct  float64 `json:"jitter_pct,omitempty"` // +/- spread applied to the computed delay; default 0.1 (10%)
+
+	// MaxAttempts is the number of consecutive failures allowed within the
+	// current cycle before OnExhaustion takes effect; 0 = unlimited.
+	MaxAttempts int `json:"max_attempts,omitempty"`
+
+	// OnExhaustion selects what happens once MaxAttempts is reached:
+	// "fail" transitions the subscription straight to StateError, bypassing
+	// checkErrorThreshold's windowed count; "delay" keeps restarting with the
+	// delay pinned at MaxDelay.
+	OnExhaustion string `json:"on_exhaustion,omitempty"`
+
+	// SuccessThreshold is how long a worker must run before a clean (exit
+	// code 0) exit resets the consecutive-failure count back to 0.
+	SuccessThreshold string `json:"success_threshold,omitempty"` // e.g. "1m"
+}
+
+// SupervisorConfig configures package supervisor's Spec. All fields are
+// optional; an empty/zero value falls back to supervisor.DefaultSpec.
+type SupervisorConfig struct {
+	BackoffBase    string `json:"backoff_base,omitempty"`    // e.g. "500ms"
+	BackoffMax     string `json:"backoff_max,omitempty"`     // e.g. "30s"
+	MaxRestarts    int    `json:"max_restarts,omitempty"`    // within RestartWindow; 0 = unlimited
+	RestartWindow  string `json:"restart_window,omitempty"`  // e.g. "1m"
+	ServiceTimeout string `json:"service_timeout,omitempty"` // e.g. "10s"
+}
+
+// NotifierConfig describes one registered alert destination.
+type NotifierConfig struct {
+	Kind string `json:"kind"` // "webhook" | "smtp" | "noop"
+	Name string `json:"name"` // unique; used in GET/POST /api/notifiers routes
+
+	URL  string `json:"url,omitempty"`  // webhook
+	Host string `json:"host,omitempty"` // smtp: "host:port"
+	From string `json:"from,omitempty"`
+	To   []string `json:"to,omitempty"`
+
+	// Filter restricts delivery to these store.State values. Empty means
+	// every state transition is delivered.
+	Filter []string `json:"filter,omitempty"`
+}
+
+// EventSink is one HTTP destination for published events.
+type EventSink struct {
+	URL string `json:"url"`
+
+	// Secret, if set, signs each delivery with HMAC-SHA256 over the raw JSON
+	// body; the hex digest is sent as the X-Sticky-Signature header.
+	Secret string `json:"secret,omitempty"`
+
+	// Types filters which CloudEvents `type` values are delivered to this
+	// sink. Empty means all types.
+	Types []string `json:"types,omitempty"`
+
+	Retry EventRetryPolicy `json:"retry"`
+}
+
+// EventRetryPolicy controls exponential backoff for a sink's retry queue.
+// Zero values fall back to events.DefaultRetryPolicy.
+type EventRetryPolicy struct {
+	MaxAttempts int    `json:"max_attempts"`
+	BaseDelay   string `json:"base_delay"` // e.g. "1s"
+	MaxDelay    string `json:"max_delay"`  // e.g. "5m"
 }
 
 // Global is a thread-safe, disk-backed wrapper around Data.
@@ -80,6 +201,7 @@ func defaults() Data {
 		ReconcileInterval: "60s",
 		ErrorThreshold:    5,
 		ErrorWindow:       "5m",
+		ShutdownGrace:     "15s",
 	}
 }
 
@@ -98,6 +220,39 @@ func (g *Global) Set(d Data) error {
 	return g.save()
 }
 
+// MergeOverrides returns a copy of base with overrides layered on top.
+// overrides is a sparse map keyed by Data's JSON field names (e.g.
+// {"framerate": 60}); fields it doesn't mention are left as in base. This is
+// how per-subscription config overrides (see store.Store's
+// Get/SetSubscriptionConfig) are applied to the global default.
+func MergeOverrides(base Data, overrides map[string]any) (Data, error) {
+	if len(overrides) == 0 {
+		return base, nil
+	}
+
+	baseRaw, err := json.Marshal(base)
+	if err != nil {
+		return base, err
+	}
+	var merged map[string]any
+	if err := json.Unmarshal(baseRaw, &merged); err != nil {
+		return base, err
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+
+	mergedRaw, err := json.Marshal(merged)
+	if err != nil {
+		return base, err
+	}
+	var out Data
+	if err := json.Unmarshal(mergedRaw, &out); err != nil {
+		return base, err
+	}
+	return out, nil
+}
+
 func (g *Global) save() error {
 	g.mu.RLock()
 	raw, err := json.MarshalIndent(g.data, "", "  ")
@@ -0,0 +1,276 @@
+// Package events publishes CloudEvents 1.0 JSON envelopes describing worker
+// lifecycle events and subscription state transitions to configurable HTTP
+// sinks, so operators can plug sticky into an existing event router instead
+// of polling the per-subscription events endpoint.
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/whisper-darkly/sticky-backend/config"
+	"github.com/whisper-darkly/sticky-backend/store"
+)
+
+// CloudEvents type values. Worker event types mirror store.EventType, except
+// that a store.EventStalled stop is published as TypeWorkerStopped with
+// data.reason "stalled";
+// subscription state transitions get their own type since they aren't
+// WorkerEvents.
+const (
+	TypeWorkerStarted     = "io.sticky.worker.started"
+	TypeWorkerExited      = "io.sticky.worker.exited"
+	TypeWorkerStopped     = "io.sticky.worker.stopped"
+	TypeStateChanged      = "io.sticky.subscription.state_changed"
+	TypeThresholdExceeded = "io.sticky.subscription.threshold_exceeded"
+	TypeRestartScheduled  = "io.sticky.subscription.restart_scheduled"
+)
+
+// Event is a CloudEvents 1.0 JSON envelope (https://cloudevents.io/).
+type Event struct {
+	SpecVersion string    `json:"specversion"`
+	Type        string    `json:"type"`
+	Source      string    `json:"source"` // /drivers/{driver}/{source}
+	ID          string    `json:"id"`
+	Time        time.Time `json:"time"`
+	Subject     string    `json:"subject"` // "{driver}/{source}"
+	Data        any       `json:"data"`
+}
+
+// Publisher publishes a CloudEvents envelope. It does not return an error:
+// implementations are expected to queue and retry failed deliveries
+// internally rather than make the caller (Manager) handle sink outages.
+type Publisher interface {
+	Publish(ctx context.Context, ev Event)
+}
+
+// DefaultRetryPolicy is used for a sink whose config.EventRetryPolicy is the
+// zero value, and as a fallback for a queued delivery whose sink has since
+// been removed from config.
+var DefaultRetryPolicy = config.EventRetryPolicy{
+	MaxAttempts: 8,
+	BaseDelay:   "1s",
+	MaxDelay:    "5m",
+}
+
+// pollInterval is how often Run drains the due-delivery queue.
+const pollInterval = 5 * time.Second
+
+// Dispatcher is the concrete Publisher: it attempts immediate delivery to
+// every sink whose Types filter matches, falling back to a DB-persisted
+// retry queue (drained by Run) on failure, with exponential backoff. Sinks
+// that exhaust their MaxAttempts are moved to the dead-letter table.
+type Dispatcher struct {
+	st  store.Store
+	cfg *config.Global
+	cl  *http.Client
+}
+
+// NewDispatcher creates a Dispatcher. Call Run in its own goroutine to drain
+// the retry queue.
+func NewDispatcher(st store.Store, cfg *config.Global) *Dispatcher {
+	return &Dispatcher{
+		st:  st,
+		cfg: cfg,
+		cl:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Publish sends ev to every configured sink whose Types filter matches,
+// queuing a retry on failure.
+func (d *Dispatcher) Publish(ctx context.Context, ev Event) {
+	raw, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("events: marshal %s: %v", ev.Type, err)
+		return
+	}
+
+	for _, sink := range d.cfg.Get().EventSinks {
+		if !matchesType(sink.Types, ev.Type) {
+			continue
+		}
+		if err := d.deliver(ctx, sink.URL, sink.Secret, raw); err != nil {
+			log.Printf("events: deliver %s to %s failed, queuing for retry: %v", ev.Type, sink.URL, err)
+			delivery := store.EventDelivery{
+				SinkURL:     sink.URL,
+				Secret:      sink.Secret,
+				Payload:     raw,
+				Attempts:    1,
+				NextAttempt: time.Now().Add(backoff(sink.Retry, 1)),
+				LastError:   err.Error(),
+			}
+			if _, qerr := d.st.EnqueueEventDelivery(context.Background(), delivery); qerr != nil {
+				log.Printf("events: enqueue retry for %s: %v", sink.URL, qerr)
+			}
+		}
+	}
+}
+
+// Serve implements supervisor.Service: it runs Run until ctx is cancelled.
+func (d *Dispatcher) Serve(ctx context.Context) error {
+	d.Run(ctx)
+	return ctx.Err()
+}
+
+// Run drains the retry queue until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.drain(ctx)
+		}
+	}
+}
+
+func (d *Dispatcher) drain(ctx context.Context) {
+	due, err := d.st.DueEventDeliveries(ctx, time.Now(), 50)
+	if err != nil {
+		log.Printf("events: list due deliveries: %v", err)
+		return
+	}
+
+	for _, del := range due {
+		retry := d.retryPolicyFor(del.SinkURL)
+
+		if err := d.deliver(ctx, del.SinkURL, del.Secret, del.Payload); err != nil {
+			attempts := del.Attempts + 1
+			if attempts >= retry.MaxAttempts {
+				log.Printf("events: %s to %s exhausted %d attempts, dead-lettering: %v",
+					del.ID, del.SinkURL, attempts, err)
+				if derr := d.st.DeadLetterEvent(ctx, del.ID, err.Error()); derr != nil {
+					log.Printf("events: dead-letter delivery %d: %v", del.ID, derr)
+				}
+				continue
+			}
+			next := time.Now().Add(backoff(retry, attempts))
+			if merr := d.st.MarkEventRetry(ctx, del.ID, next, err.Error()); merr != nil {
+				log.Printf("events: mark retry for delivery %d: %v", del.ID, merr)
+			}
+			continue
+		}
+
+		if merr := d.st.MarkEventDelivered(ctx, del.ID); merr != nil {
+			log.Printf("events: mark delivered for delivery %d: %v", del.ID, merr)
+		}
+	}
+}
+
+// retryPolicyFor looks up the current retry policy for sinkURL, falling back
+// to DefaultRetryPolicy if the sink has since been removed from config. A
+// zero MaxAttempts takes DefaultRetryPolicy's; zero delays are resolved by
+// backoff.
+func (d *Dispatcher) retryPolicyFor(sinkURL string) config.EventRetryPolicy {
+	for _, sink := range d.cfg.Get().EventSinks {
+		if sink.URL == sinkURL {
+			p := sink.Retry
+			if p.MaxAttempts <= 0 {
+				p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+			}
+			return p
+		}
+	}
+	return DefaultRetryPolicy
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, url, secret string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/cloudevents+json")
+	if secret != "" {
+		req.Header.Set("X-Sticky-Signature", sign(secret, payload))
+	}
+
+	resp, err := d.cl.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sink returned %s", resp.Status)
+	}
+	return nil
+}
+
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func matchesType(types []string, t string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, want := range types {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff computes the delay to wait after the n-th failed attempt (n >= 1):
+// BaseDelay after the first, doubling with each further one, capped at
+// MaxDelay. Zero-value fields fall back to
+// DefaultRetryPolicy's.
+func backoff(p config.EventRetryPolicy, n int) time.Duration {
+	base := parseDuration(p.BaseDelay, parseDuration(DefaultRetryPolicy.BaseDelay, time.Second))
+	max := parseDuration(p.MaxDelay, parseDuration(DefaultRetryPolicy.MaxDelay, 5*time.Minute))
+
+	d := base
+	for i := 1; i < n; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
+
+func parseDuration(s string, def time.Duration) time.Duration {
+	if s == "" {
+		return def
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// randomID returns a short random hex string, used as a CloudEvents id when
+// the caller has no more natural identifier (e.g. a subscription key plus a
+// timestamp would collide across rapid transitions).
+func randomID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand failing is effectively unrecoverable; fall back to a
+		// timestamp so callers still get a unique-enough id.
+		return strings.ReplaceAll(time.Now().UTC().Format(time.RFC3339Nano), ":", "")
+	}
+	return hex.EncodeToString(b)
+}
+
+// NewID exposes randomID to callers outside the package (manager) that build
+// Event values directly.
+func NewID() string { return randomID() }
@@ -2,20 +2,25 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/whisper-darkly/sticky-backend/auth"
 	"github.com/whisper-darkly/sticky-backend/config"
+	"github.com/whisper-darkly/sticky-backend/events"
 	"github.com/whisper-darkly/sticky-backend/manager"
+	"github.com/whisper-darkly/sticky-backend/metrics"
+	"github.com/whisper-darkly/sticky-backend/notifier"
 	"github.com/whisper-darkly/sticky-backend/overseer"
 	"github.com/whisper-darkly/sticky-backend/router"
 	"github.com/whisper-darkly/sticky-backend/store/sqlite"
+	"github.com/whisper-darkly/sticky-backend/supervisor"
 )
 
 var version = "dev"
@@ -25,64 +30,163 @@ func main() {
 	overseerURL := env("OVERSEER_URL", "ws://localhost:8081/ws")
 	confDir := env("CONF_DIR", "/data/conf")
 
-	fmt.Printf("sticky-backend %s\n", version)
+	// Bootstrap at info level; rebuilt at config.Data.LogLevel once loaded.
+	logger := newLogger("info")
+	logger.Info("starting sticky-backend", "version", version)
 
 	if err := os.MkdirAll(confDir, 0o755); err != nil {
-		log.Fatalf("conf dir: %v", err)
+		logger.Error("conf dir", "error", err)
+		os.Exit(1)
 	}
 
 	cfg, err := config.Load(confDir)
 	if err != nil {
-		log.Fatalf("config: %v", err)
+		logger.Error("config", "error", err)
+		os.Exit(1)
 	}
+	logger = newLogger(cfg.Get().LogLevel)
 
 	db, err := sqlite.Open(filepath.Join(confDir, "sticky.db"))
 	if err != nil {
-		log.Fatalf("database: %v", err)
+		logger.Error("database", "error", err)
+		os.Exit(1)
 	}
 	defer db.Close()
 
-	mgr := manager.New(cfg, db)
+	mgr := manager.New(cfg, db, logger)
+
+	reg := metrics.NewRegistry()
+	mgr.SetMetrics(reg)
+
+	signer, err := auth.LoadSigner(confDir)
+	if err != nil {
+		logger.Error("auth", "error", err)
+		os.Exit(1)
+	}
+	mgr.SetSigner(signer)
+
+	notifiers, err := notifier.NewRegistry(cfg.Get().Notifiers)
+	if err != nil {
+		logger.Error("notifiers", "error", err)
+		os.Exit(1)
+	}
+	mgr.SetNotifiers(notifiers)
 
 	oc := overseer.NewClient(overseerURL, overseer.Handler{
 		OnOutput: mgr.OnOutput,
 		OnExited: mgr.OnExited,
-	})
+	}, logger)
 	mgr.SetOverseerClient(oc)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go oc.Run(ctx)
+	// Publishes worker lifecycle events and state transitions as CloudEvents
+	// to any sinks configured in config.Data.EventSinks; a no-op until one is
+	// added via PUT /api/config.
+	pub := events.NewDispatcher(db, cfg)
+	mgr.SetPublisher(pub)
 
 	if err := mgr.Start(ctx); err != nil {
-		log.Fatalf("manager: %v", err)
+		logger.Error("manager", "error", err)
+		os.Exit(1)
 	}
 
+	spec := supervisorSpec(cfg.Get().Supervisor)
 	srv := &http.Server{
 		Addr:    ":" + port,
-		Handler: router.New(mgr, cfg),
+		Handler: router.New(mgr, cfg, logger),
 	}
 
+	sup := supervisor.New(spec, logger)
+	sup.Add("overseer", oc)
+	sup.Add("event-dispatcher", pub)
+	sup.Add("manager-reconciler", mgr)
+	sup.Add("http", &httpService{srv: srv, shutdownTimeout: spec.ServiceTimeout})
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
-	go func() {
-		log.Printf("listening on :%s", port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("http: %v", err)
+	supErrCh := make(chan error, 1)
+	logger.Info("listening", "port", port)
+	go func() { supErrCh <- sup.Serve(ctx) }()
+
+	select {
+	case <-sigCh:
+		logger.Info("shutting down")
+
+		// Drain tracked workers (EventStopped + SIGTERM, escalating to
+		// SIGKILL after ShutdownGrace) before tearing down the overseer
+		// connection Stop needs to issue those commands — this must happen
+		// before cancel().
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), drainTimeout)
+		if err := mgr.Stop(drainCtx); err != nil {
+			logger.Warn("manager drain", "error", err)
+		}
+		drainCancel()
+
+		cancel()
+		<-supErrCh
+	case err := <-supErrCh:
+		logger.Error("supervisor exited unexpectedly", "error", err)
+	}
+}
+
+// drainTimeout bounds Manager.Stop's own ShutdownGrace-paced drain so an
+// unresponsive overseer can't hang shutdown indefinitely.
+const drainTimeout = 30 * time.Second
+
+// httpService adapts *http.Server to supervisor.Service: it serves until
+// ctx is cancelled, then shuts down gracefully within shutdownTimeout.
+type httpService struct {
+	srv             *http.Server
+	shutdownTimeout time.Duration
+}
+
+func (h *httpService) Serve(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- h.srv.ListenAndServe() }()
+
+	select {
+	case <-ctx.Done():
+		shutCtx, shutCancel := context.WithTimeout(context.Background(), h.shutdownTimeout)
+		defer shutCancel()
+		if err := h.srv.Shutdown(shutCtx); err != nil {
+			return err
 		}
-	}()
+		return ctx.Err()
+	case err := <-errCh:
+		if err == http.ErrServerClosed {
+			return nil
+		}
+		return err
+	}
+}
 
-	<-sigCh
-	log.Println("shutting down…")
-	cancel()
+// supervisorSpec translates config.SupervisorConfig's string durations into
+// a supervisor.Spec; empty or unparseable fields fall back to
+// supervisor.DefaultSpec (applied by supervisor.New).
+func supervisorSpec(c config.SupervisorConfig) supervisor.Spec {
+	return supervisor.Spec{
+		BackoffBase:    parseDuration(c.BackoffBase),
+		BackoffMax:     parseDuration(c.BackoffMax),
+		MaxRestarts:    c.MaxRestarts,
+		RestartWindow:  parseDuration(c.RestartWindow),
+		ServiceTimeout: parseDuration(c.ServiceTimeout),
+	}
+}
 
-	shutCtx, shutCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer shutCancel()
-	if err := srv.Shutdown(shutCtx); err != nil {
-		log.Printf("shutdown: %v", err)
+// parseDuration returns 0 (supervisor.New's cue to fall back to
+// supervisor.DefaultSpec) for an empty or unparseable string.
+func parseDuration(s string) time.Duration {
+	if s == "" {
+		return 0
 	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0
+	}
+	return d
 }
 
 func env(key, def string) string {
@@ -91,3 +195,32 @@ func env(key, def string) string {
 	}
 	return def
 }
+
+// newLogger builds the process-wide slog.Logger. level is one of
+// config.Data.LogLevel's values (debug/info/warn/error); output format is
+// chosen via the LOG_FORMAT env var ("text" for human-readable, anything
+// else — including unset — for JSON).
+func newLogger(level string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: parseLevel(level)}
+
+	var h slog.Handler
+	if env("LOG_FORMAT", "json") == "text" {
+		h = slog.NewTextHandler(os.Stderr, opts)
+	} else {
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	}
+	return slog.New(h)
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
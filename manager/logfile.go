@@ -0,0 +1,158 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultLogMaxSize  int64 = 10 << 20 // 10 MiB
+	defaultLogMaxFiles       = 5
+)
+
+// rotatingLogFile appends lines to <dir>/<driver>_<source>.log, rotating to
+// numbered suffixes (.log.001, .log.002, …, oldest-numbered = oldest) once
+// the file exceeds maxSize, and keeping at most maxFiles total backups —
+// the oldest numbered file is deleted once every slot is in use.
+type rotatingLogFile struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	f        *os.File
+	size     int64
+}
+
+// openRotatingLogFile opens (creating if needed) dir/driver_source.log.
+// Callers serialise access themselves (subState.mu, in practice).
+func openRotatingLogFile(dir, driver, source string, maxSize int64, maxFiles int) (*rotatingLogFile, error) {
+	if maxSize <= 0 {
+		maxSize = defaultLogMaxSize
+	}
+	if maxFiles <= 0 {
+		maxFiles = defaultLogMaxFiles
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	path := logFilePath(dir, driver, source)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &rotatingLogFile{path: path, maxSize: maxSize, maxFiles: maxFiles, f: f, size: info.Size()}, nil
+}
+
+// write appends line plus a trailing newline, rotating first if that would
+// push the file past maxSize.
+func (r *rotatingLogFile) write(line string) error {
+	n := int64(len(line)) + 1
+	if r.size > 0 && r.size+n > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+	written, err := r.f.WriteString(line + "\n")
+	r.size += int64(written)
+	return err
+}
+
+// rotate closes the current file, shifts every numbered backup up by one
+// (deleting the oldest if all maxFiles slots are taken), renames the
+// current file to .001, and opens a fresh file at path.
+func (r *rotatingLogFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+
+	oldest := fmt.Sprintf("%s.%03d", r.path, r.maxFiles)
+	os.Remove(oldest) // fine if it doesn't exist
+
+	for n := r.maxFiles - 1; n >= 1; n-- {
+		from := fmt.Sprintf("%s.%03d", r.path, n)
+		to := fmt.Sprintf("%s.%03d", r.path, n+1)
+		if _, err := os.Stat(from); err == nil {
+			os.Rename(from, to)
+		}
+	}
+	if err := os.Rename(r.path, fmt.Sprintf("%s.%03d", r.path, 1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	r.f = f
+	r.size = 0
+	return nil
+}
+
+// close closes the underlying file.
+func (r *rotatingLogFile) close() error {
+	return r.f.Close()
+}
+
+// logFilePath returns the current (not-yet-rotated) log file path for
+// driver/source under dir.
+func logFilePath(dir, driver, source string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s_%s.log", driver, source))
+}
+
+// readLogTail returns up to n lines (0 = unlimited) of driver/source's
+// on-disk log under dir, oldest first, reading the rotated backups and the
+// current file in chronological order. Used by GetLogs when the requested
+// range predates what's held in the in-memory ring buffer.
+func readLogTail(dir, driver, source string, n int) ([]string, error) {
+	path := logFilePath(dir, driver, source)
+
+	var backups []string
+	for i := 1; ; i++ {
+		p := fmt.Sprintf("%s.%03d", path, i)
+		if _, err := os.Stat(p); err != nil {
+			break
+		}
+		backups = append(backups, p)
+	}
+	// backups is currently newest-backup-first (.001, .002, …); reverse to
+	// oldest-first, then the current file (newest of all) goes last.
+	for i, j := 0, len(backups)-1; i < j; i, j = i+1, j-1 {
+		backups[i], backups[j] = backups[j], backups[i]
+	}
+	files := append(backups, path)
+
+	var lines []string
+	for _, f := range files {
+		fLines, err := readAllLines(f)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		lines = append(lines, fLines...)
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+func readAllLines(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := strings.TrimRight(string(data), "\n")
+	if s == "" {
+		return nil, nil
+	}
+	return strings.Split(s, "\n"), nil
+}
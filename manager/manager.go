@@ -12,16 +12,29 @@
 //     from the error count, and establishes the cycle-reset boundary.
 //   - Periodically: reconciles in-memory state against the overseer worker
 //     list to recover from missed events (e.g. after an overseer restart).
+//   - On shutdown: Stop drains every tracked worker (EventStopped + SIGTERM)
+//     in parallel, escalating to SIGKILL for stragglers after ShutdownGrace.
+//
+// publish is the single choke point for the event bus: every lifecycle and
+// state-transition call site routes through it (or, for high-volume output
+// lines, through the stream hub directly) so CloudEvents sinks (package
+// events) and SSE/Subscribe consumers (see stream.go) never drift apart.
 package manager
 
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
+	"math/rand"
 	"sync"
 	"time"
 
+	"github.com/whisper-darkly/sticky-backend/auth"
 	"github.com/whisper-darkly/sticky-backend/config"
+	"github.com/whisper-darkly/sticky-backend/events"
+	"github.com/whisper-darkly/sticky-backend/metrics"
+	"github.com/whisper-darkly/sticky-backend/notifier"
 	"github.com/whisper-darkly/sticky-backend/overseer"
 	"github.com/whisper-darkly/sticky-backend/store"
 )
@@ -31,11 +44,45 @@ const maxLogs = 200
 // subState holds the in-memory runtime state for a single subscription.
 // Error counting is fully delegated to the store; no counter lives here.
 type subState struct {
-	sub  *store.Subscription
-	mu   sync.Mutex
-	pid  int
+	sub      *store.Subscription
+	mu       sync.Mutex
+	pid      int
 	starting bool
-	logs []string
+	logs     []string
+
+	// awaitingRecovery is set while the subscription is in error state (or
+	// has just been reset from one) so the next successful start can emit a
+	// "recovered" notification. See Manager.notifyRecovery.
+	awaitingRecovery bool
+
+	// consecutiveFailures counts non-intentional error exits since the last
+	// cycle reset (EventStopped) or successful run; it drives RestartPolicy's
+	// backoff. Independent of the store-backed windowed count
+	// checkErrorThreshold uses to decide StateError.
+	consecutiveFailures int
+
+	// runStartedAt is when the current (or most recently exited) process was
+	// started; compared against RestartPolicy.SuccessThreshold in OnExited.
+	runStartedAt time.Time
+
+	// nextRestartAt is when a pending restart is scheduled to fire, or the
+	// zero value if none is pending. Surfaced on SubscriptionStatus.
+	nextRestartAt time.Time
+
+	// restartTimer is the pending time.AfterFunc for a scheduled restart, if
+	// any; Manager.Stop stops it so shutdown doesn't race a restart that
+	// fires after the process has started tearing down.
+	restartTimer *time.Timer
+
+	// logFile is this subscription's on-disk log, opened lazily the first
+	// time config.Data.SubscriptionLogDir is set. nil means disk logging is
+	// off (or not yet opened).
+	logFile *rotatingLogFile
+
+	// lastOutputAt is when OnOutput last saw a line from the current worker;
+	// the reconciler compares it against config.Data.StallTimeout to detect
+	// a process that's alive but wedged. Zero means no output yet this run.
+	lastOutputAt time.Time
 }
 
 func (s *subState) addLog(line string) {
@@ -55,12 +102,56 @@ func (s *subState) getLogs() []string {
 	return out
 }
 
+// logLine is the single choke point for every log line a subscription
+// produces (OnOutput lines and [system] lines alike): it appends to the
+// in-memory ring buffer and, if config.Data.SubscriptionLogDir is set, also
+// appends to the subscription's on-disk rotating log, opening it lazily on
+// first use.
+func (m *Manager) logLine(state *subState, line string) {
+	state.addLog(line)
+
+	dir := m.cfg.Get().SubscriptionLogDir
+	if dir == "" {
+		return
+	}
+
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.logFile == nil {
+		g := m.cfg.Get()
+		lf, err := openRotatingLogFile(dir, state.sub.Driver, state.sub.Source, g.SubscriptionLogMaxSize, g.SubscriptionLogMaxFiles)
+		if err != nil {
+			m.log.Warn("open subscription log file failed", "driver", state.sub.Driver, "source", state.sub.Source, "error", err)
+			return
+		}
+		state.logFile = lf
+	}
+	if err := state.logFile.write(line); err != nil {
+		m.log.Warn("write subscription log file failed", "driver", state.sub.Driver, "source", state.sub.Source, "error", err)
+	}
+}
+
 // SubscriptionStatus is the API-facing view of a subscription with its runtime state.
 type SubscriptionStatus struct {
 	*store.Subscription
 	WorkerState string   `json:"worker_state"` // idle | starting | recording
 	PID         int      `json:"pid,omitempty"`
 	Logs        []string `json:"logs"`
+
+	// RestartAttempt is the current consecutive-failure count driving
+	// RestartPolicy's backoff; 0 once a cycle reset or a long-enough clean
+	// run has occurred.
+	RestartAttempt int `json:"restart_attempt,omitempty"`
+
+	// NextRestartAt is when the next scheduled restart will fire, nil if
+	// none is pending, so the UI can render a countdown.
+	NextRestartAt *time.Time `json:"next_restart_at,omitempty"`
+
+	// LastOutputAt is when the current worker last produced output, nil if
+	// it hasn't yet (or none is running). Lets the UI show how close a
+	// subscription is to StallTimeout.
+	LastOutputAt *time.Time `json:"last_output_at,omitempty"`
 }
 
 // Manager orchestrates subscription workers.
@@ -69,19 +160,36 @@ type Manager struct {
 	states   map[int64]*subState // subscriptionID → runtime state
 	pidIndex map[int]int64       // overseer PID → subscriptionID
 
-	cfg *config.Global
-	st  store.Store
-	oc  *overseer.Client
-	ctx context.Context
+	cfg    *config.Global
+	st     store.Store
+	oc     *overseer.Client
+	pub       events.Publisher
+	stream    *streamHub
+	signer    *auth.Signer
+	notifiers *notifier.Registry
+	metrics   *metrics.Registry
+	log       *slog.Logger
+	ctx       context.Context
+
+	// shuttingDown is set by Stop so OnExited and the reconciler stop
+	// scheduling new starts/restarts; wg tracks every in-flight
+	// startWorker/stopWorkerIntentionally goroutine plus any reconcile tick
+	// already in progress, so Stop can wait for all of them to finish.
+	shuttingDown bool
+	wg           sync.WaitGroup
 }
 
 // New creates a Manager.  Call SetOverseerClient then Start before use.
-func New(cfg *config.Global, st store.Store) *Manager {
+// logger is used for all of the manager's log output; pass slog.Default()
+// if the caller has no specific logger to inject.
+func New(cfg *config.Global, st store.Store, logger *slog.Logger) *Manager {
 	return &Manager{
 		states:   make(map[int64]*subState),
 		pidIndex: make(map[int]int64),
 		cfg:      cfg,
 		st:       st,
+		stream:   newStreamHub(),
+		log:      logger,
 	}
 }
 
@@ -90,9 +198,113 @@ func (m *Manager) SetOverseerClient(oc *overseer.Client) {
 	m.oc = oc
 }
 
+// SetPublisher wires in the CloudEvents publisher.  Optional — if never
+// called, worker events and state transitions simply aren't published.
+func (m *Manager) SetPublisher(pub events.Publisher) {
+	m.pub = pub
+}
+
+// SetSigner wires in the ticket signer used for authentication.  Optional —
+// if never called, IssueTicket/VerifyTicket return an error and router's
+// auth middleware treats every request as unauthenticated.
+func (m *Manager) SetSigner(s *auth.Signer) {
+	m.signer = s
+}
+
+// SetNotifiers wires in the alert registry.  Optional — if never called,
+// state-transition alerts are simply never sent.
+func (m *Manager) SetNotifiers(r *notifier.Registry) {
+	m.notifiers = r
+}
+
+// SetMetrics wires in the Prometheus metrics registry.  Optional — if never
+// called, RecordWorkerEvent counts simply aren't tallied and GatherMetrics
+// returns nil.
+func (m *Manager) SetMetrics(r *metrics.Registry) {
+	m.metrics = r
+}
+
+// recordWorkerEvent persists ev to the store and, if a metrics registry is
+// wired in, tallies it for sticky_worker_events_total. Store failures are
+// logged, never returned — metrics/persistence of an event must not affect
+// the worker lifecycle that triggered it.
+func (m *Manager) recordWorkerEvent(ctx context.Context, target store.Target, pid int, evType store.EventType, exitCode *int) {
+	if err := m.st.RecordWorkerEvent(ctx, target, pid, evType, exitCode); err != nil {
+		m.log.Warn("record worker event failed", "driver", target.Sub.Driver, "source", target.Sub.Source, "event_type", evType, "error", err)
+	}
+	if m.metrics != nil {
+		m.metrics.RecordWorkerEvent(string(evType), target.Sub.Driver)
+	}
+}
+
+// notifyState builds a notifier.Event for sub's current state (including a
+// short tail of recent worker events) and dispatches it to every registered
+// notifier whose filter matches. Delivery failures are logged, never
+// returned — notification delivery must not affect subscription lifecycle.
+func (m *Manager) notifyState(ctx context.Context, sub *store.Subscription, errMsg string) {
+	if m.notifiers == nil {
+		return
+	}
+	recent, err := m.st.RecentWorkerEvents(ctx, sub.ID, 5)
+	if err != nil {
+		m.log.Warn("recent worker events lookup failed", "driver", sub.Driver, "source", sub.Source, "error", err)
+	}
+	ev := notifier.Event{
+		Subscription: subKey(sub.Driver, sub.Source),
+		State:        sub.State,
+		ErrorMessage: errMsg,
+		Recent:       recent,
+		Time:         time.Now().UTC(),
+	}
+	m.notifiers.Dispatch(ctx, ev, func(name string, err error) {
+		m.log.Warn("notifier delivery failed", "notifier", name, "driver", sub.Driver, "source", sub.Source, "error", err)
+	})
+}
+
+// publish feeds the stream hub (for SSE subscribers) and, if a Publisher is
+// wired in, builds and sends a CloudEvents envelope. Never returns an error:
+// a sink outage or a quiet stream must not affect the worker lifecycle that
+// triggered the event.
+func (m *Manager) publish(ctx context.Context, typ string, sub *store.Subscription, data any) {
+	m.stream.publish(sub.ID, StreamEvent{
+		Subscription: subKey(sub.Driver, sub.Source),
+		Kind:         streamKindFor(typ),
+		Data:         data,
+		Time:         time.Now().UTC(),
+	})
+
+	if m.pub == nil {
+		return
+	}
+	m.pub.Publish(ctx, events.Event{
+		SpecVersion: "1.0",
+		Type:        typ,
+		Source:      fmt.Sprintf("/drivers/%s/%s", sub.Driver, sub.Source),
+		ID:          events.NewID(),
+		Time:        time.Now().UTC(),
+		Subject:     subKey(sub.Driver, sub.Source),
+		Data:        data,
+	})
+}
+
+// streamKindFor classifies a CloudEvents type for StreamEvent.Kind.
+func streamKindFor(typ string) string {
+	switch typ {
+	case events.TypeStateChanged:
+		return "state_changed"
+	case events.TypeThresholdExceeded:
+		return "threshold_exceeded"
+	case events.TypeRestartScheduled:
+		return "restart_scheduled"
+	default:
+		return "worker_event"
+	}
+}
+
 // Start loads active subscriptions, reconciles with any already-running
-// overseer workers, starts workers for anything not yet covered, and
-// launches the periodic reconciler.
+// overseer workers, and starts workers for anything not yet covered. Call
+// Serve (typically via a supervisor.Supervisor) afterwards to run the
+// periodic reconciler.
 func (m *Manager) Start(ctx context.Context) error {
 	m.ctx = ctx
 
@@ -120,24 +332,32 @@ func (m *Manager) Start(ctx context.Context) error {
 			m.mu.Lock()
 			m.pidIndex[pid] = sub.ID
 			m.mu.Unlock()
-			log.Printf("manager: claimed existing worker pid=%d for %s/%s", pid, sub.Driver, sub.Source)
-			state.addLog(fmt.Sprintf("[system] claimed existing worker (pid=%d)", pid))
+			m.log.Info("claimed existing worker", "pid", pid, "driver", sub.Driver, "source", sub.Source)
+			m.logLine(state, fmt.Sprintf("[system] claimed existing worker (pid=%d)", pid))
 		} else {
-			go m.startWorker(sub.ID)
+			m.trackedGo(func() { m.startWorker(sub.ID) })
 		}
 	}
 
-	go m.reconcileLoop(ctx)
 	return nil
 }
 
+// Serve implements supervisor.Service: it runs the periodic reconciler
+// until ctx is cancelled. Start must be called first to load subscriptions
+// and launch their initial workers — Serve only handles the ongoing
+// reconcile loop.
+func (m *Manager) Serve(ctx context.Context) error {
+	m.reconcileLoop(ctx)
+	return ctx.Err()
+}
+
 // fetchRunningByKey queries the overseer and returns a map of "driver/source" → PID
 // for all currently running workers.
 func (m *Manager) fetchRunningByKey(ctx context.Context) map[string]int {
 	out := make(map[string]int)
 	workers, err := m.oc.List(ctx)
 	if err != nil {
-		log.Printf("manager: startup overseer list failed (will start fresh): %v", err)
+		m.log.Warn("startup overseer list failed, will start fresh", "error", err)
 		return out
 	}
 	for _, w := range workers {
@@ -154,13 +374,26 @@ func (m *Manager) fetchRunningByKey(ctx context.Context) map[string]int {
 
 // ---- overseer event callbacks ----
 
-// OnOutput routes a stdout/stderr line to the correct subscription's log buffer.
-func (m *Manager) OnOutput(pid int, stream, data string, _ time.Time) {
+// OnOutput routes a stdout/stderr line to the correct subscription's log
+// buffer and streams it to any open SSE subscribers for that subscription.
+func (m *Manager) OnOutput(pid int, streamName, data string, _ time.Time) {
 	state := m.stateByPID(pid)
 	if state == nil {
 		return
 	}
-	state.addLog(fmt.Sprintf("[%s] %s", stream, data))
+	line := fmt.Sprintf("[%s] %s", streamName, data)
+	m.logLine(state, line)
+
+	state.mu.Lock()
+	sub := state.sub
+	state.lastOutputAt = time.Now()
+	state.mu.Unlock()
+	m.stream.publish(sub.ID, StreamEvent{
+		Subscription: subKey(sub.Driver, sub.Source),
+		Kind:         "log",
+		Data:         map[string]string{"stream": streamName, "line": data},
+		Time:         time.Now().UTC(),
+	})
 }
 
 // OnExited handles process termination:
@@ -190,17 +423,18 @@ func (m *Manager) OnExited(pid int, exitCode int, _ time.Time) {
 	sub := state.sub
 	state.mu.Unlock()
 
-	state.addLog(fmt.Sprintf("[system] process pid=%d exited (code %d)", pid, exitCode))
-	log.Printf("manager: worker pid=%d for %s/%s exited (code %d)", pid, sub.Driver, sub.Source, exitCode)
+	m.logLine(state, fmt.Sprintf("[system] process pid=%d exited (code %d)", pid, exitCode))
+	m.log.Info("worker exited", "pid", pid, "driver", sub.Driver, "source", sub.Source, "exit_code", exitCode)
 
-	g := m.cfg.Get()
+	g := m.effectiveConfig(context.Background(), subID)
 	target := store.Target{Sub: sub, Config: g}
 
 	// Record the exit event; this must happen BEFORE the threshold check so
 	// the current exit is included in ErrorExitsSince.
-	if err := m.st.RecordWorkerEvent(context.Background(), target, pid, store.EventExited, &exitCode); err != nil {
-		log.Printf("manager: record exited event for %s/%s: %v", sub.Driver, sub.Source, err)
-	}
+	m.recordWorkerEvent(context.Background(), target, pid, store.EventExited, &exitCode)
+	m.publish(context.Background(), events.TypeWorkerExited, sub, map[string]any{
+		"pid": pid, "exit_code": exitCode,
+	})
 
 	// Check whether the subscription should transition to error state.
 	if exitCode != 0 {
@@ -209,16 +443,53 @@ func (m *Manager) OnExited(pid int, exitCode int, _ time.Time) {
 		}
 	}
 
-	// Only restart if the subscription is still active and tracked.
+	policy := resolveRestartPolicy(g.RestartPolicy)
+
+	// Track consecutive failures for RestartPolicy's backoff, independent of
+	// checkErrorThreshold's windowed count: a non-zero exit grows it, a
+	// clean exit resets it once the process has run long enough to count as
+	// a successful cycle rather than an immediate crash.
+	state.mu.Lock()
+	if exitCode != 0 {
+		state.consecutiveFailures++
+	} else if time.Since(state.runStartedAt) >= parseDuration(policy.SuccessThreshold, time.Minute) {
+		state.consecutiveFailures = 0
+	}
+	failures := state.consecutiveFailures
+	state.mu.Unlock()
+
+	// Only restart if the subscription is still active and tracked, and the
+	// manager isn't mid-shutdown (Stop is recording EventStopped/oc.Stop for
+	// every tracked PID itself; scheduling a fresh restart here would race it).
 	m.mu.RLock()
 	_, stillTracked := m.states[subID]
+	down := m.shuttingDown
 	m.mu.RUnlock()
+	if !stillTracked || down || sub.State != store.StateActive {
+		return
+	}
 
-	if stillTracked && sub.State == store.StateActive {
-		d := parseDuration(g.RestartDelay, 30*time.Second)
-		state.addLog(fmt.Sprintf("[system] restarting in %s", d))
-		time.AfterFunc(d, func() { m.startWorker(subID) })
+	if policy.OnExhaustion == "fail" && policy.MaxAttempts > 0 && failures >= policy.MaxAttempts {
+		reason := fmt.Sprintf("restart policy exhausted after %d consecutive failures", failures)
+		m.logLine(state, fmt.Sprintf("[system] %s — use /reset-error to retry.", reason))
+		state.mu.Lock()
+		state.sub.State = store.StateError
+		state.sub.ErrorMessage = reason
+		state.awaitingRecovery = true
+		state.mu.Unlock()
+		if err := m.st.SetState(context.Background(), subID, store.StateError, reason); err != nil {
+			m.log.Warn("set error state failed", "driver", sub.Driver, "source", sub.Source, "error", err)
+		}
+		m.publish(context.Background(), events.TypeStateChanged, sub, map[string]any{
+			"state": store.StateError, "error_message": reason,
+		})
+		m.notifyState(context.Background(), sub, reason)
+		return
 	}
+
+	d := nextRestartDelay(policy, failures)
+	m.logLine(state, fmt.Sprintf("[system] restarting in %s (attempt %d)", d, failures))
+	m.scheduleRestart(sub, state, d, failures)
 }
 
 // checkErrorThreshold queries the store for the windowed error count and
@@ -236,7 +507,7 @@ func (m *Manager) checkErrorThreshold(ctx context.Context, subID int64, sub *sto
 	//   • now − errorWindow.
 	cycleStart, err := m.st.CycleResetAt(ctx, subID)
 	if err != nil {
-		log.Printf("manager: CycleResetAt for %s/%s: %v", sub.Driver, sub.Source, err)
+		m.log.Warn("CycleResetAt failed", "driver", sub.Driver, "source", sub.Source, "error", err)
 	}
 	windowStart := time.Now().Add(-errorWindow)
 	since := windowStart
@@ -246,7 +517,7 @@ func (m *Manager) checkErrorThreshold(ctx context.Context, subID int64, sub *sto
 
 	errCount, err := m.st.ErrorExitsSince(ctx, subID, since)
 	if err != nil {
-		log.Printf("manager: ErrorExitsSince for %s/%s: %v", sub.Driver, sub.Source, err)
+		m.log.Warn("ErrorExitsSince failed", "driver", sub.Driver, "source", sub.Source, "error", err)
 		return false
 	}
 
@@ -257,26 +528,100 @@ func (m *Manager) checkErrorThreshold(ctx context.Context, subID int64, sub *sto
 	reason := fmt.Sprintf(
 		"%d non-intentional error exit(s) within %s (threshold: %d)",
 		errCount, errorWindow, threshold)
-	log.Printf("manager: %s/%s exceeded error threshold: %s", sub.Driver, sub.Source, reason)
+	m.log.Warn("error threshold exceeded", "driver", sub.Driver, "source", sub.Source, "reason", reason)
+	m.publish(ctx, events.TypeThresholdExceeded, sub, map[string]any{
+		"error_count": errCount, "threshold": threshold, "reason": reason,
+	})
 
 	state := m.stateByID(subID)
 	if state != nil {
-		state.addLog("[system] error threshold reached — recording stopped. Use /reset-error to retry.")
+		m.logLine(state, "[system] error threshold reached — recording stopped. Use /reset-error to retry.")
 		state.mu.Lock()
 		state.sub.State = store.StateError
 		state.sub.ErrorMessage = reason
+		state.awaitingRecovery = true
 		state.mu.Unlock()
 	}
 
 	if err := m.st.SetState(ctx, subID, store.StateError, reason); err != nil {
-		log.Printf("manager: set error state for %s/%s: %v", sub.Driver, sub.Source, err)
+		m.log.Warn("set error state failed", "driver", sub.Driver, "source", sub.Source, "error", err)
 	}
+	m.publish(ctx, events.TypeStateChanged, sub, map[string]any{
+		"state": store.StateError, "error_message": reason,
+	})
+	m.notifyState(ctx, sub, reason)
 	return true
 }
 
+// effectiveConfig returns the global config with subID's stored overrides
+// (if any) layered on top, for use by worker lifecycle code paths that only
+// have a subscription ID on hand.
+func (m *Manager) effectiveConfig(ctx context.Context, subID int64) config.Data {
+	g := m.cfg.Get()
+	overrides, err := m.st.GetSubscriptionConfig(ctx, subID)
+	if err != nil || len(overrides) == 0 {
+		return g
+	}
+	merged, err := config.MergeOverrides(g, overrides)
+	if err != nil {
+		m.log.Warn("merge config overrides failed", "subscription_id", subID, "error", err)
+		return g
+	}
+	return merged
+}
+
 // ---- worker lifecycle ----
 
+// trackedGo runs fn in its own goroutine, tracked by m.wg so Stop can wait
+// for every in-flight startWorker/stopWorkerIntentionally call to finish
+// before the process tears down.
+func (m *Manager) trackedGo(fn func()) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		fn()
+	}()
+}
+
+// scheduleRestart arranges for startWorker(sub.ID) to run after d, tracked by
+// m.wg and recorded on state so Stop can cancel it. A no-op if shutdown has
+// begun by the time the timer fires. attempt is the consecutive-failure
+// count this delay was computed from (0 for a user-requested restart), and
+// is only used to annotate the published event.
+func (m *Manager) scheduleRestart(sub *store.Subscription, state *subState, d time.Duration, attempt int) {
+	state.mu.Lock()
+	state.nextRestartAt = time.Now().Add(d)
+	state.mu.Unlock()
+
+	m.publish(context.Background(), events.TypeRestartScheduled, sub, map[string]any{
+		"delay": d.String(), "attempt": attempt,
+	})
+
+	m.wg.Add(1)
+	timer := time.AfterFunc(d, func() {
+		defer m.wg.Done()
+		m.mu.RLock()
+		down := m.shuttingDown
+		m.mu.RUnlock()
+		if down {
+			return
+		}
+		m.startWorker(sub.ID)
+	})
+
+	state.mu.Lock()
+	state.restartTimer = timer
+	state.mu.Unlock()
+}
+
 func (m *Manager) startWorker(subID int64) {
+	m.mu.RLock()
+	down := m.shuttingDown
+	m.mu.RUnlock()
+	if down {
+		return
+	}
+
 	state := m.stateByID(subID)
 	if state == nil {
 		return
@@ -300,7 +645,7 @@ func (m *Manager) startWorker(subID int64) {
 		state.mu.Unlock()
 	}()
 
-	g := m.cfg.Get()
+	g := m.effectiveConfig(context.Background(), subID)
 	args := buildArgs(state.sub.Driver, state.sub.Source, g)
 
 	ctx, cancel := context.WithTimeout(m.ctx, 20*time.Second)
@@ -308,8 +653,8 @@ func (m *Manager) startWorker(subID int64) {
 
 	pid, err := m.oc.Start(ctx, args)
 	if err != nil {
-		log.Printf("manager: start worker for %s/%s: %v", state.sub.Driver, state.sub.Source, err)
-		state.addLog(fmt.Sprintf("[system] start failed: %v", err))
+		m.log.Warn("start worker failed", "driver", state.sub.Driver, "source", state.sub.Source, "error", err)
+		m.logLine(state, fmt.Sprintf("[system] start failed: %v", err))
 		// Don't count overseer-start failures as recorder errors; the
 		// reconciler will retry.
 		return
@@ -317,6 +662,9 @@ func (m *Manager) startWorker(subID int64) {
 
 	state.mu.Lock()
 	state.pid = pid
+	state.runStartedAt = time.Now()
+	state.lastOutputAt = time.Time{}
+	state.nextRestartAt = time.Time{}
 	state.mu.Unlock()
 
 	m.mu.Lock()
@@ -324,32 +672,49 @@ func (m *Manager) startWorker(subID int64) {
 	m.mu.Unlock()
 
 	target := store.Target{Sub: state.sub, Config: g}
-	if err := m.st.RecordWorkerEvent(ctx, target, pid, store.EventStarted, nil); err != nil {
-		log.Printf("manager: record started event for %s/%s: %v", state.sub.Driver, state.sub.Source, err)
+	m.recordWorkerEvent(ctx, target, pid, store.EventStarted, nil)
+	m.publish(ctx, events.TypeWorkerStarted, state.sub, map[string]any{"pid": pid})
+
+	state.mu.Lock()
+	recovered := state.awaitingRecovery
+	state.awaitingRecovery = false
+	state.mu.Unlock()
+	if recovered {
+		m.notifyState(ctx, state.sub, "")
 	}
 
-	state.addLog(fmt.Sprintf("[system] recorder started (pid=%d)", pid))
-	log.Printf("manager: started worker pid=%d for %s/%s", pid, state.sub.Driver, state.sub.Source)
+	m.logLine(state, fmt.Sprintf("[system] recorder started (pid=%d)", pid))
+	m.log.Info("started worker", "pid", pid, "driver", state.sub.Driver, "source", state.sub.Source)
 }
 
 // stopWorkerIntentionally records EventStopped (establishing a cycle boundary
 // and marking the subsequent exit as intentional) and sends SIGTERM.
-func (m *Manager) stopWorkerIntentionally(state *subState, pid int) {
-	g := m.cfg.Get()
+// stopWorkerIntentionally stops pid on the manager's own initiative (as
+// opposed to the process exiting on its own, which goes through OnExited).
+// kind is the WorkerEvent recorded before the signal is sent:
+//   - store.EventStopped for a user action or shutdown drain — excluded from
+//     error counting (see its doc comment) and resets the consecutive-failure
+//     streak, since it establishes a new cycle-reset boundary.
+//   - store.EventStalled for a reconciler-detected stall — the streak is left
+//     alone. The stall does not add to the store's error count: the PID is
+//     dropped from pidIndex below, so OnExited records no EventExited for it.
+func (m *Manager) stopWorkerIntentionally(state *subState, pid int, kind store.EventType) {
+	g := m.effectiveConfig(context.Background(), state.sub.ID)
 	target := store.Target{Sub: state.sub, Config: g}
 
 	// Record BEFORE sending the signal so the exit that follows can be
-	// correlated by PID and excluded from error counting.
-	if err := m.st.RecordWorkerEvent(context.Background(), target, pid, store.EventStopped, nil); err != nil {
-		log.Printf("manager: record stopped event for %s/%s: %v",
-			state.sub.Driver, state.sub.Source, err)
-	}
+	// correlated by PID.
+	m.recordWorkerEvent(context.Background(), target, pid, kind, nil)
+	m.publish(context.Background(), events.TypeWorkerStopped, state.sub, map[string]any{"pid": pid, "reason": string(kind)})
 
 	// Clear the PID and pidIndex now; OnExited will be a no-op for this PID.
 	state.mu.Lock()
 	if state.pid == pid {
 		state.pid = 0
 	}
+	if kind == store.EventStopped {
+		state.consecutiveFailures = 0
+	}
 	state.mu.Unlock()
 
 	m.mu.Lock()
@@ -357,7 +722,118 @@ func (m *Manager) stopWorkerIntentionally(state *subState, pid int) {
 	m.mu.Unlock()
 
 	if err := m.oc.Stop(pid); err != nil {
-		log.Printf("manager: stop pid=%d: %v", pid, err)
+		m.log.Warn("stop worker failed", "pid", pid, "error", err)
+	}
+}
+
+// Stop performs a graceful shutdown: it marks the manager as shutting down
+// (so OnExited and the reconciler stop scheduling new starts and restarts),
+// stops every tracked worker in parallel (EventStopped + SIGTERM), waits for
+// the overseer to confirm each has actually exited — escalating to SIGKILL
+// for any still running once ShutdownGrace elapses — and finally waits for
+// every in-flight startWorker/stopWorkerIntentionally goroutine to finish.
+// Returns ctx's error if ctx expires before shutdown completes. Call once,
+// before cancelling the context Start/Serve were given, so the overseer
+// connection used here is still up.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if m.shuttingDown {
+		m.mu.Unlock()
+		return nil
+	}
+	m.shuttingDown = true
+	states := make([]*subState, 0, len(m.states))
+	for _, s := range m.states {
+		states = append(states, s)
+	}
+	m.mu.Unlock()
+
+	// Cancel any restart not yet fired; one that already fired will see
+	// shuttingDown and no-op instead of calling startWorker.
+	for _, state := range states {
+		state.mu.Lock()
+		timer := state.restartTimer
+		state.restartTimer = nil
+		state.mu.Unlock()
+		if timer != nil && timer.Stop() {
+			m.wg.Done() // timer didn't fire; its scheduled wg.Done() never runs
+		}
+	}
+
+	// Record EventStopped and send SIGTERM for every tracked worker, in parallel.
+	var stopWg sync.WaitGroup
+	pending := make(map[int]struct{})
+	for _, state := range states {
+		state.mu.Lock()
+		pid := state.pid
+		state.mu.Unlock()
+		if pid == 0 {
+			continue
+		}
+		pending[pid] = struct{}{}
+		stopWg.Add(1)
+		go func(state *subState, pid int) {
+			defer stopWg.Done()
+			m.stopWorkerIntentionally(state, pid, store.EventStopped)
+		}(state, pid)
+	}
+	stopWg.Wait()
+
+	// stopWorkerIntentionally already cleared pidIndex for these PIDs, so
+	// OnExited won't fire for them; poll the overseer directly (same
+	// approach reconcile uses) to find out when each has actually exited.
+	grace := parseDuration(m.cfg.Get().ShutdownGrace, 15*time.Second)
+	deadline := time.Now().Add(grace)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		workers, err := m.oc.List(ctx)
+		if err != nil {
+			m.log.Warn("stop: overseer list failed", "error", err)
+			continue
+		}
+		running := make(map[int]struct{}, len(workers))
+		for _, w := range workers {
+			if w.State == "running" {
+				running[w.PID] = struct{}{}
+			}
+		}
+		for pid := range pending {
+			if _, ok := running[pid]; !ok {
+				delete(pending, pid)
+			}
+		}
+
+		if len(pending) > 0 && time.Now().After(deadline) {
+			for pid := range pending {
+				m.log.Warn("shutdown grace expired, killing worker", "pid", pid)
+				if err := m.oc.Kill(pid); err != nil {
+					m.log.Warn("kill worker failed", "pid", pid, "error", err)
+				}
+			}
+			break
+		}
+	}
+
+	// Wait for every in-flight startWorker/stopWorkerIntentionally goroutine
+	// (and any reconcile tick already in progress) to finish.
+	waitDone := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(waitDone)
+	}()
+	select {
+	case <-waitDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -382,7 +858,7 @@ func (m *Manager) Subscribe(ctx context.Context, driver, source string) (*Subscr
 	}
 	m.mu.Unlock()
 
-	go m.startWorker(sub.ID)
+	m.trackedGo(func() { m.startWorker(sub.ID) })
 	return m.statusFor(sub.ID), nil
 }
 
@@ -399,6 +875,7 @@ func (m *Manager) Unsubscribe(ctx context.Context, driver, source string) error
 	if err := m.st.SetState(ctx, sub.ID, store.StateInactive, ""); err != nil {
 		return err
 	}
+	m.publish(ctx, events.TypeStateChanged, sub, map[string]any{"state": store.StateInactive})
 
 	m.mu.Lock()
 	state, ok := m.states[sub.ID]
@@ -410,9 +887,16 @@ func (m *Manager) Unsubscribe(ctx context.Context, driver, source string) error
 	if ok {
 		state.mu.Lock()
 		pid := state.pid
+		logFile := state.logFile
+		state.logFile = nil
 		state.mu.Unlock()
 		if pid > 0 {
-			m.stopWorkerIntentionally(state, pid)
+			m.stopWorkerIntentionally(state, pid, store.EventStopped)
+		}
+		if logFile != nil {
+			if err := logFile.close(); err != nil {
+				m.log.Warn("close subscription log file failed", "driver", driver, "source", source, "error", err)
+			}
 		}
 	}
 	return nil
@@ -429,6 +913,7 @@ func (m *Manager) Pause(ctx context.Context, driver, source string) (*Subscripti
 	if err := m.st.SetState(ctx, sub.ID, store.StatePaused, ""); err != nil {
 		return nil, err
 	}
+	m.publish(ctx, events.TypeStateChanged, sub, map[string]any{"state": store.StatePaused})
 
 	state.mu.Lock()
 	state.sub.State = store.StatePaused
@@ -437,7 +922,7 @@ func (m *Manager) Pause(ctx context.Context, driver, source string) (*Subscripti
 	state.mu.Unlock()
 
 	if pid > 0 {
-		m.stopWorkerIntentionally(state, pid)
+		m.stopWorkerIntentionally(state, pid, store.EventStopped)
 	}
 
 	return m.statusFor(sub.ID), nil
@@ -455,13 +940,14 @@ func (m *Manager) Resume(ctx context.Context, driver, source string) (*Subscript
 	if err := m.st.SetState(ctx, sub.ID, store.StateActive, ""); err != nil {
 		return nil, err
 	}
+	m.publish(ctx, events.TypeStateChanged, sub, map[string]any{"state": store.StateActive})
 
 	state.mu.Lock()
 	state.sub.State = store.StateActive
 	state.sub.ErrorMessage = ""
 	state.mu.Unlock()
 
-	go m.startWorker(sub.ID)
+	m.trackedGo(func() { m.startWorker(sub.ID) })
 	return m.statusFor(sub.ID), nil
 }
 
@@ -480,22 +966,30 @@ func (m *Manager) ResetError(ctx context.Context, driver, source string) (*Subsc
 
 	// Synthetic stopped event — no live process, pid=0 is fine; we just need
 	// the timestamp marker so CycleResetAt has a 'stopped' to pivot from.
-	g := m.cfg.Get()
+	g := m.effectiveConfig(ctx, sub.ID)
 	target := store.Target{Sub: sub, Config: g}
-	if err := m.st.RecordWorkerEvent(ctx, target, 0, store.EventStopped, nil); err != nil {
-		log.Printf("manager: record reset stopped event for %s/%s: %v", driver, source, err)
-	}
+	m.recordWorkerEvent(ctx, target, 0, store.EventStopped, nil)
+	m.publish(ctx, events.TypeWorkerStopped, sub, map[string]any{"pid": 0})
 
 	if err := m.st.SetState(ctx, sub.ID, store.StateActive, ""); err != nil {
 		return nil, err
 	}
+	m.publish(ctx, events.TypeStateChanged, sub, map[string]any{"state": store.StateActive})
 
 	state.mu.Lock()
 	state.sub.State = store.StateActive
 	state.sub.ErrorMessage = ""
+	// Left set so the startWorker call below also emits a "recovered"
+	// notification once it actually starts recording again.
+	state.awaitingRecovery = true
+	state.consecutiveFailures = 0
 	state.mu.Unlock()
 
-	go m.startWorker(sub.ID)
+	sub.State = store.StateActive
+	sub.ErrorMessage = ""
+	m.notifyState(ctx, sub, "")
+
+	m.trackedGo(func() { m.startWorker(sub.ID) })
 	return m.statusFor(sub.ID), nil
 }
 
@@ -515,15 +1009,18 @@ func (m *Manager) Restart(ctx context.Context, driver, source string) (*Subscrip
 		return nil, fmt.Errorf("subscription %s/%s has no running worker", driver, source)
 	}
 
-	m.stopWorkerIntentionally(state, pid)
+	m.stopWorkerIntentionally(state, pid, store.EventStopped)
 
 	// startWorker will be triggered by OnExited, but since we cleared pidIndex
 	// before OnExited fires, OnExited won't know about this PID.  Schedule the
-	// restart directly.
+	// restart directly, at the policy's base (first-attempt) delay — this is
+	// a user-requested restart, not a failure, so consecutiveFailures was
+	// just reset by stopWorkerIntentionally above.
 	g := m.cfg.Get()
-	d := parseDuration(g.RestartDelay, 30*time.Second)
-	state.addLog(fmt.Sprintf("[system] restarting in %s (user request)", d))
-	time.AfterFunc(d, func() { m.startWorker(sub.ID) })
+	policy := resolveRestartPolicy(g.RestartPolicy)
+	d := nextRestartDelay(policy, 1)
+	m.logLine(state, fmt.Sprintf("[system] restarting in %s (user request)", d))
+	m.scheduleRestart(sub, state, d, 0)
 
 	return m.statusFor(sub.ID), nil
 }
@@ -571,7 +1068,11 @@ func (m *Manager) ListVisible(ctx context.Context) ([]*SubscriptionStatus, error
 }
 
 // GetLogs returns the in-memory log buffer for a subscription.
-func (m *Manager) GetLogs(ctx context.Context, driver, source string) ([]string, error) {
+// GetLogs returns driver/source's recent log lines. tail, if positive, asks
+// for that many lines instead of whatever the in-memory ring buffer holds;
+// if SubscriptionLogDir is set and tail exceeds what's in memory, the
+// remainder is read from the rotated on-disk log files.
+func (m *Manager) GetLogs(ctx context.Context, driver, source string, tail int) ([]string, error) {
 	sub, err := m.st.GetSubscriptionByKey(ctx, driver, source)
 	if err != nil {
 		return nil, err
@@ -579,11 +1080,54 @@ func (m *Manager) GetLogs(ctx context.Context, driver, source string) ([]string,
 	if sub == nil || sub.State == store.StateInactive {
 		return nil, fmt.Errorf("subscription %s/%s not found", driver, source)
 	}
+
 	state := m.stateByID(sub.ID)
-	if state == nil {
-		return []string{}, nil
+	var logs []string
+	if state != nil {
+		logs = state.getLogs()
+	}
+
+	if tail <= 0 || len(logs) >= tail {
+		if tail > 0 && len(logs) > tail {
+			logs = logs[len(logs)-tail:]
+		}
+		return logs, nil
+	}
+
+	dir := m.cfg.Get().SubscriptionLogDir
+	if dir == "" {
+		return logs, nil
+	}
+	diskLogs, err := readLogTail(dir, driver, source, tail)
+	if err != nil {
+		m.log.Warn("read subscription log file failed", "driver", driver, "source", source, "error", err)
+		return logs, nil
+	}
+	// diskLogs already includes everything the ring buffer would have had,
+	// since every line written to logs is also written to disk — so it
+	// supersedes rather than prepends to the in-memory slice.
+	if len(diskLogs) > tail {
+		diskLogs = diskLogs[len(diskLogs)-tail:]
 	}
-	return state.getLogs(), nil
+	return diskLogs, nil
+}
+
+// LogFilePath returns the path of driver/source's current on-disk log file,
+// for an HTTP handler that wants to stream or download the raw file. Errors
+// if SubscriptionLogDir isn't configured or the subscription doesn't exist.
+func (m *Manager) LogFilePath(ctx context.Context, driver, source string) (string, error) {
+	sub, err := m.st.GetSubscriptionByKey(ctx, driver, source)
+	if err != nil {
+		return "", err
+	}
+	if sub == nil || sub.State == store.StateInactive {
+		return "", fmt.Errorf("subscription %s/%s not found", driver, source)
+	}
+	dir := m.cfg.Get().SubscriptionLogDir
+	if dir == "" {
+		return "", fmt.Errorf("subscription log dir is not configured")
+	}
+	return logFilePath(dir, driver, source), nil
 }
 
 // GetWorkerEvents returns persisted worker lifecycle events for a subscription.
@@ -598,10 +1142,260 @@ func (m *Manager) GetWorkerEvents(ctx context.Context, driver, source string, li
 	return m.st.RecentWorkerEvents(ctx, sub.ID, limit)
 }
 
+// StreamSubscribe subscribes to live updates (worker events, state
+// transitions, log lines) for one subscription. backlog holds any buffered
+// events with ID > sinceID, for a client resuming after a reconnect via
+// ?since=<event-id>; cancel must be called once the caller is done reading.
+func (m *Manager) StreamSubscribe(ctx context.Context, driver, source string, sinceID int64) (ch <-chan StreamEvent, backlog []StreamEvent, cancel func(), err error) {
+	sub, err := m.st.GetSubscriptionByKey(ctx, driver, source)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if sub == nil || sub.State == store.StateInactive {
+		return nil, nil, nil, fmt.Errorf("subscription %s/%s not found", driver, source)
+	}
+
+	subscriber := m.stream.subscribe(sub.ID)
+	backlog = m.stream.since(sub.ID, sinceID)
+	cancel = func() { m.stream.unsubscribe(sub.ID, subscriber) }
+	return subscriber.ch, backlog, cancel, nil
+}
+
+// StreamSubscribeAll subscribes to every subscription's events, for the
+// dashboard-facing multiplexed /api/stream endpoint. There's no per-subscriber
+// backlog here since event IDs aren't coordinated across subscriptions.
+func (m *Manager) StreamSubscribeAll() (ch <-chan StreamEvent, cancel func()) {
+	subscriber := m.stream.subscribeGlobal()
+	return subscriber.ch, func() { m.stream.unsubscribeGlobal(subscriber) }
+}
+
+// EventFilter narrows a Subscribe call to a subset of the stream; a zero
+// EventFilter matches everything. Kinds matches StreamEvent.Kind (e.g.
+// "worker_event", "state_changed", "threshold_exceeded",
+// "restart_scheduled", "log"); Driver/Source, if either is set, matches the
+// subscription's "{driver}/{source}" key.
+type EventFilter struct {
+	Kinds          []string
+	Driver, Source string
+}
+
+func (f EventFilter) matches(ev StreamEvent) bool {
+	if len(f.Kinds) > 0 {
+		found := false
+		for _, k := range f.Kinds {
+			if k == ev.Kind {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if (f.Driver != "" || f.Source != "") && ev.Subscription != subKey(f.Driver, f.Source) {
+		return false
+	}
+	return true
+}
+
+// Subscribe is the general-purpose event bus entry point used by
+// integrations that want a specific slice of the stream — e.g. an outbound
+// webhook dispatcher — without polling GetStatus/GetLogs. It layers
+// filtering over the same global fan-out StreamSubscribeAll uses; cancel
+// closes ch and removes the subscription.
+func (m *Manager) Subscribe(filter EventFilter) (ch <-chan StreamEvent, cancel func()) {
+	subscriber := m.stream.subscribeGlobal()
+	out := make(chan StreamEvent, cap(subscriber.ch))
+	done := make(chan struct{})
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case ev, ok := <-subscriber.ch:
+				if !ok {
+					return
+				}
+				if !filter.matches(ev) {
+					continue
+				}
+				select {
+				case out <- ev:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	cancel = func() {
+		m.stream.unsubscribeGlobal(subscriber)
+		close(done)
+	}
+	return out, cancel
+}
+
+// DeadLetterEvents returns deliveries that exhausted their sink's retry
+// policy, newest first.
+func (m *Manager) DeadLetterEvents(ctx context.Context, limit int) ([]store.EventDelivery, error) {
+	return m.st.ListDeadLetters(ctx, limit)
+}
+
 func (m *Manager) GetConfig() config.Data             { return m.cfg.Get() }
 func (m *Manager) SetConfig(d config.Data) error      { return m.cfg.Set(d) }
 func (m *Manager) GetOverseerClient() *overseer.Client { return m.oc }
 
+// ObserveHTTPDuration records one HTTP request's duration for
+// sticky_http_request_duration_seconds. A no-op if no metrics registry has
+// been wired in via SetMetrics.
+func (m *Manager) ObserveHTTPDuration(path string, d time.Duration) {
+	if m.metrics != nil {
+		m.metrics.ObserveHTTPDuration(path, d)
+	}
+}
+
+// GatherMetrics renders the wired-in metrics registry — worker event
+// counts and HTTP durations — together with gauges computed fresh from
+// current state (subscription counts by state, overseer connectivity, and
+// the number of actively-recording workers) in Prometheus text exposition
+// format. Returns nil if no metrics registry has been wired in.
+func (m *Manager) GatherMetrics(ctx context.Context) []byte {
+	if m.metrics == nil {
+		return nil
+	}
+
+	subs, _ := m.ListVisible(ctx)
+	byState := make(map[string]int, 4)
+	recording := 0
+	for _, s := range subs {
+		byState[string(s.State)]++
+		if s.WorkerState == "recording" {
+			recording++
+		}
+	}
+
+	oc := m.GetOverseerClient()
+	connected := oc != nil && oc.IsConnected()
+
+	return m.metrics.Gather(metrics.Gauges{
+		SubscriptionsByState: byState,
+		OverseerConnected:    connected,
+		RecordingWorkers:     recording,
+	})
+}
+
+// GetSubscriptionConfig returns the stored config overrides for driver/source
+// as a sparse map keyed by config.Data's JSON field names. The returned map
+// is nil, not an error, when no overrides have been set.
+func (m *Manager) GetSubscriptionConfig(ctx context.Context, driver, source string) (map[string]any, error) {
+	sub, err := m.st.GetSubscriptionByKey(ctx, driver, source)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, fmt.Errorf("subscription %s/%s not found", driver, source)
+	}
+	return m.st.GetSubscriptionConfig(ctx, sub.ID)
+}
+
+// SetSubscriptionConfig replaces the stored config overrides for
+// driver/source. An empty map clears all overrides.
+func (m *Manager) SetSubscriptionConfig(ctx context.Context, driver, source string, overrides map[string]any) error {
+	sub, err := m.st.GetSubscriptionByKey(ctx, driver, source)
+	if err != nil {
+		return err
+	}
+	if sub == nil {
+		return fmt.Errorf("subscription %s/%s not found", driver, source)
+	}
+	return m.st.SetSubscriptionConfig(ctx, sub.ID, overrides)
+}
+
+// EffectiveConfig returns driver/source's merged config (global default with
+// overrides layered on top) along with the raw override map, so callers can
+// report per-field provenance.
+func (m *Manager) EffectiveConfig(ctx context.Context, driver, source string) (config.Data, map[string]any, error) {
+	overrides, err := m.GetSubscriptionConfig(ctx, driver, source)
+	if err != nil {
+		return config.Data{}, nil, err
+	}
+	merged, err := config.MergeOverrides(m.cfg.Get(), overrides)
+	if err != nil {
+		return config.Data{}, nil, err
+	}
+	return merged, overrides, nil
+}
+
+// ---- auth ----
+
+var errNoSigner = fmt.Errorf("manager: no ticket signer configured")
+
+// IssueTicket mints a new ticket for subject with scopes, valid for ttl.
+func (m *Manager) IssueTicket(subject string, scopes []string, ttl time.Duration) (string, auth.Ticket, error) {
+	if m.signer == nil {
+		return "", auth.Ticket{}, errNoSigner
+	}
+	return m.signer.Issue(subject, scopes, ttl)
+}
+
+// VerifyTicket authenticates token (signature, expiry, and revocation) and
+// returns its claims.
+func (m *Manager) VerifyTicket(ctx context.Context, token string) (auth.Ticket, error) {
+	if m.signer == nil {
+		return auth.Ticket{}, errNoSigner
+	}
+	claims, err := m.signer.Verify(token)
+	if err != nil {
+		return auth.Ticket{}, err
+	}
+	revoked, err := m.st.IsTicketRevoked(ctx, claims.ID)
+	if err != nil {
+		return auth.Ticket{}, err
+	}
+	if revoked {
+		return auth.Ticket{}, fmt.Errorf("auth: ticket %s has been revoked", claims.ID)
+	}
+	return claims, nil
+}
+
+// RevokeTicket marks a ticket ID as revoked, so a subsequent VerifyTicket
+// rejects it even though it has not yet expired.
+func (m *Manager) RevokeTicket(ctx context.Context, id string) error {
+	return m.st.RevokeTicket(ctx, id, time.Now().UTC())
+}
+
+// ---- notifiers ----
+
+var errNoNotifiers = fmt.Errorf("manager: no notifiers configured")
+
+// ListNotifiers returns the names of every registered notifier.
+func (m *Manager) ListNotifiers() []string {
+	if m.notifiers == nil {
+		return nil
+	}
+	return m.notifiers.List()
+}
+
+// TestNotifier sends a synthetic Event to the named notifier so operators
+// can validate a receiver without waiting for a real state transition.
+func (m *Manager) TestNotifier(ctx context.Context, name string) error {
+	if m.notifiers == nil {
+		return errNoNotifiers
+	}
+	n, ok := m.notifiers.ByName(name)
+	if !ok {
+		return fmt.Errorf("manager: no notifier named %q", name)
+	}
+	return n.Notify(ctx, notifier.Event{
+		Subscription: "test/test",
+		State:        store.StateError,
+		ErrorMessage: "synthetic test event from POST /api/notifiers/" + name + "/test",
+		Time:         time.Now().UTC(),
+	})
+}
+
 // ---- periodic reconciliation ----
 
 func (m *Manager) reconcileLoop(ctx context.Context) {
@@ -613,15 +1407,27 @@ func (m *Manager) reconcileLoop(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			// Tracked by m.wg so Stop, if called mid-tick, waits for this
+			// reconcile to finish (and see shuttingDown) before it can
+			// assume no new workers will be started.
+			m.wg.Add(1)
 			m.reconcile(ctx)
+			m.wg.Done()
 		}
 	}
 }
 
 func (m *Manager) reconcile(ctx context.Context) {
+	m.mu.RLock()
+	down := m.shuttingDown
+	m.mu.RUnlock()
+	if down {
+		return
+	}
+
 	workers, err := m.oc.List(ctx)
 	if err != nil {
-		log.Printf("manager: reconcile: overseer list: %v", err)
+		m.log.Warn("reconcile: overseer list failed", "error", err)
 		return
 	}
 
@@ -658,23 +1464,59 @@ func (m *Manager) reconcile(ctx context.Context) {
 		}
 		if pid > 0 {
 			if _, alive := runningPIDs[pid]; alive {
-				continue
-			}
-			log.Printf("manager: reconcile: pid=%d for %s/%s gone, restarting", pid, driver, source)
-			state.mu.Lock()
-			if state.pid == pid {
-				state.pid = 0
+				if stalledFor, stalled := m.checkStall(ctx, id, state); stalled {
+					m.log.Warn("reconcile: worker stalled, restarting", "pid", pid, "driver", driver, "source", source, "silent_for", stalledFor)
+					m.logLine(state, fmt.Sprintf("[system] no output for %s, worker considered stalled", stalledFor))
+					m.stopWorkerIntentionally(state, pid, store.EventStalled)
+				} else {
+					continue
+				}
+			} else {
+				m.log.Info("reconcile: worker gone, restarting", "pid", pid, "driver", driver, "source", source)
+				state.mu.Lock()
+				if state.pid == pid {
+					state.pid = 0
+				}
+				state.mu.Unlock()
+				m.mu.Lock()
+				if m.pidIndex[pid] == id {
+					delete(m.pidIndex, pid)
+				}
+				m.mu.Unlock()
+				m.logLine(state, "[system] worker gone (detected by reconciler), restarting")
 			}
-			state.mu.Unlock()
-			m.mu.Lock()
-			if m.pidIndex[pid] == id {
-				delete(m.pidIndex, pid)
-			}
-			m.mu.Unlock()
-			state.addLog("[system] worker gone (detected by reconciler), restarting")
 		}
-		go m.startWorker(id)
+		m.trackedGo(func() { m.startWorker(id) })
+	}
+}
+
+// checkStall reports whether id's active worker has gone silent for longer
+// than config.Data.StallTimeout, excluding the StallStartupGrace window
+// right after it started so slow-starting streams aren't killed. Returns
+// (0, false) if StallTimeout is unset (the default — stall detection off).
+func (m *Manager) checkStall(ctx context.Context, id int64, state *subState) (time.Duration, bool) {
+	g := m.effectiveConfig(ctx, id)
+	stallTimeout := parseDuration(g.StallTimeout, 0)
+	if stallTimeout <= 0 {
+		return 0, false
+	}
+	startupGrace := parseDuration(g.StallStartupGrace, 60*time.Second)
+
+	state.mu.Lock()
+	startedAt := state.runStartedAt
+	lastOutputAt := state.lastOutputAt
+	state.mu.Unlock()
+
+	if time.Since(startedAt) < startupGrace {
+		return 0, false
+	}
+
+	lastActivity := lastOutputAt
+	if lastActivity.IsZero() {
+		lastActivity = startedAt
 	}
+	silentFor := time.Since(lastActivity)
+	return silentFor, silentFor > stallTimeout
 }
 
 // ---- internal helpers ----
@@ -727,6 +1569,17 @@ func (m *Manager) statusFor(subID int64) *SubscriptionStatus {
 	sub := state.sub
 	pid := state.pid
 	starting := state.starting
+	failures := state.consecutiveFailures
+	var nextRestartAt *time.Time
+	if !state.nextRestartAt.IsZero() {
+		t := state.nextRestartAt
+		nextRestartAt = &t
+	}
+	var lastOutputAt *time.Time
+	if !state.lastOutputAt.IsZero() {
+		t := state.lastOutputAt
+		lastOutputAt = &t
+	}
 	logs := make([]string, len(state.logs))
 	copy(logs, state.logs)
 	state.mu.Unlock()
@@ -740,10 +1593,13 @@ func (m *Manager) statusFor(subID int64) *SubscriptionStatus {
 	}
 
 	return &SubscriptionStatus{
-		Subscription: sub,
-		WorkerState:  workerState,
-		PID:          pid,
-		Logs:         logs,
+		Subscription:   sub,
+		WorkerState:    workerState,
+		PID:            pid,
+		Logs:           logs,
+		RestartAttempt: failures,
+		NextRestartAt:  nextRestartAt,
+		LastOutputAt:   lastOutputAt,
 	}
 }
 
@@ -813,3 +1669,76 @@ func parseDuration(s string, def time.Duration) time.Duration {
 	}
 	return d
 }
+
+// DefaultRestartPolicy is used for any config.RestartPolicy field left at
+// its zero value. See resolveRestartPolicy.
+var DefaultRestartPolicy = config.RestartPolicy{
+	Mode:             "exponential",
+	Delay:            "30s",
+	MaxDelay:         "5m",
+	Multiplier:       2.0,
+	JitterPct:        0.1,
+	OnExhaustion:     "delay",
+	SuccessThreshold: "1m",
+}
+
+// resolveRestartPolicy fills zero-valued fields of p with DefaultRestartPolicy.
+func resolveRestartPolicy(p config.RestartPolicy) config.RestartPolicy {
+	if p.Mode == "" {
+		p.Mode = DefaultRestartPolicy.Mode
+	}
+	if p.Delay == "" {
+		p.Delay = DefaultRestartPolicy.Delay
+	}
+	if p.MaxDelay == "" {
+		p.MaxDelay = DefaultRestartPolicy.MaxDelay
+	}
+	if p.Multiplier <= 0 {
+		p.Multiplier = DefaultRestartPolicy.Multiplier
+	}
+	if p.JitterPct <= 0 {
+		p.JitterPct = DefaultRestartPolicy.JitterPct
+	}
+	if p.OnExhaustion == "" {
+		p.OnExhaustion = DefaultRestartPolicy.OnExhaustion
+	}
+	if p.SuccessThreshold == "" {
+		p.SuccessThreshold = DefaultRestartPolicy.SuccessThreshold
+	}
+	return p
+}
+
+// nextRestartDelay computes the restart delay for the n-th consecutive
+// failure (n >= 1; values below 1 are treated as 1, i.e. a first attempt).
+// In "fixed" mode the delay is always p.Delay; in "exponential" mode it is
+// p.Delay * p.Multiplier^(n-1) capped at p.MaxDelay — the first failure (n=1)
+// uses the base delay unmultiplied, each one after that grows it. Either way
+// the result is then spread by +/- p.JitterPct/2 so many subscriptions
+// failing together don't all retry in lockstep.
+func nextRestartDelay(p config.RestartPolicy, n int) time.Duration {
+	if n < 1 {
+		n = 1
+	}
+
+	max := parseDuration(p.MaxDelay, 5*time.Minute)
+
+	delayF := float64(parseDuration(p.Delay, 30*time.Second))
+	if p.Mode == "exponential" {
+		delayF *= math.Pow(p.Multiplier, float64(n-1))
+	}
+	// Cap in the float domain before converting back to time.Duration:
+	// for a persistently crashing source, n grows without bound and the
+	// exponential product can overflow int64, wrapping to a negative
+	// duration that would slip past a post-conversion comparison.
+	if delayF > float64(max) {
+		delayF = float64(max)
+	}
+	delay := time.Duration(delayF)
+
+	jitter := 1 + rand.Float64()*p.JitterPct - p.JitterPct/2
+	delay = time.Duration(float64(delay) * jitter)
+	if delay < 0 {
+		delay = 0
+	}
+	return delay
+}
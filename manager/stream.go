@@ -0,0 +1,150 @@
+package manager
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// streamRecentLimit bounds the in-memory backlog kept per subscription for
+// ?since resumption. Like subState's log buffer, this isn't persisted — a
+// reconnect after a backend restart starts from an empty backlog.
+const streamRecentLimit = 200
+
+// StreamEvent is a single item delivered to SSE subscribers and to
+// Manager.Subscribe: a worker lifecycle event, a subscription state
+// transition, a threshold/restart notice, or a tailed log line.
+type StreamEvent struct {
+	ID           int64  `json:"id"`
+	Subscription string `json:"subscription"` // "{driver}/{source}"
+	// Kind is one of "worker_event" | "state_changed" | "threshold_exceeded" |
+	// "restart_scheduled" | "log".
+	Kind string    `json:"kind"`
+	Data any       `json:"data"`
+	Time time.Time `json:"time"`
+}
+
+// streamSubscriber is one open SSE connection's delivery channel. Buffered
+// so a slow reader doesn't stall publish; a subscriber whose channel is full
+// has its oldest queued event evicted to make room for the new one, similar
+// to a pulsar-client-go consumer channel. dropped counts evictions, for
+// diagnosing a chronically slow reader.
+type streamSubscriber struct {
+	ch      chan StreamEvent
+	dropped atomic.Int64
+}
+
+// streamHub fans StreamEvents out to subscribers, keyed by subscription ID
+// for the per-subscription stream endpoint, plus a separate global set for
+// the multiplexed /api/stream endpoint used by dashboards.
+type streamHub struct {
+	mu     sync.Mutex
+	seq    int64
+	bySub  map[int64]map[*streamSubscriber]struct{}
+	global map[*streamSubscriber]struct{}
+	recent map[int64][]StreamEvent
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{
+		bySub:  make(map[int64]map[*streamSubscriber]struct{}),
+		global: make(map[*streamSubscriber]struct{}),
+		recent: make(map[int64][]StreamEvent),
+	}
+}
+
+func (h *streamHub) subscribe(subID int64) *streamSubscriber {
+	sub := &streamSubscriber{ch: make(chan StreamEvent, 32)}
+	h.mu.Lock()
+	if h.bySub[subID] == nil {
+		h.bySub[subID] = make(map[*streamSubscriber]struct{})
+	}
+	h.bySub[subID][sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+func (h *streamHub) unsubscribe(subID int64, sub *streamSubscriber) {
+	h.mu.Lock()
+	delete(h.bySub[subID], sub)
+	if len(h.bySub[subID]) == 0 {
+		delete(h.bySub, subID)
+	}
+	h.mu.Unlock()
+}
+
+func (h *streamHub) subscribeGlobal() *streamSubscriber {
+	sub := &streamSubscriber{ch: make(chan StreamEvent, 64)}
+	h.mu.Lock()
+	h.global[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+func (h *streamHub) unsubscribeGlobal(sub *streamSubscriber) {
+	h.mu.Lock()
+	delete(h.global, sub)
+	h.mu.Unlock()
+}
+
+// publish assigns ev a monotonic ID, appends it to subID's backlog, and
+// delivers it to every subscriber of subID plus every global subscriber.
+func (h *streamHub) publish(subID int64, ev StreamEvent) {
+	h.mu.Lock()
+	h.seq++
+	ev.ID = h.seq
+
+	buf := append(h.recent[subID], ev)
+	if len(buf) > streamRecentLimit {
+		buf = buf[len(buf)-streamRecentLimit:]
+	}
+	h.recent[subID] = buf
+
+	subs := make([]*streamSubscriber, 0, len(h.bySub[subID])+len(h.global))
+	for s := range h.bySub[subID] {
+		subs = append(subs, s)
+	}
+	for s := range h.global {
+		subs = append(subs, s)
+	}
+	h.mu.Unlock()
+
+	for _, s := range subs {
+		select {
+		case s.ch <- ev:
+			continue
+		default:
+		}
+
+		// Channel's full: evict the oldest queued event to make room rather
+		// than dropping the new one, so a slow reader still sees the most
+		// recent state.
+		select {
+		case <-s.ch:
+		default:
+		}
+		select {
+		case s.ch <- ev:
+		default:
+			// Lost the race with another publish refilling the slot just
+			// freed; count this event as dropped for this subscriber.
+		}
+		n := s.dropped.Add(1)
+		log.Printf("manager: stream subscriber backlog full, evicted oldest event for subscription %d (dropped=%d)", subID, n)
+	}
+}
+
+// since returns subID's backlogged events with ID > sinceID, oldest first,
+// for a client resuming after a reconnect via ?since=<event-id>.
+func (h *streamHub) since(subID int64, sinceID int64) []StreamEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []StreamEvent
+	for _, ev := range h.recent[subID] {
+		if ev.ID > sinceID {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
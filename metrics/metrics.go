@@ -0,0 +1,138 @@
+// Package metrics is a minimal Prometheus text-exposition-format registry,
+// hand-rolled rather than pulling in client_golang for the handful of
+// gauges, one counter, and one histogram sticky-backend exposes.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var defaultHTTPBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type workerEventKey struct {
+	eventType string
+	driver    string
+}
+
+// Registry accumulates process-lifetime counters and histograms. Gauge
+// values are not stored here — they're supplied fresh on every Gather call
+// via Gauges, since sticky_subscriptions and friends must always reflect
+// current store/manager state rather than a cached snapshot.
+type Registry struct {
+	mu           sync.Mutex
+	workerEvents map[workerEventKey]int64
+
+	httpBuckets []float64
+	httpCounts  map[string][]int64
+	httpSum     map[string]float64
+	httpTotal   map[string]int64
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		workerEvents: make(map[workerEventKey]int64),
+		httpBuckets:  defaultHTTPBuckets,
+		httpCounts:   make(map[string][]int64),
+		httpSum:      make(map[string]float64),
+		httpTotal:    make(map[string]int64),
+	}
+}
+
+// RecordWorkerEvent tallies one worker lifecycle event for
+// sticky_worker_events_total{type,driver}.
+func (r *Registry) RecordWorkerEvent(eventType, driver string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workerEvents[workerEventKey{eventType, driver}]++
+}
+
+// ObserveHTTPDuration records one request's duration for
+// sticky_http_request_duration_seconds{path}.
+func (r *Registry) ObserveHTTPDuration(path string, d time.Duration) {
+	seconds := d.Seconds()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	counts, ok := r.httpCounts[path]
+	if !ok {
+		counts = make([]int64, len(r.httpBuckets))
+		r.httpCounts[path] = counts
+	}
+	for i, le := range r.httpBuckets {
+		if seconds <= le {
+			counts[i]++
+		}
+	}
+	r.httpSum[path] += seconds
+	r.httpTotal[path]++
+}
+
+// Gauges holds point-in-time values the caller computes fresh at scrape
+// time — see Manager.GatherMetrics, which builds this from ListVisible and
+// the overseer client rather than from any cached count.
+type Gauges struct {
+	SubscriptionsByState map[string]int
+	OverseerConnected    bool
+	RecordingWorkers     int
+}
+
+// Gather renders the registry's counters and histograms, together with g,
+// in Prometheus text exposition format.
+func (r *Registry) Gather(g Gauges) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintln(&b, "# HELP sticky_subscriptions Number of subscriptions by state.")
+	fmt.Fprintln(&b, "# TYPE sticky_subscriptions gauge")
+	for state, count := range g.SubscriptionsByState {
+		fmt.Fprintf(&b, "sticky_subscriptions{state=%q} %d\n", state, count)
+	}
+
+	fmt.Fprintln(&b, "# HELP sticky_overseer_connected Whether the backend currently has an active overseer connection.")
+	fmt.Fprintln(&b, "# TYPE sticky_overseer_connected gauge")
+	fmt.Fprintf(&b, "sticky_overseer_connected %d\n", boolToInt(g.OverseerConnected))
+
+	fmt.Fprintln(&b, "# HELP sticky_recording_workers Number of subscriptions whose worker is currently recording.")
+	fmt.Fprintln(&b, "# TYPE sticky_recording_workers gauge")
+	fmt.Fprintf(&b, "sticky_recording_workers %d\n", g.RecordingWorkers)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fmt.Fprintln(&b, "# HELP sticky_worker_events_total Total worker lifecycle events recorded, by type and driver.")
+	fmt.Fprintln(&b, "# TYPE sticky_worker_events_total counter")
+	for k, v := range r.workerEvents {
+		fmt.Fprintf(&b, "sticky_worker_events_total{type=%q,driver=%q} %d\n", k.eventType, k.driver, v)
+	}
+
+	fmt.Fprintln(&b, "# HELP sticky_http_request_duration_seconds HTTP request duration in seconds, by path.")
+	fmt.Fprintln(&b, "# TYPE sticky_http_request_duration_seconds histogram")
+	for path, counts := range r.httpCounts {
+		var cumulative int64
+		for i, le := range r.httpBuckets {
+			cumulative += counts[i]
+			fmt.Fprintf(&b, "sticky_http_request_duration_seconds_bucket{path=%q,le=%q} %d\n", path, formatLE(le), cumulative)
+		}
+		fmt.Fprintf(&b, "sticky_http_request_duration_seconds_bucket{path=%q,le=\"+Inf\"} %d\n", path, r.httpTotal[path])
+		fmt.Fprintf(&b, "sticky_http_request_duration_seconds_sum{path=%q} %s\n", path, strconv.FormatFloat(r.httpSum[path], 'f', -1, 64))
+		fmt.Fprintf(&b, "sticky_http_request_duration_seconds_count{path=%q} %d\n", path, r.httpTotal[path])
+	}
+
+	return b.Bytes()
+}
+
+func boolToInt(v bool) int {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+func formatLE(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
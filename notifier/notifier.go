@@ -0,0 +1,188 @@
+// Package notifier delivers subscription state alerts (entering error,
+// recovering, and test pings) to operator-configured destinations —
+// webhooks, email, or nowhere at all.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"time"
+
+	"github.com/whisper-darkly/sticky-backend/config"
+	"github.com/whisper-darkly/sticky-backend/store"
+)
+
+// Event describes one subscription state change worth alerting on.
+type Event struct {
+	Subscription string             `json:"subscription"` // "{driver}/{source}"
+	State        store.State        `json:"state"`
+	ErrorMessage string             `json:"error_message,omitempty"`
+	Recent       []store.WorkerEvent `json:"recent_events,omitempty"`
+	Time         time.Time          `json:"time"`
+}
+
+// Notifier delivers Events to one destination.
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, ev Event) error
+}
+
+// ---- no-op ----
+
+type noopNotifier struct{ name string }
+
+func (n *noopNotifier) Name() string { return n.name }
+func (n *noopNotifier) Notify(context.Context, Event) error { return nil }
+
+// ---- webhook ----
+
+type webhookNotifier struct {
+	name string
+	url  string
+}
+
+func (n *webhookNotifier) Name() string { return n.name }
+
+func (n *webhookNotifier) Notify(ctx context.Context, ev Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("notifier %s: webhook returned %s", n.name, resp.Status)
+	}
+	return nil
+}
+
+// ---- smtp ----
+
+type smtpNotifier struct {
+	name string
+	host string // host:port
+	from string
+	to   []string
+}
+
+func (n *smtpNotifier) Name() string { return n.name }
+
+func (n *smtpNotifier) Notify(ctx context.Context, ev Event) error {
+	subject := fmt.Sprintf("[sticky] %s is now %s", ev.Subscription, ev.State)
+	body := fmt.Sprintf("Subscription: %s\nState: %s\nError: %s\nTime: %s\n",
+		ev.Subscription, ev.State, ev.ErrorMessage, ev.Time.Format(time.RFC3339))
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
+		n.from, joinAddrs(n.to), subject, body)
+
+	// smtp.SendMail has no ctx parameter; it's a quick single-shot dial so we
+	// don't bother plumbing cancellation through it.
+	return smtp.SendMail(n.host, nil, n.from, n.to, []byte(msg))
+}
+
+func joinAddrs(addrs []string) string {
+	out := ""
+	for i, a := range addrs {
+		if i > 0 {
+			out += ", "
+		}
+		out += a
+	}
+	return out
+}
+
+// ---- registry ----
+
+// Registry holds the set of configured notifiers and dispatches Events to
+// whichever ones match an event's state.
+type Registry struct {
+	notifiers []Notifier
+	filters   map[string][]store.State // notifier name → states it cares about; nil/empty means all
+}
+
+// NewRegistry builds a Registry from config.Data.Notifiers. Unknown kinds
+// are rejected so a typo in config surfaces immediately rather than
+// silently dropping alerts.
+func NewRegistry(cfgs []config.NotifierConfig) (*Registry, error) {
+	r := &Registry{filters: make(map[string][]store.State)}
+	for _, c := range cfgs {
+		var n Notifier
+		switch c.Kind {
+		case "webhook":
+			n = &webhookNotifier{name: c.Name, url: c.URL}
+		case "smtp":
+			n = &smtpNotifier{name: c.Name, host: c.Host, from: c.From, to: c.To}
+		case "noop":
+			n = &noopNotifier{name: c.Name}
+		default:
+			return nil, fmt.Errorf("notifier: unknown kind %q for notifier %q", c.Kind, c.Name)
+		}
+		r.notifiers = append(r.notifiers, n)
+
+		states := make([]store.State, 0, len(c.Filter))
+		for _, s := range c.Filter {
+			states = append(states, store.State(s))
+		}
+		r.filters[c.Name] = states
+	}
+	return r, nil
+}
+
+// List returns the names of every registered notifier.
+func (r *Registry) List() []string {
+	names := make([]string, 0, len(r.notifiers))
+	for _, n := range r.notifiers {
+		names = append(names, n.Name())
+	}
+	return names
+}
+
+// ByName returns the notifier registered under name, or false if none matches.
+func (r *Registry) ByName(name string) (Notifier, bool) {
+	for _, n := range r.notifiers {
+		if n.Name() == name {
+			return n, true
+		}
+	}
+	return nil, false
+}
+
+// Dispatch sends ev to every registered notifier whose filter matches
+// ev.State, passing individual delivery failures to onError (when non-nil)
+// rather than returning them, so one broken notifier never blocks the others
+// or the caller.
+func (r *Registry) Dispatch(ctx context.Context, ev Event, onError func(notifierName string, err error)) {
+	for _, n := range r.notifiers {
+		if !r.matches(n.Name(), ev.State) {
+			continue
+		}
+		if err := n.Notify(ctx, ev); err != nil && onError != nil {
+			onError(n.Name(), err)
+		}
+	}
+}
+
+func (r *Registry) matches(name string, state store.State) bool {
+	states := r.filters[name]
+	if len(states) == 0 {
+		return true
+	}
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
@@ -5,7 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -42,6 +42,7 @@ type startResult struct {
 type Client struct {
 	url     string
 	handler Handler
+	log     *slog.Logger
 
 	// conn is the active connection; nil when disconnected.
 	connMu  sync.Mutex
@@ -58,15 +59,26 @@ type Client struct {
 	reconnectDelay time.Duration
 }
 
-// NewClient creates a Client targeting the given WebSocket URL.
-func NewClient(url string, h Handler) *Client {
+// NewClient creates a Client targeting the given WebSocket URL. logger is
+// used for all of the client's log output; pass slog.Default() if the
+// caller has no specific logger to inject.
+func NewClient(url string, h Handler, logger *slog.Logger) *Client {
 	return &Client{
 		url:            url,
 		handler:        h,
+		log:            logger,
 		reconnectDelay: 5 * time.Second,
 	}
 }
 
+// Serve implements supervisor.Service: it runs Run until ctx is cancelled.
+// Run already reconnects internally on connection loss, so Serve never
+// returns a restartable error — only ctx.Err() once ctx is cancelled.
+func (c *Client) Serve(ctx context.Context) error {
+	c.Run(ctx)
+	return ctx.Err()
+}
+
 // Run connects and reconnects until ctx is cancelled.
 // Call this in a dedicated goroutine.
 func (c *Client) Run(ctx context.Context) {
@@ -75,7 +87,7 @@ func (c *Client) Run(ctx context.Context) {
 			return
 		}
 		if err := c.connect(ctx); err != nil && ctx.Err() == nil {
-			log.Printf("overseer: %v — retrying in %s", err, c.reconnectDelay)
+			c.log.Warn("connection lost, retrying", "error", err, "delay", c.reconnectDelay)
 		}
 		select {
 		case <-ctx.Done():
@@ -102,7 +114,7 @@ func (c *Client) connect(ctx context.Context) error {
 	c.conn = conn
 	c.connMu.Unlock()
 
-	log.Printf("overseer: connected to %s", c.url)
+	c.log.Info("connected", "url", c.url)
 
 	defer func() {
 		conn.Close()
@@ -124,7 +136,7 @@ func (c *Client) connect(ctx context.Context) error {
 			return true
 		})
 
-		log.Printf("overseer: disconnected from %s", c.url)
+		c.log.Info("disconnected", "url", c.url)
 	}()
 
 	for {
@@ -157,7 +169,7 @@ type inbound struct {
 func (c *Client) dispatch(raw []byte) {
 	var msg inbound
 	if err := json.Unmarshal(raw, &msg); err != nil {
-		log.Printf("overseer: bad message: %v", err)
+		c.log.Warn("bad message", "error", err)
 		return
 	}
 
@@ -252,6 +264,16 @@ func (c *Client) Stop(pid int) error {
 	})
 }
 
+// Kill sends SIGKILL to the process with the given PID, escalating past an
+// unresponsive Stop (SIGTERM). Used by Manager.Stop once ShutdownGrace
+// expires for any PID whose exit hasn't been observed yet.
+func (c *Client) Kill(pid int) error {
+	return c.send(map[string]any{
+		"type": "kill",
+		"pid":  pid,
+	})
+}
+
 // List returns all workers tracked by the overseer.
 func (c *Client) List(ctx context.Context) ([]WorkerInfo, error) {
 	id := c.nextID()
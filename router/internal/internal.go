@@ -0,0 +1,50 @@
+// Package internal holds response helpers shared by every router API
+// version, so adding router/v2 doesn't mean duplicating JSON encoding and
+// content negotiation.
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// WriteJSON writes v as a JSON response with the given status code.
+func WriteJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// WriteError writes a {"error": msg} JSON body with the given status code.
+func WriteError(w http.ResponseWriter, code int, msg string) {
+	WriteJSON(w, code, map[string]string{"error": msg})
+}
+
+// SupportedContentTypes lists response content types a handler may write, in
+// preference order. v1 only ever writes the first; v2 is expected to append
+// to this list (e.g. "application/cloudevents+json") as it adds framings,
+// rather than each version hand-rolling its own Accept parsing.
+var SupportedContentTypes = []string{"application/json"}
+
+// Negotiate returns the best content type from SupportedContentTypes
+// satisfying the request's Accept header, or "" if none match — the caller
+// should then reply 406 Not Acceptable.
+func Negotiate(r *http.Request) string {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return SupportedContentTypes[0]
+	}
+	for _, want := range strings.Split(accept, ",") {
+		want = strings.TrimSpace(strings.SplitN(want, ";", 2)[0])
+		if want == "*/*" {
+			return SupportedContentTypes[0]
+		}
+		for _, supported := range SupportedContentTypes {
+			if want == supported {
+				return supported
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,653 @@
+// Package v1 registers sticky-backend's original (now versioned) HTTP
+// endpoints using vanilla net/http (Go 1.22+ mux). router.New mounts this
+// handler at both /api/v1 and, as a deprecated alias, /api.
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/whisper-darkly/sticky-backend/auth"
+	"github.com/whisper-darkly/sticky-backend/config"
+	"github.com/whisper-darkly/sticky-backend/manager"
+	"github.com/whisper-darkly/sticky-backend/router/internal"
+	"github.com/whisper-darkly/sticky-backend/store"
+)
+
+// New builds v1's handler. Patterns are registered without a version or
+// /api prefix — router.New mounts this under both via http.StripPrefix.
+//
+// Subscription endpoints are keyed by {driver}/{source} — e.g.
+//
+//	POST /subscriptions          {"driver":"chaturbate","source":"alice"}
+//	GET  /subscriptions/chaturbate/alice
+//	DELETE /subscriptions/chaturbate/alice
+//
+// Every route except /health requires a bearer ticket (see requireScope),
+// the /auth/* routes included; /auth/tickets requires ScopeAdmin, which can
+// be bootstrapped on a fresh install via the ADMIN_TOKEN env var.
+func New(mgr *manager.Manager) http.Handler {
+	mux := http.NewServeMux()
+
+	read := func(h http.HandlerFunc) http.HandlerFunc { return requireScope(mgr, auth.ScopeSubscriptionsRead, h) }
+	write := func(h http.HandlerFunc) http.HandlerFunc { return requireScope(mgr, auth.ScopeSubscriptionsWrite, h) }
+	configWrite := func(h http.HandlerFunc) http.HandlerFunc { return requireScope(mgr, auth.ScopeConfigWrite, h) }
+	admin := func(h http.HandlerFunc) http.HandlerFunc { return requireScope(mgr, auth.ScopeAdmin, h) }
+
+	// Collection
+	mux.HandleFunc("GET /subscriptions", read(listSubscriptions(mgr)))
+	mux.HandleFunc("POST /subscriptions", write(createSubscription(mgr)))
+
+	// Single subscription — {driver}/{source}
+	mux.HandleFunc("GET /subscriptions/{driver}/{source}", read(getSubscription(mgr)))
+	mux.HandleFunc("DELETE /subscriptions/{driver}/{source}", write(deleteSubscription(mgr)))
+
+	// Actions
+	mux.HandleFunc("POST /subscriptions/{driver}/{source}/pause", write(pauseSubscription(mgr)))
+	mux.HandleFunc("POST /subscriptions/{driver}/{source}/resume", write(resumeSubscription(mgr)))
+	mux.HandleFunc("POST /subscriptions/{driver}/{source}/restart", write(restartSubscription(mgr)))
+	mux.HandleFunc("POST /subscriptions/{driver}/{source}/reset-error", write(resetError(mgr)))
+
+	// Logs convenience endpoint (also present in the full GET response)
+	mux.HandleFunc("GET /subscriptions/{driver}/{source}/logs", read(getSubscriptionLogs(mgr)))
+	mux.HandleFunc("GET /subscriptions/{driver}/{source}/logs/download", read(downloadSubscriptionLog(mgr)))
+
+	// Worker lifecycle events
+	mux.HandleFunc("GET /subscriptions/{driver}/{source}/events", read(getSubscriptionEvents(mgr)))
+
+	// Real-time event/log streaming (SSE)
+	mux.HandleFunc("GET /subscriptions/{driver}/{source}/stream", read(streamSubscription(mgr)))
+	mux.HandleFunc("GET /stream", read(streamAll(mgr)))
+
+	// Per-subscription config overrides
+	mux.HandleFunc("GET /subscriptions/{driver}/{source}/config", read(getSubscriptionConfig(mgr)))
+	mux.HandleFunc("PUT /subscriptions/{driver}/{source}/config", configWrite(putSubscriptionConfig(mgr)))
+
+	// Global config
+	mux.HandleFunc("GET /config", read(getConfig(mgr)))
+	mux.HandleFunc("PUT /config", configWrite(putConfig(mgr)))
+
+	// System / diagnostics — health is intentionally unauthenticated so
+	// infra probes don't need a ticket.
+	mux.HandleFunc("GET /health", health(mgr))
+	mux.HandleFunc("GET /workers", read(listWorkers(mgr)))
+
+	// CloudEvents delivery
+	mux.HandleFunc("GET /events/dead-letter", read(deadLetterEvents(mgr)))
+
+	// Notifiers
+	mux.HandleFunc("GET /notifiers", read(listNotifiers(mgr)))
+	mux.HandleFunc("POST /notifiers/{name}/test", admin(testNotifier(mgr)))
+
+	// Auth
+	mux.HandleFunc("POST /auth/tickets", admin(issueTicket(mgr)))
+	mux.HandleFunc("DELETE /auth/tickets/{id}", admin(revokeTicket(mgr)))
+	mux.HandleFunc("GET /auth/whoami", requireScope(mgr, "", whoami))
+
+	return mux
+}
+
+// ---- auth middleware ----
+
+type ticketContextKey struct{}
+
+// requireScope authenticates the request's "Authorization: Bearer <ticket>"
+// header and rejects it unless the ticket carries scope. An empty scope
+// means "any authenticated ticket is fine" (used by /auth/whoami).
+//
+// As a bootstrap path, a bearer value that exactly matches the ADMIN_TOKEN
+// env var is treated as a ticket with ScopeAdmin — this is how the very
+// first real ticket gets minted on a fresh install, before any ticket
+// exists to authenticate the call that creates one.
+func requireScope(mgr *manager.Manager, scope auth.Scope, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			internal.WriteError(w, http.StatusUnauthorized, "missing Authorization: Bearer <ticket>")
+			return
+		}
+
+		var claims auth.Ticket
+		if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" && token == adminToken {
+			claims = auth.Ticket{Subject: "admin-token", Scopes: []string{string(auth.ScopeAdmin)}}
+		} else {
+			var err error
+			claims, err = mgr.VerifyTicket(r.Context(), token)
+			if err != nil {
+				internal.WriteError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+		}
+
+		if scope != "" && !claims.Has(scope) {
+			internal.WriteError(w, http.StatusForbidden, fmt.Sprintf("ticket lacks required scope %q", scope))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ticketContextKey{}, claims)
+		h(w, r.WithContext(ctx))
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(h, prefix), true
+}
+
+// issueTicket serves POST /auth/tickets: {"subject":"...","scopes":[...],"ttl":"24h"}.
+func issueTicket(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Subject string   `json:"subject"`
+			Scopes  []string `json:"scopes"`
+			TTL     string   `json:"ttl"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			internal.WriteError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+		if body.Subject == "" {
+			internal.WriteError(w, http.StatusBadRequest, "subject is required")
+			return
+		}
+		ttl := 24 * time.Hour
+		if body.TTL != "" {
+			d, err := time.ParseDuration(body.TTL)
+			if err != nil {
+				internal.WriteError(w, http.StatusBadRequest, "invalid ttl: "+err.Error())
+				return
+			}
+			ttl = d
+		}
+
+		token, claims, err := mgr.IssueTicket(body.Subject, body.Scopes, ttl)
+		if err != nil {
+			internal.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusCreated, map[string]any{
+			"ticket": token,
+			"claims": claims,
+		})
+	}
+}
+
+// revokeTicket serves DELETE /auth/tickets/{id}.
+func revokeTicket(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if err := mgr.RevokeTicket(r.Context(), id); err != nil {
+			internal.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// whoami serves GET /auth/whoami, returning the caller's own decoded ticket
+// claims — useful for client UIs that want to show who's logged in and with
+// what scopes, without decoding the ticket themselves.
+func whoami(w http.ResponseWriter, r *http.Request) {
+	claims, _ := r.Context().Value(ticketContextKey{}).(auth.Ticket)
+	internal.WriteJSON(w, http.StatusOK, claims)
+}
+
+// ---- handlers ----
+
+func listSubscriptions(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		subs, err := mgr.ListVisible(r.Context())
+		if err != nil {
+			internal.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, subs)
+	}
+}
+
+func createSubscription(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Driver string `json:"driver"`
+			Source string `json:"source"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			internal.WriteError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+		if body.Driver == "" {
+			internal.WriteError(w, http.StatusBadRequest, "driver is required (chaturbate, stripchat, …)")
+			return
+		}
+		if body.Source == "" {
+			internal.WriteError(w, http.StatusBadRequest, "source is required")
+			return
+		}
+		status, err := mgr.Subscribe(r.Context(), body.Driver, body.Source)
+		if err != nil {
+			internal.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusCreated, status)
+	}
+}
+
+func getSubscription(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, source := r.PathValue("driver"), r.PathValue("source")
+		status, err := mgr.GetStatus(r.Context(), driver, source)
+		if err != nil {
+			internal.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, status)
+	}
+}
+
+func deleteSubscription(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, source := r.PathValue("driver"), r.PathValue("source")
+		if err := mgr.Unsubscribe(r.Context(), driver, source); err != nil {
+			internal.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func pauseSubscription(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, source := r.PathValue("driver"), r.PathValue("source")
+		status, err := mgr.Pause(r.Context(), driver, source)
+		if err != nil {
+			internal.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, status)
+	}
+}
+
+func resumeSubscription(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, source := r.PathValue("driver"), r.PathValue("source")
+		status, err := mgr.Resume(r.Context(), driver, source)
+		if err != nil {
+			internal.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, status)
+	}
+}
+
+func restartSubscription(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, source := r.PathValue("driver"), r.PathValue("source")
+		status, err := mgr.Restart(r.Context(), driver, source)
+		if err != nil {
+			code := http.StatusNotFound
+			if err.Error() == fmt.Sprintf("subscription %s/%s has no running worker", driver, source) {
+				code = http.StatusConflict
+			}
+			internal.WriteError(w, code, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, status)
+	}
+}
+
+func resetError(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, source := r.PathValue("driver"), r.PathValue("source")
+		current, err := mgr.GetStatus(r.Context(), driver, source)
+		if err != nil {
+			internal.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if current.State != store.StateError {
+			internal.WriteError(w, http.StatusConflict, "subscription is not in error state")
+			return
+		}
+		status, err := mgr.ResetError(r.Context(), driver, source)
+		if err != nil {
+			internal.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, status)
+	}
+}
+
+func getSubscriptionLogs(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, source := r.PathValue("driver"), r.PathValue("source")
+		var tail int
+		if t := r.URL.Query().Get("tail"); t != "" {
+			tail, _ = strconv.Atoi(t)
+		}
+		logs, err := mgr.GetLogs(r.Context(), driver, source, tail)
+		if err != nil {
+			internal.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, map[string]any{
+			"driver": driver,
+			"source": source,
+			"logs":   logs,
+		})
+	}
+}
+
+// downloadSubscriptionLog serves GET .../logs/download: the raw current
+// on-disk log file for a subscription, for operators who want more than the
+// tail GetLogs returns. 404s if SubscriptionLogDir isn't configured.
+func downloadSubscriptionLog(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, source := r.PathValue("driver"), r.PathValue("source")
+		path, err := mgr.LogFilePath(r.Context(), driver, source)
+		if err != nil {
+			internal.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s_%s.log"`, driver, source))
+		http.ServeFile(w, r, path)
+	}
+}
+
+func getSubscriptionEvents(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, source := r.PathValue("driver"), r.PathValue("source")
+		limit := 50
+		events, err := mgr.GetWorkerEvents(r.Context(), driver, source, limit)
+		if err != nil {
+			internal.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, map[string]any{
+			"driver": driver,
+			"source": source,
+			"events": events,
+		})
+	}
+}
+
+func deadLetterEvents(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deliveries, err := mgr.DeadLetterEvents(r.Context(), 100)
+		if err != nil {
+			internal.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, map[string]any{"deliveries": deliveries})
+	}
+}
+
+func listNotifiers(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		internal.WriteJSON(w, http.StatusOK, map[string]any{"notifiers": mgr.ListNotifiers()})
+	}
+}
+
+// testNotifier serves POST /notifiers/{name}/test, sending a synthetic Event
+// to the named notifier so operators can validate a receiver without
+// waiting for a real state transition.
+func testNotifier(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		if err := mgr.TestNotifier(r.Context(), name); err != nil {
+			internal.WriteError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, map[string]any{"sent": true})
+	}
+}
+
+// streamSubscription serves GET /subscriptions/{driver}/{source}/stream: an
+// SSE connection that pushes worker events, state transitions, and log
+// lines for one subscription. ?since=<event-id> replays buffered events
+// newer than that ID before switching to live delivery, so a client that
+// reconnects doesn't miss anything still in the backlog.
+func streamSubscription(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, source := r.PathValue("driver"), r.PathValue("source")
+		var since int64
+		if s := r.URL.Query().Get("since"); s != "" {
+			since, _ = strconv.ParseInt(s, 10, 64)
+		}
+
+		ch, backlog, cancel, err := mgr.StreamSubscribe(r.Context(), driver, source, since)
+		if err != nil {
+			internal.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		defer cancel()
+
+		serveSSE(w, r.Context(), backlog, ch)
+	}
+}
+
+// streamAll serves GET /stream: a single SSE connection multiplexing every
+// subscription's events, for dashboard UIs that want one socket instead of
+// one per subscription.
+func streamAll(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ch, cancel := mgr.StreamSubscribeAll()
+		defer cancel()
+		serveSSE(w, r.Context(), nil, ch)
+	}
+}
+
+// serveSSE writes backlog then streams ch as SSE frames until ctx is done or
+// ch is closed.
+func serveSSE(w http.ResponseWriter, ctx context.Context, backlog []manager.StreamEvent, ch <-chan manager.StreamEvent) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		internal.WriteError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for _, ev := range backlog {
+		writeSSEFrame(w, ev)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			writeSSEFrame(w, ev)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSSEFrame(w http.ResponseWriter, ev manager.StreamEvent) {
+	raw, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Kind, raw)
+}
+
+// getSubscriptionConfig serves GET /subscriptions/{driver}/{source}/config.
+// By default it returns the stored override map only (a sparse subset of
+// config.Data's fields); zero/omitted fields mean "inherit from global".
+// ?effective=true instead returns the fully-merged config.Data plus a
+// "_source" map naming, per field, whether it came from "override" or
+// "global".
+func getSubscriptionConfig(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, source := r.PathValue("driver"), r.PathValue("source")
+
+		if r.URL.Query().Get("effective") != "true" {
+			overrides, err := mgr.GetSubscriptionConfig(r.Context(), driver, source)
+			if err != nil {
+				internal.WriteError(w, http.StatusNotFound, err.Error())
+				return
+			}
+			if overrides == nil {
+				overrides = map[string]any{}
+			}
+			internal.WriteJSON(w, http.StatusOK, overrides)
+			return
+		}
+
+		merged, overrides, err := mgr.EffectiveConfig(r.Context(), driver, source)
+		if err != nil {
+			internal.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, map[string]any{
+			"config":  merged,
+			"_source": fieldProvenance(merged, overrides),
+		})
+	}
+}
+
+// fieldProvenance reports, for each JSON field of merged, whether it was set
+// by a per-subscription override or inherited from the global config.
+func fieldProvenance(merged config.Data, overrides map[string]any) map[string]string {
+	raw, err := json.Marshal(merged)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+
+	source := make(map[string]string, len(fields))
+	for field := range fields {
+		if _, overridden := overrides[field]; overridden {
+			source[field] = "override"
+		} else {
+			source[field] = "global"
+		}
+	}
+	return source
+}
+
+// putSubscriptionConfig serves PUT /subscriptions/{driver}/{source}/config.
+// The body is a sparse JSON object keyed by config.Data's field names;
+// fields it omits continue to inherit the global default.
+func putSubscriptionConfig(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		driver, source := r.PathValue("driver"), r.PathValue("source")
+
+		var overrides map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&overrides); err != nil {
+			internal.WriteError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+		if err := mgr.SetSubscriptionConfig(r.Context(), driver, source, overrides); err != nil {
+			internal.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		merged, overrides, err := mgr.EffectiveConfig(r.Context(), driver, source)
+		if err != nil {
+			internal.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, map[string]any{
+			"config":  merged,
+			"_source": fieldProvenance(merged, overrides),
+		})
+	}
+}
+
+func getConfig(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		internal.WriteJSON(w, http.StatusOK, mgr.GetConfig())
+	}
+}
+
+func putConfig(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var d config.Data
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			internal.WriteError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+		if err := mgr.SetConfig(d); err != nil {
+			internal.WriteError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, mgr.GetConfig())
+	}
+}
+
+func health(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		oc := mgr.GetOverseerClient()
+		connected := oc != nil && oc.IsConnected()
+
+		subs, _ := mgr.ListVisible(r.Context())
+		recording, errored, paused := 0, 0, 0
+		for _, s := range subs {
+			switch s.State {
+			case store.StateError:
+				errored++
+			case store.StatePaused:
+				paused++
+			}
+			if s.WorkerState == "recording" {
+				recording++
+			}
+		}
+
+		code := http.StatusOK
+		if !connected {
+			code = http.StatusServiceUnavailable
+		}
+		internal.WriteJSON(w, code, map[string]any{
+			"status":             statusStr(connected),
+			"overseer_connected": connected,
+			"subscriptions":      len(subs),
+			"recording":          recording,
+			"paused":             paused,
+			"errored":            errored,
+		})
+	}
+}
+
+func listWorkers(mgr *manager.Manager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		oc := mgr.GetOverseerClient()
+		if oc == nil || !oc.IsConnected() {
+			internal.WriteError(w, http.StatusServiceUnavailable, "not connected to overseer")
+			return
+		}
+		workers, err := oc.List(r.Context())
+		if err != nil {
+			internal.WriteError(w, http.StatusBadGateway, "overseer error: "+err.Error())
+			return
+		}
+		internal.WriteJSON(w, http.StatusOK, workers)
+	}
+}
+
+func statusStr(connected bool) string {
+	if connected {
+		return "ok"
+	}
+	return "overseer_disconnected"
+}
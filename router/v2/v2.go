@@ -0,0 +1,16 @@
+// Package v2 is reserved for the next API version. It exists to establish
+// the /api/v1 vs /api/v2 namespace split before any v2-only endpoint lands,
+// so clients can pin a version. Per-subscription config overrides,
+// streaming events and auth are all served by v1.
+package v2
+
+import (
+	"net/http"
+
+	"github.com/whisper-darkly/sticky-backend/manager"
+)
+
+// New returns v2's handler. Empty for now: nothing has moved here yet.
+func New(_ *manager.Manager) http.Handler {
+	return http.NewServeMux()
+}
@@ -6,6 +6,7 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -78,6 +79,39 @@ func (s *DB) migrate() error {
 			ON worker_events(subscription_id, ts)`,
 		`CREATE INDEX IF NOT EXISTS idx_we_sub_pid_type
 			ON worker_events(subscription_id, pid, event_type)`,
+
+		`CREATE TABLE IF NOT EXISTS event_deliveries (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			sink_url     TEXT    NOT NULL,
+			secret       TEXT    NOT NULL DEFAULT '',
+			payload      TEXT    NOT NULL,
+			attempts     INTEGER NOT NULL DEFAULT 0,
+			next_attempt TEXT    NOT NULL,
+			last_error   TEXT    NOT NULL DEFAULT '',
+			created_at   TEXT    NOT NULL
+		)`,
+
+		`CREATE INDEX IF NOT EXISTS idx_ed_next_attempt
+			ON event_deliveries(next_attempt)`,
+
+		`CREATE TABLE IF NOT EXISTS dead_letter_events (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			sink_url     TEXT    NOT NULL,
+			payload      TEXT    NOT NULL,
+			attempts     INTEGER NOT NULL,
+			last_error   TEXT    NOT NULL DEFAULT '',
+			created_at   TEXT    NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS subscription_config (
+			subscription_id INTEGER PRIMARY KEY REFERENCES subscriptions(id),
+			overrides       TEXT    NOT NULL
+		)`,
+
+		`CREATE TABLE IF NOT EXISTS revoked_tickets (
+			id         TEXT PRIMARY KEY,
+			revoked_at TEXT NOT NULL
+		)`,
 	}
 
 	for _, stmt := range stmts {
@@ -256,6 +290,182 @@ func (s *DB) RecentWorkerEvents(ctx context.Context, subscriptionID int64, limit
 	return events, rows.Err()
 }
 
+// ---- event delivery ----
+
+func (s *DB) EnqueueEventDelivery(ctx context.Context, d store.EventDelivery) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `
+		INSERT INTO event_deliveries (sink_url, secret, payload, attempts, next_attempt, last_error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, d.SinkURL, d.Secret, string(d.Payload), d.Attempts,
+		d.NextAttempt.UTC().Format(time.RFC3339), d.LastError, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (s *DB) DueEventDeliveries(ctx context.Context, now time.Time, limit int) ([]store.EventDelivery, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, sink_url, secret, payload, attempts, next_attempt, last_error, created_at
+		  FROM event_deliveries
+		 WHERE next_attempt <= ?
+		 ORDER BY next_attempt
+		 LIMIT ?
+	`, now.UTC().Format(time.RFC3339), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []store.EventDelivery
+	for rows.Next() {
+		d, err := scanEventDelivery(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (s *DB) MarkEventDelivered(ctx context.Context, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM event_deliveries WHERE id = ?`, id)
+	return err
+}
+
+func (s *DB) MarkEventRetry(ctx context.Context, id int64, nextAttempt time.Time, lastErr string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE event_deliveries
+		   SET attempts = attempts + 1, next_attempt = ?, last_error = ?
+		 WHERE id = ?
+	`, nextAttempt.UTC().Format(time.RFC3339), lastErr, id)
+	return err
+}
+
+func (s *DB) DeadLetterEvent(ctx context.Context, id int64, lastErr string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	row := tx.QueryRowContext(ctx, `
+		SELECT sink_url, payload, attempts FROM event_deliveries WHERE id = ?
+	`, id)
+	var sinkURL, payload string
+	var attempts int
+	if err := row.Scan(&sinkURL, &payload, &attempts); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO dead_letter_events (sink_url, payload, attempts, last_error, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, sinkURL, payload, attempts, lastErr, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM event_deliveries WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *DB) ListDeadLetters(ctx context.Context, limit int) ([]store.EventDelivery, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, sink_url, payload, attempts, last_error, created_at
+		  FROM dead_letter_events
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []store.EventDelivery
+	for rows.Next() {
+		var d store.EventDelivery
+		var payload, createdAt string
+		if err := rows.Scan(&d.ID, &d.SinkURL, &payload, &d.Attempts, &d.LastError, &createdAt); err != nil {
+			return nil, err
+		}
+		d.Payload = json.RawMessage(payload)
+		d.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func scanEventDelivery(scan scanFn) (store.EventDelivery, error) {
+	var d store.EventDelivery
+	var payload, nextAttempt, createdAt string
+	err := scan(&d.ID, &d.SinkURL, &d.Secret, &payload, &d.Attempts, &nextAttempt, &d.LastError, &createdAt)
+	if err != nil {
+		return store.EventDelivery{}, err
+	}
+	d.Payload = json.RawMessage(payload)
+	d.NextAttempt, _ = time.Parse(time.RFC3339, nextAttempt)
+	d.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	return d, nil
+}
+
+// ---- auth ----
+
+func (s *DB) RevokeTicket(ctx context.Context, id string, revokedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO revoked_tickets (id, revoked_at) VALUES (?, ?)
+		ON CONFLICT(id) DO NOTHING
+	`, id, revokedAt.UTC().Format(time.RFC3339))
+	return err
+}
+
+func (s *DB) IsTicketRevoked(ctx context.Context, id string) (bool, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT 1 FROM revoked_tickets WHERE id = ?`, id)
+	var discard int
+	err := row.Scan(&discard)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ---- per-subscription config overrides ----
+
+func (s *DB) GetSubscriptionConfig(ctx context.Context, subscriptionID int64) (map[string]any, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT overrides FROM subscription_config WHERE subscription_id = ?`, subscriptionID)
+
+	var raw string
+	if err := row.Scan(&raw); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var overrides map[string]any
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+func (s *DB) SetSubscriptionConfig(ctx context.Context, subscriptionID int64, overrides map[string]any) error {
+	raw, err := json.Marshal(overrides)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO subscription_config (subscription_id, overrides)
+		VALUES (?, ?)
+		ON CONFLICT(subscription_id) DO UPDATE SET overrides = excluded.overrides
+	`, subscriptionID, string(raw))
+	return err
+}
+
 func (s *DB) Close() error { return s.db.Close() }
 
 // ---- internal helpers ----
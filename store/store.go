@@ -6,6 +6,7 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/whisper-darkly/sticky-backend/config"
@@ -45,9 +46,9 @@ type Subscription struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
-// Target bundles a Subscription with its effective configuration.
-// Config is currently the global default; per-subscription overrides may be
-// added to the store layer in the future without changing call sites.
+// Target bundles a Subscription with its effective configuration: the
+// global default with any per-subscription overrides (see
+// Get/SetSubscriptionConfig) layered on top.
 type Target struct {
 	Sub    *Subscription
 	Config config.Data
@@ -75,6 +76,15 @@ const (
 	// the first EventStarted that arrives after the most recent EventStopped
 	// defines the start of a new error-counting window.
 	EventStopped EventType = "stopped"
+
+	// EventStalled is recorded immediately before the backend sends SIGTERM
+	// to a worker the reconciler has decided is stalled (alive, but silent
+	// for longer than config.Data.StallTimeout). Unlike EventStopped, it is
+	// not a cycle-reset boundary. It does not add to the error count either:
+	// ErrorExitsSince counts only EventExited records, and the manager
+	// records no EventExited for the stalled process, since it drops the PID
+	// before sending the signal.
+	EventStalled EventType = "stalled"
 )
 
 // WorkerEvent is a single persisted lifecycle event for a worker process.
@@ -87,6 +97,22 @@ type WorkerEvent struct {
 	TS             time.Time `json:"ts"`
 }
 
+// ---- event delivery ----
+
+// EventDelivery is a single queued (or dead-lettered) CloudEvents delivery to
+// one sink. Payload is the fully-rendered envelope, captured at enqueue time
+// so retries don't depend on the sink config that produced it still existing.
+type EventDelivery struct {
+	ID          int64           `json:"id"`
+	SinkURL     string          `json:"sink_url"`
+	Secret      string          `json:"-"` // never serialised back to clients
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	LastError   string          `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
 // ---- store interface ----
 
 // Store is the persistence abstraction.  All methods are context-aware.
@@ -143,6 +169,52 @@ type Store interface {
 	// ordered newest first.
 	RecentWorkerEvents(ctx context.Context, subscriptionID int64, limit int) ([]WorkerEvent, error)
 
+	// ---- event delivery ----
+
+	// EnqueueEventDelivery persists a pending delivery for one sink and
+	// returns its ID.
+	EnqueueEventDelivery(ctx context.Context, d EventDelivery) (int64, error)
+
+	// DueEventDeliveries returns queued deliveries whose NextAttempt is at or
+	// before now, oldest first, capped at limit.
+	DueEventDeliveries(ctx context.Context, now time.Time, limit int) ([]EventDelivery, error)
+
+	// MarkEventDelivered removes a delivery from the retry queue after a
+	// successful send.
+	MarkEventDelivered(ctx context.Context, id int64) error
+
+	// MarkEventRetry increments the attempt count and reschedules a delivery
+	// for nextAttempt, recording lastErr.
+	MarkEventRetry(ctx context.Context, id int64, nextAttempt time.Time, lastErr string) error
+
+	// DeadLetterEvent moves a delivery that exhausted its retry policy out of
+	// the retry queue and into the dead-letter table.
+	DeadLetterEvent(ctx context.Context, id int64, lastErr string) error
+
+	// ListDeadLetters returns dead-lettered deliveries, newest first, capped
+	// at limit.
+	ListDeadLetters(ctx context.Context, limit int) ([]EventDelivery, error)
+
+	// ---- auth ----
+
+	// RevokeTicket records a ticket ID as revoked as of revokedAt. Idempotent.
+	RevokeTicket(ctx context.Context, id string, revokedAt time.Time) error
+
+	// IsTicketRevoked reports whether a ticket ID has been revoked.
+	IsTicketRevoked(ctx context.Context, id string) (bool, error)
+
+	// ---- per-subscription config overrides ----
+
+	// GetSubscriptionConfig returns the stored sparse override map for a
+	// subscription, keyed by config.Data's JSON field names. Returns an empty
+	// (nil) map, not an error, when no overrides have been set.
+	GetSubscriptionConfig(ctx context.Context, subscriptionID int64) (map[string]any, error)
+
+	// SetSubscriptionConfig replaces the stored override map for a
+	// subscription. An empty map clears all overrides, reverting the
+	// subscription to the global default.
+	SetSubscriptionConfig(ctx context.Context, subscriptionID int64, overrides map[string]any) error
+
 	// ---- lifecycle ----
 
 	Close() error
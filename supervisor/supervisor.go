@@ -0,0 +1,160 @@
+// Package supervisor is a minimal suture-style service supervisor: each
+// registered Service runs in its own goroutine, is restarted with backoff
+// if it returns an error, and the whole tree shuts down cleanly once its
+// context is cancelled.
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Service is a unit of supervised work. Serve should block until ctx is
+// cancelled; a nil error (or ctx.Err()) on return is a clean exit and is
+// not restarted, any other error is restarted with backoff.
+type Service interface {
+	Serve(ctx context.Context) error
+}
+
+// Spec configures restart behavior. Zero-valued fields fall back to the
+// matching field in DefaultSpec.
+type Spec struct {
+	BackoffBase    time.Duration // initial delay before the first restart
+	BackoffMax     time.Duration // delay is doubled on each consecutive restart, capped here
+	MaxRestarts    int           // restarts allowed within RestartWindow before giving up; 0 = unlimited
+	RestartWindow  time.Duration // window MaxRestarts counts within; the first failure more than this long after the window began resets the counter and backoff and starts a new window
+	ServiceTimeout time.Duration // how long a Service gets to exit after ctx is cancelled before Serve gives up waiting on it
+}
+
+// DefaultSpec is used for any Spec field left at its zero value.
+var DefaultSpec = Spec{
+	BackoffBase:    500 * time.Millisecond,
+	BackoffMax:     30 * time.Second,
+	RestartWindow:  time.Minute,
+	ServiceTimeout: 10 * time.Second,
+}
+
+type namedService struct {
+	name string
+	svc  Service
+}
+
+// Supervisor runs a set of Services, restarting each with backoff if it
+// returns a non-nil, non-cancellation error, and waiting for all of them to
+// return before Serve itself returns.
+type Supervisor struct {
+	spec Spec
+	log  *slog.Logger
+
+	mu       sync.Mutex
+	services []namedService
+}
+
+// New creates a Supervisor. logger is used to report service failures and
+// restarts; pass slog.Default() if the caller has no specific logger to
+// inject.
+func New(spec Spec, logger *slog.Logger) *Supervisor {
+	if spec.BackoffBase <= 0 {
+		spec.BackoffBase = DefaultSpec.BackoffBase
+	}
+	if spec.BackoffMax <= 0 {
+		spec.BackoffMax = DefaultSpec.BackoffMax
+	}
+	if spec.RestartWindow <= 0 {
+		spec.RestartWindow = DefaultSpec.RestartWindow
+	}
+	if spec.ServiceTimeout <= 0 {
+		spec.ServiceTimeout = DefaultSpec.ServiceTimeout
+	}
+	return &Supervisor{spec: spec, log: logger}
+}
+
+// Add registers a service under name (used only for logging). Must be
+// called before Serve.
+func (s *Supervisor) Add(name string, svc Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.services = append(s.services, namedService{name: name, svc: svc})
+}
+
+// Serve starts every registered service in its own goroutine and blocks
+// until ctx is cancelled and all of them have returned.
+func (s *Supervisor) Serve(ctx context.Context) error {
+	s.mu.Lock()
+	services := make([]namedService, len(s.services))
+	copy(services, s.services)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, ns := range services {
+		wg.Add(1)
+		go func(ns namedService) {
+			defer wg.Done()
+			s.runWithRestart(ctx, ns)
+		}(ns)
+	}
+
+	<-ctx.Done()
+	wg.Wait()
+	return ctx.Err()
+}
+
+// runWithRestart runs ns.svc until it returns cleanly or ctx is cancelled,
+// restarting it with exponential backoff on every other error.
+func (s *Supervisor) runWithRestart(ctx context.Context, ns namedService) {
+	backoff := s.spec.BackoffBase
+	restarts := 0
+	windowStart := time.Now()
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		err := s.serveOnce(ctx, ns)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			s.log.Info("service exited cleanly, not restarting", "service", ns.name)
+			return
+		}
+
+		if time.Since(windowStart) > s.spec.RestartWindow {
+			windowStart = time.Now()
+			restarts = 0
+			backoff = s.spec.BackoffBase
+		}
+		restarts++
+		if s.spec.MaxRestarts > 0 && restarts > s.spec.MaxRestarts {
+			s.log.Error("service exceeded max restarts, giving up", "service", ns.name, "restarts", restarts, "error", err)
+			return
+		}
+
+		s.log.Error("service failed, restarting", "service", ns.name, "error", err, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > s.spec.BackoffMax {
+			backoff = s.spec.BackoffMax
+		}
+	}
+}
+
+// serveOnce runs ns.svc.Serve, recovering a panic as an error so one
+// misbehaving service can't take the whole process down with it.
+func (s *Supervisor) serveOnce(ctx context.Context, ns namedService) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Error("service panicked", "service", ns.name, "panic", r)
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return ns.svc.Serve(ctx)
+}
@@ -16,6 +16,8 @@ import (
 	"time"
 
 	overseer "github.com/whisper-darkly/sticky-overseer/v2"
+
+	"github.com/whisper-darkly/sticky-thumbnailer/internal/fsutil"
 )
 
 func init() {
@@ -28,12 +30,29 @@ type thumbnailerConfig struct {
 	Paths        []string `json:"paths"`
 	DBPath       string   `json:"db_path"`
 	ThumbLevels  int      `json:"thumb_levels"`
+	// PropagationMode controls how propagateThumbnail materialises each
+	// hierarchy level: "link" (hard link only), "reflink" (copy-on-write
+	// clone only), "copy" (plain byte copy), or "auto" (try link, then
+	// reflink, then copy — the default).
+	PropagationMode string `json:"propagation_mode"`
+	// ScanMode controls how RunService discovers new files: "poll" (the
+	// original periodic full/recent walk), "watch" (fsnotify only), or
+	// "hybrid" (fsnotify plus a slow periodic safety-net scan — the
+	// default).
+	ScanMode string `json:"scan_mode"`
 }
 
+const (
+	scanModePoll   = "poll"
+	scanModeWatch  = "watch"
+	scanModeHybrid = "hybrid"
+)
+
 type thumbnailerHandler struct {
-	actionName string
-	cfg        thumbnailerConfig
-	store      *Store
+	actionName      string
+	cfg             thumbnailerConfig
+	store           *Store
+	propagationMode fsutil.Mode
 }
 
 // ---- Factory ----
@@ -66,16 +85,28 @@ func (f *thumbnailerFactory) Create(
 	if cfg.ScanInterval == "" {
 		cfg.ScanInterval = "5s"
 	}
+	propMode, err := fsutil.ParseMode(cfg.PropagationMode)
+	if err != nil {
+		return nil, fmt.Errorf("thumbnailer: %w", err)
+	}
+	switch cfg.ScanMode {
+	case "":
+		cfg.ScanMode = scanModeHybrid
+	case scanModePoll, scanModeWatch, scanModeHybrid:
+	default:
+		return nil, fmt.Errorf("thumbnailer: unknown scan_mode %q", cfg.ScanMode)
+	}
 
-	st, err := OpenStore(cfg.DBPath)
+	st, err := OpenStore(cfg.DBPath, StoreConfig{})
 	if err != nil {
 		return nil, fmt.Errorf("thumbnailer: open store: %w", err)
 	}
 
 	return &thumbnailerHandler{
-		actionName: actionName,
-		cfg:        cfg,
-		store:      st,
+		actionName:      actionName,
+		cfg:             cfg,
+		store:           st,
+		propagationMode: propMode,
 	}, nil
 }
 
@@ -115,7 +146,9 @@ func (h *thumbnailerHandler) Start(taskID string, params map[string]string, cb o
 		"-y", thumbPath,
 	}
 
-	h.store.MarkInFlight(inputPath)
+	if err := h.store.MarkInFlight(inputPath); err != nil {
+		log.Printf("thumbnailer: mark in_flight for %s: %v", inputPath, err)
+	}
 
 	wrappedCB := overseer.WorkerCallbacks{
 		OnOutput: cb.OnOutput,
@@ -129,15 +162,21 @@ func (h *thumbnailerHandler) Start(taskID string, params map[string]string, cb o
 					os.Remove(thumbPath)
 					if retryErr := runFFmpegThumb(inputPath, thumbPath); retryErr != nil {
 						log.Printf("thumbnailer: retry failed for %s: %v", inputPath, retryErr)
-						h.store.MarkErrored(inputPath)
+						if err := h.store.MarkErrored(inputPath); err != nil {
+							log.Printf("thumbnailer: mark errored for %s: %v", inputPath, err)
+						}
 						cb.OnExited(w, 1, intentional, t)
 						return
 					}
 				}
 				h.propagateThumbnail(thumbPath, h.cfg.ThumbLevels)
-				h.store.MarkCompleted(inputPath)
+				if err := h.store.MarkCompleted(inputPath); err != nil {
+					log.Printf("thumbnailer: mark completed for %s: %v", inputPath, err)
+				}
 			} else {
-				h.store.MarkErrored(inputPath)
+				if err := h.store.MarkErrored(inputPath); err != nil {
+					log.Printf("thumbnailer: mark errored for %s: %v", inputPath, err)
+				}
 			}
 			cb.OnExited(w, exitCode, intentional, t)
 		},
@@ -176,40 +215,87 @@ func (h *thumbnailerHandler) propagateThumbnail(thumbPath string, levels int) {
 	dir := filepath.Dir(thumbPath)
 	for i := 1; i <= levels; i++ {
 		destPath := dir + ".jpg"
-		if err := copyFile(thumbPath, destPath); err != nil {
+		used, err := fsutil.Materialise(h.propagationMode, thumbPath, destPath)
+		if err != nil {
 			log.Printf("thumbnailer: propagate level %d → %s: %v", i, destPath, err)
+		} else if err := h.store.RecordMode(destPath, thumbPath, string(used)); err != nil {
+			log.Printf("thumbnailer: record propagation mode for %s: %v", destPath, err)
 		}
 		dir = filepath.Dir(dir)
 	}
 }
 
-func copyFile(src, dst string) error {
-	in, err := os.Open(src)
-	if err != nil {
-		return err
+// rewriteStaleMode re-materialises any propagated thumbnail still recorded
+// under a propagation mode other than the currently configured one, so
+// changing PropagationMode in config converges existing files onto it
+// instead of leaving them stuck on whatever mode originally wrote them. It's
+// a no-op under ModeAuto, since "auto" already picks the best available
+// strategy at propagation time and isn't a concrete mode to compare against.
+func (h *thumbnailerHandler) rewriteStaleMode() {
+	if h.propagationMode == fsutil.ModeAuto {
+		return
 	}
-	defer in.Close()
-
-	out, err := os.Create(dst)
+	stale, err := h.store.StaleModePaths(string(h.propagationMode))
 	if err != nil {
-		return err
+		log.Printf("thumbnailer: list stale propagation mode entries: %v", err)
+		return
+	}
+	for _, sm := range stale {
+		used, err := fsutil.Materialise(h.propagationMode, sm.Src, sm.Path)
+		if err != nil {
+			log.Printf("thumbnailer: rewrite %s: %v", sm.Path, err)
+			continue
+		}
+		if err := h.store.RecordMode(sm.Path, sm.Src, string(used)); err != nil {
+			log.Printf("thumbnailer: record propagation mode for %s: %v", sm.Path, err)
+		}
 	}
-	defer out.Close()
-
-	_, err = io.Copy(out, in)
-	return err
 }
 
 // ---- ServiceHandler (directory scanner) ----
 
-// RunService implements overseer.ServiceHandler. It scans configured paths
-// on startup and periodically, submitting video files as thumbnail tasks.
+// staleInFlightAge is how long an in_flight record can go without an update
+// before RunService assumes the worker that owned it crashed and reclaims it.
+const staleInFlightAge = time.Hour
+
+// RunService implements overseer.ServiceHandler. It always walks configured
+// paths once on startup to seed Store, then discovers new files either by
+// periodic re-scanning ("poll"), fsnotify watches ("watch"), or both
+// ("hybrid", the default — see dirWatcher and runSafetyNet).
 func (h *thumbnailerHandler) RunService(ctx context.Context, submit overseer.TaskSubmitter) {
 	interval := parseDuration(h.cfg.ScanInterval, 5*time.Second)
 
+	h.reclaimStale()
+	h.rewriteStaleMode()
+
 	// Startup: full scan of all paths (skips only in_flight)
 	h.scan(submit, false)
 
+	if h.cfg.ScanMode == scanModePoll {
+		h.runPoll(ctx, submit, interval)
+		return
+	}
+
+	dw, err := newDirWatcher(h, submit)
+	if err != nil {
+		log.Printf("thumbnailer: fsnotify unavailable, falling back to polling: %v", err)
+		h.runPoll(ctx, submit, interval)
+		return
+	}
+	dw.seed()
+	go dw.run(ctx)
+
+	if h.cfg.ScanMode == scanModeWatch {
+		<-ctx.Done()
+		return
+	}
+
+	h.runSafetyNet(ctx, submit)
+}
+
+// runPoll is the original periodic scanning loop, used directly under
+// scan_mode "poll" and as the fallback when fsnotify can't be initialised.
+func (h *thumbnailerHandler) runPoll(ctx context.Context, submit overseer.TaskSubmitter, interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -218,18 +304,53 @@ func (h *thumbnailerHandler) RunService(ctx context.Context, submit overseer.Tas
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			h.reclaimStale()
+			h.rewriteStaleMode()
 			// Periodic: recent files only (mtime within last interval)
 			h.scanRecent(submit, interval)
 		}
 	}
 }
 
+// runSafetyNet is scan_mode "hybrid"'s slow periodic full re-scan, catching
+// any file or directory event inotify missed (e.g. during a watcher restart
+// or an event-queue overflow) without the latency cost of "poll"'s
+// ScanInterval-frequency walk.
+func (h *thumbnailerHandler) runSafetyNet(ctx context.Context, submit overseer.TaskSubmitter) {
+	ticker := time.NewTicker(safetyNetInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.reclaimStale()
+			h.rewriteStaleMode()
+			h.scan(submit, false)
+		}
+	}
+}
+
+// reclaimStale flips any in_flight record older than staleInFlightAge back
+// to errored so a worker that crashed mid-run doesn't wedge its path forever.
+func (h *thumbnailerHandler) reclaimStale() {
+	if err := h.store.ReclaimStale(staleInFlightAge); err != nil {
+		log.Printf("thumbnailer: reclaim stale in_flight records: %v", err)
+	}
+}
+
 // scan walks all configured glob paths and submits video files.
 // If inFlightOnly is true, only skip in_flight entries (completed/errored are re-submitted).
 func (h *thumbnailerHandler) scan(submit overseer.TaskSubmitter, _ bool) {
 	for _, pattern := range h.cfg.Paths {
 		if err := walkGlob(pattern, func(path string) {
-			if h.store.IsInFlight(path) {
+			inFlight, err := h.store.IsInFlight(path)
+			if err != nil {
+				log.Printf("thumbnailer: check in_flight for %s: %v", path, err)
+				return
+			}
+			if inFlight {
 				return
 			}
 			submit.Submit(h.actionName, "", map[string]string{"file": path}) //nolint:errcheck
@@ -251,7 +372,12 @@ func (h *thumbnailerHandler) scanRecent(submit overseer.TaskSubmitter, since tim
 			if fi.ModTime().Before(cutoff) {
 				return
 			}
-			if h.store.IsInFlight(path) {
+			inFlight, err := h.store.IsInFlight(path)
+			if err != nil {
+				log.Printf("thumbnailer: check in_flight for %s: %v", path, err)
+				return
+			}
+			if inFlight {
 				return
 			}
 			submit.Submit(h.actionName, "", map[string]string{"file": path}) //nolint:errcheck
@@ -264,29 +390,14 @@ func (h *thumbnailerHandler) scanRecent(submit overseer.TaskSubmitter, since tim
 // walkGlob expands a glob pattern (supports **) and calls fn for each matching file.
 // Pattern example: /recordings/**/*.ts
 func walkGlob(pattern string, fn func(string)) error {
-	// Split on ** to get the base dir and file pattern
-	parts := strings.SplitN(pattern, "**", 2)
-	baseDir := strings.TrimRight(parts[0], "/")
-	if baseDir == "" {
-		baseDir = "/"
-	}
-
-	var filePattern string
-	if len(parts) == 2 {
-		filePattern = strings.TrimLeft(parts[1], "/")
-	}
-
-	return filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
+	g := splitGlob(pattern)
+	return filepath.Walk(g.baseDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info == nil || info.IsDir() {
 			return nil
 		}
-		if filePattern != "" {
-			matched, _ := filepath.Match(filePattern, filepath.Base(path))
-			if !matched {
-				return nil
-			}
+		if g.matches(path) {
+			fn(path)
 		}
-		fn(path)
 		return nil
 	})
 }
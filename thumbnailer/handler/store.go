@@ -3,6 +3,7 @@ package handler
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -14,56 +15,229 @@ CREATE TABLE IF NOT EXISTS thumbnails (
 	status     TEXT NOT NULL DEFAULT 'in_flight',
 	updated_at TEXT NOT NULL
 );
+CREATE TABLE IF NOT EXISTS propagated (
+	path       TEXT PRIMARY KEY,
+	src        TEXT NOT NULL,
+	mode       TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
 `
 
+const (
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = 10 * time.Millisecond
+	defaultMaxBackoff     = 500 * time.Millisecond
+)
+
+// StoreConfig configures Store's busy-retry behavior. The zero value applies
+// sensible defaults.
+type StoreConfig struct {
+	// MaxAttempts is how many times in all a write is attempted (the first
+	// try included) while it keeps failing on SQLITE_BUSY / SQLITE_LOCKED.
+	// Defaults to 5.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubling (capped at
+	// MaxBackoff) on each subsequent attempt. Defaults to 10ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the retry delay. Defaults to 500ms.
+	MaxBackoff time.Duration
+}
+
 // Store tracks per-file thumbnail generation status to prevent concurrent runs.
 type Store struct {
-	db *sql.DB
+	db          *sql.DB
+	maxAttempts int
+	initial     time.Duration
+	max         time.Duration
 }
 
-// OpenStore opens (or creates) the SQLite dedup store at the given path.
-func OpenStore(path string) (*Store, error) {
-	db, err := sql.Open("sqlite", path)
+// OpenStore opens (or creates) the SQLite dedup store at the given path,
+// enabling WAL mode and a busy timeout, and applying cfg's retry settings
+// (or their defaults) to every write.
+func OpenStore(path string, cfg StoreConfig) (*Store, error) {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultInitialBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+
+	// _txlock=immediate makes every db.Begin() issue BEGIN IMMEDIATE instead
+	// of BEGIN DEFERRED, so writers contend for the write lock at transaction
+	// start rather than at their first write statement.
+	db, err := sql.Open("sqlite", path+"?_txlock=immediate")
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite %s: %w", path, err)
 	}
+	if _, err := db.Exec(`PRAGMA journal_mode=WAL`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("enable WAL: %w", err)
+	}
+	if _, err := db.Exec(`PRAGMA busy_timeout=5000`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("set busy_timeout: %w", err)
+	}
 	if _, err := db.Exec(schema); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("create schema: %w", err)
 	}
-	return &Store{db: db}, nil
+	return &Store{
+		db:          db,
+		maxAttempts: cfg.MaxAttempts,
+		initial:     cfg.InitialBackoff,
+		max:         cfg.MaxBackoff,
+	}, nil
 }
 
 func (s *Store) Close() { s.db.Close() }
 
-// IsInFlight returns true if the file has an active in_flight record.
-func (s *Store) IsInFlight(path string) bool {
+// withRetry runs fn inside a BEGIN IMMEDIATE transaction, retrying with
+// capped exponential backoff if it fails on SQLITE_BUSY or SQLITE_LOCKED.
+func (s *Store) withRetry(fn func(tx *sql.Tx) error) error {
+	delay := s.initial
+	var lastErr error
+	for attempt := 0; attempt < s.maxAttempts; attempt++ {
+		err := func() error {
+			tx, err := s.db.Begin()
+			if err != nil {
+				return err
+			}
+			if err := fn(tx); err != nil {
+				tx.Rollback()
+				return err
+			}
+			return tx.Commit()
+		}()
+		if err == nil {
+			return nil
+		}
+		if !isBusyOrLocked(err) {
+			return err
+		}
+		lastErr = err
+		time.Sleep(delay)
+		delay *= 2
+		if delay > s.max {
+			delay = s.max
+		}
+	}
+	return fmt.Errorf("sqlite: giving up after %d attempts: %w", s.maxAttempts, lastErr)
+}
+
+// isBusyOrLocked reports whether err is a transient SQLITE_BUSY or
+// SQLITE_LOCKED condition from a concurrent writer, as opposed to a
+// persistent error (constraint violation, corruption, ...).
+func isBusyOrLocked(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "SQLITE_BUSY") ||
+		strings.Contains(msg, "SQLITE_LOCKED") ||
+		strings.Contains(msg, "database is locked")
+}
+
+// IsInFlight returns whether the file has an active in_flight record.
+func (s *Store) IsInFlight(path string) (bool, error) {
 	var status string
 	err := s.db.QueryRow(`SELECT status FROM thumbnails WHERE path = ?`, path).Scan(&status)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
 	if err != nil {
-		return false
+		return false, fmt.Errorf("query status for %s: %w", path, err)
 	}
-	return status == "in_flight"
+	return status == "in_flight", nil
 }
 
 // MarkInFlight upserts the path with status in_flight.
-func (s *Store) MarkInFlight(path string) {
+func (s *Store) MarkInFlight(path string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
-	s.db.Exec(
-		`INSERT INTO thumbnails (path, status, updated_at) VALUES (?, 'in_flight', ?)
-		 ON CONFLICT(path) DO UPDATE SET status='in_flight', updated_at=excluded.updated_at`,
-		path, now,
-	)
+	return s.withRetry(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO thumbnails (path, status, updated_at) VALUES (?, 'in_flight', ?)
+			 ON CONFLICT(path) DO UPDATE SET status='in_flight', updated_at=excluded.updated_at`,
+			path, now,
+		)
+		return err
+	})
 }
 
 // MarkCompleted updates the path status to completed.
-func (s *Store) MarkCompleted(path string) {
+func (s *Store) MarkCompleted(path string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
-	s.db.Exec(`UPDATE thumbnails SET status='completed', updated_at=? WHERE path=?`, now, path)
+	return s.withRetry(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`UPDATE thumbnails SET status='completed', updated_at=? WHERE path=?`, now, path)
+		return err
+	})
 }
 
 // MarkErrored updates the path status to errored.
-func (s *Store) MarkErrored(path string) {
+func (s *Store) MarkErrored(path string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	return s.withRetry(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`UPDATE thumbnails SET status='errored', updated_at=? WHERE path=?`, now, path)
+		return err
+	})
+}
+
+// StaleMode is a propagated thumbnail recorded under a propagation mode
+// other than the one currently configured.
+type StaleMode struct {
+	Path string
+	Src  string
+}
+
+// RecordMode upserts the propagation mode used to materialise path from src,
+// so StaleModePaths can later find entries written under a mode that's
+// since changed (e.g. PropagationMode flipped from "copy" to "reflink" once the
+// filesystem started supporting reflinks) and the scanner can rewrite them.
+func (s *Store) RecordMode(path, src, mode string) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	return s.withRetry(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO propagated (path, src, mode, updated_at) VALUES (?, ?, ?, ?)
+			 ON CONFLICT(path) DO UPDATE SET src=excluded.src, mode=excluded.mode, updated_at=excluded.updated_at`,
+			path, src, mode, now,
+		)
+		return err
+	})
+}
+
+// StaleModePaths returns every propagated thumbnail whose recorded mode
+// differs from current.
+func (s *Store) StaleModePaths(current string) ([]StaleMode, error) {
+	rows, err := s.db.Query(`SELECT path, src FROM propagated WHERE mode != ?`, current)
+	if err != nil {
+		return nil, fmt.Errorf("query stale propagation mode entries: %w", err)
+	}
+	defer rows.Close()
+
+	var stale []StaleMode
+	for rows.Next() {
+		var sm StaleMode
+		if err := rows.Scan(&sm.Path, &sm.Src); err != nil {
+			return nil, fmt.Errorf("scan stale propagation mode entry: %w", err)
+		}
+		stale = append(stale, sm)
+	}
+	return stale, rows.Err()
+}
+
+// ReclaimStale flips any in_flight rows whose updated_at is older than
+// maxAge back to errored, so a crashed worker doesn't wedge a path in
+// in_flight forever.
+func (s *Store) ReclaimStale(maxAge time.Duration) error {
+	cutoff := time.Now().UTC().Add(-maxAge).Format(time.RFC3339)
 	now := time.Now().UTC().Format(time.RFC3339)
-	s.db.Exec(`UPDATE thumbnails SET status='errored', updated_at=? WHERE path=?`, now, path)
+	return s.withRetry(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`UPDATE thumbnails SET status='errored', updated_at=? WHERE status='in_flight' AND updated_at < ?`,
+			now, cutoff,
+		)
+		return err
+	})
 }
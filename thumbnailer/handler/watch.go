@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	overseer "github.com/whisper-darkly/sticky-overseer/v2"
+)
+
+// watchedDescriptors is the number of directories currently registered with
+// inotify across this process's watcher, so operators can see when a
+// deployment is approaching the kernel's fs.inotify.max_user_watches limit.
+var watchedDescriptors = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "thumbnailer_watch_descriptors",
+	Help: "Number of directories currently registered with inotify by the thumbnailer scanner.",
+})
+
+// safetyNetInterval is how often scan_mode "hybrid" re-runs a full scan to
+// catch any file or directory event inotify missed, e.g. during a watcher
+// restart or an event-queue overflow.
+const safetyNetInterval = 5 * time.Minute
+
+// globPattern is a glob split into the directory to recurse and the
+// filename pattern to match within it — the same split walkGlob performs,
+// factored out so dirWatcher can apply it to fsnotify events too.
+type globPattern struct {
+	baseDir     string
+	filePattern string
+}
+
+func splitGlob(pattern string) globPattern {
+	parts := strings.SplitN(pattern, "**", 2)
+	baseDir := strings.TrimRight(parts[0], "/")
+	if baseDir == "" {
+		baseDir = "/"
+	}
+	var filePattern string
+	if len(parts) == 2 {
+		filePattern = strings.TrimLeft(parts[1], "/")
+	}
+	return globPattern{baseDir: baseDir, filePattern: filePattern}
+}
+
+func (g globPattern) matches(path string) bool {
+	if g.filePattern == "" {
+		return true
+	}
+	matched, _ := filepath.Match(g.filePattern, filepath.Base(path))
+	return matched
+}
+
+// dirWatcher maintains recursive inotify watches — fsnotify, like inotify
+// itself, doesn't recurse — across every configured glob's base directory,
+// submitting a task whenever a file matching that glob's pattern is created
+// or written to.
+type dirWatcher struct {
+	h        *thumbnailerHandler
+	submit   overseer.TaskSubmitter
+	watcher  *fsnotify.Watcher
+	patterns []globPattern
+
+	mu   sync.Mutex
+	dirs map[string]struct{}
+}
+
+func newDirWatcher(h *thumbnailerHandler, submit overseer.TaskSubmitter) (*dirWatcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("create fsnotify watcher: %w", err)
+	}
+	patterns := make([]globPattern, 0, len(h.cfg.Paths))
+	for _, p := range h.cfg.Paths {
+		patterns = append(patterns, splitGlob(p))
+	}
+	return &dirWatcher{
+		h:        h,
+		submit:   submit,
+		watcher:  w,
+		patterns: patterns,
+		dirs:     make(map[string]struct{}),
+	}, nil
+}
+
+// seed recursively adds a watch on every directory under each configured
+// glob's base directory. Call once before run.
+func (dw *dirWatcher) seed() {
+	for _, p := range dw.patterns {
+		if err := filepath.Walk(p.baseDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info == nil || !info.IsDir() {
+				return nil
+			}
+			dw.addDir(path)
+			return nil
+		}); err != nil {
+			log.Printf("thumbnailer: watch seed %s: %v", p.baseDir, err)
+		}
+	}
+}
+
+func (dw *dirWatcher) addDir(path string) {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	if _, ok := dw.dirs[path]; ok {
+		return
+	}
+	if err := dw.watcher.Add(path); err != nil {
+		log.Printf("thumbnailer: watch %s: %v", path, err)
+		return
+	}
+	dw.dirs[path] = struct{}{}
+	watchedDescriptors.Set(float64(len(dw.dirs)))
+}
+
+func (dw *dirWatcher) removeDir(path string) {
+	dw.mu.Lock()
+	defer dw.mu.Unlock()
+	if _, ok := dw.dirs[path]; !ok {
+		return
+	}
+	dw.watcher.Remove(path) //nolint:errcheck
+	delete(dw.dirs, path)
+	watchedDescriptors.Set(float64(len(dw.dirs)))
+}
+
+// matchingPattern returns the glob pattern whose base directory contains
+// path, if any.
+func (dw *dirWatcher) matchingPattern(path string) (globPattern, bool) {
+	for _, p := range dw.patterns {
+		if strings.HasPrefix(path, p.baseDir) {
+			return p, true
+		}
+	}
+	return globPattern{}, false
+}
+
+// run processes fsnotify events until ctx is cancelled.
+func (dw *dirWatcher) run(ctx context.Context) {
+	defer dw.watcher.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-dw.watcher.Events:
+			if !ok {
+				return
+			}
+			dw.handleEvent(ev)
+		case err, ok := <-dw.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("thumbnailer: watch error: %v", err)
+		}
+	}
+}
+
+func (dw *dirWatcher) handleEvent(ev fsnotify.Event) {
+	switch {
+	case ev.Op&fsnotify.Create != 0:
+		if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+			dw.addDir(ev.Name)
+			return
+		}
+		dw.maybeSubmit(ev.Name)
+	case ev.Op&fsnotify.Write != 0:
+		// fsnotify has no direct CLOSE_WRITE: Write fires on every buffered
+		// write to the file, not just the last one. maybeSubmit's in_flight
+		// check in Store skips Write events only while a task for the file
+		// is marked in_flight (from the worker's MarkInFlight until it
+		// finishes); a Write before or after that submits the file again,
+		// so a file that is still being written can be submitted many times.
+		dw.maybeSubmit(ev.Name)
+	case ev.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+		dw.removeDir(ev.Name)
+	}
+}
+
+func (dw *dirWatcher) maybeSubmit(path string) {
+	pattern, ok := dw.matchingPattern(path)
+	if !ok || !pattern.matches(path) {
+		return
+	}
+	inFlight, err := dw.h.store.IsInFlight(path)
+	if err != nil {
+		log.Printf("thumbnailer: check in_flight for %s: %v", path, err)
+		return
+	}
+	if inFlight {
+		return
+	}
+	dw.submit.Submit(dw.h.actionName, "", map[string]string{"file": path}) //nolint:errcheck
+}
@@ -0,0 +1,131 @@
+// Package fsutil provides filesystem helpers for materialising a copy of a
+// file as cheaply as the underlying filesystem allows: a hard link when
+// possible, a reflink (copy-on-write clone) when the filesystem supports it,
+// and a byte-for-byte copy only as a last resort across devices. Modelled on
+// the link/reflink/copy fallback chain used by buildah's copier package.
+package fsutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// Mode selects how Materialise propagates a file to a new path.
+type Mode string
+
+const (
+	// ModeAuto tries Link, then Reflink, then Copy, moving on from Link only
+	// when it fails with EXDEV, and trying Reflink only when src and dst's
+	// directory are on the same device (see materialiseAuto). It's the default and the right choice unless a deployment
+	// needs to force one strategy (e.g. to avoid silently falling back to
+	// slow copies on a filesystem that doesn't support reflinks).
+	ModeAuto    Mode = "auto"
+	ModeLink    Mode = "link"
+	ModeReflink Mode = "reflink"
+	ModeCopy    Mode = "copy"
+)
+
+// ParseMode validates s against the known modes, defaulting "" to ModeAuto.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "":
+		return ModeAuto, nil
+	case ModeAuto, ModeLink, ModeReflink, ModeCopy:
+		return Mode(s), nil
+	default:
+		return "", fmt.Errorf("fsutil: unknown propagation mode %q", s)
+	}
+}
+
+// Materialise makes dst a copy of src using mode, writing to dst+".tmp" and
+// renaming into place so a concurrent reader never observes a truncated or
+// partially-written file. It returns the mode actually used, which for
+// ModeAuto may differ from the requested mode (e.g. "link" once a hard link
+// succeeds). For ModeLink/ModeReflink/ModeCopy specifically, Materialise
+// returns an error rather than silently falling back to a different
+// strategy, since the caller asked for that one explicitly.
+func Materialise(mode Mode, src, dst string) (Mode, error) {
+	tmp := dst + ".tmp"
+	os.Remove(tmp) // best-effort: clear any leftover tmp file from a crashed run
+
+	switch mode {
+	case ModeLink:
+		if err := link(src, tmp); err != nil {
+			return "", fmt.Errorf("fsutil: link %s -> %s: %w", src, dst, err)
+		}
+		return ModeLink, rename(tmp, dst)
+	case ModeReflink:
+		if err := reflink(src, tmp); err != nil {
+			return "", fmt.Errorf("fsutil: reflink %s -> %s: %w", src, dst, err)
+		}
+		return ModeReflink, rename(tmp, dst)
+	case ModeCopy:
+		if err := byteCopy(src, tmp); err != nil {
+			return "", fmt.Errorf("fsutil: copy %s -> %s: %w", src, dst, err)
+		}
+		return ModeCopy, rename(tmp, dst)
+	case ModeAuto, "":
+		return materialiseAuto(src, dst, tmp)
+	default:
+		return "", fmt.Errorf("fsutil: unknown propagation mode %q", mode)
+	}
+}
+
+// materialiseAuto tries the cheapest strategy first, falling back only when
+// the previous one is unsupported for this (src, dst) pair — a Link that
+// fails for some other reason (e.g. src missing) is a real error, not a
+// signal to try the next strategy.
+func materialiseAuto(src, dst, tmp string) (Mode, error) {
+	err := link(src, tmp)
+	if err == nil {
+		return ModeLink, rename(tmp, dst)
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return "", fmt.Errorf("fsutil: link %s -> %s: %w", src, dst, err)
+	}
+
+	if sameDevice(src, filepath.Dir(dst)) {
+		if err := reflink(src, tmp); err == nil {
+			return ModeReflink, rename(tmp, dst)
+		}
+	}
+
+	if err := byteCopy(src, tmp); err != nil {
+		return "", fmt.Errorf("fsutil: copy %s -> %s: %w", src, dst, err)
+	}
+	return ModeCopy, rename(tmp, dst)
+}
+
+func link(src, dst string) error {
+	return os.Link(src, dst)
+}
+
+func rename(tmp, dst string) error {
+	if err := os.Rename(tmp, dst); err != nil {
+		return fmt.Errorf("fsutil: rename %s -> %s: %w", tmp, dst, err)
+	}
+	return nil
+}
+
+func byteCopy(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Close()
+}
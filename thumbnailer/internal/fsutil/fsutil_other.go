@@ -0,0 +1,18 @@
+//go:build !linux
+
+package fsutil
+
+import "errors"
+
+// reflink is unsupported outside Linux; materialiseAuto falls back to a
+// byte copy whenever this returns an error, and ModeReflink surfaces it
+// directly to the caller.
+func reflink(src, dst string) error {
+	return errors.New("fsutil: reflink unsupported on this platform")
+}
+
+// sameDevice conservatively reports false off Linux, since reflink isn't
+// implemented there anyway.
+func sameDevice(path, dir string) bool {
+	return false
+}